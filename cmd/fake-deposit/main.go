@@ -0,0 +1,75 @@
+// Команда fake-deposit отправляет в запущенный сервис подписанное уведомление локального
+// провайдера о пополнении, как это сделал бы платёжный провайдер. Секрет подписи и порт
+// берутся из .env (FAKE_PROVIDER_SECRET, PORT).
+//
+//	go run ./cmd/fake-deposit -reference GW1A2B3C4D5E -amount 100 -currency USD
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/config"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/providers"
+	"github.com/google/uuid"
+)
+
+func main() {
+	config, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	url := flag.String("url", "http://localhost:"+config.Port, "Base URL of the wallet service")
+	eventID := flag.String("event", uuid.NewString(), "Provider event ID (reuse it to test deduplication)")
+	reference := flag.String("reference", "", "User deposit reference")
+	amount := flag.Float64("amount", 0, "Deposit amount")
+	currency := flag.String("currency", "USD", "Deposit currency")
+	flag.Parse()
+
+	if config.FakeProviderSecret == "" {
+		fmt.Fprintln(os.Stderr, "FAKE_PROVIDER_SECRET is not set")
+		os.Exit(1)
+	}
+
+	provider := providers.NewFakeProvider(config.FakeProviderSecret)
+	payload, signature, err := provider.Deposit(&models.DepositEvent{
+		EventID:   *eventID,
+		Reference: *reference,
+		Amount:    *amount,
+		Currency:  *currency,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build event: %v\n", err)
+		os.Exit(1)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, *url+"/api/v1/webhooks/deposits/"+provider.Name(), bytes.NewReader(payload))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to build request: %v\n", err)
+		os.Exit(1)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Signature", signature)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to send event: %v\n", err)
+		os.Exit(1)
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	fmt.Printf("%s %s\n", response.Status, body)
+	if response.StatusCode != http.StatusOK {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,55 @@
+// Команда verify-journal проверяет целостность журнала транзакций: цепочки хешей проводок
+// и контрольные точки. Печатает результат в JSON и завершается с кодом 1, если найдено нарушение.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/config"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/db"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/logger"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/utils"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	logger := logger.InitLogger()
+
+	config, err := config.LoadConfig()
+	if err != nil {
+		logger.Fatalf("Could not load config: %v", err)
+	}
+
+	dbase, err := db.Init(config)
+	if err != nil {
+		logger.Fatalf("Could not initialize DB connection: %v", err)
+	}
+	defer db.Close(dbase)
+
+	repo := repository.NewRepository(dbase, logger)
+	// Проверке журнала не нужны сервис курсов валют и провайдеры пополнений и выплат.
+	service := services.NewService(repo, nil, nil, nil, nil, nil, utils.NewManager(config), logger)
+
+	result, err := service.VerifyJournal(context.Background())
+	if err != nil {
+		logger.Fatalf("Failed to verify journal: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		logger.Fatalf("Failed to print result: %v", err)
+	}
+
+	if !result.Valid {
+		return 1
+	}
+	return 0
+}
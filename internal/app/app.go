@@ -1,13 +1,19 @@
 package app
 
 import (
+	"context"
+
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/config"
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/db"
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/delivery/handlers"
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/delivery/routes"
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/grpc"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/mailers"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/providers"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/publishers"
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/workers"
 	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/logger"
 	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/migrator"
 	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/utils"
@@ -49,7 +55,229 @@ func Run() {
 
 	tokenManager := utils.NewManager(config)
 
-	service := services.NewService(repo, grpcClient, tokenManager, logger)
+	// Тенанты со своими ключами подписывают токены ими, остальные - ключами из конфигурации.
+	tenants, err := repo.GetTenants(context.Background())
+	if err != nil {
+		logger.Fatalf("Failed to load tenants: %v", err)
+	}
+	for _, t := range tenants {
+		if t.JWTPublicKeyPath != "" && t.JWTPrivateKeyPath != "" {
+			tokenManager.TenantKeys[t.ID] = utils.KeyPair{PublicKey: t.JWTPublicKeyPath, PrivateKey: t.JWTPrivateKeyPath}
+		}
+	}
+
+	// Платёжные провайдеры входящих пополнений. Локальный провайдер подключается
+	// только при заданном секрете подписи.
+	var depositProviders []services.DepositProvider
+	if config.FakeProviderSecret != "" {
+		depositProviders = append(depositProviders, providers.NewFakeProvider(config.FakeProviderSecret))
+	}
+
+	// Провайдер выплат для вывода средств; без него вывод через /withdrawals недоступен.
+	var payout services.PayoutProvider
+	switch config.PayoutProvider {
+	case "":
+	case providers.LocalPayoutName:
+		payout = providers.NewLocalPayout(config.LocalPayoutDelay)
+	default:
+		logger.Fatalf("Unknown payout provider: %s", config.PayoutProvider)
+	}
+
+	// Брокер доменных событий; без него события копятся в outbox и публикуются после подключения.
+	var publisher services.EventPublisher
+	switch config.EventPublisher {
+	case "":
+	case publishers.MemoryPublisherName:
+		publisher = publishers.NewMemoryPublisher()
+	case publishers.FilePublisherName:
+		publisher = publishers.NewFilePublisher(config.EventFilePath)
+	case publishers.NATSPublisherName:
+		nats, err := publishers.NewNATSPublisher(config.NATSURL, config.EventSubjectPrefix)
+		if err != nil {
+			logger.Fatalf("Failed to create NATS publisher: %v", err)
+		}
+		defer nats.Close()
+		publisher = nats
+	default:
+		logger.Fatalf("Unknown event publisher: %s", config.EventPublisher)
+	}
+
+	// Отправка писем с уведомлениями; без неё письма ждут в очереди до подключения.
+	var mailer services.Mailer
+	switch config.Mailer {
+	case "":
+	case mailers.LogMailerName:
+		mailer = mailers.NewLogMailer(logger)
+	case mailers.SMTPMailerName:
+		mailer = mailers.NewSMTPMailer(config.SMTPAddr, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+	default:
+		logger.Fatalf("Unknown mailer: %s", config.Mailer)
+	}
+
+	service := services.NewService(repo, grpcClient, depositProviders, payout, publisher, mailer, tokenManager, logger)
+
+	// Фоновые задачи
+	runner := workers.NewRunner(logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		runner.Wait()
+	}()
+
+	runner.Add(workers.Job{
+		Name:     "expire-holds",
+		Interval: config.HoldExpiryInterval,
+		Run: func(ctx context.Context) error {
+			expired, err := service.ExpireHolds(ctx)
+			if expired > 0 {
+				logger.Infof("Expired %d holds", expired)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "run-schedules",
+		Interval: config.SchedulePollInterval,
+		Run: func(ctx context.Context) error {
+			processed, err := service.RunDueSchedules(ctx)
+			if processed > 0 {
+				logger.Infof("Processed %d scheduled transfers", processed)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "match-limit-orders",
+		Interval: config.LimitOrderPollInterval,
+		Run: func(ctx context.Context) error {
+			expired, err := service.ExpireLimitOrders(ctx)
+			if err != nil {
+				return err
+			}
+			if expired > 0 {
+				logger.Infof("Expired %d limit orders", expired)
+			}
+
+			filled, err := service.MatchLimitOrders(ctx)
+			if filled > 0 {
+				logger.Infof("Filled %d limit orders", filled)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "reconcile-balances",
+		Interval: config.ReconciliationInterval,
+		Run: func(ctx context.Context) error {
+			run, err := service.RunReconciliation(ctx)
+			if run != nil {
+				logger.Infof("Reconciled %d wallets, %d mismatches", run.WalletsChecked, run.Mismatches)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "journal-checkpoint",
+		Interval: config.CheckpointInterval,
+		Run: func(ctx context.Context) error {
+			_, err := service.CreateJournalCheckpoint(ctx)
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "accrue-overdraft",
+		Interval: config.OverdraftInterval,
+		Run: func(ctx context.Context) error {
+			accrued, err := service.AccrueOverdraftCharges(ctx)
+			if accrued > 0 {
+				logger.Infof("Accrued overdraft charges for %d credit lines", accrued)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "accrue-interest",
+		Interval: config.InterestInterval,
+		Run: func(ctx context.Context) error {
+			processed, err := service.AccrueInterest(ctx)
+			if processed > 0 {
+				logger.Infof("Accrued or paid interest for %d wallets", processed)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "expire-payment-requests",
+		Interval: config.PaymentRequestInterval,
+		Run: func(ctx context.Context) error {
+			expired, err := service.ExpirePaymentRequests(ctx)
+			if expired > 0 {
+				logger.Infof("Expired %d payment requests", expired)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "process-withdrawals",
+		Interval: config.WithdrawalPollInterval,
+		Run: func(ctx context.Context) error {
+			processed, err := service.ProcessWithdrawals(ctx)
+			if processed > 0 {
+				logger.Infof("Updated %d withdrawals", processed)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "relay-outbox",
+		Interval: config.OutboxRelayInterval,
+		Run: func(ctx context.Context) error {
+			published, err := service.RelayOutbox(ctx)
+			if published > 0 {
+				logger.Infof("Published %d events", published)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "deliver-webhooks",
+		Interval: config.WebhookPollInterval,
+		Run: func(ctx context.Context) error {
+			delivered, err := service.DeliverWebhooks(ctx)
+			if delivered > 0 {
+				logger.Infof("Delivered %d webhooks", delivered)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "send-notifications",
+		Interval: config.NotificationInterval,
+		Run: func(ctx context.Context) error {
+			sent, err := service.DeliverNotificationEmails(ctx)
+			if sent > 0 {
+				logger.Infof("Sent %d notification emails", sent)
+			}
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "poll-stream-events",
+		Interval: config.StreamPollInterval,
+		Run: func(ctx context.Context) error {
+			_, err := service.PollStreamEvents(ctx)
+			return err
+		},
+	})
+	runner.Add(workers.Job{
+		Name:     "poll-stream-rates",
+		Interval: config.StreamRatesInterval,
+		Run: func(ctx context.Context) error {
+			_, err := service.PollStreamRates(ctx)
+			return err
+		},
+	})
+	runner.Start(ctx)
 
 	handler := handlers.NewHandler(service, &tokenManager, logger)
 
@@ -23,6 +23,32 @@ type Config struct {
 	RefreshTokenExpiration time.Duration
 	GRPCExchangeHost       string
 	GRPCExchangePort       string
+	HoldExpiryInterval     time.Duration
+	SchedulePollInterval   time.Duration
+	LimitOrderPollInterval time.Duration
+	ReconciliationInterval time.Duration
+	CheckpointInterval     time.Duration
+	OverdraftInterval      time.Duration
+	InterestInterval       time.Duration
+	PaymentRequestInterval time.Duration
+	FakeProviderSecret     string
+	PayoutProvider         string
+	LocalPayoutDelay       time.Duration
+	WithdrawalPollInterval time.Duration
+	EventPublisher         string
+	EventFilePath          string
+	NATSURL                string
+	EventSubjectPrefix     string
+	OutboxRelayInterval    time.Duration
+	WebhookPollInterval    time.Duration
+	StreamPollInterval     time.Duration
+	StreamRatesInterval    time.Duration
+	Mailer                 string
+	SMTPAddr               string
+	SMTPUsername           string
+	SMTPPassword           string
+	MailFrom               string
+	NotificationInterval   time.Duration
 }
 
 // LoadConfig загружает переменные конфигурации из файла .env.
@@ -41,6 +67,22 @@ func LoadConfig() (*Config, error) {
 		log.Fatalf("Invalid REFRESH_TOKEN_EXPIRATION format: %v", err)
 	}
 
+	holdExpiryInterval := durationOrDefault("HOLD_EXPIRY_INTERVAL", time.Minute)
+	schedulePollInterval := durationOrDefault("SCHEDULE_POLL_INTERVAL", 30*time.Second)
+	limitOrderPollInterval := durationOrDefault("LIMIT_ORDER_POLL_INTERVAL", 15*time.Second)
+	reconciliationInterval := durationOrDefault("RECONCILIATION_INTERVAL", time.Hour)
+	checkpointInterval := durationOrDefault("JOURNAL_CHECKPOINT_INTERVAL", time.Hour)
+	overdraftInterval := durationOrDefault("OVERDRAFT_ACCRUAL_INTERVAL", time.Hour)
+	interestInterval := durationOrDefault("INTEREST_ACCRUAL_INTERVAL", time.Hour)
+	paymentRequestInterval := durationOrDefault("PAYMENT_REQUEST_EXPIRY_INTERVAL", time.Minute)
+	localPayoutDelay := durationOrDefault("LOCAL_PAYOUT_DELAY", time.Minute)
+	withdrawalPollInterval := durationOrDefault("WITHDRAWAL_POLL_INTERVAL", 30*time.Second)
+	outboxRelayInterval := durationOrDefault("OUTBOX_RELAY_INTERVAL", 5*time.Second)
+	webhookPollInterval := durationOrDefault("WEBHOOK_POLL_INTERVAL", 5*time.Second)
+	streamPollInterval := durationOrDefault("STREAM_POLL_INTERVAL", time.Second)
+	streamRatesInterval := durationOrDefault("STREAM_RATES_INTERVAL", 5*time.Second)
+	notificationInterval := durationOrDefault("NOTIFICATION_POLL_INTERVAL", 10*time.Second)
+
 	return &Config{
 		Port:                   os.Getenv("PORT"),
 		DBHost:                 os.Getenv("DB_HOST"),
@@ -54,9 +96,59 @@ func LoadConfig() (*Config, error) {
 		RefreshTokenExpiration: refreshTokenExpiration,
 		GRPCExchangeHost:       os.Getenv("GRPC_EXCHANGE_HOST"),
 		GRPCExchangePort:       os.Getenv("GRPC_EXCHANGE_PORT"),
+		HoldExpiryInterval:     holdExpiryInterval,
+		SchedulePollInterval:   schedulePollInterval,
+		LimitOrderPollInterval: limitOrderPollInterval,
+		ReconciliationInterval: reconciliationInterval,
+		CheckpointInterval:     checkpointInterval,
+		OverdraftInterval:      overdraftInterval,
+		InterestInterval:       interestInterval,
+		PaymentRequestInterval: paymentRequestInterval,
+		FakeProviderSecret:     os.Getenv("FAKE_PROVIDER_SECRET"),
+		PayoutProvider:         os.Getenv("PAYOUT_PROVIDER"),
+		LocalPayoutDelay:       localPayoutDelay,
+		WithdrawalPollInterval: withdrawalPollInterval,
+		EventPublisher:         os.Getenv("EVENT_PUBLISHER"),
+		EventFilePath:          stringOrDefault("EVENT_FILE_PATH", "events.jsonl"),
+		NATSURL:                stringOrDefault("NATS_URL", "nats://localhost:4222"),
+		EventSubjectPrefix:     stringOrDefault("EVENT_SUBJECT_PREFIX", "events"),
+		OutboxRelayInterval:    outboxRelayInterval,
+		WebhookPollInterval:    webhookPollInterval,
+		StreamPollInterval:     streamPollInterval,
+		StreamRatesInterval:    streamRatesInterval,
+		Mailer:                 os.Getenv("MAILER"),
+		SMTPAddr:               stringOrDefault("SMTP_ADDR", "localhost:25"),
+		SMTPUsername:           os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:           os.Getenv("SMTP_PASSWORD"),
+		MailFrom:               stringOrDefault("MAIL_FROM", "wallet@localhost"),
+		NotificationInterval:   notificationInterval,
 	}, nil
 }
 
+// durationOrDefault читает длительность из переменной окружения key,
+// возвращая def, если переменная не задана.
+func durationOrDefault(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid %s format: %v", key, err)
+	}
+	return duration
+}
+
+// stringOrDefault читает строку из переменной окружения key,
+// возвращая def, если переменная не задана.
+func stringOrDefault(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
 // GetAuthJWTPublicKeyPath возвращает путь к публичному ключу JWT.
 func (cfg *Config) GetAuthJWTPublicKeyPath() string {
 	return cfg.AuthJWTPublicKeyPath
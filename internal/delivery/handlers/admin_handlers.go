@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireAdmin пропускает запрос дальше только для пользователей с ролью администратора.
+// Роль проверяется по БД, а не по токену, чтобы её отзыв действовал сразу.
+func (h *handler) RequireAdmin(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	user, err := h.service.GetUserByID(ctx.UserContext(), userID)
+	if err != nil || user.Role != models.RoleAdmin {
+		h.logger.Errorf("User %d is not an administrator", userID)
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Forbidden",
+		})
+	}
+
+	return ctx.Next()
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminGetCreditLine возвращает кредитную линию кошелька.
+// @Summary Get wallet credit line (admin)
+// @Description Возвращает лимит овердрафта кошелька, ставку, ежедневную плату и дату последнего начисления.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Success 200 {object} models.CreditLineResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Credit line not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/wallets/{id}/credit-line [get]
+func (h *handler) AdminGetCreditLine(ctx *fiber.Ctx) error {
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	line, err := h.service.GetCreditLine(ctxWithTimeout, walletID)
+	if err != nil {
+		h.logger.Errorf("Failed to get credit line of wallet %d: %v", walletID, err)
+		return ctx.Status(creditLineErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.CreditLineResponse{CreditLine: line})
+}
+
+// AdminSetCreditLine открывает кредитную линию кошелька или изменяет её условия.
+// @Summary Set wallet credit line (admin)
+// @Description Открывает овердрафт кошелька или изменяет его условия. Баланс может уйти в минус не более чем на limit;
+// @Description на задолженность ежедневно начисляются проценты по annual_rate (доля, 0.18 - 18% годовых) и плата daily_fee.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param credit_line body models.CreditLineRequest true "Credit line terms"
+// @Success 200 {object} models.CreditLineResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/wallets/{id}/credit-line [put]
+func (h *handler) AdminSetCreditLine(ctx *fiber.Ctx) error {
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.CreditLineRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	line, err := h.service.SetCreditLine(ctxWithTimeout, walletID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to set credit line of wallet %d: %v", walletID, err)
+		return ctx.Status(creditLineErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.CreditLineResponse{
+		Message:    "Credit line updated successfully",
+		CreditLine: line,
+	})
+}
+
+// AdminDeleteCreditLine закрывает кредитную линию кошелька.
+// @Summary Close wallet credit line (admin)
+// @Description Закрывает овердрафт кошелька. Линию с непогашенной задолженностью закрыть нельзя.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Success 200 {object} models.CreditLineResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Credit line not found"
+// @Failure 409 {object} models.ErrorResponse "Outstanding debt"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/wallets/{id}/credit-line [delete]
+func (h *handler) AdminDeleteCreditLine(ctx *fiber.Ctx) error {
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	if err := h.service.DeleteCreditLine(ctxWithTimeout, walletID); err != nil {
+		h.logger.Errorf("Failed to close credit line of wallet %d: %v", walletID, err)
+		return ctx.Status(creditLineErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.CreditLineResponse{
+		Message: "Credit line closed successfully",
+	})
+}
+
+// creditLineErrorStatus сопоставляет ошибки кредитных линий с HTTP-статусами.
+func creditLineErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCreditLineNotFound), errors.Is(err, services.ErrWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrCreditLineInUse):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrInvalidCreditLine):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
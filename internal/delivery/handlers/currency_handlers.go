@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// currencyErrorStatus сопоставляет ошибки справочника валют с HTTP-статусами.
+func currencyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCurrencyNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrCurrencyExists):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrInvalidCurrency):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// GetCurrencies возвращает включённые валюты.
+// @Summary List currencies
+// @Description Возвращает включённые валюты справочника с метаданными ISO 4217.
+// @Tags Currencies
+// @Produce json
+// @Success 200 {object} models.CurrenciesResponse
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/v1/currencies [get]
+func (h *handler) GetCurrencies(ctx *fiber.Ctx) error {
+	return h.listCurrencies(ctx, false)
+}
+
+// AdminGetCurrencies возвращает все валюты, включая отключённые.
+// @Summary List all currencies (admin)
+// @Description Возвращает все валюты справочника, включая отключённые.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.CurrenciesResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/currencies [get]
+func (h *handler) AdminGetCurrencies(ctx *fiber.Ctx) error {
+	return h.listCurrencies(ctx, true)
+}
+
+func (h *handler) listCurrencies(ctx *fiber.Ctx, includeDisabled bool) error {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	currencies, err := h.service.GetCurrencies(ctxWithTimeout, includeDisabled)
+	if err != nil {
+		h.logger.Errorf("Failed to get currencies: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get currencies",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.CurrenciesResponse{Currencies: currencies})
+}
+
+// AdminCreateCurrency добавляет валюту в справочник.
+// @Summary Create currency (admin)
+// @Description Добавляет валюту в справочник. По умолчанию валюта включена и имеет 2 знака после запятой.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param currency body models.CurrencyRequest true "Currency"
+// @Success 201 {object} models.CurrencyResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 409 {object} models.ErrorResponse "Currency already exists"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/currencies [post]
+func (h *handler) AdminCreateCurrency(ctx *fiber.Ctx) error {
+	var request models.CurrencyRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	currency, err := h.service.CreateCurrency(ctxWithTimeout, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to create currency %s: %v", request.Code, err)
+		return ctx.Status(currencyErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.CurrencyResponse{
+		Message:  "Currency created successfully",
+		Currency: currency,
+	})
+}
+
+// AdminUpdateCurrency изменяет валюту справочника.
+// @Summary Update currency (admin)
+// @Description Изменяет метаданные валюты или включает/отключает её. Незаданные поля не меняются.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Currency code"
+// @Param currency body models.CurrencyRequest true "Currency changes"
+// @Success 200 {object} models.CurrencyResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Currency not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/currencies/{code} [put]
+func (h *handler) AdminUpdateCurrency(ctx *fiber.Ctx) error {
+	var request models.CurrencyRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	code := ctx.Params("code")
+	currency, err := h.service.UpdateCurrency(ctxWithTimeout, code, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to update currency %s: %v", code, err)
+		return ctx.Status(currencyErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.CurrencyResponse{
+		Message:  "Currency updated successfully",
+		Currency: currency,
+	})
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SignatureHeader - заголовок с HMAC-подписью тела уведомления провайдера.
+const SignatureHeader = "X-Signature"
+
+// depositWebhookErrorStatus сопоставляет ошибки обработки уведомлений провайдеров с HTTP-статусами.
+func depositWebhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUnknownProvider):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrInvalidSignature):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, services.ErrInvalidDepositEvent), errors.Is(err, services.ErrInvalidDepositStatus):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// HandleDepositWebhook принимает уведомление платёжного провайдера о входящем пополнении.
+// @Summary Deposit webhook
+// @Description Принимает уведомление провайдера о пополнении, подписанное HMAC-SHA256 тела запроса в заголовке X-Signature. Пополнение зачисляется на кошелёк пользователя с указанным кодом пополнения. Повторная доставка того же события не зачисляется повторно. Уведомления, которые нельзя зачислить, сохраняются для ручного разбора и также подтверждаются ответом 200.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param X-Signature header string true "HMAC-SHA256 signature of the request body"
+// @Param request body models.DepositEvent true "Deposit event (fake provider format)"
+// @Success 200 {object} models.DepositWebhookResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid event"
+// @Failure 401 {object} models.ErrorResponse "Invalid signature"
+// @Failure 404 {object} models.ErrorResponse "Unknown provider"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Router /api/v1/webhooks/deposits/{provider} [post]
+func (h *handler) HandleDepositWebhook(ctx *fiber.Ctx) error {
+	provider := ctx.Params("provider")
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	deposit, duplicate, err := h.service.HandleProviderDeposit(ctxWithTimeout, provider, ctx.Body(), ctx.Get(SignatureHeader))
+	if err != nil {
+		h.logger.Errorf("Failed to handle deposit webhook from %s: %v", provider, err)
+		return ctx.Status(depositWebhookErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.DepositWebhookResponse{
+		Status:    deposit.Status,
+		Duplicate: duplicate,
+	})
+}
+
+// GetDepositReference возвращает код пополнения пользователя.
+// @Summary Get deposit reference
+// @Description Возвращает код, который нужно указать в назначении платежа при пополнении через платёжного провайдера.
+// @Tags Wallet
+// @Produce json
+// @Success 200 {object} models.DepositReferenceResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallet/deposit-reference [get]
+func (h *handler) GetDepositReference(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	reference, err := h.service.GetDepositReference(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get deposit reference for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get deposit reference",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.DepositReferenceResponse{Reference: reference})
+}
+
+// AdminGetProviderDeposits возвращает последние пополнения от платёжных провайдеров.
+// @Summary List provider deposits (admin)
+// @Description Возвращает последние уведомления провайдеров о пополнениях. С параметром status=unmatched или status=rejected - только пополнения, требующие ручного разбора.
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Filter by status (received, credited, unmatched, rejected)"
+// @Success 200 {object} models.ProviderDepositsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/deposits [get]
+func (h *handler) AdminGetProviderDeposits(ctx *fiber.Ctx) error {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	deposits, err := h.service.GetProviderDeposits(ctxWithTimeout, ctx.Query("status"))
+	if err != nil {
+		h.logger.Errorf("Failed to get provider deposits: %v", err)
+		return ctx.Status(depositWebhookErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.ProviderDepositsResponse{Deposits: deposits})
+}
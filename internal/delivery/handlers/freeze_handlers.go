@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminFreezeUser замораживает пользователя.
+// @Summary Freeze user (admin)
+// @Description Замораживает все кошельки пользователя: full запрещает любые операции и вход, debit - только списания.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param freeze body models.FreezeRequest true "Freeze state and reason"
+// @Success 200 {object} models.FreezeResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 409 {object} models.ErrorResponse "State is already set"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/users/{id}/freeze [post]
+func (h *handler) AdminFreezeUser(ctx *fiber.Ctx) error {
+	return h.changeFreeze(ctx, models.FreezeTargetUser, false)
+}
+
+// AdminUnfreezeUser снимает заморозку пользователя.
+// @Summary Unfreeze user (admin)
+// @Description Снимает заморозку пользователя. Поле state игнорируется.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param freeze body models.FreezeRequest true "Reason"
+// @Success 200 {object} models.FreezeResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "User not found"
+// @Failure 409 {object} models.ErrorResponse "User is not frozen"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/users/{id}/unfreeze [post]
+func (h *handler) AdminUnfreezeUser(ctx *fiber.Ctx) error {
+	return h.changeFreeze(ctx, models.FreezeTargetUser, true)
+}
+
+// AdminFreezeWallet замораживает кошелёк.
+// @Summary Freeze wallet (admin)
+// @Description Замораживает кошелёк: full запрещает любые операции, debit - только списания.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param freeze body models.FreezeRequest true "Freeze state and reason"
+// @Success 200 {object} models.FreezeResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 409 {object} models.ErrorResponse "State is already set"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/wallets/{id}/freeze [post]
+func (h *handler) AdminFreezeWallet(ctx *fiber.Ctx) error {
+	return h.changeFreeze(ctx, models.FreezeTargetWallet, false)
+}
+
+// AdminUnfreezeWallet снимает заморозку кошелька.
+// @Summary Unfreeze wallet (admin)
+// @Description Снимает заморозку кошелька. Поле state игнорируется.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param freeze body models.FreezeRequest true "Reason"
+// @Success 200 {object} models.FreezeResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 409 {object} models.ErrorResponse "Wallet is not frozen"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/wallets/{id}/unfreeze [post]
+func (h *handler) AdminUnfreezeWallet(ctx *fiber.Ctx) error {
+	return h.changeFreeze(ctx, models.FreezeTargetWallet, true)
+}
+
+// AdminGetUserFreezeEvents возвращает журнал заморозок пользователя.
+// @Summary Get user freeze history (admin)
+// @Description Возвращает историю заморозок пользователя с операторами и причинами.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.FreezeEventsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/users/{id}/freeze-events [get]
+func (h *handler) AdminGetUserFreezeEvents(ctx *fiber.Ctx) error {
+	return h.getFreezeEvents(ctx, models.FreezeTargetUser)
+}
+
+// AdminGetWalletFreezeEvents возвращает журнал заморозок кошелька.
+// @Summary Get wallet freeze history (admin)
+// @Description Возвращает историю заморозок кошелька с операторами и причинами.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Success 200 {object} models.FreezeEventsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/wallets/{id}/freeze-events [get]
+func (h *handler) AdminGetWalletFreezeEvents(ctx *fiber.Ctx) error {
+	return h.getFreezeEvents(ctx, models.FreezeTargetWallet)
+}
+
+// changeFreeze изменяет состояние заморозки пользователя или кошелька; unfreeze снимает заморозку.
+func (h *handler) changeFreeze(ctx *fiber.Ctx, target string, unfreeze bool) error {
+	operatorID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.FreezeRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+	if unfreeze {
+		request.State = models.FreezeStateNone
+	} else if request.State == models.FreezeStateNone {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": services.ErrInvalidFreeze.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	var event *models.FreezeEvent
+	if target == models.FreezeTargetUser {
+		event, err = h.service.SetUserFreeze(ctxWithTimeout, operatorID, id, request.State, request.Reason)
+	} else {
+		event, err = h.service.SetWalletFreeze(ctxWithTimeout, operatorID, id, request.State, request.Reason)
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to change freeze state of %s %d: %v", target, id, err)
+		return ctx.Status(freezeErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.FreezeResponse{
+		Message: "Freeze state updated successfully",
+		Event:   event,
+	})
+}
+
+func (h *handler) getFreezeEvents(ctx *fiber.Ctx, target string) error {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	events, err := h.service.GetFreezeEvents(ctxWithTimeout, target, id)
+	if err != nil {
+		h.logger.Errorf("Failed to get freeze events of %s %d: %v", target, id, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get freeze events",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.FreezeEventsResponse{Events: events})
+}
+
+// freezeErrorStatus сопоставляет ошибки изменения заморозки с HTTP-статусами.
+func freezeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrFreezeUnchanged):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrInvalidFreeze), errors.Is(err, services.ErrReasonRequired):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// isFrozen сообщает, отклонена ли операция из-за заморозки пользователя или кошелька.
+func isFrozen(err error) bool {
+	return errors.Is(err, services.ErrAccountFrozen) || errors.Is(err, services.ErrWalletFrozen)
+}
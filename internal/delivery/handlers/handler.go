@@ -9,14 +9,119 @@ import (
 
 // HandlerInterface определяет интерфейс для обработчиков.
 type HandlerInterface interface {
+	ResolveTenant(ctx *fiber.Ctx) error
+
 	RegisterUser(ctx *fiber.Ctx) error
 	LoginUser(ctx *fiber.Ctx) error
 
 	GetBalance(ctx *fiber.Ctx) error
+	GetBalanceValuation(ctx *fiber.Ctx) error
 	Deposit(ctx *fiber.Ctx) error
 	Withdraw(ctx *fiber.Ctx) error
+	GetDepositReference(ctx *fiber.Ctx) error
+	GetAccruedInterest(ctx *fiber.Ctx) error
+	RedeemVoucher(ctx *fiber.Ctx) error
+	HandleDepositWebhook(ctx *fiber.Ctx) error
 	GetExchangeRates(ctx *fiber.Ctx) error
 	ExchangeCurrency(ctx *fiber.Ctx) error
+	PreviewRebalance(ctx *fiber.Ctx) error
+	ConfirmRebalance(ctx *fiber.Ctx) error
+	GetCurrencies(ctx *fiber.Ctx) error
+
+	ExportStatement(ctx *fiber.Ctx) error
+
+	AuthorizeHold(ctx *fiber.Ctx) error
+	CaptureHold(ctx *fiber.Ctx) error
+	ReleaseHold(ctx *fiber.Ctx) error
+	GetHolds(ctx *fiber.Ctx) error
+
+	RequestWithdrawal(ctx *fiber.Ctx) error
+	GetWithdrawals(ctx *fiber.Ctx) error
+	GetWithdrawal(ctx *fiber.Ctx) error
+	CancelWithdrawal(ctx *fiber.Ctx) error
+
+	PlaceLimitOrder(ctx *fiber.Ctx) error
+	GetLimitOrders(ctx *fiber.Ctx) error
+	CancelLimitOrder(ctx *fiber.Ctx) error
+
+	GetWallets(ctx *fiber.Ctx) error
+	GetWallet(ctx *fiber.Ctx) error
+	DepositToWallet(ctx *fiber.Ctx) error
+	WithdrawFromWallet(ctx *fiber.Ctx) error
+	ExchangeFromWallet(ctx *fiber.Ctx) error
+	TransferFromWallet(ctx *fiber.Ctx) error
+	AuthorizeWalletHold(ctx *fiber.Ctx) error
+	GetWalletPots(ctx *fiber.Ctx) error
+	CreateWalletPot(ctx *fiber.Ctx) error
+	ExportWalletStatement(ctx *fiber.Ctx) error
+	GetWalletMembers(ctx *fiber.Ctx) error
+	InviteWalletMember(ctx *fiber.Ctx) error
+	RemoveWalletMember(ctx *fiber.Ctx) error
+	GetWalletInvitations(ctx *fiber.Ctx) error
+	AcceptWalletInvitation(ctx *fiber.Ctx) error
+	DeclineWalletInvitation(ctx *fiber.Ctx) error
+
+	CreatePaymentRequest(ctx *fiber.Ctx) error
+	GetPaymentRequests(ctx *fiber.Ctx) error
+	PayPaymentRequest(ctx *fiber.Ctx) error
+	DeclinePaymentRequest(ctx *fiber.Ctx) error
+	CancelPaymentRequest(ctx *fiber.Ctx) error
+
+	CreatePot(ctx *fiber.Ctx) error
+	GetPots(ctx *fiber.Ctx) error
+	DepositToPot(ctx *fiber.Ctx) error
+	WithdrawFromPot(ctx *fiber.Ctx) error
+	ClosePot(ctx *fiber.Ctx) error
+	GetPotMovements(ctx *fiber.Ctx) error
+
+	CreateSchedule(ctx *fiber.Ctx) error
+	GetSchedules(ctx *fiber.Ctx) error
+	GetScheduleExecutions(ctx *fiber.Ctx) error
+	PauseSchedule(ctx *fiber.Ctx) error
+	ResumeSchedule(ctx *fiber.Ctx) error
+	CancelSchedule(ctx *fiber.Ctx) error
+
+	CreateWebhookSubscription(ctx *fiber.Ctx) error
+	GetWebhookSubscriptions(ctx *fiber.Ctx) error
+	DeleteWebhookSubscription(ctx *fiber.Ctx) error
+	GetWebhookDeliveries(ctx *fiber.Ctx) error
+	GetWebhookDelivery(ctx *fiber.Ctx) error
+	RedeliverWebhook(ctx *fiber.Ctx) error
+
+	GetNotifications(ctx *fiber.Ctx) error
+	MarkNotificationRead(ctx *fiber.Ctx) error
+	MarkAllNotificationsRead(ctx *fiber.Ctx) error
+	GetNotificationPreferences(ctx *fiber.Ctx) error
+	UpdateNotificationPreferences(ctx *fiber.Ctx) error
+
+	StreamUpdates(ctx *fiber.Ctx) error
+	StreamUpdatesWebSocket(ctx *fiber.Ctx) error
+
+	// Admin
+	RequireAdmin(ctx *fiber.Ctx) error
+	AdminGetCurrencies(ctx *fiber.Ctx) error
+	AdminCreateCurrency(ctx *fiber.Ctx) error
+	AdminUpdateCurrency(ctx *fiber.Ctx) error
+	AdminReverseTransaction(ctx *fiber.Ctx) error
+	AdminFreezeUser(ctx *fiber.Ctx) error
+	AdminUnfreezeUser(ctx *fiber.Ctx) error
+	AdminGetUserFreezeEvents(ctx *fiber.Ctx) error
+	AdminFreezeWallet(ctx *fiber.Ctx) error
+	AdminUnfreezeWallet(ctx *fiber.Ctx) error
+	AdminGetWalletFreezeEvents(ctx *fiber.Ctx) error
+	AdminGetCreditLine(ctx *fiber.Ctx) error
+	AdminSetCreditLine(ctx *fiber.Ctx) error
+	AdminDeleteCreditLine(ctx *fiber.Ctx) error
+	AdminGetProviderDeposits(ctx *fiber.Ctx) error
+	AdminGetWithdrawals(ctx *fiber.Ctx) error
+	AdminCreateVoucherBatch(ctx *fiber.Ctx) error
+	AdminGetVoucherBatches(ctx *fiber.Ctx) error
+	AdminGetVoucherBatch(ctx *fiber.Ctx) error
+	AdminApproveWithdrawal(ctx *fiber.Ctx) error
+	AdminRejectWithdrawal(ctx *fiber.Ctx) error
+	AdminGetReconciliationRuns(ctx *fiber.Ctx) error
+	AdminGetReconciliationReport(ctx *fiber.Ctx) error
+	AdminVerifyJournal(ctx *fiber.Ctx) error
 }
 
 type handler struct {
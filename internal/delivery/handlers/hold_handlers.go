@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseIDParam извлекает положительный числовой идентификатор из параметра пути.
+func parseIDParam(c *fiber.Ctx, name string) (uint64, error) {
+	id, err := c.ParamsInt(name)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid " + name)
+	}
+	return uint64(id), nil
+}
+
+// holdErrorStatus сопоставляет ошибки блокировок с HTTP-статусами.
+func holdErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrHoldNotFound), errors.Is(err, services.ErrWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrHoldNotActive), errors.Is(err, services.ErrHoldExpired), errors.Is(err, services.ErrHoldSystemManaged):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrWalletForbidden), isFrozen(err):
+		return fiber.StatusForbidden
+	case errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrInsufficientFunds),
+		errors.Is(err, services.ErrCaptureExceedHold),
+		errors.Is(err, services.ErrSpendLimitExceeded):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// AuthorizeHold блокирует средства на кошельке пользователя.
+// @Summary Authorize hold
+// @Description Блокирует сумму на кошельке: доступный баланс уменьшается, учётный не меняется до списания.
+// @Tags Holds
+// @Accept json
+// @Produce json
+// @Param hold body models.HoldRequest true "Hold request"
+// @Success 201 {object} models.HoldResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input or insufficient funds"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/holds [post]
+func (h *handler) AuthorizeHold(ctx *fiber.Ctx) error {
+	var request models.HoldRequest
+	if err := ctx.BodyParser(&request); err != nil || request.ExpiresIn < 0 {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	ttl := time.Duration(request.ExpiresIn) * time.Second
+	hold, err := h.service.AuthorizeHold(ctxWithTimeout, userID, request.Amount, request.Currency, request.Reference, ttl)
+	if err != nil {
+		h.logger.Errorf("Failed to authorize hold for user %d: %v", userID, err)
+		return ctx.Status(holdErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.HoldResponse{
+		Message: "Funds held successfully",
+		Hold:    hold,
+	})
+}
+
+// CaptureHold списывает заблокированные средства.
+// @Summary Capture hold
+// @Description Списывает всю или часть заблокированной суммы; остаток частичного списания освобождается. Доступно владельцам кошелька и участникам с ролью spender в пределах их лимита списаний.
+// @Tags Holds
+// @Accept json
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Param capture body models.CaptureHoldRequest false "Capture request"
+// @Success 200 {object} models.HoldResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation, account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Hold not found"
+// @Failure 409 {object} models.ErrorResponse "Hold is not active or belongs to a withdrawal or limit order"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/holds/{id}/capture [post]
+func (h *handler) CaptureHold(ctx *fiber.Ctx) error {
+	holdID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid hold id",
+		})
+	}
+
+	var request models.CaptureHoldRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&request); err != nil {
+			h.logger.Errorf("Invalid input")
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid input",
+			})
+		}
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	hold, err := h.service.CaptureHold(ctxWithTimeout, userID, holdID, request.Amount)
+	if err != nil {
+		h.logger.Errorf("Failed to capture hold %d: %v", holdID, err)
+		return ctx.Status(holdErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.HoldResponse{
+		Message: "Hold captured successfully",
+		Hold:    hold,
+	})
+}
+
+// ReleaseHold снимает блокировку средств.
+// @Summary Release hold
+// @Description Снимает блокировку и возвращает средства в доступный баланс. Доступно владельцам кошелька и участникам с ролью spender.
+// @Tags Holds
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Success 200 {object} models.HoldResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation"
+// @Failure 404 {object} models.ErrorResponse "Hold not found"
+// @Failure 409 {object} models.ErrorResponse "Hold is not active or belongs to a withdrawal or limit order"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/holds/{id}/release [post]
+func (h *handler) ReleaseHold(ctx *fiber.Ctx) error {
+	holdID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid hold id",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	hold, err := h.service.ReleaseHold(ctxWithTimeout, userID, holdID)
+	if err != nil {
+		h.logger.Errorf("Failed to release hold %d: %v", holdID, err)
+		return ctx.Status(holdErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.HoldResponse{
+		Message: "Hold released successfully",
+		Hold:    hold,
+	})
+}
+
+// GetHolds возвращает блокировки пользователя.
+// @Summary List holds
+// @Description Возвращает все блокировки средств пользователя.
+// @Tags Holds
+// @Produce json
+// @Success 200 {object} models.HoldsResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/holds [get]
+func (h *handler) GetHolds(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	holds, err := h.service.GetHolds(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get holds for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get holds",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.HoldsResponse{Holds: holds})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAccruedInterest возвращает начисленные, но ещё не выплаченные проценты по кошелькам пользователя.
+// @Summary Get accrued interest
+// @Description Возвращает по каждому кошельку годовую ставку валюты и сумму процентов, начисленных ежедневно на остаток на конец дня, но ещё не выплаченных. Проценты выплачиваются проводкой interest в начале следующего месяца.
+// @Tags Wallet
+// @Produce json
+// @Success 200 {object} models.InterestResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/interest [get]
+func (h *handler) GetAccruedInterest(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	summaries, err := h.service.GetAccruedInterest(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get accrued interest for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get accrued interest",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.InterestResponse{Wallets: summaries})
+}
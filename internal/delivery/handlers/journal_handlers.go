@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// JournalVerifyTimeout - время на проверку всего журнала транзакций.
+const JournalVerifyTimeout = 5 * time.Minute
+
+// AdminVerifyJournal проверяет целостность журнала транзакций.
+// @Summary Verify transaction journal (admin)
+// @Description Проходит журнал, проверяя цепочки хешей проводок и контрольные точки, и возвращает первое найденное нарушение.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.JournalVerification
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/journal/verify [get]
+func (h *handler) AdminVerifyJournal(ctx *fiber.Ctx) error {
+	ctxWithTimeout, cancel := context.WithTimeout(context.Background(), JournalVerifyTimeout)
+	defer cancel()
+
+	result, err := h.service.VerifyJournal(ctxWithTimeout)
+	if err != nil {
+		h.logger.Errorf("Failed to verify journal: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify journal",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(result)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// limitOrderErrorStatus сопоставляет ошибки лимитных заявок с HTTP-статусами.
+func limitOrderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound), errors.Is(err, services.ErrWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrOrderNotOpen):
+		return fiber.StatusConflict
+	case isFrozen(err):
+		return fiber.StatusForbidden
+	case errors.Is(err, services.ErrInvalidOrder),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrSameCurrency),
+		errors.Is(err, services.ErrInsufficientFunds):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// PlaceLimitOrder создаёт лимитную заявку на обмен.
+// @Summary Place limit order
+// @Description Создаёт заявку на обмен, исполняемую, когда курс from_currency/to_currency достигает target_rate. Сумма резервируется до исполнения, отмены или истечения заявки.
+// @Tags Limit orders
+// @Accept json
+// @Produce json
+// @Param order body models.LimitOrderRequest true "Limit order request"
+// @Success 201 {object} models.LimitOrderResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input or insufficient funds"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/limit-orders [post]
+func (h *handler) PlaceLimitOrder(ctx *fiber.Ctx) error {
+	var request models.LimitOrderRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	order, err := h.service.PlaceLimitOrder(ctxWithTimeout, userID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to place limit order for user %d: %v", userID, err)
+		return ctx.Status(limitOrderErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.LimitOrderResponse{
+		Message: "Limit order placed successfully",
+		Order:   order,
+	})
+}
+
+// GetLimitOrders возвращает лимитные заявки пользователя.
+// @Summary List limit orders
+// @Description Возвращает все лимитные заявки пользователя, начиная с последних.
+// @Tags Limit orders
+// @Produce json
+// @Success 200 {object} models.LimitOrdersResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/limit-orders [get]
+func (h *handler) GetLimitOrders(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	orders, err := h.service.GetLimitOrders(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get limit orders for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get limit orders",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.LimitOrdersResponse{Orders: orders})
+}
+
+// CancelLimitOrder отменяет открытую лимитную заявку.
+// @Summary Cancel limit order
+// @Description Отменяет открытую заявку и освобождает зарезервированные средства.
+// @Tags Limit orders
+// @Produce json
+// @Param id path int true "Limit order ID"
+// @Success 200 {object} models.LimitOrderResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Limit order not found"
+// @Failure 409 {object} models.ErrorResponse "Limit order is not open"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/limit-orders/{id}/cancel [post]
+func (h *handler) CancelLimitOrder(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	orderID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	order, err := h.service.CancelLimitOrder(ctxWithTimeout, userID, orderID)
+	if err != nil {
+		h.logger.Errorf("Failed to cancel limit order %d: %v", orderID, err)
+		return ctx.Status(limitOrderErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.LimitOrderResponse{
+		Message: "Limit order cancelled successfully",
+		Order:   order,
+	})
+}
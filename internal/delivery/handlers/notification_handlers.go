@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// notificationErrorStatus сопоставляет ошибки уведомлений с HTTP-статусами.
+func notificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotificationNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrInvalidNotificationPreference):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// GetNotifications возвращает входящие уведомления пользователя.
+// @Summary List notifications
+// @Description Возвращает последние уведомления пользователя о крупных выводах (large_withdrawal), обменах (exchange), неудачных входах (failed_login) и входах с нового устройства (new_session), начиная с новых, и количество непрочитанных.
+// @Tags Notifications
+// @Produce json
+// @Param unread query bool false "Только непрочитанные"
+// @Success 200 {object} models.NotificationsResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/notifications [get]
+func (h *handler) GetNotifications(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	notifications, unread, err := h.service.GetNotifications(ctxWithTimeout, userID, ctx.QueryBool("unread"))
+	if err != nil {
+		h.logger.Errorf("Failed to get notifications for user %d: %v", userID, err)
+		return ctx.Status(notificationErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.NotificationsResponse{
+		Notifications: notifications,
+		UnreadCount:   unread,
+	})
+}
+
+// MarkNotificationRead отмечает уведомление прочитанным.
+// @Summary Mark notification as read
+// @Description Отмечает уведомление пользователя прочитанным; повторная отметка не меняет время прочтения.
+// @Tags Notifications
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} models.NotificationsReadResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Notification not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/notifications/{id}/read [post]
+func (h *handler) MarkNotificationRead(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	notificationID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	if err := h.service.MarkNotificationRead(ctxWithTimeout, userID, notificationID); err != nil {
+		h.logger.Errorf("Failed to mark notification %d read: %v", notificationID, err)
+		return ctx.Status(notificationErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.NotificationsReadResponse{
+		Message: "Notification marked as read",
+		Marked:  1,
+	})
+}
+
+// MarkAllNotificationsRead отмечает прочитанными все уведомления пользователя.
+// @Summary Mark all notifications as read
+// @Description Отмечает прочитанными все непрочитанные уведомления пользователя и возвращает их количество.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} models.NotificationsReadResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/notifications/read-all [post]
+func (h *handler) MarkAllNotificationsRead(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	marked, err := h.service.MarkAllNotificationsRead(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to mark notifications read for user %d: %v", userID, err)
+		return ctx.Status(notificationErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.NotificationsReadResponse{
+		Message: "Notifications marked as read",
+		Marked:  marked,
+	})
+}
+
+// GetNotificationPreferences возвращает настройки уведомлений пользователя.
+// @Summary Get notification preferences
+// @Description Возвращает для каждого типа событий, включены ли уведомления во входящих (in_app) и по почте (email), и порог суммы для large_withdrawal в валюте кошелька. Для типов, которые пользователь не менял, возвращаются настройки по умолчанию.
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} models.NotificationPreferencesResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/notification-preferences [get]
+func (h *handler) GetNotificationPreferences(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	preferences, err := h.service.GetNotificationPreferences(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get notification preferences for user %d: %v", userID, err)
+		return ctx.Status(notificationErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.NotificationPreferencesResponse{
+		Preferences: preferences,
+	})
+}
+
+// UpdateNotificationPreferences изменяет настройки уведомлений пользователя.
+// @Summary Update notification preferences
+// @Description Сохраняет настройки для указанных типов событий; настройки остальных типов не меняются. Если оба канала выключены, уведомления этого типа не создаются.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param preferences body models.NotificationPreferencesRequest true "Notification preferences"
+// @Success 200 {object} models.NotificationPreferencesResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/notification-preferences [put]
+func (h *handler) UpdateNotificationPreferences(ctx *fiber.Ctx) error {
+	var request models.NotificationPreferencesRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	preferences, err := h.service.UpdateNotificationPreferences(ctxWithTimeout, userID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to update notification preferences for user %d: %v", userID, err)
+		return ctx.Status(notificationErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.NotificationPreferencesResponse{
+		Preferences: preferences,
+	})
+}
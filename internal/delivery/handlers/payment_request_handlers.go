@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// paymentRequestErrorStatus сопоставляет ошибки запросов на оплату с HTTP-статусами.
+func paymentRequestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrPaymentRequestNotFound),
+		errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, services.ErrWalletNotFound),
+		errors.Is(err, services.ErrRecipientWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrPaymentRequestNotPending), errors.Is(err, services.ErrPaymentRequestExpired):
+		return fiber.StatusConflict
+	case isFrozen(err):
+		return fiber.StatusForbidden
+	case errors.Is(err, services.ErrInvalidPaymentRequest),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrSelfTransfer),
+		errors.Is(err, services.ErrInsufficientFunds):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// CreatePaymentRequest выставляет запрос на оплату другому пользователю.
+// @Summary Create payment request
+// @Description Запрашивает у пользователя payer_username оплату суммы в указанной валюте. У получателя должен быть кошелёк в этой валюте.
+// @Tags Payment requests
+// @Accept json
+// @Produce json
+// @Param request body models.CreatePaymentRequest true "Payment request"
+// @Success 201 {object} models.PaymentRequestResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Payer or wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/payment-requests [post]
+func (h *handler) CreatePaymentRequest(ctx *fiber.Ctx) error {
+	var request models.CreatePaymentRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	paymentRequest, err := h.service.CreatePaymentRequest(ctxWithTimeout, userID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to create payment request for user %d: %v", userID, err)
+		return ctx.Status(paymentRequestErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.PaymentRequestResponse{
+		Message: "Payment request created successfully",
+		Request: paymentRequest,
+	})
+}
+
+// GetPaymentRequests возвращает запросы на оплату пользователя.
+// @Summary List payment requests
+// @Description Возвращает входящие (пользователь - плательщик) или исходящие запросы на оплату, начиная с последних.
+// @Tags Payment requests
+// @Produce json
+// @Param direction query string false "incoming (по умолчанию) или outgoing"
+// @Success 200 {object} models.PaymentRequestsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid direction"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/payment-requests [get]
+func (h *handler) GetPaymentRequests(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	direction := ctx.Query("direction", models.PaymentRequestDirectionIncoming)
+	requests, err := h.service.GetPaymentRequests(ctxWithTimeout, userID, direction)
+	if err != nil {
+		h.logger.Errorf("Failed to get payment requests for user %d: %v", userID, err)
+		return ctx.Status(paymentRequestErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.PaymentRequestsResponse{Requests: requests})
+}
+
+// PayPaymentRequest оплачивает входящий запрос.
+// @Summary Pay payment request
+// @Description Оплачивает входящий запрос переводом получателю. Если pay_currency отличается от валюты запроса, сумма списания пересчитывается по текущему курсу.
+// @Tags Payment requests
+// @Accept json
+// @Produce json
+// @Param id path int true "Payment request ID"
+// @Param payment body models.PayPaymentRequest false "Payment currency"
+// @Success 200 {object} models.PaymentRequestResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input or insufficient funds"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Payment request or wallet not found"
+// @Failure 409 {object} models.ErrorResponse "Payment request is not pending or expired"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/payment-requests/{id}/pay [post]
+func (h *handler) PayPaymentRequest(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	requestID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Тело запроса необязательно: по умолчанию оплата в валюте запроса
+	var request models.PayPaymentRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&request); err != nil {
+			h.logger.Errorf("Invalid input")
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid input",
+			})
+		}
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	paymentRequest, err := h.service.PayPaymentRequest(ctxWithTimeout, userID, requestID, request.PayCurrency)
+	if err != nil {
+		h.logger.Errorf("Failed to pay payment request %d: %v", requestID, err)
+		return ctx.Status(paymentRequestErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.PaymentRequestResponse{
+		Message: "Payment request paid successfully",
+		Request: paymentRequest,
+	})
+}
+
+// DeclinePaymentRequest отклоняет входящий запрос на оплату.
+// @Summary Decline payment request
+// @Description Отклоняет входящий запрос на оплату.
+// @Tags Payment requests
+// @Produce json
+// @Param id path int true "Payment request ID"
+// @Success 200 {object} models.PaymentRequestResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Payment request not found"
+// @Failure 409 {object} models.ErrorResponse "Payment request is not pending"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/payment-requests/{id}/decline [post]
+func (h *handler) DeclinePaymentRequest(ctx *fiber.Ctx) error {
+	return h.resolvePaymentRequest(ctx, false)
+}
+
+// CancelPaymentRequest отзывает исходящий запрос на оплату.
+// @Summary Cancel payment request
+// @Description Отзывает исходящий запрос на оплату, пока он не оплачен.
+// @Tags Payment requests
+// @Produce json
+// @Param id path int true "Payment request ID"
+// @Success 200 {object} models.PaymentRequestResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Payment request not found"
+// @Failure 409 {object} models.ErrorResponse "Payment request is not pending"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/payment-requests/{id}/cancel [post]
+func (h *handler) CancelPaymentRequest(ctx *fiber.Ctx) error {
+	return h.resolvePaymentRequest(ctx, true)
+}
+
+// resolvePaymentRequest отклоняет входящий запрос или, при cancel, отзывает исходящий.
+func (h *handler) resolvePaymentRequest(ctx *fiber.Ctx, cancelRequest bool) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	requestID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	var paymentRequest *models.PaymentRequest
+	message := "Payment request declined"
+	if cancelRequest {
+		message = "Payment request cancelled"
+		paymentRequest, err = h.service.CancelPaymentRequest(ctxWithTimeout, userID, requestID)
+	} else {
+		paymentRequest, err = h.service.DeclinePaymentRequest(ctxWithTimeout, userID, requestID)
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to resolve payment request %d: %v", requestID, err)
+		return ctx.Status(paymentRequestErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.PaymentRequestResponse{
+		Message: message,
+		Request: paymentRequest,
+	})
+}
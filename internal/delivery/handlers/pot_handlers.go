@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// potErrorStatus сопоставляет ошибки копилок с HTTP-статусами.
+func potErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrPotNotFound), errors.Is(err, services.ErrWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrPotClosed):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrWalletForbidden), isFrozen(err):
+		return fiber.StatusForbidden
+	case errors.Is(err, services.ErrInvalidPot),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrInsufficientFunds),
+		errors.Is(err, services.ErrPotInsufficientFunds):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// CreatePot создаёт копилку.
+// @Summary Create pot
+// @Description Создаёт копилку в кошельке указанной валюты с необязательными целевой суммой и датой.
+// @Tags Pots
+// @Accept json
+// @Produce json
+// @Param pot body models.PotRequest true "Pot request"
+// @Success 201 {object} models.PotResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/pots [post]
+func (h *handler) CreatePot(ctx *fiber.Ctx) error {
+	var request models.PotRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	pot, err := h.service.CreatePot(ctxWithTimeout, userID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to create pot for user %d: %v", userID, err)
+		return ctx.Status(potErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.PotResponse{
+		Message: "Pot created successfully",
+		Pot:     pot,
+	})
+}
+
+// GetPots возвращает копилки пользователя.
+// @Summary List pots
+// @Description Возвращает все копилки пользователя, включая закрытые.
+// @Tags Pots
+// @Produce json
+// @Success 200 {object} models.PotsResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/pots [get]
+func (h *handler) GetPots(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	pots, err := h.service.GetPots(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get pots for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get pots",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.PotsResponse{Pots: pots})
+}
+
+// DepositToPot перекладывает средства из основного баланса в копилку.
+// @Summary Move money into pot
+// @Description Перекладывает сумму из свободного остатка кошелька в копилку. Учётный баланс не меняется, доступный уменьшается. Доступно владельцам кошелька.
+// @Tags Pots
+// @Accept json
+// @Produce json
+// @Param id path int true "Pot ID"
+// @Param move body models.PotMoveRequest true "Amount"
+// @Success 200 {object} models.PotResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input or insufficient funds"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation, account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Pot not found"
+// @Failure 409 {object} models.ErrorResponse "Pot is closed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/pots/{id}/deposit [post]
+func (h *handler) DepositToPot(ctx *fiber.Ctx) error {
+	return h.movePot(ctx, false)
+}
+
+// WithdrawFromPot возвращает средства из копилки в основной баланс.
+// @Summary Move money out of pot
+// @Description Возвращает сумму из копилки в свободный остаток кошелька. Доступно владельцам кошелька.
+// @Tags Pots
+// @Accept json
+// @Produce json
+// @Param id path int true "Pot ID"
+// @Param move body models.PotMoveRequest true "Amount"
+// @Success 200 {object} models.PotResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input or insufficient funds in pot"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation, account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Pot not found"
+// @Failure 409 {object} models.ErrorResponse "Pot is closed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/pots/{id}/withdraw [post]
+func (h *handler) WithdrawFromPot(ctx *fiber.Ctx) error {
+	return h.movePot(ctx, true)
+}
+
+// ClosePot закрывает копилку.
+// @Summary Close pot
+// @Description Закрывает копилку и возвращает её остаток в основной баланс кошелька. Доступно владельцам кошелька.
+// @Tags Pots
+// @Produce json
+// @Param id path int true "Pot ID"
+// @Success 200 {object} models.PotResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation, account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Pot not found"
+// @Failure 409 {object} models.ErrorResponse "Pot is closed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/pots/{id}/close [post]
+func (h *handler) ClosePot(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	potID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	pot, err := h.service.ClosePot(ctxWithTimeout, userID, potID)
+	if err != nil {
+		h.logger.Errorf("Failed to close pot %d: %v", potID, err)
+		return ctx.Status(potErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.PotResponse{
+		Message: "Pot closed successfully",
+		Pot:     pot,
+	})
+}
+
+// GetPotMovements возвращает историю перемещений копилки.
+// @Summary Get pot movements
+// @Description Возвращает историю пополнений копилки и возвратов из неё в основной баланс. Доступно любому участнику кошелька.
+// @Tags Pots
+// @Produce json
+// @Param id path int true "Pot ID"
+// @Success 200 {object} models.PotMovementsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Pot not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/pots/{id}/movements [get]
+func (h *handler) GetPotMovements(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	potID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	movements, err := h.service.GetPotMovements(ctxWithTimeout, userID, potID)
+	if err != nil {
+		h.logger.Errorf("Failed to get movements of pot %d: %v", potID, err)
+		return ctx.Status(potErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.PotMovementsResponse{Movements: movements})
+}
+
+// movePot перекладывает средства в копилку или, при withdraw, из неё.
+func (h *handler) movePot(ctx *fiber.Ctx, withdraw bool) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	potID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.PotMoveRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	var pot *models.Pot
+	if withdraw {
+		pot, err = h.service.MoveFromPot(ctxWithTimeout, userID, potID, request.Amount)
+	} else {
+		pot, err = h.service.MoveToPot(ctxWithTimeout, userID, potID, request.Amount)
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to move funds of pot %d: %v", potID, err)
+		return ctx.Status(potErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.PotResponse{
+		Message: "Pot updated successfully",
+		Pot:     pot,
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// rebalanceErrorStatus сопоставляет ошибки ребалансировки с HTTP-статусами.
+func rebalanceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrRebalanceNotFound), errors.Is(err, services.ErrWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrRebalanceNotPending), errors.Is(err, services.ErrRebalanceExpired):
+		return fiber.StatusConflict
+	case isFrozen(err):
+		return fiber.StatusForbidden
+	case errors.Is(err, services.ErrRateUnavailable):
+		return fiber.StatusServiceUnavailable
+	case errors.Is(err, services.ErrInvalidRebalance),
+		errors.Is(err, services.ErrNothingToRebalance),
+		errors.Is(err, services.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrInsufficientFunds):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// PreviewRebalance рассчитывает обмены для приведения портфеля к целевому распределению.
+// @Summary Preview portfolio rebalancing
+// @Description Рассчитывает по текущим курсам минимальный набор обменов (не больше числа валют минус один), приводящий свободные средства к целевым долям в процентах. Валюты, не указанные в targets, продаются полностью. План можно подтвердить в течение минуты по курсам предпросмотра.
+// @Tags Exchange
+// @Accept json
+// @Produce json
+// @Param request body models.RebalanceRequest true "Target allocation"
+// @Success 201 {object} models.RebalancePlanResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid targets"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 503 {object} models.ErrorResponse "Exchange rate unavailable"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/rebalance/preview [post]
+func (h *handler) PreviewRebalance(ctx *fiber.Ctx) error {
+	var request models.RebalanceRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	plan, err := h.service.PreviewRebalance(ctxWithTimeout, userID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to preview rebalance for user %d: %v", userID, err)
+		return ctx.Status(rebalanceErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.RebalancePlanResponse{
+		Message: "Rebalance plan created",
+		Plan:    plan,
+	})
+}
+
+// ConfirmRebalance исполняет план ребалансировки.
+// @Summary Confirm portfolio rebalancing
+// @Description Исполняет все обмены плана одной операцией по курсам предпросмотра. Если средств для любого обмена не хватает, не исполняется ни один.
+// @Tags Exchange
+// @Produce json
+// @Param id path int true "Rebalance plan ID"
+// @Success 200 {object} models.RebalancePlanResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input or insufficient funds"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Plan not found"
+// @Failure 409 {object} models.ErrorResponse "Plan already executed or expired"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/rebalance/{id}/confirm [post]
+func (h *handler) ConfirmRebalance(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	planID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	plan, err := h.service.ConfirmRebalance(ctxWithTimeout, userID, planID)
+	if err != nil {
+		h.logger.Errorf("Failed to confirm rebalance plan %d: %v", planID, err)
+		return ctx.Status(rebalanceErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.RebalancePlanResponse{
+		Message: "Rebalance executed successfully",
+		Plan:    plan,
+	})
+}
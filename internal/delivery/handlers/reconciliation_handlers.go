@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminGetReconciliationRuns возвращает последние сверки балансов.
+// @Summary List reconciliation runs (admin)
+// @Description Возвращает последние ежедневные сверки балансов с журналом транзакций и число расхождений в каждой.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.ReconciliationRunsResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/reconciliation [get]
+func (h *handler) AdminGetReconciliationRuns(ctx *fiber.Ctx) error {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	runs, err := h.service.GetReconciliationRuns(ctxWithTimeout)
+	if err != nil {
+		h.logger.Errorf("Failed to get reconciliation runs: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get reconciliation runs",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.ReconciliationRunsResponse{Runs: runs})
+}
+
+// AdminGetReconciliationReport возвращает отчёт по сверке.
+// @Summary Get reconciliation report (admin)
+// @Description Возвращает снимки балансов сверки. С параметром status=mismatch - только кошельки с расхождениями.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Reconciliation run ID"
+// @Param status query string false "Filter by status (mismatch)"
+// @Success 200 {object} models.ReconciliationReportResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Reconciliation run not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/reconciliation/{id} [get]
+func (h *handler) AdminGetReconciliationReport(ctx *fiber.Ctx) error {
+	runID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	status := ctx.Query("status")
+	if status != "" && status != models.SnapshotStatusMismatch {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid status filter",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	run, snapshots, err := h.service.GetReconciliationReport(ctxWithTimeout, runID, status == models.SnapshotStatusMismatch)
+	if err != nil {
+		h.logger.Errorf("Failed to get reconciliation report %d: %v", runID, err)
+		if errors.Is(err, services.ErrReconciliationNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get reconciliation report",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.ReconciliationReportResponse{
+		Run:       run,
+		Snapshots: snapshots,
+	})
+}
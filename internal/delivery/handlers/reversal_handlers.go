@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminReverseTransaction сторнирует операцию, к которой относится транзакция.
+// @Summary Reverse transaction (admin)
+// @Description Записывает компенсирующие проводки по всем проводкам операции (включая обе ноги обмена или перевода). Операцию можно сторнировать только один раз; оператор и причина сохраняются.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param reversal body models.ReversalRequest true "Reversal reason"
+// @Success 200 {object} models.ReversalResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input or insufficient funds"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Transaction not found"
+// @Failure 409 {object} models.ErrorResponse "Operation already reversed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/transactions/{id}/reverse [post]
+func (h *handler) AdminReverseTransaction(ctx *fiber.Ctx) error {
+	operatorID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	transactionID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.ReversalRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	reversal, err := h.service.ReverseTransaction(ctxWithTimeout, operatorID, transactionID, request.Reason)
+	if err != nil {
+		h.logger.Errorf("Failed to reverse transaction %d: %v", transactionID, err)
+		return ctx.Status(reversalErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.ReversalResponse{
+		Message:  "Transaction reversed successfully",
+		Reversal: reversal,
+	})
+}
+
+// reversalErrorStatus сопоставляет ошибки сторнирования с HTTP-статусами.
+func reversalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrTransactionNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrAlreadyReversed):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrNotReversible),
+		errors.Is(err, services.ErrReasonRequired),
+		errors.Is(err, services.ErrInsufficientFunds):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
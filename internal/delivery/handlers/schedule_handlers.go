@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// scheduleErrorStatus сопоставляет ошибки запланированных операций с HTTP-статусами.
+func scheduleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrScheduleNotFound),
+		errors.Is(err, services.ErrRecipientNotFound),
+		errors.Is(err, services.ErrWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrScheduleNotAllowed):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrSameCurrency),
+		errors.Is(err, services.ErrSelfTransfer):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// CreateSchedule создаёт разовое или повторяющееся поручение.
+// @Summary Create scheduled transfer
+// @Description Создаёт поручение на обмен валюты или перевод другому пользователю: разовое (run_at), с интервалом (interval_seconds) или по cron-выражению (cron).
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param schedule body models.ScheduleRequest true "Schedule request"
+// @Success 201 {object} models.ScheduleResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Wallet or recipient not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/schedules [post]
+func (h *handler) CreateSchedule(ctx *fiber.Ctx) error {
+	var request models.ScheduleRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	schedule, err := h.service.CreateSchedule(ctxWithTimeout, userID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to create schedule for user %d: %v", userID, err)
+		return ctx.Status(scheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.ScheduleResponse{
+		Message:  "Schedule created successfully",
+		Schedule: schedule,
+	})
+}
+
+// GetSchedules возвращает запланированные операции пользователя.
+// @Summary List scheduled transfers
+// @Description Возвращает все запланированные операции пользователя.
+// @Tags Schedules
+// @Produce json
+// @Success 200 {object} models.SchedulesResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/schedules [get]
+func (h *handler) GetSchedules(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	schedules, err := h.service.GetSchedules(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get schedules for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get schedules",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.SchedulesResponse{Schedules: schedules})
+}
+
+// GetScheduleExecutions возвращает историю выполнения операции.
+// @Summary Get schedule execution history
+// @Description Возвращает историю запусков запланированной операции.
+// @Tags Schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} models.ScheduleExecutionsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Schedule not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/schedules/{id}/executions [get]
+func (h *handler) GetScheduleExecutions(ctx *fiber.Ctx) error {
+	scheduleID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid schedule id",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	executions, err := h.service.GetScheduleExecutions(ctxWithTimeout, userID, scheduleID)
+	if err != nil {
+		h.logger.Errorf("Failed to get executions of schedule %d: %v", scheduleID, err)
+		return ctx.Status(scheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.ScheduleExecutionsResponse{Executions: executions})
+}
+
+// PauseSchedule приостанавливает операцию.
+// @Summary Pause scheduled transfer
+// @Description Приостанавливает активную запланированную операцию.
+// @Tags Schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} models.ScheduleResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Schedule not found"
+// @Failure 409 {object} models.ErrorResponse "Schedule is not active"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/schedules/{id}/pause [post]
+func (h *handler) PauseSchedule(ctx *fiber.Ctx) error {
+	return h.changeSchedule(ctx, h.service.PauseSchedule, "Schedule paused successfully")
+}
+
+// ResumeSchedule возобновляет операцию.
+// @Summary Resume scheduled transfer
+// @Description Возобновляет приостановленную операцию; пропущенные запуски не выполняются.
+// @Tags Schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} models.ScheduleResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Schedule not found"
+// @Failure 409 {object} models.ErrorResponse "Schedule is not paused"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/schedules/{id}/resume [post]
+func (h *handler) ResumeSchedule(ctx *fiber.Ctx) error {
+	return h.changeSchedule(ctx, h.service.ResumeSchedule, "Schedule resumed successfully")
+}
+
+// CancelSchedule отменяет операцию.
+// @Summary Cancel scheduled transfer
+// @Description Отменяет активную или приостановленную операцию.
+// @Tags Schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} models.ScheduleResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Schedule not found"
+// @Failure 409 {object} models.ErrorResponse "Schedule is already finished"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/schedules/{id}/cancel [post]
+func (h *handler) CancelSchedule(ctx *fiber.Ctx) error {
+	return h.changeSchedule(ctx, h.service.CancelSchedule, "Schedule cancelled successfully")
+}
+
+// changeSchedule выполняет изменение статуса операции, принадлежащей пользователю.
+func (h *handler) changeSchedule(
+	ctx *fiber.Ctx,
+	change func(ctx context.Context, userID, scheduleID uint64) (*models.ScheduledTransfer, error),
+	message string,
+) error {
+	scheduleID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid schedule id",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	schedule, err := change(ctxWithTimeout, userID, scheduleID)
+	if err != nil {
+		h.logger.Errorf("Failed to change schedule %d: %v", scheduleID, err)
+		return ctx.Status(scheduleErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.ScheduleResponse{
+		Message:  message,
+		Schedule: schedule,
+	})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/statement"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StatementTimeout ограничивает время потоковой выгрузки одной выписки.
+const StatementTimeout = 5 * time.Minute
+
+const statementDateLayout = "2006-01-02"
+
+// ExportStatement выгружает выписку по кошельку.
+// @Summary Export account statement
+// @Description Выгружает выписку по кошельку за период с входящим и исходящим остатками в формате CSV, OFX или ISO 20022 camt.053. Проводки передаются потоком.
+// @Tags Statements
+// @Produce text/csv
+// @Produce application/x-ofx
+// @Produce application/xml
+// @Param currency query string true "Wallet currency"
+// @Param from query string true "Period start date (YYYY-MM-DD, inclusive)"
+// @Param to query string true "Period end date (YYYY-MM-DD, inclusive)"
+// @Param format query string false "Statement format" Enums(csv, ofx, camt053) default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/statements [get]
+func (h *handler) ExportStatement(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	currency := ctx.Query("currency")
+	if currency == "" {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	return h.exportStatement(ctx, userID, func(c context.Context, from, to time.Time) (*models.Statement, error) {
+		return h.service.PrepareStatement(c, userID, currency, from, to)
+	})
+}
+
+// ExportWalletStatement выгружает выписку по кошельку с указанным ID.
+// @Summary Export wallet statement by ID
+// @Description Выгружает выписку по кошельку за период в формате CSV, OFX или ISO 20022 camt.053. Доступно владельцу и любому участнику кошелька.
+// @Tags Shared wallets
+// @Produce text/csv
+// @Produce application/x-ofx
+// @Produce application/xml
+// @Param id path int true "Wallet ID"
+// @Param from query string true "Period start date (YYYY-MM-DD, inclusive)"
+// @Param to query string true "Period end date (YYYY-MM-DD, inclusive)"
+// @Param format query string false "Statement format" Enums(csv, ofx, camt053) default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/statements [get]
+func (h *handler) ExportWalletStatement(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return h.exportStatement(ctx, userID, func(c context.Context, from, to time.Time) (*models.Statement, error) {
+		return h.service.PrepareWalletStatement(c, userID, walletID, from, to)
+	})
+}
+
+// exportStatement разбирает период и формат выписки, готовит её функцией prepare
+// и передаёт проводки потоком.
+func (h *handler) exportStatement(ctx *fiber.Ctx, userID uint64, prepare func(ctx context.Context, from, to time.Time) (*models.Statement, error)) error {
+	formatName := ctx.Query("format", statement.FormatCSV)
+	from, fromErr := time.Parse(statementDateLayout, ctx.Query("from"))
+	to, toErr := time.Parse(statementDateLayout, ctx.Query("to"))
+	format, formatErr := statement.Lookup(formatName)
+	if fromErr != nil || toErr != nil || formatErr != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+	// Дата окончания включается в период
+	to = to.AddDate(0, 0, 1)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	st, err := prepare(ctxWithTimeout, from, to)
+	if err != nil {
+		h.logger.Errorf("Failed to prepare statement for user %d: %v", userID, err)
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrWalletNotFound):
+			status = fiber.StatusNotFound
+		case errors.Is(err, services.ErrInvalidPeriod):
+			status = fiber.StatusBadRequest
+		}
+		return ctx.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s-%s.%s",
+		st.Currency, from.Format(statementDateLayout), to.AddDate(0, 0, -1).Format(statementDateLayout), format.Extension)
+	ctx.Set(fiber.HeaderContentType, format.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		streamCtx, cancel := context.WithTimeout(context.Background(), StatementTimeout)
+		defer cancel()
+
+		if err := h.service.WriteStatement(streamCtx, st, formatName, w); err != nil {
+			h.logger.Errorf("Failed to stream statement for user %d: %v", userID, err)
+		}
+		if err := w.Flush(); err != nil {
+			h.logger.Errorf("Failed to flush statement for user %d: %v", userID, err)
+		}
+	})
+
+	return nil
+}
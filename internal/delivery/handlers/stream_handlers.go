@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// StreamHeartbeatInterval - интервал, с которым в простаивающий поток отправляется
+	// комментарий SSE или ping WebSocket, чтобы прокси не закрывали соединение.
+	StreamHeartbeatInterval = 15 * time.Second
+	// StreamRetryInterval - задержка переподключения, которую сервер сообщает EventSource.
+	StreamRetryInterval = 3 * time.Second
+)
+
+// streamErrorStatus сопоставляет ошибки подписки на поток обновлений с HTTP-статусами.
+func streamErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidStream),
+		errors.Is(err, services.ErrSameCurrency),
+		errors.Is(err, services.ErrUnsupportedCurrency):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// StreamUpdates передаёт изменения балансов и курсов через Server-Sent Events.
+// @Summary Stream balance and rate updates (SSE)
+// @Description Поток Server-Sent Events: событие balance при каждом изменении баланса собственного или совместного кошелька пользователя (с id - ID события) и rate при изменении курса пары из pairs (сначала передаются текущие курсы). Поле data содержит JSON вида {"id", "type", "data"}. При переподключении EventSource передаёт заголовок Last-Event-ID, и поток сначала повторяет пропущенные события; если их слишком много, приходит событие resync, после которого балансы нужно запросить заново. Браузерный EventSource может передать токен параметром access_token.
+// @Tags Stream
+// @Produce text/event-stream
+// @Param pairs query string false "Пары валют через запятую, например USD/EUR,USD/RUB"
+// @Param Last-Event-ID header string false "ID последнего полученного события"
+// @Param last_event_id query string false "ID последнего полученного события, если заголовок недоступен"
+// @Success 200 {object} models.StreamEvent
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/stream [get]
+func (h *handler) StreamUpdates(ctx *fiber.Ctx) error {
+	lastEventID := ctx.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+
+	sub, err := h.subscribeStream(ctx, lastEventID)
+	if sub == nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	// Отключает буферизацию ответа в nginx
+	ctx.Set("X-Accel-Buffering", "no")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		fmt.Fprintf(w, "retry: %d\n\n", StreamRetryInterval.Milliseconds())
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			event, err := nextStreamEvent(context.Background(), sub)
+			if err != nil {
+				return
+			}
+
+			if event == nil {
+				fmt.Fprint(w, ": ping\n\n")
+			} else {
+				body, err := json.Marshal(event)
+				if err != nil {
+					h.logger.Errorf("Failed to encode stream event: %v", err)
+					continue
+				}
+				if event.ID != 0 {
+					fmt.Fprintf(w, "id: %d\n", event.ID)
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, body)
+			}
+
+			// Ошибка записи означает, что клиент отключился
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// StreamUpdatesWebSocket передаёт изменения балансов и курсов через WebSocket.
+// @Summary Stream balance and rate updates (WebSocket)
+// @Description Поток WebSocket с теми же событиями, что и /api/v1/stream: каждое текстовое сообщение - JSON вида {"id", "type", "data"}. Для возобновления ID последнего полученного события balance передаётся параметром last_event_id. Браузерный WebSocket может передать токен параметром access_token.
+// @Tags Stream
+// @Param pairs query string false "Пары валют через запятую, например USD/EUR,USD/RUB"
+// @Param last_event_id query string false "ID последнего полученного события"
+// @Success 101 {object} models.StreamEvent
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 426 {object} models.ErrorResponse "Upgrade Required"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/stream/ws [get]
+func (h *handler) StreamUpdatesWebSocket(ctx *fiber.Ctx) error {
+	if !websocket.IsUpgrade(ctx) {
+		return ctx.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{
+			"error": "WebSocket upgrade required",
+		})
+	}
+
+	sub, err := h.subscribeStream(ctx, ctx.Query("last_event_id"))
+	if sub == nil {
+		return err
+	}
+
+	return websocket.Upgrade(ctx, func(conn *websocket.Conn) {
+		defer sub.Close()
+		defer conn.Close(websocket.CloseNormal)
+
+		// Сообщения клиента не ожидаются; чтение нужно для ответов на ping и обнаружения закрытия.
+		streamCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer cancel()
+			for {
+				if _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			event, err := nextStreamEvent(streamCtx, sub)
+			if err != nil {
+				return
+			}
+
+			if event == nil {
+				err = conn.Ping()
+			} else {
+				var body []byte
+				body, err = json.Marshal(event)
+				if err == nil {
+					err = conn.WriteText(body)
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+}
+
+// subscribeStream подписывает пользователя запроса на поток обновлений. При ошибке ответ
+// уже записан в ctx, а возвращаемая ошибка - результат записи, поэтому подписка равна nil.
+func (h *handler) subscribeStream(ctx *fiber.Ctx, lastEventID string) (*services.StreamSubscription, error) {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return nil, ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var afterID uint64
+	if lastEventID != "" {
+		afterID, err = strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			return nil, ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid last event ID",
+			})
+		}
+	}
+
+	var pairs []string
+	if query := ctx.Query("pairs"); query != "" {
+		pairs = strings.Split(query, ",")
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	sub, err := h.service.SubscribeStream(ctxWithTimeout, userID, afterID, pairs)
+	if err != nil {
+		h.logger.Errorf("Failed to subscribe user %d to stream: %v", userID, err)
+		return nil, ctx.Status(streamErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return sub, nil
+}
+
+// nextStreamEvent ожидает следующее событие потока не дольше StreamHeartbeatInterval.
+// Возвращает nil без ошибки, если пора отправить heartbeat.
+func nextStreamEvent(ctx context.Context, sub *services.StreamSubscription) (*models.StreamEvent, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, StreamHeartbeatInterval)
+	defer cancel()
+
+	event, err := sub.Next(ctxWithTimeout)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, nil
+	}
+	return event, err
+}
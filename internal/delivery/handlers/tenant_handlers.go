@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TenantHeader - заголовок, в котором клиент явно указывает slug тенанта.
+const TenantHeader = "X-Tenant-ID"
+
+// ResolveTenant определяет тенант запроса по заголовку X-Tenant-ID или по хосту
+// и передаёт его дальше через контекст запроса. Неизвестный slug в заголовке отклоняется.
+func (h *handler) ResolveTenant(ctx *fiber.Ctx) error {
+	host := ctx.Hostname()
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	tenantID, err := h.service.ResolveTenant(ctxWithTimeout, ctx.Get(TenantHeader), host)
+	if err != nil {
+		h.logger.Errorf("Failed to resolve tenant: %v", err)
+		if errors.Is(err, services.ErrTenantNotFound) {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resolve tenant",
+		})
+	}
+
+	ctx.SetUserContext(tenant.WithID(ctx.UserContext(), tenantID))
+	return ctx.Next()
+}
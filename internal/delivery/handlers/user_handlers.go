@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -29,7 +31,7 @@ func (h *handler) RegisterUser(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
 	}
 
-	id, err := h.service.RegisterUser(&user)
+	id, err := h.service.RegisterUser(ctx.UserContext(), &user)
 	if err != nil {
 		if err.Error() == "username already exists" {
 			h.logger.Errorf("Username already exists")
@@ -53,6 +55,7 @@ func (h *handler) RegisterUser(ctx *fiber.Ctx) error {
 // @Param credentials body models.LoginRequest true "User credentials"
 // @Success 200 {object} models.LoginResponse
 // @Failure 401 {object} models.ErrorResponse "Invalid username or password"
+// @Failure 403 {object} models.ErrorResponse "Account is frozen"
 // @Router /api/v1/login [post]
 func (h *handler) LoginUser(ctx *fiber.Ctx) error {
 	var credentials struct {
@@ -63,23 +66,29 @@ func (h *handler) LoginUser(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input"})
 	}
 
-	ctxWithTimeout, cancel := context.WithTimeout(context.Background(), RequestTimeout)
+	// Адрес и клиент попадают в уведомления о неудачном входе и новой сессии
+	clientCtx := services.WithClient(ctx.UserContext(), ctx.IP(), ctx.Get(fiber.HeaderUserAgent))
+	ctxWithTimeout, cancel := context.WithTimeout(clientCtx, RequestTimeout)
 	defer cancel()
 
-	user, err := h.service.AuthenticateUser(credentials.Username, credentials.Password)
+	user, err := h.service.AuthenticateUser(ctxWithTimeout, credentials.Username, credentials.Password)
+	if errors.Is(err, services.ErrAccountFrozen) {
+		h.logger.Errorf("Login rejected: %v", err)
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		h.logger.Error("Invalid password")
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid password"})
 	}
 
 	deviceID := ctx.IP()
-	accessToken, err := h.service.NewJWT(user.ID, user.Email, deviceID, uuid.New().String())
+	accessToken, err := h.service.NewJWT(user.ID, user.TenantID, user.Email, deviceID, uuid.New().String())
 	if err != nil {
 		h.logger.Error("Failed to generate access token.")
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to generate access token."})
 	}
 
-	refreshToken, err := h.service.NewJWT(user.ID, user.Email, deviceID, uuid.New().String())
+	refreshToken, err := h.service.NewJWT(user.ID, user.TenantID, user.Email, deviceID, uuid.New().String())
 	if err != nil {
 		h.logger.Error("Failed to generate refresh token.")
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to generate refresh token."})
@@ -90,6 +99,7 @@ func (h *handler) LoginUser(ctx *fiber.Ctx) error {
 
 	refreshTokenModel := models.RefreshToken{
 		UserID:    user.ID,
+		TenantID:  user.TenantID,
 		Token:     refreshToken,
 		DeviceID:  deviceID,
 		CreatedAt: now,
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// voucherErrorStatus сопоставляет ошибки ваучеров с HTTP-статусами.
+func voucherErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrVoucherNotFound), errors.Is(err, services.ErrWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrVoucherExpired),
+		errors.Is(err, services.ErrVoucherExhausted),
+		errors.Is(err, services.ErrVoucherAlreadyRedeemed),
+		errors.Is(err, services.ErrVoucherCodeExists):
+		return fiber.StatusConflict
+	case isFrozen(err):
+		return fiber.StatusForbidden
+	case errors.Is(err, services.ErrInvalidVoucherBatch),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnsupportedCurrency):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// RedeemVoucher погашает ваучер или промокод и зачисляет его сумму на кошелёк.
+// @Summary Redeem voucher
+// @Description Погашает ваучер или промокод и зачисляет его сумму на кошелёк пользователя в валюте ваучера. Регистр, пробелы и дефисы в коде не учитываются. Один пользователь может погасить код только один раз.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param request body models.RedeemVoucherRequest true "Voucher code"
+// @Success 200 {object} models.VoucherRedemptionResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Voucher or wallet not found"
+// @Failure 409 {object} models.ErrorResponse "Voucher expired, exhausted or already redeemed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/vouchers/redeem [post]
+func (h *handler) RedeemVoucher(ctx *fiber.Ctx) error {
+	var request models.RedeemVoucherRequest
+	if err := ctx.BodyParser(&request); err != nil || request.Code == "" {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	redemption, err := h.service.RedeemVoucher(ctxWithTimeout, userID, request.Code)
+	if err != nil {
+		h.logger.Errorf("Failed to redeem voucher for user %d: %v", userID, err)
+		return ctx.Status(voucherErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.VoucherRedemptionResponse{
+		Message:    "Voucher redeemed successfully",
+		Redemption: redemption,
+	})
+}
+
+// AdminCreateVoucherBatch выпускает партию ваучеров.
+// @Summary Create voucher batch (admin)
+// @Description Выпускает партию ваучеров на фиксированную сумму в валюте со сроком действия: count случайных кодов или один промокод с заданным code. max_redemptions ограничивает число погашений каждого кода (по умолчанию 1 - одноразовые коды).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body models.VoucherBatchRequest true "Voucher batch"
+// @Success 201 {object} models.VoucherBatchResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 409 {object} models.ErrorResponse "Voucher code already exists"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/vouchers [post]
+func (h *handler) AdminCreateVoucherBatch(ctx *fiber.Ctx) error {
+	var request models.VoucherBatchRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	operatorID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	batch, err := h.service.CreateVoucherBatch(ctxWithTimeout, operatorID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to create voucher batch: %v", err)
+		return ctx.Status(voucherErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.VoucherBatchResponse{
+		Message: "Voucher batch created successfully",
+		Batch:   batch,
+	})
+}
+
+// AdminGetVoucherBatches возвращает партии ваучеров с числом погашений.
+// @Summary List voucher batches (admin)
+// @Description Возвращает последние партии ваучеров с количеством кодов и погашений.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.VoucherBatchesResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/vouchers [get]
+func (h *handler) AdminGetVoucherBatches(ctx *fiber.Ctx) error {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	batches, err := h.service.GetVoucherBatches(ctxWithTimeout)
+	if err != nil {
+		h.logger.Errorf("Failed to get voucher batches: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get voucher batches",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.VoucherBatchesResponse{Batches: batches})
+}
+
+// AdminGetVoucherBatch возвращает партию ваучеров с кодами и их погашениями.
+// @Summary Get voucher batch (admin)
+// @Description Возвращает партию ваучеров со всеми кодами и количеством погашений каждого.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Voucher batch ID"
+// @Success 200 {object} models.VoucherBatchResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Voucher batch not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/vouchers/{id} [get]
+func (h *handler) AdminGetVoucherBatch(ctx *fiber.Ctx) error {
+	batchID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	batch, err := h.service.GetVoucherBatch(ctxWithTimeout, batchID)
+	if err != nil {
+		h.logger.Errorf("Failed to get voucher batch %d: %v", batchID, err)
+		return ctx.Status(voucherErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.VoucherBatchResponse{
+		Message: "Voucher batch retrieved successfully",
+		Batch:   batch,
+	})
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
 	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,11 +21,12 @@ func extractUserIDFromToken(c *fiber.Ctx) (uint64, error) {
 
 // GetBalance возвращает баланс пользователя.
 // @Summary Get user balance
-// @Description Получает текущий баланс пользователя.
+// @Description Получает текущий учётный и доступный (за вычетом блокировок) баланс пользователя и версии кошельков. Заголовок ETag можно передать в If-Match пополнения, вывода или обмена, чтобы операция не выполнилась, если баланс успел измениться.
 // @Tags Wallet
 // @Accept json
 // @Produce json
 // @Success 200 {object} models.BalanceResponse
+// @Header 200 {string} ETag "Balance version"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Security     BearerAuth
@@ -47,22 +50,67 @@ func (h *handler) GetBalance(ctx *fiber.Ctx) error {
 		})
 	}
 
-	// Возвращаем ответ с балансом
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
-		"balance": balance,
+	// Возвращаем ответ с учётным и доступным балансом
+	ctx.Set(fiber.HeaderETag, balance.ETag)
+	return ctx.Status(fiber.StatusOK).JSON(models.BalanceResponse{
+		Balance:      balance.Total,
+		Available:    balance.Available,
+		CreditLimits: balance.CreditLimits,
+		Pots:         balance.Pots,
+		Versions:     balance.Versions,
 	})
 }
 
+// GetBalanceValuation оценивает кошельки пользователя в базовой валюте.
+// @Summary Get portfolio valuation
+// @Description Оценивает каждый кошелёк и весь портфель пользователя в базовой валюте по текущим курсам.
+// @Tags Wallet
+// @Produce json
+// @Param base query string false "Base currency" default(USD)
+// @Success 200 {object} models.ValuationResponse
+// @Failure 400 {object} models.ErrorResponse "Unknown base currency"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/balance/valuation [get]
+func (h *handler) GetBalanceValuation(ctx *fiber.Ctx) error {
+	// Извлекаем ID пользователя из токена
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	valuation, err := h.service.GetValuation(ctx.UserContext(), userID, ctx.Query("base", services.DefaultValuationBase))
+	if err != nil {
+		h.logger.Errorf("Failed to get valuation for user %d: %v", userID, err)
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrRateUnavailable) || errors.Is(err, services.ErrUnsupportedCurrency) {
+			status = fiber.StatusBadRequest
+		}
+		return ctx.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(valuation)
+}
+
 // Deposit пополняет баланс пользователя.
 // @Summary Deposit funds to user balance
-// @Description Пополняет баланс пользователя на указанную сумму.
+// @Description Пополняет баланс на указанную сумму без платёжного провайдера. Доступно только администраторам; пользователи пополняют кошелёк через провайдеров по коду пополнения.
 // @Tags Wallet
 // @Accept json
 // @Produce json
 // @Param deposit body models.DepositRequest true "Deposit request"
+// @Param If-Match header string false "Balance ETag"
 // @Success 200 {object} models.DepositResponse
 // @Failure 400 {object} models.ErrorResponse "Invalid input"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Admin role required, account or wallet is frozen"
+// @Failure 412 {object} models.ErrorResponse "Balance has changed"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Security     BearerAuth
 // @Router /api/v1/wallet/deposit [post]
@@ -85,7 +133,19 @@ func (h *handler) Deposit(ctx *fiber.Ctx) error {
 	}
 
 	// Пополнение счета
-	newBalance, err := h.service.Deposit(userID, deposit.Amount, deposit.Currency)
+	newBalance, err := h.service.Deposit(services.WithIfMatch(ctx.UserContext(), ctx.Get(fiber.HeaderIfMatch)), userID, deposit.Amount, deposit.Currency)
+	if errors.Is(err, services.ErrPreconditionFailed) {
+		h.logger.Errorf("Deposit rejected: %v", err)
+		return ctx.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if isFrozen(err) {
+		h.logger.Errorf("Deposit rejected: %v", err)
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		h.logger.Errorf("Invalid amount or currency")
 		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -106,9 +166,12 @@ func (h *handler) Deposit(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param withdraw body models.WithdrawRequest true "Withdraw request"
+// @Param If-Match header string false "Balance ETag"
 // @Success 200 {object} models.WithdrawResponse
 // @Failure 400 {object} models.ErrorResponse "Invalid input"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Account or wallet is frozen"
+// @Failure 412 {object} models.ErrorResponse "Balance has changed"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Security     BearerAuth
 // @Router /api/v1/wallet/withdraw [post]
@@ -131,7 +194,19 @@ func (h *handler) Withdraw(ctx *fiber.Ctx) error {
 	}
 
 	// Вывод средств
-	newBalance, err := h.service.Withdraw(userID, withdraw.Amount, withdraw.Currency)
+	newBalance, err := h.service.Withdraw(services.WithIfMatch(ctx.UserContext(), ctx.Get(fiber.HeaderIfMatch)), userID, withdraw.Amount, withdraw.Currency)
+	if errors.Is(err, services.ErrPreconditionFailed) {
+		h.logger.Errorf("Withdrawal rejected: %v", err)
+		return ctx.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if isFrozen(err) {
+		h.logger.Errorf("Withdrawal rejected: %v", err)
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		h.logger.Errorf("Insufficient funds or invalid amount")
 		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -147,7 +222,7 @@ func (h *handler) Withdraw(ctx *fiber.Ctx) error {
 
 // GetExchangeRates получает актуальные курсы валют.
 // @Summary Get exchange rates
-// @Description Получает актуальные курсы валют для различных валютных пар.
+// @Description Получает актуальные курсы включённых валют справочника.
 // @Tags Exchange
 // @Accept json
 // @Produce json
@@ -155,7 +230,10 @@ func (h *handler) Withdraw(ctx *fiber.Ctx) error {
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Router /api/v1/wallet/rates [get]
 func (h *handler) GetExchangeRates(ctx *fiber.Ctx) error {
-	rates, err := h.service.GetAllRates()
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	rates, err := h.service.GetEnabledRates(ctxWithTimeout)
 	if err != nil {
 		h.logger.Errorf("Failed to retrieve exchange rates")
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -163,12 +241,13 @@ func (h *handler) GetExchangeRates(ctx *fiber.Ctx) error {
 		})
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
-		"rates": rates,
-		"USD":   rates["USD"],
-		"RUB":   rates["RUB"],
-		"EUR":   rates["EUR"],
-	})
+	// Курс каждой включённой валюты также возвращается полем верхнего уровня
+	response := fiber.Map{"rates": rates}
+	for code, rate := range rates {
+		response[code] = rate
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
 }
 
 // ExchangeCurrency - обработка обмена валют.
@@ -178,9 +257,12 @@ func (h *handler) GetExchangeRates(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param exchange body models.ExchangeRequest true "Exchange request"
+// @Param If-Match header string false "Balance ETag"
 // @Success 200 {object} models.ExchangeResponse
 // @Failure 400 {object} models.ErrorResponse "Insufficient funds or invalid currencies"
 // @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Account or wallet is frozen"
+// @Failure 412 {object} models.ErrorResponse "Balance has changed"
 // @Failure 500 {object} models.ErrorResponse "Internal Server Error"
 // @Security     BearerAuth
 // @Router /api/v1/wallet/exchange [post]
@@ -204,39 +286,40 @@ func (h *handler) ExchangeCurrency(ctx *fiber.Ctx) error {
 		})
 	}
 
-	// Проверка наличия достаточного баланса
-	userBalance, err := h.service.GetBalance(userID)
+	// Обмен валюты с обновлением баланса пользователя
+	exchangedAmount, newBalance, err := h.service.Exchange(services.WithIfMatch(ctx.UserContext(), ctx.Get(fiber.HeaderIfMatch)), userID, exchangeRequest.FromCurrency, exchangeRequest.ToCurrency, exchangeRequest.Amount)
 	if err != nil {
-		h.logger.Errorf("Failed to retrieve user balance for user %d: %v", userID, err)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve user balance",
-		})
-	}
-
-	fromCurrencyBalance, ok := userBalance[exchangeRequest.FromCurrency]
-	if !ok || fromCurrencyBalance < exchangeRequest.Amount {
-		h.logger.Errorf("Insufficient funds or invalid currencies")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Insufficient funds or invalid currencies",
-		})
-	}
-
-	// Конвертация валюты
-	exchangedAmount, err := h.service.ExchangeCurrency(exchangeRequest.FromCurrency, exchangeRequest.ToCurrency, exchangeRequest.Amount)
-	if err != nil {
-		h.logger.Errorf("Failed to convert currency: %v", err)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Currency conversion failed",
-		})
-	}
-
-	// Обновление баланса пользователя
-	newBalance, err := h.service.UpdateUserBalance(userID, exchangeRequest.FromCurrency, exchangeRequest.ToCurrency, exchangeRequest.Amount, exchangedAmount)
-	if err != nil {
-		h.logger.Errorf("Failed to update user balance: %v", err)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update user balance",
-		})
+		switch {
+		case isFrozen(err):
+			h.logger.Errorf("Exchange rejected: %v", err)
+			return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrPreconditionFailed):
+			h.logger.Errorf("Exchange rejected: %v", err)
+			return ctx.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrInsufficientFunds),
+			errors.Is(err, services.ErrWalletNotFound),
+			errors.Is(err, services.ErrSameCurrency),
+			errors.Is(err, services.ErrUnsupportedCurrency),
+			errors.Is(err, services.ErrInvalidAmount):
+			h.logger.Errorf("Insufficient funds or invalid currencies")
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Insufficient funds or invalid currencies",
+			})
+		case errors.Is(err, services.ErrConversionFailed):
+			h.logger.Errorf("Failed to convert currency: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Currency conversion failed",
+			})
+		default:
+			h.logger.Errorf("Failed to update user balance: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update user balance",
+			})
+		}
 	}
 
 	// Возвращаем успешный ответ
@@ -0,0 +1,759 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// walletMemberErrorStatus сопоставляет ошибки совместных кошельков с HTTP-статусами.
+func walletMemberErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrWalletNotFound),
+		errors.Is(err, services.ErrRecipientWalletNotFound),
+		errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, services.ErrMemberNotFound),
+		errors.Is(err, services.ErrInvitationNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrWalletForbidden), isFrozen(err):
+		return fiber.StatusForbidden
+	case errors.Is(err, services.ErrMemberExists):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrPreconditionFailed):
+		return fiber.StatusPreconditionFailed
+	case errors.Is(err, services.ErrInvalidMember),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrInsufficientFunds),
+		errors.Is(err, services.ErrSpendLimitExceeded),
+		errors.Is(err, services.ErrSameCurrency),
+		errors.Is(err, services.ErrSelfTransfer),
+		errors.Is(err, services.ErrInvalidPot):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// GetWallets возвращает кошельки, доступные пользователю.
+// @Summary List accessible wallets
+// @Description Возвращает собственные кошельки пользователя и совместные кошельки, в которых он участвует, с его ролью.
+// @Tags Shared wallets
+// @Produce json
+// @Success 200 {object} models.WalletsResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets [get]
+func (h *handler) GetWallets(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	wallets, err := h.service.GetAccessibleWallets(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get wallets for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get wallets",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WalletsResponse{Wallets: wallets})
+}
+
+// GetWallet возвращает кошелёк по ID.
+// @Summary Get wallet
+// @Description Возвращает баланс, версию кошелька и роль пользователя в нём. Доступно владельцу и любому участнику. Заголовок ETag можно передать в If-Match операций с кошельком.
+// @Tags Shared wallets
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Success 200 {object} models.WalletAccess
+// @Header 200 {string} ETag "Wallet version"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id} [get]
+func (h *handler) GetWallet(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	wallet, err := h.service.GetSharedWallet(ctxWithTimeout, userID, walletID)
+	if err != nil {
+		h.logger.Errorf("Failed to get wallet %d for user %d: %v", walletID, userID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderETag, services.WalletETag(wallet.Version))
+	return ctx.Status(fiber.StatusOK).JSON(wallet)
+}
+
+// DepositToWallet пополняет кошелёк по ID.
+// @Summary Deposit to wallet by ID
+// @Description Пополняет кошелёк. Доступно владельцам и участникам с ролью spender.
+// @Tags Shared wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param deposit body models.WalletAmountRequest true "Amount"
+// @Param If-Match header string false "Wallet ETag"
+// @Success 200 {object} models.WalletOperationResponse
+// @Header 200 {string} ETag "Wallet version"
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 412 {object} models.ErrorResponse "Wallet has changed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/deposit [post]
+func (h *handler) DepositToWallet(ctx *fiber.Ctx) error {
+	return h.operateWallet(ctx, false)
+}
+
+// WithdrawFromWallet списывает средства с кошелька по ID.
+// @Summary Withdraw from wallet by ID
+// @Description Списывает средства с кошелька. Доступно владельцам и участникам с ролью spender в пределах их лимита списаний за скользящие 30 дней.
+// @Tags Shared wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param withdraw body models.WalletAmountRequest true "Amount"
+// @Param If-Match header string false "Wallet ETag"
+// @Success 200 {object} models.WalletOperationResponse
+// @Header 200 {string} ETag "Wallet version"
+// @Failure 400 {object} models.ErrorResponse "Invalid input, insufficient funds or spend limit exceeded"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 412 {object} models.ErrorResponse "Wallet has changed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/withdraw [post]
+func (h *handler) WithdrawFromWallet(ctx *fiber.Ctx) error {
+	return h.operateWallet(ctx, true)
+}
+
+// ExchangeFromWallet обменивает средства кошелька по ID.
+// @Summary Exchange from wallet by ID
+// @Description Обменивает сумму с кошелька по актуальному курсу и зачисляет результат на кошелёк владельца в валюте to_currency. Доступно владельцам и участникам с ролью spender в обоих кошельках; списание участника spender учитывается в его лимите.
+// @Tags Shared wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param exchange body models.WalletExchangeRequest true "Exchange request"
+// @Param If-Match header string false "Wallet ETag"
+// @Success 200 {object} models.ExchangeResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input, insufficient funds or spend limit exceeded"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 412 {object} models.ErrorResponse "Wallet has changed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/exchange [post]
+func (h *handler) ExchangeFromWallet(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.WalletExchangeRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(services.WithIfMatch(ctx.UserContext(), ctx.Get(fiber.HeaderIfMatch)), RequestTimeout)
+	defer cancel()
+
+	exchangedAmount, newBalance, err := h.service.ExchangeFromWallet(ctxWithTimeout, userID, walletID, request.ToCurrency, request.Amount)
+	if err != nil {
+		h.logger.Errorf("Failed to exchange from wallet %d for user %d: %v", walletID, userID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.ExchangeResponse{
+		Message:         "Exchange successful",
+		ExchangedAmount: exchangedAmount,
+		NewBalance:      newBalance,
+	})
+}
+
+// TransferFromWallet переводит средства с кошелька по ID другому пользователю.
+// @Summary Transfer from wallet by ID
+// @Description Переводит сумму с кошелька на кошелёк пользователя to_user_id в той же валюте. Доступно владельцам и участникам с ролью spender в пределах их лимита списаний.
+// @Tags Shared wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param transfer body models.WalletTransferRequest true "Transfer request"
+// @Param If-Match header string false "Wallet ETag"
+// @Success 200 {object} models.WalletOperationResponse
+// @Header 200 {string} ETag "Wallet version"
+// @Failure 400 {object} models.ErrorResponse "Invalid input, insufficient funds or spend limit exceeded"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Wallet or recipient wallet not found"
+// @Failure 412 {object} models.ErrorResponse "Wallet has changed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/transfer [post]
+func (h *handler) TransferFromWallet(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.WalletTransferRequest
+	if err := ctx.BodyParser(&request); err != nil || request.ToUserID == 0 {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(services.WithIfMatch(ctx.UserContext(), ctx.Get(fiber.HeaderIfMatch)), RequestTimeout)
+	defer cancel()
+
+	wallet, err := h.service.TransferFromWallet(ctxWithTimeout, userID, walletID, request.ToUserID, request.Amount)
+	if err != nil {
+		h.logger.Errorf("Failed to transfer from wallet %d for user %d: %v", walletID, userID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderETag, services.WalletETag(wallet.Version))
+	return ctx.Status(fiber.StatusOK).JSON(models.WalletOperationResponse{
+		Message:    "Transfer successful",
+		WalletID:   wallet.ID,
+		NewBalance: wallet.Balance,
+		Version:    wallet.Version,
+	})
+}
+
+// AuthorizeWalletHold блокирует средства на кошельке по ID.
+// @Summary Authorize hold on wallet by ID
+// @Description Блокирует сумму на кошельке. Доступно владельцам и участникам с ролью spender; сумма блокировки участника spender должна укладываться в его лимит. Списать и освободить блокировку можно через /api/v1/holds/{id}.
+// @Tags Shared wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param hold body models.WalletHoldRequest true "Hold request"
+// @Param If-Match header string false "Wallet ETag"
+// @Success 201 {object} models.HoldResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input, insufficient funds or spend limit exceeded"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Role does not allow the operation or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 412 {object} models.ErrorResponse "Wallet has changed"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/holds [post]
+func (h *handler) AuthorizeWalletHold(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.WalletHoldRequest
+	if err := ctx.BodyParser(&request); err != nil || request.ExpiresIn < 0 {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(services.WithIfMatch(ctx.UserContext(), ctx.Get(fiber.HeaderIfMatch)), RequestTimeout)
+	defer cancel()
+
+	ttl := time.Duration(request.ExpiresIn) * time.Second
+	hold, err := h.service.AuthorizeWalletHold(ctxWithTimeout, userID, walletID, request.Amount, request.Reference, ttl)
+	if err != nil {
+		h.logger.Errorf("Failed to authorize hold on wallet %d for user %d: %v", walletID, userID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.HoldResponse{
+		Message: "Funds held successfully",
+		Hold:    hold,
+	})
+}
+
+// GetWalletPots возвращает копилки кошелька по ID.
+// @Summary List wallet pots
+// @Description Возвращает все копилки кошелька, включая закрытые. Доступно любому участнику.
+// @Tags Shared wallets
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Success 200 {object} models.PotsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/pots [get]
+func (h *handler) GetWalletPots(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	pots, err := h.service.GetWalletPots(ctxWithTimeout, userID, walletID)
+	if err != nil {
+		h.logger.Errorf("Failed to get pots of wallet %d: %v", walletID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.PotsResponse{Pots: pots})
+}
+
+// CreateWalletPot создаёт копилку в кошельке по ID.
+// @Summary Create pot in wallet by ID
+// @Description Создаёт копилку в кошельке. Доступно владельцам; распоряжаться копилкой через /api/v1/pots/{id} также могут только владельцы.
+// @Tags Shared wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param pot body models.WalletPotRequest true "Pot request"
+// @Success 201 {object} models.PotResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only owners can create pots"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/pots [post]
+func (h *handler) CreateWalletPot(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.WalletPotRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	pot, err := h.service.CreateWalletPot(ctxWithTimeout, userID, walletID, &models.PotRequest{
+		Name:       request.Name,
+		GoalAmount: request.GoalAmount,
+		GoalDate:   request.GoalDate,
+	})
+	if err != nil {
+		h.logger.Errorf("Failed to create pot in wallet %d: %v", walletID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.PotResponse{
+		Message: "Pot created successfully",
+		Pot:     pot,
+	})
+}
+
+// GetWalletMembers возвращает участников кошелька.
+// @Summary List wallet members
+// @Description Возвращает участников совместного кошелька и неподтверждённые приглашения. Доступно любому участнику.
+// @Tags Shared wallets
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Success 200 {object} models.WalletMembersResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/members [get]
+func (h *handler) GetWalletMembers(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	members, err := h.service.GetWalletMembers(ctxWithTimeout, userID, walletID)
+	if err != nil {
+		h.logger.Errorf("Failed to get members of wallet %d: %v", walletID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WalletMembersResponse{Members: members})
+}
+
+// InviteWalletMember приглашает пользователя в кошелёк.
+// @Summary Invite wallet member
+// @Description Приглашает пользователя в совместный кошелёк с ролью owner, spender (с обязательным лимитом на операцию) или viewer. Доступно владельцам.
+// @Tags Shared wallets
+// @Accept json
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param member body models.WalletMemberRequest true "Invitation"
+// @Success 201 {object} models.WalletMemberResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only owners can invite"
+// @Failure 404 {object} models.ErrorResponse "Wallet or user not found"
+// @Failure 409 {object} models.ErrorResponse "User is already a member"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/members [post]
+func (h *handler) InviteWalletMember(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.WalletMemberRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	member, err := h.service.InviteWalletMember(ctxWithTimeout, userID, walletID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to invite member to wallet %d: %v", walletID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.WalletMemberResponse{
+		Message: "Invitation sent successfully",
+		Member:  member,
+	})
+}
+
+// RemoveWalletMember исключает участника из кошелька.
+// @Summary Remove wallet member
+// @Description Исключает участника или отзывает приглашение. Владельцы могут исключить любого участника, остальные - только выйти из кошелька сами.
+// @Tags Shared wallets
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Param userId path int true "Member user ID"
+// @Success 200 {object} models.WalletMemberResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Only owners can remove other members"
+// @Failure 404 {object} models.ErrorResponse "Wallet or member not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallets/{id}/members/{userId} [delete]
+func (h *handler) RemoveWalletMember(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	memberID, err := parseIDParam(ctx, "userId")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	if err := h.service.RemoveWalletMember(ctxWithTimeout, userID, walletID, memberID); err != nil {
+		h.logger.Errorf("Failed to remove member %d from wallet %d: %v", memberID, walletID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WalletMemberResponse{
+		Message: "Member removed successfully",
+	})
+}
+
+// GetWalletInvitations возвращает приглашения пользователя.
+// @Summary List wallet invitations
+// @Description Возвращает неподтверждённые приглашения пользователя в совместные кошельки.
+// @Tags Shared wallets
+// @Produce json
+// @Success 200 {object} models.WalletMembersResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallet-invitations [get]
+func (h *handler) GetWalletInvitations(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	invitations, err := h.service.GetWalletInvitations(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get wallet invitations for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get wallet invitations",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WalletMembersResponse{Members: invitations})
+}
+
+// AcceptWalletInvitation принимает приглашение в кошелёк.
+// @Summary Accept wallet invitation
+// @Description Принимает приглашение в совместный кошелёк; после этого операции доступны согласно роли.
+// @Tags Shared wallets
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Success 200 {object} models.WalletMemberResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Invitation not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallet-invitations/{id}/accept [post]
+func (h *handler) AcceptWalletInvitation(ctx *fiber.Ctx) error {
+	return h.answerWalletInvitation(ctx, true)
+}
+
+// DeclineWalletInvitation отклоняет приглашение в кошелёк.
+// @Summary Decline wallet invitation
+// @Description Отклоняет приглашение в совместный кошелёк.
+// @Tags Shared wallets
+// @Produce json
+// @Param id path int true "Wallet ID"
+// @Success 200 {object} models.WalletMemberResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Invitation not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/wallet-invitations/{id}/decline [post]
+func (h *handler) DeclineWalletInvitation(ctx *fiber.Ctx) error {
+	return h.answerWalletInvitation(ctx, false)
+}
+
+func (h *handler) answerWalletInvitation(ctx *fiber.Ctx, accept bool) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	response := models.WalletMemberResponse{Message: "Invitation declined"}
+	if accept {
+		response.Message = "Invitation accepted"
+		response.Member, err = h.service.AcceptWalletInvitation(ctxWithTimeout, userID, walletID)
+	} else {
+		err = h.service.DeclineWalletInvitation(ctxWithTimeout, userID, walletID)
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to answer invitation to wallet %d: %v", walletID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response)
+}
+
+// operateWallet пополняет кошелёк по ID или, при withdraw, списывает с него средства.
+func (h *handler) operateWallet(ctx *fiber.Ctx, withdraw bool) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	walletID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.WalletAmountRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(services.WithIfMatch(ctx.UserContext(), ctx.Get(fiber.HeaderIfMatch)), RequestTimeout)
+	defer cancel()
+
+	var wallet *models.Wallet
+	message := "Account topped up successfully"
+	if withdraw {
+		message = "Withdrawal successful"
+		wallet, err = h.service.WithdrawFromWallet(ctxWithTimeout, userID, walletID, request.Amount)
+	} else {
+		wallet, err = h.service.DepositToWallet(ctxWithTimeout, userID, walletID, request.Amount)
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to operate wallet %d for user %d: %v", walletID, userID, err)
+		return ctx.Status(walletMemberErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx.Set(fiber.HeaderETag, services.WalletETag(wallet.Version))
+	return ctx.Status(fiber.StatusOK).JSON(models.WalletOperationResponse{
+		Message:    message,
+		WalletID:   wallet.ID,
+		NewBalance: wallet.Balance,
+		Version:    wallet.Version,
+	})
+}
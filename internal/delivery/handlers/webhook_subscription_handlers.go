@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// webhookErrorStatus сопоставляет ошибки вебхуков с HTTP-статусами.
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrInvalidWebhook):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// CreateWebhookSubscription подписывает пользователя на события его кошельков.
+// @Summary Create webhook subscription
+// @Description Подписывает пользователя на события его кошельков (wallet.deposit, wallet.withdrawal, wallet.exchange_out, wallet.exchange_in и другие типы проводок или "*" - все события) с доставкой POST-запросом на url. Каждая доставка подписывается: заголовок X-Webhook-Signature содержит "sha256=" и HMAC-SHA256 строки "<X-Webhook-Timestamp>.<тело>" на секрете подписки. Если секрет не задан, он генерируется и возвращается только в этом ответе.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body models.WebhookSubscriptionRequest true "Webhook subscription"
+// @Success 201 {object} models.WebhookSubscriptionResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/webhook-subscriptions [post]
+func (h *handler) CreateWebhookSubscription(ctx *fiber.Ctx) error {
+	var request models.WebhookSubscriptionRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	subscription, err := h.service.CreateWebhookSubscription(ctxWithTimeout, userID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to create webhook subscription for user %d: %v", userID, err)
+		return ctx.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.WebhookSubscriptionResponse{
+		Message:      "Webhook subscription created successfully",
+		Subscription: subscription,
+		Secret:       subscription.Secret,
+	})
+}
+
+// GetWebhookSubscriptions возвращает подписки пользователя на вебхуки.
+// @Summary List webhook subscriptions
+// @Description Возвращает подписки пользователя на вебхуки без секретов.
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {object} models.WebhookSubscriptionsResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/webhook-subscriptions [get]
+func (h *handler) GetWebhookSubscriptions(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	subscriptions, err := h.service.GetWebhookSubscriptions(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get webhook subscriptions for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get webhook subscriptions",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WebhookSubscriptionsResponse{Subscriptions: subscriptions})
+}
+
+// DeleteWebhookSubscription удаляет подписку на вебхуки.
+// @Summary Delete webhook subscription
+// @Description Удаляет подписку вместе с её доставками и журналом попыток.
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} models.WebhookSubscriptionResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Subscription not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/webhook-subscriptions/{id} [delete]
+func (h *handler) DeleteWebhookSubscription(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	subscriptionID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	if err := h.service.DeleteWebhookSubscription(ctxWithTimeout, userID, subscriptionID); err != nil {
+		h.logger.Errorf("Failed to delete webhook subscription %d: %v", subscriptionID, err)
+		return ctx.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WebhookSubscriptionResponse{
+		Message: "Webhook subscription deleted successfully",
+	})
+}
+
+// GetWebhookDeliveries возвращает последние доставки подписки.
+// @Summary List webhook deliveries
+// @Description Возвращает последние доставки подписки со статусом (pending, succeeded, dead), числом попыток, кодом ответа и ошибкой последней попытки.
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Success 200 {object} models.WebhookDeliveriesResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Subscription not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/webhook-subscriptions/{id}/deliveries [get]
+func (h *handler) GetWebhookDeliveries(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	subscriptionID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	deliveries, err := h.service.GetWebhookDeliveries(ctxWithTimeout, userID, subscriptionID)
+	if err != nil {
+		h.logger.Errorf("Failed to get deliveries of webhook subscription %d: %v", subscriptionID, err)
+		return ctx.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WebhookDeliveriesResponse{Deliveries: deliveries})
+}
+
+// GetWebhookDelivery возвращает доставку с журналом попыток.
+// @Summary Get webhook delivery
+// @Description Возвращает доставку вебхука с телом события и журналом всех попыток.
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 200 {object} models.WebhookDeliveryResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Subscription or delivery not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/webhook-subscriptions/{id}/deliveries/{deliveryId} [get]
+func (h *handler) GetWebhookDelivery(ctx *fiber.Ctx) error {
+	return h.webhookDelivery(ctx, false)
+}
+
+// RedeliverWebhook повторно отправляет доставку.
+// @Summary Redeliver webhook
+// @Description Ставит доставку в очередь на немедленную повторную отправку с новым счётчиком попыток, в том числе успешную или перешедшую в статус dead.
+// @Tags Webhooks
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 200 {object} models.WebhookDeliveryResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Subscription or delivery not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/webhook-subscriptions/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *handler) RedeliverWebhook(ctx *fiber.Ctx) error {
+	return h.webhookDelivery(ctx, true)
+}
+
+// webhookDelivery возвращает доставку подписки или, при redeliver, ставит её на повторную отправку.
+func (h *handler) webhookDelivery(ctx *fiber.Ctx, redeliver bool) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	subscriptionID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	deliveryID, err := parseIDParam(ctx, "deliveryId")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	var delivery *models.WebhookDelivery
+	message := "Webhook delivery retrieved successfully"
+	if redeliver {
+		message = "Webhook delivery queued for redelivery"
+		delivery, err = h.service.RedeliverWebhook(ctxWithTimeout, userID, subscriptionID, deliveryID)
+	} else {
+		delivery, err = h.service.GetWebhookDelivery(ctxWithTimeout, userID, subscriptionID, deliveryID)
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to process webhook delivery %d: %v", deliveryID, err)
+		return ctx.Status(webhookErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WebhookDeliveryResponse{
+		Message:  message,
+		Delivery: delivery,
+	})
+}
@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// withdrawalErrorStatus сопоставляет ошибки вывода средств с HTTP-статусами.
+func withdrawalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrWithdrawalNotFound), errors.Is(err, services.ErrWalletNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrWithdrawalStateConflict):
+		return fiber.StatusConflict
+	case isFrozen(err):
+		return fiber.StatusForbidden
+	case errors.Is(err, services.ErrPayoutUnavailable):
+		return fiber.StatusServiceUnavailable
+	case errors.Is(err, services.ErrInvalidWithdrawal),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrInsufficientFunds),
+		errors.Is(err, services.ErrReasonRequired):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// RequestWithdrawal создаёт вывод средств.
+// @Summary Request withdrawal
+// @Description Создаёт вывод средств на указанные реквизиты. Сумма блокируется на кошельке и списывается только после подтверждения выплаты провайдером; до одобрения администратором вывод можно отменить.
+// @Tags Withdrawals
+// @Accept json
+// @Produce json
+// @Param request body models.WithdrawalRequest true "Withdrawal request"
+// @Success 201 {object} models.WithdrawalResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input or insufficient funds"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Account or wallet is frozen"
+// @Failure 404 {object} models.ErrorResponse "Wallet not found"
+// @Failure 503 {object} models.ErrorResponse "Payout provider is not configured"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/withdrawals [post]
+func (h *handler) RequestWithdrawal(ctx *fiber.Ctx) error {
+	var request models.WithdrawalRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	withdrawal, err := h.service.RequestWithdrawal(ctxWithTimeout, userID, &request)
+	if err != nil {
+		h.logger.Errorf("Failed to request withdrawal for user %d: %v", userID, err)
+		return ctx.Status(withdrawalErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.WithdrawalResponse{
+		Message:    "Withdrawal requested successfully",
+		Withdrawal: withdrawal,
+	})
+}
+
+// GetWithdrawals возвращает выводы средств пользователя.
+// @Summary List withdrawals
+// @Description Возвращает выводы средств пользователя с текущими статусами, начиная с последних.
+// @Tags Withdrawals
+// @Produce json
+// @Success 200 {object} models.WithdrawalsResponse
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/withdrawals [get]
+func (h *handler) GetWithdrawals(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	withdrawals, err := h.service.GetWithdrawals(ctxWithTimeout, userID)
+	if err != nil {
+		h.logger.Errorf("Failed to get withdrawals for user %d: %v", userID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get withdrawals",
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WithdrawalsResponse{Withdrawals: withdrawals})
+}
+
+// GetWithdrawal возвращает вывод средств и историю его статусов.
+// @Summary Get withdrawal
+// @Description Возвращает вывод средств пользователя и историю переходов по статусам (requested, held, approved, sent, settled, failed, returned, cancelled, manual_review).
+// @Tags Withdrawals
+// @Produce json
+// @Param id path int true "Withdrawal ID"
+// @Success 200 {object} models.WithdrawalDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Withdrawal not found"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/withdrawals/{id} [get]
+func (h *handler) GetWithdrawal(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	withdrawalID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	withdrawal, events, err := h.service.GetWithdrawal(ctxWithTimeout, userID, withdrawalID)
+	if err != nil {
+		h.logger.Errorf("Failed to get withdrawal %d: %v", withdrawalID, err)
+		return ctx.Status(withdrawalErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WithdrawalDetailsResponse{
+		Withdrawal: withdrawal,
+		Events:     events,
+	})
+}
+
+// CancelWithdrawal отменяет ещё не одобренный вывод средств.
+// @Summary Cancel withdrawal
+// @Description Отменяет вывод средств, ещё не одобренный администратором, и снимает блокировку суммы.
+// @Tags Withdrawals
+// @Produce json
+// @Param id path int true "Withdrawal ID"
+// @Success 200 {object} models.WithdrawalResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Withdrawal not found"
+// @Failure 409 {object} models.ErrorResponse "Withdrawal is already approved or finished"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/withdrawals/{id}/cancel [post]
+func (h *handler) CancelWithdrawal(ctx *fiber.Ctx) error {
+	userID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	withdrawalID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	withdrawal, err := h.service.CancelWithdrawal(ctxWithTimeout, userID, withdrawalID)
+	if err != nil {
+		h.logger.Errorf("Failed to cancel withdrawal %d: %v", withdrawalID, err)
+		return ctx.Status(withdrawalErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WithdrawalResponse{
+		Message:    "Withdrawal cancelled successfully",
+		Withdrawal: withdrawal,
+	})
+}
+
+// AdminGetWithdrawals возвращает выводы средств в указанном статусе.
+// @Summary List withdrawals by status (admin)
+// @Description Возвращает выводы средств в статусе status, начиная с самых давних. По умолчанию - ожидающие одобрения (held).
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Withdrawal status (default held)"
+// @Success 200 {object} models.WithdrawalsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/withdrawals [get]
+func (h *handler) AdminGetWithdrawals(ctx *fiber.Ctx) error {
+	status := ctx.Query("status", models.WithdrawalStatusHeld)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	withdrawals, err := h.service.GetWithdrawalsByStatus(ctxWithTimeout, status)
+	if err != nil {
+		h.logger.Errorf("Failed to get withdrawals: %v", err)
+		return ctx.Status(withdrawalErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WithdrawalsResponse{Withdrawals: withdrawals})
+}
+
+// AdminApproveWithdrawal одобряет вывод средств.
+// @Summary Approve withdrawal (admin)
+// @Description Одобряет вывод средств; одобренный вывод отправляется провайдеру выплат фоновой задачей.
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Withdrawal ID"
+// @Success 200 {object} models.WithdrawalResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Withdrawal not found"
+// @Failure 409 {object} models.ErrorResponse "Withdrawal is not awaiting approval"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/withdrawals/{id}/approve [post]
+func (h *handler) AdminApproveWithdrawal(ctx *fiber.Ctx) error {
+	operatorID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	withdrawalID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	withdrawal, err := h.service.ApproveWithdrawal(ctxWithTimeout, operatorID, withdrawalID)
+	if err != nil {
+		h.logger.Errorf("Failed to approve withdrawal %d: %v", withdrawalID, err)
+		return ctx.Status(withdrawalErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WithdrawalResponse{
+		Message:    "Withdrawal approved successfully",
+		Withdrawal: withdrawal,
+	})
+}
+
+// AdminRejectWithdrawal отклоняет вывод средств.
+// @Summary Reject withdrawal (admin)
+// @Description Отклоняет ещё не одобренный вывод средств с указанием причины и снимает блокировку суммы.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Withdrawal ID"
+// @Param request body models.RejectWithdrawalRequest true "Rejection reason"
+// @Success 200 {object} models.WithdrawalResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid input"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden"
+// @Failure 404 {object} models.ErrorResponse "Withdrawal not found"
+// @Failure 409 {object} models.ErrorResponse "Withdrawal is not awaiting approval"
+// @Failure 500 {object} models.ErrorResponse "Internal Server Error"
+// @Security     BearerAuth
+// @Router /api/v1/admin/withdrawals/{id}/reject [post]
+func (h *handler) AdminRejectWithdrawal(ctx *fiber.Ctx) error {
+	operatorID, err := extractUserIDFromToken(ctx)
+	if err != nil {
+		h.logger.Errorf("Unauthorized")
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	withdrawalID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var request models.RejectWithdrawalRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		h.logger.Errorf("Invalid input")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid input",
+		})
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx.UserContext(), RequestTimeout)
+	defer cancel()
+
+	withdrawal, err := h.service.RejectWithdrawal(ctxWithTimeout, operatorID, withdrawalID, request.Reason)
+	if err != nil {
+		h.logger.Errorf("Failed to reject withdrawal %d: %v", withdrawalID, err)
+		return ctx.Status(withdrawalErrorStatus(err)).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.WithdrawalResponse{
+		Message:    "Withdrawal rejected successfully",
+		Withdrawal: withdrawal,
+	})
+}
@@ -12,23 +12,141 @@ import (
 func RegistrationRoutes(app *fiber.App, h handlers.HandlerInterface, tokenManager utils.TokenManager) {
 	// Middleware для CORS
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
+		AllowOrigins:  "*",
+		AllowMethods:  "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
+		ExposeHeaders: "ETag",
 	}))
 
-	// Группа API
-	api := app.Group("/api/v1")
+	// Группа API; все запросы выполняются в контексте тенанта
+	api := app.Group("/api/v1", h.ResolveTenant)
 
 	// Регистрация и авторизация пользователей
 	api.Post("/register", h.RegisterUser)
 	api.Post("/login", h.LoginUser)
 
+	// Уведомления платёжных провайдеров о пополнениях (аутентифицируются подписью)
+	api.Post("/webhooks/deposits/:provider", h.HandleDepositWebhook)
+
+	// Справочник валют
+	api.Get("/currencies", h.GetCurrencies)
+
 	// Маршруты с авторизацией (используют JWT-токен)
 	api.Get("/balance", middleware.AuthMiddleware(tokenManager), h.GetBalance)
-	api.Post("/wallet/deposit", middleware.AuthMiddleware(tokenManager), h.Deposit)
+	api.Get("/balance/valuation", middleware.AuthMiddleware(tokenManager), h.GetBalanceValuation)
+	// Прямое зачисление без платёжного провайдера доступно только администраторам
+	api.Post("/wallet/deposit", middleware.AuthMiddleware(tokenManager), h.RequireAdmin, h.Deposit)
 	api.Post("/wallet/withdraw", middleware.AuthMiddleware(tokenManager), h.Withdraw)
+	api.Get("/wallet/deposit-reference", middleware.AuthMiddleware(tokenManager), h.GetDepositReference)
+	api.Get("/interest", middleware.AuthMiddleware(tokenManager), h.GetAccruedInterest)
+	api.Post("/vouchers/redeem", middleware.AuthMiddleware(tokenManager), h.RedeemVoucher)
 	api.Get("/exchange/rates", middleware.AuthMiddleware(tokenManager), h.GetExchangeRates)
 	api.Post("/exchange", middleware.AuthMiddleware(tokenManager), h.ExchangeCurrency)
+	api.Post("/rebalance/preview", middleware.AuthMiddleware(tokenManager), h.PreviewRebalance)
+	api.Post("/rebalance/:id/confirm", middleware.AuthMiddleware(tokenManager), h.ConfirmRebalance)
+	api.Get("/statements", middleware.AuthMiddleware(tokenManager), h.ExportStatement)
+
+	// Блокировки средств (authorize / capture / release)
+	api.Get("/holds", middleware.AuthMiddleware(tokenManager), h.GetHolds)
+	api.Post("/holds", middleware.AuthMiddleware(tokenManager), h.AuthorizeHold)
+	api.Post("/holds/:id/capture", middleware.AuthMiddleware(tokenManager), h.CaptureHold)
+	api.Post("/holds/:id/release", middleware.AuthMiddleware(tokenManager), h.ReleaseHold)
+
+	// Вывод средств через провайдера выплат
+	api.Get("/withdrawals", middleware.AuthMiddleware(tokenManager), h.GetWithdrawals)
+	api.Post("/withdrawals", middleware.AuthMiddleware(tokenManager), h.RequestWithdrawal)
+	api.Get("/withdrawals/:id", middleware.AuthMiddleware(tokenManager), h.GetWithdrawal)
+	api.Post("/withdrawals/:id/cancel", middleware.AuthMiddleware(tokenManager), h.CancelWithdrawal)
+
+	// Совместные кошельки: операции по ID кошелька проверяются по роли участника
+	api.Get("/wallets", middleware.AuthMiddleware(tokenManager), h.GetWallets)
+	api.Get("/wallets/:id", middleware.AuthMiddleware(tokenManager), h.GetWallet)
+	api.Post("/wallets/:id/deposit", middleware.AuthMiddleware(tokenManager), h.DepositToWallet)
+	api.Post("/wallets/:id/withdraw", middleware.AuthMiddleware(tokenManager), h.WithdrawFromWallet)
+	api.Post("/wallets/:id/exchange", middleware.AuthMiddleware(tokenManager), h.ExchangeFromWallet)
+	api.Post("/wallets/:id/transfer", middleware.AuthMiddleware(tokenManager), h.TransferFromWallet)
+	api.Post("/wallets/:id/holds", middleware.AuthMiddleware(tokenManager), h.AuthorizeWalletHold)
+	api.Get("/wallets/:id/pots", middleware.AuthMiddleware(tokenManager), h.GetWalletPots)
+	api.Post("/wallets/:id/pots", middleware.AuthMiddleware(tokenManager), h.CreateWalletPot)
+	api.Get("/wallets/:id/statements", middleware.AuthMiddleware(tokenManager), h.ExportWalletStatement)
+	api.Get("/wallets/:id/members", middleware.AuthMiddleware(tokenManager), h.GetWalletMembers)
+	api.Post("/wallets/:id/members", middleware.AuthMiddleware(tokenManager), h.InviteWalletMember)
+	api.Delete("/wallets/:id/members/:userId", middleware.AuthMiddleware(tokenManager), h.RemoveWalletMember)
+	api.Get("/wallet-invitations", middleware.AuthMiddleware(tokenManager), h.GetWalletInvitations)
+	api.Post("/wallet-invitations/:id/accept", middleware.AuthMiddleware(tokenManager), h.AcceptWalletInvitation)
+	api.Post("/wallet-invitations/:id/decline", middleware.AuthMiddleware(tokenManager), h.DeclineWalletInvitation)
+
+	// Запросы на оплату между пользователями
+	api.Get("/payment-requests", middleware.AuthMiddleware(tokenManager), h.GetPaymentRequests)
+	api.Post("/payment-requests", middleware.AuthMiddleware(tokenManager), h.CreatePaymentRequest)
+	api.Post("/payment-requests/:id/pay", middleware.AuthMiddleware(tokenManager), h.PayPaymentRequest)
+	api.Post("/payment-requests/:id/decline", middleware.AuthMiddleware(tokenManager), h.DeclinePaymentRequest)
+	api.Post("/payment-requests/:id/cancel", middleware.AuthMiddleware(tokenManager), h.CancelPaymentRequest)
+
+	// Копилки внутри кошельков
+	api.Get("/pots", middleware.AuthMiddleware(tokenManager), h.GetPots)
+	api.Post("/pots", middleware.AuthMiddleware(tokenManager), h.CreatePot)
+	api.Get("/pots/:id/movements", middleware.AuthMiddleware(tokenManager), h.GetPotMovements)
+	api.Post("/pots/:id/deposit", middleware.AuthMiddleware(tokenManager), h.DepositToPot)
+	api.Post("/pots/:id/withdraw", middleware.AuthMiddleware(tokenManager), h.WithdrawFromPot)
+	api.Post("/pots/:id/close", middleware.AuthMiddleware(tokenManager), h.ClosePot)
+
+	// Запланированные и повторяющиеся операции
+	api.Get("/schedules", middleware.AuthMiddleware(tokenManager), h.GetSchedules)
+	api.Post("/schedules", middleware.AuthMiddleware(tokenManager), h.CreateSchedule)
+	api.Get("/schedules/:id/executions", middleware.AuthMiddleware(tokenManager), h.GetScheduleExecutions)
+	api.Post("/schedules/:id/pause", middleware.AuthMiddleware(tokenManager), h.PauseSchedule)
+	api.Post("/schedules/:id/resume", middleware.AuthMiddleware(tokenManager), h.ResumeSchedule)
+	api.Post("/schedules/:id/cancel", middleware.AuthMiddleware(tokenManager), h.CancelSchedule)
+
+	// Лимитные заявки на обмен
+	api.Get("/limit-orders", middleware.AuthMiddleware(tokenManager), h.GetLimitOrders)
+	api.Post("/limit-orders", middleware.AuthMiddleware(tokenManager), h.PlaceLimitOrder)
+	api.Post("/limit-orders/:id/cancel", middleware.AuthMiddleware(tokenManager), h.CancelLimitOrder)
+
+	// Вебхуки о событиях кошельков
+	api.Get("/webhook-subscriptions", middleware.AuthMiddleware(tokenManager), h.GetWebhookSubscriptions)
+	api.Post("/webhook-subscriptions", middleware.AuthMiddleware(tokenManager), h.CreateWebhookSubscription)
+	api.Delete("/webhook-subscriptions/:id", middleware.AuthMiddleware(tokenManager), h.DeleteWebhookSubscription)
+	api.Get("/webhook-subscriptions/:id/deliveries", middleware.AuthMiddleware(tokenManager), h.GetWebhookDeliveries)
+	api.Get("/webhook-subscriptions/:id/deliveries/:deliveryId", middleware.AuthMiddleware(tokenManager), h.GetWebhookDelivery)
+	api.Post("/webhook-subscriptions/:id/deliveries/:deliveryId/redeliver", middleware.AuthMiddleware(tokenManager), h.RedeliverWebhook)
+
+	// Уведомления о событиях аккаунта
+	api.Get("/notifications", middleware.AuthMiddleware(tokenManager), h.GetNotifications)
+	api.Post("/notifications/read-all", middleware.AuthMiddleware(tokenManager), h.MarkAllNotificationsRead)
+	api.Post("/notifications/:id/read", middleware.AuthMiddleware(tokenManager), h.MarkNotificationRead)
+	api.Get("/notification-preferences", middleware.AuthMiddleware(tokenManager), h.GetNotificationPreferences)
+	api.Put("/notification-preferences", middleware.AuthMiddleware(tokenManager), h.UpdateNotificationPreferences)
+
+	// Поток изменений балансов и курсов; браузерные клиенты передают токен параметром access_token
+	api.Get("/stream", middleware.QueryTokenMiddleware(), middleware.AuthMiddleware(tokenManager), h.StreamUpdates)
+	api.Get("/stream/ws", middleware.QueryTokenMiddleware(), middleware.AuthMiddleware(tokenManager), h.StreamUpdatesWebSocket)
+
+	// Администрирование (требуется роль admin)
+	admin := api.Group("/admin", middleware.AuthMiddleware(tokenManager), h.RequireAdmin)
+	admin.Get("/currencies", h.AdminGetCurrencies)
+	admin.Post("/currencies", h.AdminCreateCurrency)
+	admin.Put("/currencies/:code", h.AdminUpdateCurrency)
+	admin.Post("/transactions/:id/reverse", h.AdminReverseTransaction)
+	admin.Post("/users/:id/freeze", h.AdminFreezeUser)
+	admin.Post("/users/:id/unfreeze", h.AdminUnfreezeUser)
+	admin.Get("/users/:id/freeze-events", h.AdminGetUserFreezeEvents)
+	admin.Post("/wallets/:id/freeze", h.AdminFreezeWallet)
+	admin.Post("/wallets/:id/unfreeze", h.AdminUnfreezeWallet)
+	admin.Get("/wallets/:id/freeze-events", h.AdminGetWalletFreezeEvents)
+	admin.Get("/wallets/:id/credit-line", h.AdminGetCreditLine)
+	admin.Put("/wallets/:id/credit-line", h.AdminSetCreditLine)
+	admin.Delete("/wallets/:id/credit-line", h.AdminDeleteCreditLine)
+	admin.Get("/deposits", h.AdminGetProviderDeposits)
+	admin.Get("/vouchers", h.AdminGetVoucherBatches)
+	admin.Post("/vouchers", h.AdminCreateVoucherBatch)
+	admin.Get("/vouchers/:id", h.AdminGetVoucherBatch)
+	admin.Get("/withdrawals", h.AdminGetWithdrawals)
+	admin.Post("/withdrawals/:id/approve", h.AdminApproveWithdrawal)
+	admin.Post("/withdrawals/:id/reject", h.AdminRejectWithdrawal)
+	admin.Get("/reconciliation", h.AdminGetReconciliationRuns)
+	admin.Get("/reconciliation/:id", h.AdminGetReconciliationReport)
+	admin.Get("/journal/verify", h.AdminVerifyJournal)
 
 	// Включаем Swagger-документацию
 	app.Get("/swagger/*", swagger.New(swagger.Config{
@@ -0,0 +1,163 @@
+// Package journal реализует цепочку хешей журнала транзакций: каждая проводка кошелька
+// хранит хеш предыдущей проводки того же кошелька и хеш собственного содержимого,
+// поэтому изменение или удаление любой проводки напрямую в БД обнаруживается при проверке.
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// TimeLayout - представление времени проводки в хешируемом содержимом.
+// Совпадает с форматом, которым хеши существующих проводок заполняются в миграции.
+const TimeLayout = "2006-01-02T15:04:05.000000Z"
+
+// Hash вычисляет хеш проводки по хешу предыдущей проводки кошелька и содержимому проводки.
+// Поля кодируются с префиксом длины в байтах, чтобы границы полей были однозначны.
+// Инициатор добавляется последним полем только для проводок участников, поэтому хеши
+// проводок без инициатора совпадают с хешами, посчитанными миграцией.
+func Hash(transaction *models.Transaction) string {
+	reversedID := ""
+	if transaction.ReversedTransactionID != 0 {
+		reversedID = strconv.FormatUint(transaction.ReversedTransactionID, 10)
+	}
+
+	fields := []string{
+		transaction.PrevHash,
+		strconv.FormatUint(transaction.WalletID, 10),
+		transaction.OperationID,
+		transaction.Type,
+		formatAmount(transaction.Amount),
+		formatAmount(transaction.BalanceAfter),
+		transaction.Description,
+		reversedID,
+		transaction.CreatedAt.UTC().Format(TimeLayout),
+	}
+	if transaction.InitiatedBy != 0 {
+		fields = append(fields, strconv.FormatUint(transaction.InitiatedBy, 10))
+	}
+
+	var b strings.Builder
+	for _, field := range fields {
+		fmt.Fprintf(&b, "%d:%s", len(field), field)
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Timestamp возвращает время для новой проводки с точностью, которую хранит PostgreSQL,
+// чтобы хеш, вычисленный до вставки, совпадал с хешем прочитанной из БД проводки.
+func Timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// Digest вычисляет хеш контрольной точки по последним хешам цепочек кошельков.
+func Digest(heads map[uint64]string) string {
+	walletIDs := make([]uint64, 0, len(heads))
+	for walletID := range heads {
+		walletIDs = append(walletIDs, walletID)
+	}
+	sort.Slice(walletIDs, func(i, j int) bool { return walletIDs[i] < walletIDs[j] })
+
+	h := sha256.New()
+	for _, walletID := range walletIDs {
+		fmt.Fprintf(h, "%d:%s\n", walletID, heads[walletID])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Причины нарушения цепочки.
+const (
+	ReasonPrevHashMismatch   = "prev_hash does not match the previous entry of the wallet"
+	ReasonHashMismatch       = "hash does not match the entry contents"
+	ReasonCheckpointMismatch = "wallet chain heads do not match the checkpoint"
+)
+
+// Verifier последовательно проверяет проводки в порядке возрастания ID и контрольные точки.
+// Не хранит проводки в памяти - только последний хеш каждого кошелька.
+type Verifier struct {
+	heads       map[uint64]string
+	checkpoints []*models.JournalCheckpoint
+	result      *models.JournalVerification
+}
+
+// NewVerifier создаёт проверку с контрольными точками, упорядоченными по LastTransactionID.
+func NewVerifier(checkpoints []*models.JournalCheckpoint) *Verifier {
+	return &Verifier{
+		heads:       make(map[uint64]string),
+		checkpoints: checkpoints,
+		result:      &models.JournalVerification{Valid: true},
+	}
+}
+
+// Add проверяет очередную проводку. Возвращает false после обнаружения первого нарушения.
+func (v *Verifier) Add(transaction *models.Transaction) bool {
+	if !v.checkpointsBefore(transaction.ID) {
+		return false
+	}
+
+	switch {
+	case transaction.PrevHash != v.heads[transaction.WalletID]:
+		v.fail(&models.JournalBrokenLink{TransactionID: transaction.ID, WalletID: transaction.WalletID, Reason: ReasonPrevHashMismatch})
+		return false
+	case transaction.Hash != Hash(transaction):
+		v.fail(&models.JournalBrokenLink{TransactionID: transaction.ID, WalletID: transaction.WalletID, Reason: ReasonHashMismatch})
+		return false
+	}
+
+	v.heads[transaction.WalletID] = transaction.Hash
+	v.result.EntriesChecked++
+	return true
+}
+
+// Result завершает проверку оставшихся контрольных точек и возвращает результат.
+func (v *Verifier) Result() *models.JournalVerification {
+	if v.result.Valid {
+		v.checkpointsBefore(0)
+	}
+	return v.result
+}
+
+// checkpointsBefore сверяет контрольные точки, покрывающие проводки с ID меньше nextID
+// (все оставшиеся, если nextID равен нулю).
+func (v *Verifier) checkpointsBefore(nextID uint64) bool {
+	for len(v.checkpoints) > 0 {
+		checkpoint := v.checkpoints[0]
+		if nextID != 0 && checkpoint.LastTransactionID >= nextID {
+			return true
+		}
+		v.checkpoints = v.checkpoints[1:]
+
+		if Digest(v.heads) != checkpoint.Digest {
+			v.fail(&models.JournalBrokenLink{
+				TransactionID: checkpoint.LastTransactionID,
+				CheckpointID:  checkpoint.ID,
+				Reason:        ReasonCheckpointMismatch,
+			})
+			return false
+		}
+		v.result.CheckpointsChecked++
+	}
+	return true
+}
+
+func (v *Verifier) fail(link *models.JournalBrokenLink) {
+	v.result.Valid = false
+	v.result.BrokenLink = link
+}
+
+// formatAmount форматирует сумму так же, как PostgreSQL выводит NUMERIC(18, 2).
+func formatAmount(amount float64) string {
+	if amount == 0 {
+		amount = 0 // отрицательный ноль
+	}
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,116 @@
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// chain строит корректную цепочку проводок: две по кошельку 1 и одну по кошельку 2.
+func chain() []*models.Transaction {
+	createdAt := time.Date(2024, 3, 1, 12, 30, 45, 123456000, time.UTC)
+	entries := []*models.Transaction{
+		{ID: 1, WalletID: 1, OperationID: "op-1", Type: models.TransactionTypeDeposit, Amount: 100, BalanceAfter: 100, Description: "Deposit", CreatedAt: createdAt},
+		{ID: 2, WalletID: 2, OperationID: "op-2", Type: models.TransactionTypeDeposit, Amount: 5.5, BalanceAfter: 5.5, Description: "Deposit", CreatedAt: createdAt},
+		{ID: 3, WalletID: 1, OperationID: "op-3", Type: models.TransactionTypeWithdrawal, Amount: -40.25, BalanceAfter: 59.75, Description: "Withdrawal", CreatedAt: createdAt.Add(time.Minute)},
+	}
+	heads := map[uint64]string{}
+	for _, entry := range entries {
+		entry.PrevHash = heads[entry.WalletID]
+		entry.Hash = Hash(entry)
+		heads[entry.WalletID] = entry.Hash
+	}
+	return entries
+}
+
+func verify(entries []*models.Transaction, checkpoints []*models.JournalCheckpoint) *models.JournalVerification {
+	verifier := NewVerifier(checkpoints)
+	for _, entry := range entries {
+		if !verifier.Add(entry) {
+			break
+		}
+	}
+	return verifier.Result()
+}
+
+func TestHashMatchesMigrationFormat(t *testing.T) {
+	// Ожидаемое значение посчитано по формату, которым миграция заполняет хеши существующих проводок.
+	entry := &models.Transaction{
+		WalletID:     1,
+		OperationID:  "op-1",
+		Type:         models.TransactionTypeDeposit,
+		Amount:       100,
+		BalanceAfter: 100,
+		Description:  "Deposit",
+		CreatedAt:    time.Date(2024, 3, 1, 12, 30, 45, 123456000, time.UTC),
+	}
+	assert.Equal(t, "3585e64b7af28c42ac9c1708cc31a64e06227077bbbdd8b2b5039ea406266703", Hash(entry))
+}
+
+func TestHashCoversInitiator(t *testing.T) {
+	entries := chain()
+	entries[2].InitiatedBy = 5
+	entries[2].Hash = Hash(entries[2])
+
+	// Подмена инициатора проводки участника обнаруживается так же, как изменение суммы.
+	entries[2].InitiatedBy = 6
+	result := verify(entries, nil)
+	assert.False(t, result.Valid)
+	assert.Equal(t, uint64(3), result.BrokenLink.TransactionID)
+	assert.Equal(t, ReasonHashMismatch, result.BrokenLink.Reason)
+}
+
+func TestVerifyValidChain(t *testing.T) {
+	entries := chain()
+	checkpoint := &models.JournalCheckpoint{ID: 1, LastTransactionID: 2, Digest: Digest(map[uint64]string{
+		1: entries[0].Hash,
+		2: entries[1].Hash,
+	})}
+
+	result := verify(entries, []*models.JournalCheckpoint{checkpoint})
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(3), result.EntriesChecked)
+	assert.Equal(t, 1, result.CheckpointsChecked)
+}
+
+func TestVerifyDetectsModifiedEntry(t *testing.T) {
+	entries := chain()
+	entries[2].Amount = -4.25
+
+	result := verify(entries, nil)
+	assert.False(t, result.Valid)
+	assert.Equal(t, uint64(3), result.BrokenLink.TransactionID)
+	assert.Equal(t, ReasonHashMismatch, result.BrokenLink.Reason)
+}
+
+func TestVerifyDetectsDeletedEntry(t *testing.T) {
+	entries := chain()
+	entries = append(entries[:0], entries[1:]...)
+
+	result := verify(entries, nil)
+	assert.False(t, result.Valid)
+	assert.Equal(t, uint64(3), result.BrokenLink.TransactionID)
+	assert.Equal(t, ReasonPrevHashMismatch, result.BrokenLink.Reason)
+}
+
+func TestVerifyDetectsRewrittenChain(t *testing.T) {
+	entries := chain()
+	checkpoint := &models.JournalCheckpoint{ID: 7, LastTransactionID: 3, Digest: Digest(map[uint64]string{
+		1: entries[2].Hash,
+		2: entries[1].Hash,
+	})}
+
+	// Цепочка пересчитана целиком после изменения - каждая ссылка корректна, но точка не совпадает.
+	entries[0].Amount, entries[0].BalanceAfter, entries[2].BalanceAfter = 1000, 1000, 959.75
+	entries[0].Hash = Hash(entries[0])
+	entries[2].PrevHash = entries[0].Hash
+	entries[2].Hash = Hash(entries[2])
+
+	result := verify(entries, []*models.JournalCheckpoint{checkpoint})
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(3), result.EntriesChecked)
+	assert.Equal(t, uint64(7), result.BrokenLink.CheckpointID)
+	assert.Equal(t, ReasonCheckpointMismatch, result.BrokenLink.Reason)
+}
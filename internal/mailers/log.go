@@ -0,0 +1,27 @@
+package mailers
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogMailerName - имя способа отправки, записывающего письма в журнал.
+const LogMailerName = "log"
+
+// LogMailer записывает письма в журнал приложения вместо отправки. Предназначен для
+// разработки: письма никуда не доставляются.
+type LogMailer struct {
+	logger *logrus.Logger
+}
+
+// NewLogMailer создаёт способ отправки, записывающий письма в logger.
+func NewLogMailer(logger *logrus.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+// Send записывает письмо в журнал.
+func (m *LogMailer) Send(_ context.Context, to, subject, body string) error {
+	m.logger.WithFields(logrus.Fields{"to": to, "subject": subject}).Info(body)
+	return nil
+}
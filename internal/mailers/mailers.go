@@ -0,0 +1,35 @@
+// Пакет mailers содержит реализации отправки писем с уведомлениями: через SMTP-сервер
+// и в журнал приложения.
+package mailers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+// ErrInvalidAddress - адрес получателя или отправителя содержит переводы строк.
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Encode возвращает письмо в формате RFC 5322 с текстом в UTF-8. Тема кодируется
+// по RFC 2047, чтобы в ней можно было использовать не только ASCII.
+func Encode(from, to, subject, body string, date time.Time) ([]byte, error) {
+	if strings.ContainsAny(from+to, "\r\n") {
+		return nil, ErrInvalidAddress
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	message.WriteString("\r\n")
+	return message.Bytes(), nil
+}
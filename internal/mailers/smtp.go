@@ -0,0 +1,86 @@
+package mailers
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SMTPMailerName - имя способа отправки через SMTP-сервер.
+const SMTPMailerName = "smtp"
+
+// SMTPMailer отправляет письма через SMTP-сервер. Если сервер поддерживает STARTTLS,
+// соединение шифруется; авторизация выполняется, только если задан логин.
+type SMTPMailer struct {
+	addr     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer создаёт способ отправки через SMTP-сервер addr от имени from.
+func NewSMTPMailer(addr, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{addr: addr, username: username, password: password, from: from}
+}
+
+// Send отправляет письмо. Отправка прерывается по отмене или истечению ctx.
+func (m *SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	message, err := Encode(m.from, to, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,99 @@
+package mailers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type smtpMessage struct {
+	from string
+	to   string
+	data string
+}
+
+// serveSMTP принимает одно подключение и отвечает на него как SMTP-сервер без STARTTLS
+// и авторизации, передавая принятое письмо в messages.
+func serveSMTP(t *testing.T, listener net.Listener, messages chan<- smtpMessage) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	fmt.Fprint(conn, "220 localhost ESMTP test\r\n")
+	reader := bufio.NewReader(conn)
+	var message smtpMessage
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.Fields(line)[0])
+		switch command {
+		case "EHLO", "HELO":
+			fmt.Fprint(conn, "250-localhost\r\n250 8BITMIME\r\n")
+		case "MAIL":
+			message.from = strings.Fields(line[len("MAIL FROM:"):])[0]
+			fmt.Fprint(conn, "250 OK\r\n")
+		case "RCPT":
+			message.to = strings.TrimSpace(line[len("RCPT TO:"):])
+			fmt.Fprint(conn, "250 OK\r\n")
+		case "DATA":
+			fmt.Fprint(conn, "354 Go ahead\r\n")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					t.Errorf("failed to read data: %v", err)
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			message.data = data.String()
+			fmt.Fprint(conn, "250 OK\r\n")
+			messages <- message
+		case "QUIT":
+			fmt.Fprint(conn, "221 Bye\r\n")
+			return
+		default:
+			fmt.Fprint(conn, "250 OK\r\n")
+		}
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	messages := make(chan smtpMessage, 1)
+	go serveSMTP(t, listener, messages)
+
+	mailer := NewSMTPMailer(listener.Addr().String(), "", "", "wallet@example.com")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, mailer.Send(ctx, "user@example.com", "Вход в аккаунт", "Line one\nLine two"))
+
+	message := <-messages
+	assert.Equal(t, "<wallet@example.com>", message.from)
+	assert.Equal(t, "<user@example.com>", message.to)
+	assert.Contains(t, message.data, "To: user@example.com\r\n")
+	assert.Contains(t, message.data, "Subject: =?utf-8?q?")
+	assert.Contains(t, message.data, "Content-Type: text/plain; charset=UTF-8\r\n")
+	assert.True(t, strings.HasSuffix(message.data, "\r\n\r\nLine one\r\nLine two\r\n"))
+}
+
+func TestEncodeRejectsHeaderInjection(t *testing.T) {
+	_, err := Encode("wallet@example.com", "user@example.com\r\nBcc: other@example.com", "Subject", "Body", time.Now())
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
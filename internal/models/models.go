@@ -1,17 +1,32 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+// Роли пользователей.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
 
 type User struct {
-	ID           uint64         `json:"id" db:"id"`
-	Username     string         `json:"username" db:"username"`
-	Password     string         `json:"password" db:"password"`
-	Email        string         `json:"email" db:"email"`
-	RefreshToken []RefreshToken `json:"refreshToken" db:"refreshToken"`
+	ID          uint64 `json:"id" db:"id"`
+	TenantID    uint64 `json:"tenant_id" db:"tenant_id"`
+	Username    string `json:"username" db:"username"`
+	Password    string `json:"password" db:"password"`
+	Email       string `json:"email" db:"email"`
+	Role        string `json:"role" db:"role"`
+	FreezeState string `json:"freeze_state" db:"freeze_state"`
+	// Код, который пользователь указывает в назначении платежа при пополнении через провайдера.
+	DepositReference string         `json:"deposit_reference" db:"deposit_reference"`
+	RefreshToken     []RefreshToken `json:"refreshToken" db:"refreshToken"`
 }
 
 type RefreshToken struct {
 	ID        uint64    `db:"id"`
+	TenantID  uint64    `db:"tenant_id"`
 	UserID    uint64    `db:"user_id"`
 	Token     string    `db:"token"`
 	DeviceID  string    `db:"device_id"`
@@ -19,11 +34,27 @@ type RefreshToken struct {
 	ExpiresAt time.Time `db:"expires_at"`
 }
 
+// Tenant представляет бренд, для которого работает сервис. Пользователи, кошельки, токены
+// и справочник валют принадлежат одному тенанту. Пустые пути к ключам JWT означают ключи
+// из конфигурации сервиса.
+type Tenant struct {
+	ID                uint64    `json:"id" db:"id"`
+	Slug              string    `json:"slug" db:"slug"`
+	Name              string    `json:"name" db:"name"`
+	Host              *string   `json:"host,omitempty" db:"host"`
+	JWTPublicKeyPath  string    `json:"-" db:"jwt_public_key_path"`
+	JWTPrivateKeyPath string    `json:"-" db:"jwt_private_key_path"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
 type Wallet struct {
-	ID       uint64  `json:"id" db:"id"`
-	UserID   uint64  `json:"user_id" db:"user_id"`
-	Balance  float64 `json:"balance" db:"balance"`
-	Currency string  `json:"currency" db:"currency"`
+	ID          uint64  `json:"id" db:"id"`
+	TenantID    uint64  `json:"tenant_id" db:"tenant_id"`
+	UserID      uint64  `json:"user_id" db:"user_id"`
+	Balance     float64 `json:"balance" db:"balance"`
+	Currency    string  `json:"currency" db:"currency"`
+	FreezeState string  `json:"freeze_state" db:"freeze_state"`
+	Version     uint64  `json:"version" db:"version"` // Увеличивается при каждом изменении кошелька
 }
 
 // RegisterRequest представляет тело запроса для регистрации пользователя
@@ -76,7 +107,11 @@ type ExchangeRequest struct {
 
 // BalanceResponse представляет ответ с балансом пользователя.
 type BalanceResponse struct {
-	Balance map[string]float64 `json:"balance"`
+	Balance      map[string]float64 `json:"balance"`                 // Учётный баланс
+	Available    map[string]float64 `json:"available"`               // Баланс за вычетом заблокированных средств и копилок с учётом овердрафта
+	CreditLimits map[string]float64 `json:"credit_limits,omitempty"` // Лимиты овердрафта по валютам
+	Pots         map[string][]*Pot  `json:"pots,omitempty"`          // Действующие копилки по валютам; входят в учётный баланс
+	Versions     map[string]uint64  `json:"versions"`                // Версии кошельков по валютам
 }
 
 // DepositResponse представляет ответ на успешное пополнение баланса.
@@ -99,9 +134,1248 @@ type ExchangeResponse struct {
 }
 
 // RatesResponse представляет ответ с текущими курсами валют.
+// Помимо карты rates, курс каждой включённой валюты справочника
+// дублируется полем верхнего уровня с её кодом (например, "USD").
 type RatesResponse struct {
 	Rates map[string]float64 `json:"rates"`
-	USD   float64            `json:"USD"`
-	RUB   float64            `json:"RUB"`
-	EUR   float64            `json:"EUR"`
+}
+
+// Статусы блокировок средств.
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+// Виды блокировок средств. Блокировки выводов и лимитных заявок создаёт и снимает сам сервис,
+// списать или освободить их через API блокировок нельзя.
+const (
+	HoldKindUser       = "user"
+	HoldKindWithdrawal = "withdrawal"
+	HoldKindLimitOrder = "limit_order"
+)
+
+// Hold представляет блокировку средств кошелька: уменьшает доступный баланс,
+// не изменяя учётный баланс до списания (capture).
+type Hold struct {
+	ID             uint64    `json:"id" db:"id"`
+	WalletID       uint64    `json:"wallet_id" db:"wallet_id"`
+	UserID         uint64    `json:"user_id" db:"user_id"`
+	Currency       string    `json:"currency" db:"currency"`
+	Amount         float64   `json:"amount" db:"amount"`
+	CapturedAmount float64   `json:"captured_amount" db:"captured_amount"`
+	Kind           string    `json:"kind" db:"kind"`
+	Status         string    `json:"status" db:"status"`
+	Reference      string    `json:"reference" db:"reference"`
+	ExpiresAt      time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	// InitiatedBy - пользователь, создавший блокировку, если она создана от его имени.
+	InitiatedBy uint64 `json:"initiated_by,omitempty" db:"initiated_by"`
+}
+
+// Balances содержит учётный (total) и доступный (available) балансы пользователя по валютам.
+// Средства копилок входят в учётный баланс и не входят в доступный.
+type Balances struct {
+	Total        map[string]float64
+	Available    map[string]float64
+	CreditLimits map[string]float64
+	Pots         map[string][]*Pot
+	Versions     map[string]uint64
+	ETag         string
+}
+
+// HoldRequest представляет запрос на блокировку средств.
+type HoldRequest struct {
+	Amount    float64 `json:"amount" validate:"required,gt=0"`
+	Currency  string  `json:"currency" validate:"required"`
+	Reference string  `json:"reference"`
+	// TTL блокировки в секундах; если не задан, используется значение по умолчанию.
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// CaptureHoldRequest представляет запрос на списание заблокированных средств.
+// Если Amount не задан, списывается вся заблокированная сумма.
+type CaptureHoldRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+// HoldResponse представляет ответ с информацией о блокировке.
+type HoldResponse struct {
+	Message string `json:"message"`
+	Hold    *Hold  `json:"hold"`
+}
+
+// HoldsResponse представляет ответ со списком блокировок пользователя.
+type HoldsResponse struct {
+	Holds []*Hold `json:"holds"`
+}
+
+// Типы запланированных операций.
+const (
+	ScheduleTypeExchange = "exchange"
+	ScheduleTypeTransfer = "transfer"
+)
+
+// Виды расписаний.
+const (
+	ScheduleKindOnce     = "once"
+	ScheduleKindInterval = "interval"
+	ScheduleKindCron     = "cron"
+)
+
+// Статусы запланированных операций.
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCancelled = "cancelled"
+	ScheduleStatusCompleted = "completed"
+)
+
+// Статусы выполнения запланированных операций.
+const (
+	ExecutionStatusSucceeded = "succeeded"
+	ExecutionStatusFailed    = "failed"
+)
+
+// ScheduledTransfer представляет разовое или повторяющееся поручение:
+// обмен валюты (exchange) или перевод другому пользователю (transfer).
+type ScheduledTransfer struct {
+	ID              uint64     `json:"id" db:"id"`
+	UserID          uint64     `json:"user_id" db:"user_id"`
+	Type            string     `json:"type" db:"type"`
+	FromCurrency    string     `json:"from_currency" db:"from_currency"`
+	ToCurrency      string     `json:"to_currency" db:"to_currency"`
+	ToUserID        uint64     `json:"to_user_id,omitempty" db:"to_user_id"`
+	Amount          float64    `json:"amount" db:"amount"`
+	Kind            string     `json:"kind" db:"kind"`
+	CronExpr        string     `json:"cron,omitempty" db:"cron_expr"`
+	IntervalSeconds int64      `json:"interval_seconds,omitempty" db:"interval_seconds"`
+	Status          string     `json:"status" db:"status"`
+	NextRunAt       *time.Time `json:"next_run_at,omitempty" db:"next_run_at"`
+	LastRunAt       *time.Time `json:"last_run_at,omitempty" db:"last_run_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ScheduleExecution представляет запись истории выполнения запланированной операции.
+type ScheduleExecution struct {
+	ID              uint64    `json:"id" db:"id"`
+	ScheduleID      uint64    `json:"schedule_id" db:"schedule_id"`
+	Status          string    `json:"status" db:"status"`
+	Error           string    `json:"error,omitempty" db:"error"`
+	Amount          float64   `json:"amount" db:"amount"`
+	ExchangedAmount float64   `json:"exchanged_amount,omitempty" db:"exchanged_amount"`
+	ScheduledFor    time.Time `json:"scheduled_for" db:"scheduled_for"` // Время запуска по расписанию
+	ExecutedAt      time.Time `json:"executed_at" db:"executed_at"`
+}
+
+// ScheduleRequest представляет запрос на создание запланированной операции.
+// Для обмена (type=exchange) указываются from_currency и to_currency,
+// для перевода (type=transfer) - from_currency и to_username.
+// Если задан cron - операция повторяется по cron-выражению, если interval_seconds -
+// с указанным интервалом, иначе выполняется один раз в момент run_at.
+type ScheduleRequest struct {
+	Type            string     `json:"type" validate:"required,oneof=exchange transfer"`
+	FromCurrency    string     `json:"from_currency" validate:"required"`
+	ToCurrency      string     `json:"to_currency"`
+	ToUsername      string     `json:"to_username"`
+	Amount          float64    `json:"amount" validate:"required,gt=0"`
+	RunAt           *time.Time `json:"run_at"`
+	IntervalSeconds int64      `json:"interval_seconds"`
+	Cron            string     `json:"cron"`
+}
+
+// ScheduleResponse представляет ответ с информацией о запланированной операции.
+type ScheduleResponse struct {
+	Message  string             `json:"message"`
+	Schedule *ScheduledTransfer `json:"schedule"`
+}
+
+// SchedulesResponse представляет ответ со списком запланированных операций.
+type SchedulesResponse struct {
+	Schedules []*ScheduledTransfer `json:"schedules"`
+}
+
+// ScheduleExecutionsResponse представляет ответ с историей выполнения запланированной операции.
+type ScheduleExecutionsResponse struct {
+	Executions []*ScheduleExecution `json:"executions"`
+}
+
+// WalletValuation представляет стоимость одного кошелька в базовой валюте.
+type WalletValuation struct {
+	Currency string  `json:"currency"`
+	Balance  float64 `json:"balance"`
+	Rate     float64 `json:"rate"`  // Стоимость единицы валюты кошелька в базовой валюте
+	Value    float64 `json:"value"` // Стоимость баланса в базовой валюте
+}
+
+// ValuationResponse представляет оценку всех кошельков пользователя в базовой валюте.
+type ValuationResponse struct {
+	Base    string             `json:"base"`
+	Wallets []*WalletValuation `json:"wallets"`
+	Total   float64            `json:"total"`
+	RatesAt time.Time          `json:"rates_at"` // Момент получения использованных курсов
+}
+
+// Статусы плана ребалансировки.
+const (
+	RebalanceStatusPending  = "pending"
+	RebalanceStatusExecuted = "executed"
+)
+
+// RebalanceRequest представляет целевое распределение портфеля: доля каждой валюты в процентах
+// стоимости в сумме 100. Валюты кошельков, не указанные в Targets, продаются полностью.
+type RebalanceRequest struct {
+	Targets map[string]float64 `json:"targets" validate:"required"`
+	Base    string             `json:"base"` // Валюта оценки; по умолчанию USD
+}
+
+// RebalanceAllocation представляет текущую и целевую долю валюты в портфеле.
+// Стоимости указаны в базовой валюте плана.
+type RebalanceAllocation struct {
+	Currency      string  `json:"currency"`
+	Available     float64 `json:"available"`
+	Value         float64 `json:"value"`
+	CurrentWeight float64 `json:"current_weight"`
+	TargetWeight  float64 `json:"target_weight"`
+	TargetValue   float64 `json:"target_value"`
+}
+
+// RebalanceLeg представляет один обмен плана: Amount в FromCurrency по курсу Rate
+// (единиц ToCurrency за единицу FromCurrency) на ExchangedAmount в ToCurrency.
+type RebalanceLeg struct {
+	ID              uint64  `json:"id" db:"id"`
+	PlanID          uint64  `json:"plan_id" db:"plan_id"`
+	FromCurrency    string  `json:"from_currency" db:"from_currency"`
+	ToCurrency      string  `json:"to_currency" db:"to_currency"`
+	Amount          float64 `json:"amount" db:"amount"`
+	Rate            float64 `json:"rate" db:"rate"`
+	ExchangedAmount float64 `json:"exchanged_amount" db:"exchanged_amount"`
+}
+
+// RebalancePlan представляет набор обменов, приводящих портфель пользователя к целевому
+// распределению по курсам на момент RatesAt. План исполняется целиком одной операцией
+// по курсам предпросмотра, если подтверждён до ExpiresAt.
+type RebalancePlan struct {
+	ID          uint64                 `json:"id" db:"id"`
+	UserID      uint64                 `json:"user_id" db:"user_id"`
+	Base        string                 `json:"base" db:"base"`
+	Total       float64                `json:"total" db:"total"`
+	Status      string                 `json:"status" db:"status"`
+	OperationID string                 `json:"operation_id,omitempty" db:"operation_id"`
+	Allocations []*RebalanceAllocation `json:"allocations,omitempty" db:"-"`
+	Legs        []*RebalanceLeg        `json:"legs" db:"-"`
+	RatesAt     time.Time              `json:"rates_at" db:"rates_at"`
+	ExpiresAt   time.Time              `json:"expires_at" db:"expires_at"`
+	ExecutedAt  *time.Time             `json:"executed_at,omitempty" db:"executed_at"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+}
+
+// RebalancePlanResponse представляет ответ с планом ребалансировки.
+type RebalancePlanResponse struct {
+	Message string         `json:"message"`
+	Plan    *RebalancePlan `json:"plan"`
+}
+
+// Типы проводок журнала транзакций.
+const (
+	TransactionTypeOpeningBalance    = "opening_balance"
+	TransactionTypeDeposit           = "deposit"
+	TransactionTypeWithdrawal        = "withdrawal"
+	TransactionTypeExchangeOut       = "exchange_out"
+	TransactionTypeExchangeIn        = "exchange_in"
+	TransactionTypeTransferOut       = "transfer_out"
+	TransactionTypeTransferIn        = "transfer_in"
+	TransactionTypeHoldCapture       = "hold_capture"
+	TransactionTypeReversal          = "reversal"
+	TransactionTypeOverdraftInterest = "overdraft_interest"
+	TransactionTypeOverdraftFee      = "overdraft_fee"
+	TransactionTypeWithdrawalReturn  = "withdrawal_return"
+	TransactionTypeInterest          = "interest"
+	TransactionTypeVoucher           = "voucher"
+)
+
+// Transaction представляет проводку журнала: изменение баланса кошелька.
+// Amount положителен для зачислений и отрицателен для списаний. Все проводки
+// одной бизнес-операции (например, обе ноги обмена) имеют общий OperationID.
+type Transaction struct {
+	ID           uint64  `json:"id" db:"id"`
+	WalletID     uint64  `json:"wallet_id" db:"wallet_id"`
+	OperationID  string  `json:"operation_id" db:"operation_id"`
+	Type         string  `json:"type" db:"type"`
+	Amount       float64 `json:"amount" db:"amount"`
+	BalanceAfter float64 `json:"balance_after" db:"balance_after"`
+	Description  string  `json:"description" db:"description"`
+	// ReversedTransactionID - проводка, которую компенсирует данная (только для сторно).
+	ReversedTransactionID uint64    `json:"reversed_transaction_id,omitempty" db:"reversed_transaction_id"`
+	CreatedAt             time.Time `json:"created_at" db:"created_at"`
+	// PrevHash и Hash связывают проводки кошелька в цепочку (см. пакет journal).
+	PrevHash string `json:"prev_hash" db:"prev_hash"`
+	Hash     string `json:"hash" db:"hash"`
+	// InitiatedBy - пользователь, выполнивший операцию, если она выполнена от его имени.
+	InitiatedBy uint64 `json:"initiated_by,omitempty" db:"initiated_by"`
+}
+
+// Statement описывает выписку по кошельку за период [From, To).
+type Statement struct {
+	WalletID       uint64
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	GeneratedAt    time.Time
+}
+
+// Currency представляет валюту справочника с метаданными ISO 4217.
+// Справочник ведётся для каждого тенанта отдельно. InterestRate - годовая ставка на положительный
+// остаток, задаётся долей: 0.02 - 2% годовых. MaxAmount - лимит суммы одной операции, 0 - без лимита.
+type Currency struct {
+	Code         string    `json:"code" db:"code"`
+	Name         string    `json:"name" db:"name"`
+	MinorUnits   int       `json:"minor_units" db:"minor_units"`
+	Symbol       string    `json:"symbol" db:"symbol"`
+	Enabled      bool      `json:"enabled" db:"enabled"`
+	InterestRate float64   `json:"interest_rate" db:"interest_rate"`
+	MaxAmount    float64   `json:"max_amount" db:"max_amount"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CurrencyRequest представляет запрос администратора на создание или изменение валюты.
+// При изменении незаданные поля остаются прежними.
+type CurrencyRequest struct {
+	Code         string   `json:"code" validate:"required,len=3"`
+	Name         *string  `json:"name"`
+	MinorUnits   *int     `json:"minor_units"`
+	Symbol       *string  `json:"symbol"`
+	Enabled      *bool    `json:"enabled"`
+	InterestRate *float64 `json:"interest_rate"`
+	MaxAmount    *float64 `json:"max_amount"`
+}
+
+// CurrencyResponse представляет ответ с информацией о валюте.
+type CurrencyResponse struct {
+	Message  string    `json:"message"`
+	Currency *Currency `json:"currency"`
+}
+
+// CurrenciesResponse представляет ответ со списком валют.
+type CurrenciesResponse struct {
+	Currencies []*Currency `json:"currencies"`
+}
+
+// Reversal представляет сторнирование операции администратором: компенсирующие
+// проводки по всем проводкам исходной операции.
+type Reversal struct {
+	ID                  uint64         `json:"id" db:"id"`
+	OriginalOperationID string         `json:"original_operation_id" db:"original_operation_id"`
+	ReversalOperationID string         `json:"reversal_operation_id" db:"reversal_operation_id"`
+	OperatorID          uint64         `json:"operator_id" db:"operator_id"`
+	Reason              string         `json:"reason" db:"reason"`
+	CreatedAt           time.Time      `json:"created_at" db:"created_at"`
+	Entries             []*Transaction `json:"entries,omitempty"`
+}
+
+// ReversalRequest представляет запрос на сторнирование транзакции.
+type ReversalRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// ReversalResponse представляет ответ на сторнирование транзакции.
+type ReversalResponse struct {
+	Message  string    `json:"message"`
+	Reversal *Reversal `json:"reversal"`
+}
+
+// Состояния заморозки пользователя и кошелька.
+const (
+	FreezeStateNone  = "none"  // операции разрешены
+	FreezeStateDebit = "debit" // запрещены списания, зачисления разрешены
+	FreezeStateFull  = "full"  // запрещены любые операции
+)
+
+// Объекты заморозки.
+const (
+	FreezeTargetUser   = "user"
+	FreezeTargetWallet = "wallet"
+)
+
+// FreezeEvent представляет запись журнала заморозок: кто, когда и почему изменил состояние.
+type FreezeEvent struct {
+	ID            uint64    `json:"id" db:"id"`
+	TargetType    string    `json:"target_type" db:"target_type"`
+	TargetID      uint64    `json:"target_id" db:"target_id"`
+	PreviousState string    `json:"previous_state" db:"previous_state"`
+	State         string    `json:"state" db:"state"`
+	Reason        string    `json:"reason" db:"reason"`
+	OperatorID    uint64    `json:"operator_id" db:"operator_id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// FreezeRequest представляет запрос на заморозку. State - full или debit; при разморозке не указывается.
+type FreezeRequest struct {
+	State  string `json:"state" example:"full"`
+	Reason string `json:"reason" validate:"required"`
+}
+
+// FreezeResponse представляет ответ на изменение состояния заморозки.
+type FreezeResponse struct {
+	Message string       `json:"message"`
+	Event   *FreezeEvent `json:"event"`
+}
+
+// FreezeEventsResponse представляет журнал заморозок объекта.
+type FreezeEventsResponse struct {
+	Events []*FreezeEvent `json:"events"`
+}
+
+// Статусы лимитных заявок.
+const (
+	LimitOrderStatusOpen      = "open"
+	LimitOrderStatusFilled    = "filled"
+	LimitOrderStatusCancelled = "cancelled"
+	LimitOrderStatusExpired   = "expired"
+)
+
+// LimitOrder представляет лимитную заявку на обмен: amount в FromCurrency обменивается,
+// когда курс FromCurrency/ToCurrency достигает TargetRate. Средства резервируются блокировкой HoldID.
+type LimitOrder struct {
+	ID           uint64     `json:"id" db:"id"`
+	UserID       uint64     `json:"user_id" db:"user_id"`
+	FromCurrency string     `json:"from_currency" db:"from_currency"`
+	ToCurrency   string     `json:"to_currency" db:"to_currency"`
+	Amount       float64    `json:"amount" db:"amount"`
+	TargetRate   float64    `json:"target_rate" db:"target_rate"`
+	HoldID       uint64     `json:"hold_id" db:"hold_id"`
+	Status       string     `json:"status" db:"status"`
+	FilledRate   float64    `json:"filled_rate,omitempty" db:"filled_rate"`
+	FilledAmount float64    `json:"filled_amount,omitempty" db:"filled_amount"`
+	OperationID  string     `json:"operation_id,omitempty" db:"operation_id"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	FilledAt     *time.Time `json:"filled_at,omitempty" db:"filled_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// LimitOrderRequest представляет запрос на создание лимитной заявки.
+// TargetRate - количество единиц to_currency за одну единицу from_currency.
+type LimitOrderRequest struct {
+	FromCurrency string  `json:"from_currency" validate:"required"`
+	ToCurrency   string  `json:"to_currency" validate:"required"`
+	Amount       float64 `json:"amount" validate:"required,gt=0"`
+	TargetRate   float64 `json:"target_rate" validate:"required,gt=0"`
+	// Срок действия заявки в секундах; если не задан, используется значение по умолчанию.
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// LimitOrderResponse представляет ответ с информацией о лимитной заявке.
+type LimitOrderResponse struct {
+	Message string      `json:"message"`
+	Order   *LimitOrder `json:"order"`
+}
+
+// LimitOrdersResponse представляет ответ со списком лимитных заявок.
+type LimitOrdersResponse struct {
+	Orders []*LimitOrder `json:"orders"`
+}
+
+// Статусы запросов на оплату.
+const (
+	PaymentRequestStatusPending   = "pending"
+	PaymentRequestStatusPaid      = "paid"
+	PaymentRequestStatusDeclined  = "declined"
+	PaymentRequestStatusCancelled = "cancelled"
+	PaymentRequestStatusExpired   = "expired"
+)
+
+// Направления списка запросов на оплату относительно пользователя.
+const (
+	PaymentRequestDirectionIncoming = "incoming"
+	PaymentRequestDirectionOutgoing = "outgoing"
+)
+
+// PaymentRequest представляет запрос RequesterID к PayerID на оплату Amount в Currency.
+// Плательщик может оплатить его из кошелька в другой валюте: тогда PaidAmount в PaidCurrency
+// пересчитывается по курсу Rate (единиц Currency за единицу PaidCurrency).
+type PaymentRequest struct {
+	ID           uint64     `json:"id" db:"id"`
+	RequesterID  uint64     `json:"requester_id" db:"requester_id"`
+	PayerID      uint64     `json:"payer_id" db:"payer_id"`
+	Amount       float64    `json:"amount" db:"amount"`
+	Currency     string     `json:"currency" db:"currency"`
+	Description  string     `json:"description" db:"description"`
+	Status       string     `json:"status" db:"status"`
+	PaidCurrency string     `json:"paid_currency,omitempty" db:"paid_currency"`
+	PaidAmount   float64    `json:"paid_amount,omitempty" db:"paid_amount"`
+	Rate         float64    `json:"rate,omitempty" db:"rate"`
+	OperationID  string     `json:"operation_id,omitempty" db:"operation_id"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CreatePaymentRequest представляет запрос на выставление счёта другому пользователю.
+type CreatePaymentRequest struct {
+	PayerUsername string  `json:"payer_username" validate:"required"`
+	Amount        float64 `json:"amount" validate:"required,gt=0"`
+	Currency      string  `json:"currency" validate:"required"`
+	Description   string  `json:"description" validate:"max=255"`
+	// Срок действия запроса в секундах; если не задан, используется значение по умолчанию.
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// PayPaymentRequest представляет оплату запроса. Если PayCurrency не задана,
+// оплата выполняется из кошелька в валюте запроса.
+type PayPaymentRequest struct {
+	PayCurrency string `json:"pay_currency"`
+}
+
+// PaymentRequestResponse представляет ответ с информацией о запросе на оплату.
+type PaymentRequestResponse struct {
+	Message string          `json:"message"`
+	Request *PaymentRequest `json:"request"`
+}
+
+// PaymentRequestsResponse представляет ответ со списком запросов на оплату.
+type PaymentRequestsResponse struct {
+	Requests []*PaymentRequest `json:"requests"`
+}
+
+// Статусы входящих пополнений от платёжных провайдеров.
+const (
+	ProviderDepositStatusReceived  = "received"
+	ProviderDepositStatusCredited  = "credited"
+	ProviderDepositStatusUnmatched = "unmatched"
+	ProviderDepositStatusRejected  = "rejected"
+)
+
+// DepositEvent представляет уведомление провайдера о поступлении Amount в Currency
+// с кодом пополнения Reference. EventID уникален в пределах провайдера.
+type DepositEvent struct {
+	EventID   string  `json:"event_id"`
+	Reference string  `json:"reference"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+}
+
+// ProviderDeposit представляет обработанное уведомление провайдера о пополнении.
+// Пополнения, которые не удалось сопоставить с кошельком или зачислить, сохраняются
+// со статусом unmatched или rejected и причиной в Reason для ручного разбора.
+type ProviderDeposit struct {
+	ID          uint64    `json:"id" db:"id"`
+	TenantID    uint64    `json:"tenant_id" db:"tenant_id"`
+	Provider    string    `json:"provider" db:"provider"`
+	EventID     string    `json:"event_id" db:"event_id"`
+	Reference   string    `json:"reference" db:"reference"`
+	Amount      float64   `json:"amount" db:"amount"`
+	Currency    string    `json:"currency" db:"currency"`
+	Status      string    `json:"status" db:"status"`
+	UserID      *uint64   `json:"user_id,omitempty" db:"user_id"`
+	WalletID    *uint64   `json:"wallet_id,omitempty" db:"wallet_id"`
+	OperationID string    `json:"operation_id,omitempty" db:"operation_id"`
+	Reason      string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DepositWebhookResponse представляет ответ провайдеру на уведомление о пополнении.
+// Duplicate означает, что событие уже было обработано ранее.
+type DepositWebhookResponse struct {
+	Status    string `json:"status"`
+	Duplicate bool   `json:"duplicate"`
+}
+
+// DepositReferenceResponse представляет ответ с кодом пополнения пользователя.
+type DepositReferenceResponse struct {
+	Reference string `json:"reference"`
+}
+
+// ProviderDepositsResponse представляет ответ со списком входящих пополнений.
+type ProviderDepositsResponse struct {
+	Deposits []*ProviderDeposit `json:"deposits"`
+}
+
+// Статусы вывода средств. Вывод создаётся сразу с заблокированными средствами (held),
+// одобряется администратором, отправляется провайдеру выплат и завершается зачислением
+// получателю (settled), ошибкой (failed) или возвратом уже выплаченных средств (returned).
+// Выплата, завершённая провайдером без действующего резерва средств, ждёт разбора
+// администратором (manual_review).
+const (
+	WithdrawalStatusRequested    = "requested"
+	WithdrawalStatusHeld         = "held"
+	WithdrawalStatusApproved     = "approved"
+	WithdrawalStatusSent         = "sent"
+	WithdrawalStatusSettled      = "settled"
+	WithdrawalStatusFailed       = "failed"
+	WithdrawalStatusReturned     = "returned"
+	WithdrawalStatusCancelled    = "cancelled"
+	WithdrawalStatusManualReview = "manual_review"
+)
+
+// Статусы выплаты у провайдера.
+const (
+	PayoutStatusPending  = "pending"
+	PayoutStatusSettled  = "settled"
+	PayoutStatusFailed   = "failed"
+	PayoutStatusReturned = "returned"
+)
+
+// Withdrawal представляет вывод Amount в Currency с кошелька WalletID на реквизиты Destination.
+// До завершения выплаты средства зарезервированы блокировкой HoldID и списываются с баланса
+// только после подтверждения провайдером.
+type Withdrawal struct {
+	ID                uint64    `json:"id" db:"id"`
+	UserID            uint64    `json:"user_id" db:"user_id"`
+	WalletID          uint64    `json:"wallet_id" db:"wallet_id"`
+	Amount            float64   `json:"amount" db:"amount"`
+	Currency          string    `json:"currency" db:"currency"`
+	Destination       string    `json:"destination" db:"destination"`
+	Status            string    `json:"status" db:"status"`
+	HoldID            uint64    `json:"hold_id,omitempty" db:"hold_id"`
+	Provider          string    `json:"provider" db:"provider"`
+	ProviderReference string    `json:"provider_reference,omitempty" db:"provider_reference"`
+	OperationID       string    `json:"operation_id,omitempty" db:"operation_id"`
+	FailureReason     string    `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WithdrawalEvent представляет переход вывода средств в статус Status.
+type WithdrawalEvent struct {
+	ID           uint64    `json:"id" db:"id"`
+	WithdrawalID uint64    `json:"withdrawal_id" db:"withdrawal_id"`
+	Status       string    `json:"status" db:"status"`
+	Note         string    `json:"note,omitempty" db:"note"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// WithdrawalRequest представляет запрос на вывод средств.
+type WithdrawalRequest struct {
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	Currency    string  `json:"currency" validate:"required"`
+	Destination string  `json:"destination" validate:"required,max=255"`
+}
+
+// RejectWithdrawalRequest представляет отклонение вывода средств администратором.
+type RejectWithdrawalRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// WithdrawalResponse представляет ответ с информацией о выводе средств.
+type WithdrawalResponse struct {
+	Message    string      `json:"message"`
+	Withdrawal *Withdrawal `json:"withdrawal"`
+}
+
+// WithdrawalDetailsResponse представляет ответ с выводом средств и историей его статусов.
+type WithdrawalDetailsResponse struct {
+	Withdrawal *Withdrawal        `json:"withdrawal"`
+	Events     []*WithdrawalEvent `json:"events"`
+}
+
+// WithdrawalsResponse представляет ответ со списком выводов средств.
+type WithdrawalsResponse struct {
+	Withdrawals []*Withdrawal `json:"withdrawals"`
+}
+
+// Результаты сверки баланса кошелька с журналом транзакций.
+const (
+	SnapshotStatusMatched  = "matched"
+	SnapshotStatusMismatch = "mismatch"
+)
+
+// ReconciliationRun представляет ежедневную сверку: снимок балансов всех кошельков
+// и их сравнение с балансами, пересчитанными по журналу транзакций.
+type ReconciliationRun struct {
+	ID             uint64    `json:"id" db:"id"`
+	SnapshotDate   time.Time `json:"snapshot_date" db:"snapshot_date"`
+	WalletsChecked int64     `json:"wallets_checked" db:"wallets_checked"`
+	Mismatches     int64     `json:"mismatches" db:"mismatches"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// BalanceSnapshot представляет снимок баланса кошелька в рамках сверки.
+// Difference - расхождение учётного баланса с суммой проводок журнала.
+type BalanceSnapshot struct {
+	ID              uint64    `json:"id" db:"id"`
+	RunID           uint64    `json:"run_id" db:"run_id"`
+	WalletID        uint64    `json:"wallet_id" db:"wallet_id"`
+	UserID          uint64    `json:"user_id" db:"user_id"`
+	Currency        string    `json:"currency" db:"currency"`
+	SnapshotDate    time.Time `json:"snapshot_date" db:"snapshot_date"`
+	Balance         float64   `json:"balance" db:"balance"`
+	ComputedBalance float64   `json:"computed_balance" db:"computed_balance"`
+	Difference      float64   `json:"difference" db:"difference"`
+	Status          string    `json:"status" db:"status"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReconciliationRunsResponse представляет список проведённых сверок.
+type ReconciliationRunsResponse struct {
+	Runs []*ReconciliationRun `json:"runs"`
+}
+
+// ReconciliationReportResponse представляет отчёт по сверке.
+type ReconciliationReportResponse struct {
+	Run       *ReconciliationRun `json:"run"`
+	Snapshots []*BalanceSnapshot `json:"snapshots"`
+}
+
+// JournalCheckpoint представляет контрольную точку журнала: хеш последних проводок
+// всех кошельков на момент LastTransactionID.
+type JournalCheckpoint struct {
+	ID                uint64    `json:"id" db:"id"`
+	LastTransactionID uint64    `json:"last_transaction_id" db:"last_transaction_id"`
+	Wallets           int64     `json:"wallets" db:"wallets"`
+	Digest            string    `json:"digest" db:"digest"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
+// JournalBrokenLink описывает первое найденное нарушение цепочки журнала.
+type JournalBrokenLink struct {
+	TransactionID uint64 `json:"transaction_id"`
+	WalletID      uint64 `json:"wallet_id,omitempty"`
+	CheckpointID  uint64 `json:"checkpoint_id,omitempty"`
+	Reason        string `json:"reason"`
+}
+
+// JournalVerification представляет результат проверки целостности журнала.
+type JournalVerification struct {
+	Valid              bool               `json:"valid"`
+	EntriesChecked     int64              `json:"entries_checked"`
+	CheckpointsChecked int                `json:"checkpoints_checked"`
+	BrokenLink         *JournalBrokenLink `json:"broken_link,omitempty"`
+}
+
+// CreditLine представляет кредитную линию (овердрафт) кошелька: баланс может уйти в минус
+// не более чем на Limit. На отрицательный баланс ежедневно начисляются проценты по AnnualRate
+// и фиксированная плата DailyFee.
+type CreditLine struct {
+	WalletID      uint64    `json:"wallet_id" db:"wallet_id"`
+	UserID        uint64    `json:"user_id" db:"user_id"`
+	Currency      string    `json:"currency" db:"currency"`
+	Limit         float64   `json:"limit" db:"credit_limit"`
+	AnnualRate    float64   `json:"annual_rate" db:"annual_rate"`
+	DailyFee      float64   `json:"daily_fee" db:"daily_fee"`
+	LastAccruedOn time.Time `json:"last_accrued_on" db:"last_accrued_on"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreditLineRequest представляет запрос на открытие или изменение кредитной линии.
+// AnnualRate задаётся долей: 0.18 - 18% годовых.
+type CreditLineRequest struct {
+	Limit      float64 `json:"limit" validate:"gte=0"`
+	AnnualRate float64 `json:"annual_rate" validate:"gte=0,lte=1"`
+	DailyFee   float64 `json:"daily_fee" validate:"gte=0"`
+}
+
+// CreditLineResponse представляет ответ с информацией о кредитной линии.
+type CreditLineResponse struct {
+	Message    string      `json:"message,omitempty"`
+	CreditLine *CreditLine `json:"credit_line,omitempty"`
+}
+
+// InterestAccrual представляет начисление процентов на положительный остаток кошелька
+// за одни сутки. Начисления копятся с точностью до 8 знаков и выплачиваются раз в месяц.
+type InterestAccrual struct {
+	WalletID    uint64     `json:"wallet_id" db:"wallet_id"`
+	AccrualDate time.Time  `json:"accrual_date" db:"accrual_date"`
+	Balance     float64    `json:"balance" db:"balance"`
+	AnnualRate  float64    `json:"annual_rate" db:"annual_rate"`
+	Amount      float64    `json:"amount" db:"amount"`
+	OperationID string     `json:"operation_id,omitempty" db:"operation_id"`
+	PaidAt      *time.Time `json:"paid_at,omitempty" db:"paid_at"`
+}
+
+// InterestDue описывает кошелёк, по которому есть неначисленные дни, и дату последнего начисления.
+type InterestDue struct {
+	WalletID      uint64
+	Currency      string
+	AnnualRate    float64
+	LastAccrualOn *time.Time
+}
+
+// InterestSummary представляет начисленные, но ещё не выплаченные проценты по кошельку.
+type InterestSummary struct {
+	WalletID   uint64     `json:"wallet_id" db:"wallet_id"`
+	Currency   string     `json:"currency" db:"currency"`
+	AnnualRate float64    `json:"annual_rate" db:"annual_rate"`
+	Accrued    float64    `json:"accrued" db:"accrued"`
+	From       *time.Time `json:"from,omitempty" db:"accrued_from"`
+	To         *time.Time `json:"to,omitempty" db:"accrued_to"`
+}
+
+// InterestResponse представляет ответ с невыплаченными процентами по кошелькам пользователя.
+type InterestResponse struct {
+	Wallets []*InterestSummary `json:"wallets"`
+}
+
+// VoucherBatch представляет партию ваучеров на зачисление Amount в Currency. Каждый код партии
+// можно погасить не более MaxRedemptions раз (1 - одноразовый код) и не более одного раза
+// одним пользователем. Codes и Redemptions - количество кодов и выполненных погашений.
+type VoucherBatch struct {
+	ID             uint64     `json:"id" db:"id"`
+	TenantID       uint64     `json:"tenant_id" db:"tenant_id"`
+	Name           string     `json:"name" db:"name"`
+	Amount         float64    `json:"amount" db:"amount"`
+	Currency       string     `json:"currency" db:"currency"`
+	MaxRedemptions int        `json:"max_redemptions" db:"max_redemptions"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedBy      uint64     `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	Codes          int        `json:"codes" db:"codes"`
+	Redemptions    int        `json:"redemptions" db:"redemptions"`
+	Vouchers       []*Voucher `json:"vouchers,omitempty" db:"-"`
+}
+
+// Voucher представляет код партии ваучеров и число его погашений.
+type Voucher struct {
+	ID          uint64    `json:"id" db:"id"`
+	BatchID     uint64    `json:"batch_id" db:"batch_id"`
+	Code        string    `json:"code" db:"code"`
+	Redemptions int       `json:"redemptions" db:"redemptions"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// VoucherRedemption представляет погашение ваучера пользователем.
+type VoucherRedemption struct {
+	ID          uint64    `json:"id" db:"id"`
+	VoucherID   uint64    `json:"voucher_id" db:"voucher_id"`
+	UserID      uint64    `json:"user_id" db:"user_id"`
+	WalletID    uint64    `json:"wallet_id" db:"wallet_id"`
+	Amount      float64   `json:"amount" db:"amount"`
+	Currency    string    `json:"currency" db:"currency"`
+	OperationID string    `json:"operation_id" db:"operation_id"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// VoucherBatchRequest представляет запрос администратора на выпуск партии ваучеров.
+// Если задан Code, выпускается один промокод с этим кодом (Count не указывается или равен 1);
+// иначе генерируется Count случайных кодов. MaxRedemptions по умолчанию равен 1.
+type VoucherBatchRequest struct {
+	Name           string    `json:"name" validate:"required,max=100"`
+	Amount         float64   `json:"amount" validate:"required,gt=0"`
+	Currency       string    `json:"currency" validate:"required"`
+	Count          int       `json:"count"`
+	MaxRedemptions int       `json:"max_redemptions"`
+	ExpiresAt      time.Time `json:"expires_at" validate:"required"`
+	Code           string    `json:"code"`
+}
+
+// RedeemVoucherRequest представляет запрос пользователя на погашение ваучера.
+type RedeemVoucherRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// VoucherBatchResponse представляет ответ с партией ваучеров.
+type VoucherBatchResponse struct {
+	Message string        `json:"message"`
+	Batch   *VoucherBatch `json:"batch"`
+}
+
+// VoucherBatchesResponse представляет ответ со списком партий ваучеров.
+type VoucherBatchesResponse struct {
+	Batches []*VoucherBatch `json:"batches"`
+}
+
+// VoucherRedemptionResponse представляет ответ с результатом погашения ваучера.
+type VoucherRedemptionResponse struct {
+	Message    string             `json:"message"`
+	Redemption *VoucherRedemption `json:"redemption"`
+}
+
+// Статусы копилок.
+const (
+	PotStatusActive = "active"
+	PotStatusClosed = "closed"
+)
+
+// Pot представляет копилку - именованную часть баланса кошелька, отложенную на цель.
+// Средства копилки входят в учётный баланс кошелька, но не в доступный.
+type Pot struct {
+	ID         uint64     `json:"id" db:"id"`
+	WalletID   uint64     `json:"wallet_id" db:"wallet_id"`
+	UserID     uint64     `json:"user_id" db:"user_id"`
+	Currency   string     `json:"currency" db:"currency"`
+	Name       string     `json:"name" db:"name"`
+	GoalAmount float64    `json:"goal_amount" db:"goal_amount"`
+	GoalDate   *time.Time `json:"goal_date,omitempty" db:"goal_date"`
+	Balance    float64    `json:"balance" db:"balance"`
+	Status     string     `json:"status" db:"status"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+}
+
+// PotMovement представляет перемещение средств между основным балансом кошелька и копилкой.
+// Amount положителен при пополнении копилки и отрицателен при возврате в основной баланс.
+type PotMovement struct {
+	ID           uint64    `json:"id" db:"id"`
+	PotID        uint64    `json:"pot_id" db:"pot_id"`
+	Amount       float64   `json:"amount" db:"amount"`
+	BalanceAfter float64   `json:"balance_after" db:"balance_after"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// PotRequest представляет запрос на создание копилки.
+type PotRequest struct {
+	Currency   string     `json:"currency" validate:"required"`
+	Name       string     `json:"name" validate:"required,max=100"`
+	GoalAmount float64    `json:"goal_amount" validate:"gte=0"`
+	GoalDate   *time.Time `json:"goal_date"`
+}
+
+// PotMoveRequest представляет запрос на перемещение средств в копилку или из неё.
+type PotMoveRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+}
+
+// PotResponse представляет ответ с информацией о копилке.
+type PotResponse struct {
+	Message string `json:"message"`
+	Pot     *Pot   `json:"pot"`
+}
+
+// PotsResponse представляет ответ со списком копилок пользователя.
+type PotsResponse struct {
+	Pots []*Pot `json:"pots"`
+}
+
+// PotMovementsResponse представляет ответ с историей перемещений копилки.
+type PotMovementsResponse struct {
+	Movements []*PotMovement `json:"movements"`
+}
+
+// Роли участников совместного кошелька.
+const (
+	WalletRoleOwner   = "owner"
+	WalletRoleSpender = "spender"
+	WalletRoleViewer  = "viewer"
+)
+
+// Статусы участников совместного кошелька.
+const (
+	WalletMemberStatusInvited = "invited"
+	WalletMemberStatusActive  = "active"
+)
+
+// WalletMember представляет участника совместного кошелька. Владелец кошелька (Wallet.UserID)
+// имеет роль owner неявно и в списке участников не хранится. SpendLimit ограничивает
+// сумму списаний участника с ролью spender за скользящий период (services.SpendLimitPeriod).
+type WalletMember struct {
+	WalletID   uint64     `json:"wallet_id" db:"wallet_id"`
+	UserID     uint64     `json:"user_id" db:"user_id"`
+	Username   string     `json:"username" db:"username"`
+	Currency   string     `json:"currency" db:"currency"`
+	Role       string     `json:"role" db:"role"`
+	SpendLimit float64    `json:"spend_limit" db:"spend_limit"`
+	Status     string     `json:"status" db:"status"`
+	InvitedBy  uint64     `json:"invited_by" db:"invited_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+}
+
+// WalletAccess представляет кошелёк, доступный пользователю, и его роль в нём.
+type WalletAccess struct {
+	WalletID   uint64  `json:"wallet_id"`
+	OwnerID    uint64  `json:"owner_id"`
+	Currency   string  `json:"currency"`
+	Balance    float64 `json:"balance"`
+	Role       string  `json:"role"`
+	SpendLimit float64 `json:"spend_limit,omitempty"`
+	Version    uint64  `json:"version"`
+}
+
+// WalletMemberRequest представляет приглашение пользователя в совместный кошелёк.
+type WalletMemberRequest struct {
+	Username   string  `json:"username" validate:"required"`
+	Role       string  `json:"role" validate:"required,oneof=owner spender viewer"`
+	SpendLimit float64 `json:"spend_limit" validate:"gte=0"`
+}
+
+// WalletAmountRequest представляет запрос на пополнение или списание с кошелька по его ID.
+type WalletAmountRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+}
+
+// WalletExchangeRequest представляет запрос на обмен с кошелька по ID на кошелёк владельца в другой валюте.
+type WalletExchangeRequest struct {
+	ToCurrency string  `json:"to_currency" validate:"required"`
+	Amount     float64 `json:"amount" validate:"required,gt=0"`
+}
+
+// WalletTransferRequest представляет запрос на перевод с кошелька по ID другому пользователю.
+type WalletTransferRequest struct {
+	ToUserID uint64  `json:"to_user_id" validate:"required"`
+	Amount   float64 `json:"amount" validate:"required,gt=0"`
+}
+
+// WalletHoldRequest представляет запрос на блокировку средств на кошельке по ID.
+type WalletHoldRequest struct {
+	Amount    float64 `json:"amount" validate:"required,gt=0"`
+	Reference string  `json:"reference"`
+	// TTL блокировки в секундах; если не задан, используется значение по умолчанию.
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// WalletPotRequest представляет запрос на создание копилки в кошельке по ID.
+type WalletPotRequest struct {
+	Name       string     `json:"name" validate:"required,max=100"`
+	GoalAmount float64    `json:"goal_amount" validate:"gte=0"`
+	GoalDate   *time.Time `json:"goal_date"`
+}
+
+// WalletMemberResponse представляет ответ с информацией об участнике кошелька.
+type WalletMemberResponse struct {
+	Message string        `json:"message"`
+	Member  *WalletMember `json:"member,omitempty"`
+}
+
+// WalletMembersResponse представляет ответ со списком участников кошелька или приглашений.
+type WalletMembersResponse struct {
+	Members []*WalletMember `json:"members"`
+}
+
+// WalletsResponse представляет ответ со списком кошельков, доступных пользователю.
+type WalletsResponse struct {
+	Wallets []*WalletAccess `json:"wallets"`
+}
+
+// WalletOperationResponse представляет ответ на операцию с кошельком по его ID.
+type WalletOperationResponse struct {
+	Message    string  `json:"message"`
+	WalletID   uint64  `json:"wallet_id"`
+	NewBalance float64 `json:"new_balance"`
+	Version    uint64  `json:"version"`
+}
+
+// EventTypePrefix - префикс типа события о проводке по кошельку; тип события - префикс
+// и тип проводки, например "wallet.deposit".
+const EventTypePrefix = "wallet."
+
+// OutboxEvent представляет доменное событие кошелька WalletID, записанное в outbox в одной
+// транзакции с изменением баланса. Sequence нумерует события кошелька подряд, начиная с 1,
+// и позволяет получателю восстановить порядок и обнаружить повторы.
+type OutboxEvent struct {
+	ID          uint64          `json:"id" db:"id"`
+	TenantID    uint64          `json:"tenant_id" db:"tenant_id"`
+	WalletID    uint64          `json:"wallet_id" db:"wallet_id"`
+	Sequence    uint64          `json:"sequence" db:"sequence"`
+	Type        string          `json:"type" db:"event_type"`
+	Payload     json.RawMessage `json:"data" db:"payload" swaggertype:"object"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	Attempts    int             `json:"-" db:"attempts"`
+	LastError   string          `json:"-" db:"last_error"`
+	PublishedAt *time.Time      `json:"-" db:"published_at"`
+	// UserIDs - владелец и активные участники кошелька, заполняется при выборке событий
+	// для потока обновлений.
+	UserIDs []uint64 `json:"-"`
+}
+
+// WalletEventData - данные события о проводке по кошельку.
+type WalletEventData struct {
+	TransactionID uint64  `json:"transaction_id"`
+	UserID        uint64  `json:"user_id"`
+	OperationID   string  `json:"operation_id"`
+	Amount        float64 `json:"amount"`
+	Currency      string  `json:"currency"`
+	BalanceAfter  float64 `json:"balance_after"`
+	Description   string  `json:"description,omitempty"`
+}
+
+// WebhookEventAll - тип события в подписке на вебхуки, означающий любое событие.
+const WebhookEventAll = "*"
+
+// Статусы доставки вебхука.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription представляет подписку пользователя на события его кошельков с доставкой
+// на URL. Доставки подписываются HMAC-SHA256 на секрете подписки.
+type WebhookSubscription struct {
+	ID         uint64    `json:"id" db:"id"`
+	TenantID   uint64    `json:"tenant_id" db:"tenant_id"`
+	UserID     uint64    `json:"user_id" db:"user_id"`
+	URL        string    `json:"url" db:"url"`
+	EventTypes []string  `json:"event_types" db:"event_types"`
+	Secret     string    `json:"-" db:"secret"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// WebhookDelivery представляет доставку события EventID подписке SubscriptionID.
+// URL и Secret заполняются при выборке доставок для отправки.
+type WebhookDelivery struct {
+	ID             uint64          `json:"id" db:"id"`
+	SubscriptionID uint64          `json:"subscription_id" db:"subscription_id"`
+	EventID        uint64          `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	// Log - попытки доставки, заполняется только при запросе одной доставки.
+	Log    []*WebhookAttempt `json:"log,omitempty"`
+	URL    string            `json:"-"`
+	Secret string            `json:"-"`
+}
+
+// WebhookAttempt представляет попытку доставки вебхука: код ответа получателя или ошибку.
+type WebhookAttempt struct {
+	ID         uint64    `json:"id" db:"id"`
+	DeliveryID uint64    `json:"delivery_id" db:"delivery_id"`
+	Attempt    int       `json:"attempt" db:"attempt"`
+	StatusCode int       `json:"status_code,omitempty" db:"status_code"`
+	Error      string    `json:"error,omitempty" db:"error"`
+	DurationMS int64     `json:"duration_ms" db:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// WebhookSubscriptionRequest представляет запрос на создание подписки на вебхуки.
+// Если секрет не задан, он генерируется.
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url" validate:"required,url"`
+	EventTypes []string `json:"event_types" validate:"required"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+// WebhookSubscriptionResponse представляет ответ с подпиской на вебхуки. Секрет возвращается
+// только при создании подписки.
+type WebhookSubscriptionResponse struct {
+	Message      string               `json:"message"`
+	Subscription *WebhookSubscription `json:"subscription,omitempty"`
+	Secret       string               `json:"secret,omitempty"`
+}
+
+// WebhookSubscriptionsResponse представляет ответ со списком подписок на вебхуки.
+type WebhookSubscriptionsResponse struct {
+	Subscriptions []*WebhookSubscription `json:"subscriptions"`
+}
+
+// WebhookDeliveryResponse представляет ответ с доставкой вебхука и журналом её попыток.
+type WebhookDeliveryResponse struct {
+	Message  string           `json:"message"`
+	Delivery *WebhookDelivery `json:"delivery"`
+}
+
+// WebhookDeliveriesResponse представляет ответ со списком доставок подписки.
+type WebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+}
+
+// Типы событий потока обновлений.
+const (
+	StreamEventBalance = "balance"
+	StreamEventRate    = "rate"
+	// StreamEventResync сообщает, что пропущенных событий больше, чем поток передаёт
+	// при возобновлении, и балансы нужно запросить заново.
+	StreamEventResync = "resync"
+)
+
+// StreamEvent представляет событие потока обновлений (SSE или WebSocket). ID задан только
+// у событий об изменении баланса: это ID события outbox, с которого клиент возобновляет поток.
+type StreamEvent struct {
+	ID   uint64      `json:"id,omitempty"`
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// BalanceUpdate - данные события потока об изменении баланса кошелька.
+type BalanceUpdate struct {
+	WalletID      uint64    `json:"wallet_id"`
+	Currency      string    `json:"currency"`
+	Balance       float64   `json:"balance"`
+	Amount        float64   `json:"amount"`
+	EventType     string    `json:"event_type"`
+	Sequence      uint64    `json:"sequence"`
+	TransactionID uint64    `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RateUpdate - данные события потока об изменении курса пары валют вида "USD/EUR":
+// сколько единиц второй валюты стоит единица первой.
+type RateUpdate struct {
+	Pair string    `json:"pair"`
+	Rate float64   `json:"rate"`
+	At   time.Time `json:"at"`
+}
+
+// Типы уведомлений об активности в аккаунте.
+const (
+	NotificationLargeWithdrawal = "large_withdrawal"
+	NotificationExchange        = "exchange"
+	NotificationFailedLogin     = "failed_login"
+	NotificationNewSession      = "new_session"
+)
+
+// Статусы отправки уведомления по почте.
+const (
+	NotificationEmailPending = "pending"
+	NotificationEmailSent    = "sent"
+	NotificationEmailFailed  = "failed"
+	NotificationEmailSkipped = "skipped"
+)
+
+// Notification представляет уведомление пользователя, сформированное по шаблону типа события.
+// Email заполняется при выборке уведомлений для отправки писем.
+type Notification struct {
+	ID            uint64     `json:"id" db:"id"`
+	TenantID      uint64     `json:"-" db:"tenant_id"`
+	UserID        uint64     `json:"user_id" db:"user_id"`
+	EventType     string     `json:"event_type" db:"event_type"`
+	Subject       string     `json:"subject" db:"subject"`
+	Body          string     `json:"body" db:"body"`
+	InApp         bool       `json:"-" db:"in_app"`
+	EmailStatus   string     `json:"email_status" db:"email_status"`
+	EmailAttempts int        `json:"-" db:"email_attempts"`
+	EmailError    string     `json:"-" db:"email_error"`
+	NextAttemptAt *time.Time `json:"-" db:"next_attempt_at"`
+	ReadAt        *time.Time `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	Email         string     `json:"-"`
+}
+
+// NotificationPreference - настройки уведомлений пользователя о событиях одного типа.
+type NotificationPreference struct {
+	UserID    uint64 `json:"-" db:"user_id"`
+	EventType string `json:"event_type" db:"event_type"`
+	InApp     bool   `json:"in_app" db:"in_app"`
+	Email     bool   `json:"email" db:"email"`
+	// Threshold - минимальная сумма вывода в валюте кошелька, о которой приходит уведомление
+	// large_withdrawal; для остальных типов не используется.
+	Threshold float64 `json:"threshold,omitempty" db:"threshold"`
+}
+
+// NotificationPreferencesRequest представляет запрос на изменение настроек уведомлений.
+// Настройки типов, не указанных в запросе, не меняются.
+type NotificationPreferencesRequest struct {
+	Preferences []*NotificationPreference `json:"preferences" validate:"required"`
+}
+
+// NotificationPreferencesResponse представляет ответ с настройками уведомлений по всем типам событий.
+type NotificationPreferencesResponse struct {
+	Preferences []*NotificationPreference `json:"preferences"`
+}
+
+// NotificationsResponse представляет ответ со входящими уведомлениями пользователя.
+type NotificationsResponse struct {
+	Notifications []*Notification `json:"notifications"`
+	UnreadCount   int             `json:"unread_count"`
+}
+
+// NotificationsReadResponse представляет ответ на отметку уведомлений прочитанными.
+type NotificationsReadResponse struct {
+	Message string `json:"message"`
+	Marked  int    `json:"marked"`
 }
@@ -0,0 +1,60 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// FakeProviderName - имя локального провайдера в пути вебхука.
+const FakeProviderName = "fake"
+
+// FakeProvider - локальный провайдер для разработки и тестов. Уведомление - JSON с полями
+// models.DepositEvent, подписанный HMAC-SHA256 на общем секрете.
+type FakeProvider struct {
+	secret []byte
+}
+
+// NewFakeProvider создаёт локального провайдера с секретом подписи secret.
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{secret: []byte(secret)}
+}
+
+// Name возвращает имя провайдера.
+func (p *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+// VerifySignature проверяет подпись уведомления.
+func (p *FakeProvider) VerifySignature(payload []byte, signature string) bool {
+	return VerifySignature(p.secret, payload, signature)
+}
+
+// ParseDeposit разбирает тело уведомления о пополнении.
+func (p *FakeProvider) ParseDeposit(payload []byte) (*models.DepositEvent, error) {
+	var event models.DepositEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid payload: %v", err)
+	}
+
+	event.EventID = strings.TrimSpace(event.EventID)
+	event.Reference = strings.TrimSpace(event.Reference)
+	event.Currency = strings.ToUpper(strings.TrimSpace(event.Currency))
+	if event.EventID == "" {
+		return nil, errors.New("event_id is required")
+	}
+	return &event, nil
+}
+
+// Deposit формирует подписанное уведомление о пополнении, как его отправил бы провайдер.
+// Возвращает тело запроса и подпись для заголовка X-Signature.
+func (p *FakeProvider) Deposit(event *models.DepositEvent) ([]byte, string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, signaturePrefix + Sign(p.secret, payload), nil
+}
@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// LocalPayoutName - имя локального провайдера выплат.
+const LocalPayoutName = "local"
+
+// LocalPayout - локальный провайдер выплат для разработки и тестов. Выплаты хранятся в памяти
+// и завершаются через delay после отправки. Исход определяется реквизитами получателя:
+// с префиксом "fail" выплата завершается ошибкой, с префиксом "return" - выплачивается
+// и затем возвращается, остальные выплачиваются успешно.
+type LocalPayout struct {
+	delay time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	payouts map[string]*localPayout
+}
+
+type localPayout struct {
+	destination string
+	sentAt      time.Time
+}
+
+// NewLocalPayout создаёт локального провайдера выплат, завершающего выплаты через delay.
+func NewLocalPayout(delay time.Duration) *LocalPayout {
+	return &LocalPayout{delay: delay, now: time.Now, payouts: make(map[string]*localPayout)}
+}
+
+// Name возвращает имя провайдера.
+func (p *LocalPayout) Name() string {
+	return LocalPayoutName
+}
+
+// Send регистрирует выплату. Повторная отправка того же вывода возвращает ту же выплату.
+func (p *LocalPayout) Send(_ context.Context, withdrawal *models.Withdrawal) (string, error) {
+	reference := fmt.Sprintf("local-%d", withdrawal.ID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.payouts[reference]; !ok {
+		p.payouts[reference] = &localPayout{destination: strings.ToLower(withdrawal.Destination), sentAt: p.now()}
+	}
+	return reference, nil
+}
+
+// GetStatus возвращает статус выплаты. Выплаты, отправленные до перезапуска, неизвестны
+// и считаются неуспешными.
+func (p *LocalPayout) GetStatus(_ context.Context, reference string) (string, error) {
+	p.mu.Lock()
+	payout, ok := p.payouts[reference]
+	p.mu.Unlock()
+	if !ok {
+		return models.PayoutStatusFailed, nil
+	}
+
+	elapsed := p.now().Sub(payout.sentAt)
+	switch {
+	case elapsed < p.delay:
+		return models.PayoutStatusPending, nil
+	case strings.HasPrefix(payout.destination, "fail"):
+		return models.PayoutStatusFailed, nil
+	case strings.HasPrefix(payout.destination, "return") && elapsed >= 2*p.delay:
+		return models.PayoutStatusReturned, nil
+	default:
+		return models.PayoutStatusSettled, nil
+	}
+}
@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalPayoutLifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	payout := NewLocalPayout(time.Minute)
+	payout.now = func() time.Time { return now }
+
+	settled, err := payout.Send(ctx, &models.Withdrawal{ID: 1, Destination: "DE89370400440532013000"})
+	assert.NoError(t, err)
+	failed, _ := payout.Send(ctx, &models.Withdrawal{ID: 2, Destination: "FAIL-account"})
+	returned, _ := payout.Send(ctx, &models.Withdrawal{ID: 3, Destination: "return-account"})
+
+	// Повторная отправка не создаёт новую выплату
+	again, _ := payout.Send(ctx, &models.Withdrawal{ID: 1, Destination: "DE89370400440532013000"})
+	assert.Equal(t, settled, again)
+
+	status, _ := payout.GetStatus(ctx, settled)
+	assert.Equal(t, models.PayoutStatusPending, status)
+
+	now = now.Add(time.Minute)
+	status, _ = payout.GetStatus(ctx, settled)
+	assert.Equal(t, models.PayoutStatusSettled, status)
+	status, _ = payout.GetStatus(ctx, failed)
+	assert.Equal(t, models.PayoutStatusFailed, status)
+	status, _ = payout.GetStatus(ctx, returned)
+	assert.Equal(t, models.PayoutStatusSettled, status)
+
+	now = now.Add(time.Minute)
+	status, _ = payout.GetStatus(ctx, returned)
+	assert.Equal(t, models.PayoutStatusReturned, status)
+
+	status, _ = payout.GetStatus(ctx, "local-99")
+	assert.Equal(t, models.PayoutStatusFailed, status)
+}
@@ -0,0 +1,37 @@
+// Пакет providers содержит реализации платёжных провайдеров: источников уведомлений о входящих
+// пополнениях и провайдеров выплат для вывода средств.
+package providers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// signaturePrefix - необязательный префикс подписи, указывающий алгоритм.
+const signaturePrefix = "sha256="
+
+// Sign возвращает HMAC-SHA256 тела уведомления payload на секрете secret в шестнадцатеричном виде.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature проверяет подпись HMAC-SHA256 тела уведомления за постоянное время.
+// Подпись принимается как с префиксом "sha256=", так и без него.
+func VerifySignature(secret, payload []byte, signature string) bool {
+	if len(secret) == 0 {
+		return false
+	}
+
+	decoded, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), signaturePrefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(decoded, mac.Sum(nil))
+}
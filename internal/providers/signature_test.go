@@ -0,0 +1,37 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"event_id":"evt-1"}`)
+	signature := Sign(secret, payload)
+
+	assert.True(t, VerifySignature(secret, payload, signature))
+	assert.True(t, VerifySignature(secret, payload, "sha256="+signature))
+	assert.False(t, VerifySignature(secret, []byte(`{"event_id":"evt-2"}`), signature))
+	assert.False(t, VerifySignature([]byte("other"), payload, signature))
+	assert.False(t, VerifySignature(secret, payload, "not-hex"))
+	assert.False(t, VerifySignature(nil, payload, Sign(nil, payload)))
+}
+
+func TestFakeProviderRoundTrip(t *testing.T) {
+	provider := NewFakeProvider("secret")
+	payload, signature, err := provider.Deposit(&models.DepositEvent{EventID: "evt-1", Reference: " GWABC ", Amount: 10.5, Currency: "usd"})
+	assert.NoError(t, err)
+	assert.True(t, provider.VerifySignature(payload, signature))
+
+	event, err := provider.ParseDeposit(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "GWABC", event.Reference)
+	assert.Equal(t, "USD", event.Currency)
+	assert.Equal(t, 10.5, event.Amount)
+
+	_, err = provider.ParseDeposit([]byte(`{"amount":1}`))
+	assert.Error(t, err)
+}
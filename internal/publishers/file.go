@@ -0,0 +1,55 @@
+package publishers
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// FilePublisherName - имя брокера событий, записывающего их в файл.
+const FilePublisherName = "file"
+
+// FilePublisher дописывает события в файл по одному JSON на строку (JSON Lines).
+type FilePublisher struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFilePublisher создаёт брокер событий, дописывающий их в файл path.
+func NewFilePublisher(path string) *FilePublisher {
+	return &FilePublisher{path: path}
+}
+
+// Name возвращает имя брокера.
+func (p *FilePublisher) Name() string {
+	return FilePublisherName
+}
+
+// Publish дописывает событие в файл и сбрасывает его на диск, чтобы событие не было
+// отмечено опубликованным раньше, чем сохранено.
+func (p *FilePublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	line, err := Encode(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	file, err := os.OpenFile(p.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,39 @@
+package publishers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilePublisherAppendsLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	publisher := NewFilePublisher(path)
+	ctx := context.Background()
+
+	assert.NoError(t, publisher.Publish(ctx, &models.OutboxEvent{ID: 1, WalletID: 7, Sequence: 1, Type: "wallet.deposit", Payload: json.RawMessage(`{"amount":10}`)}))
+	assert.NoError(t, publisher.Publish(ctx, &models.OutboxEvent{ID: 2, WalletID: 7, Sequence: 2, Type: "wallet.withdrawal", Payload: json.RawMessage(`{"amount":-5}`)}))
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	var events []models.OutboxEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event models.OutboxEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, uint64(2), events[1].Sequence)
+		assert.Equal(t, "wallet.withdrawal", events[1].Type)
+		assert.JSONEq(t, `{"amount":-5}`, string(events[1].Payload))
+	}
+}
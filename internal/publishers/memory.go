@@ -0,0 +1,45 @@
+package publishers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// MemoryPublisherName - имя брокера событий в памяти.
+const MemoryPublisherName = "memory"
+
+// MemoryPublisher хранит опубликованные события в памяти процесса. Предназначен для разработки
+// и тестов: события теряются при перезапуске.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []*models.OutboxEvent
+}
+
+// NewMemoryPublisher создаёт брокер событий в памяти.
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Name возвращает имя брокера.
+func (p *MemoryPublisher) Name() string {
+	return MemoryPublisherName
+}
+
+// Publish сохраняет событие.
+func (p *MemoryPublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events возвращает опубликованные события в порядке публикации.
+func (p *MemoryPublisher) Events() []*models.OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := make([]*models.OutboxEvent, len(p.events))
+	copy(events, p.events)
+	return events
+}
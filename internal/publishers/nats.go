@@ -0,0 +1,197 @@
+package publishers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+const (
+	// NATSPublisherName - имя брокера событий NATS.
+	NATSPublisherName = "nats"
+	// natsTimeout ограничивает подключение и публикацию одного события, если контекст
+	// не задаёт меньший срок.
+	natsTimeout = 10 * time.Second
+	// natsClientName - имя клиента в CONNECT, по которому подключение видно в мониторинге NATS.
+	natsClientName = "gw-currency-wallet"
+)
+
+// NATSPublisher публикует события в NATS в тему "<prefix>.<тип события>", например
+// "events.wallet.deposit". Реализует текстовый протокол NATS поверх TCP: после каждой публикации
+// отправляет PING и ждёт PONG, так что успешный Publish означает, что сервер принял сообщение.
+// При ошибке соединение закрывается и устанавливается заново при следующей публикации.
+type NATSPublisher struct {
+	address  string
+	user     string
+	password string
+	prefix   string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSPublisher создаёт брокер событий NATS по адресу вида nats://[user:password@]host:port
+// с префиксом тем prefix.
+func NewNATSPublisher(rawURL, prefix string) (*NATSPublisher, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %v", err)
+	}
+	if parsed.Scheme != "nats" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q: expected nats://host:port", rawURL)
+	}
+	if prefix == "" {
+		return nil, errors.New("NATS subject prefix is required")
+	}
+
+	publisher := &NATSPublisher{address: parsed.Host, prefix: prefix}
+	if parsed.Port() == "" {
+		publisher.address = net.JoinHostPort(parsed.Hostname(), "4222")
+	}
+	if parsed.User != nil {
+		publisher.user = parsed.User.Username()
+		publisher.password, _ = parsed.User.Password()
+	}
+	return publisher, nil
+}
+
+// Name возвращает имя брокера.
+func (p *NATSPublisher) Name() string {
+	return NATSPublisherName
+}
+
+// Subject возвращает тему, в которую публикуется событие.
+func (p *NATSPublisher) Subject(event *models.OutboxEvent) string {
+	return p.prefix + "." + event.Type
+}
+
+// Publish публикует событие и ждёт подтверждения сервера.
+func (p *NATSPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	payload, err := Encode(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(ctx, p.Subject(event), payload); err != nil {
+		p.closeConn()
+		return fmt.Errorf("nats: %v", err)
+	}
+	return nil
+}
+
+// Close закрывает соединение с сервером.
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closeConn()
+}
+
+func (p *NATSPublisher) publish(ctx context.Context, subject string, payload []byte) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.conn.SetDeadline(deadline(ctx)); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if _, err := p.conn.Write([]byte(message)); err != nil {
+		return err
+	}
+	return p.awaitPong()
+}
+
+// connect подключается к серверу, отправляет CONNECT и проверяет его PING/PONG, чтобы ошибка
+// авторизации обнаружилась сразу.
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	var dialer net.Dialer
+	dialCtx, cancel := context.WithDeadline(ctx, deadline(ctx))
+	defer cancel()
+	conn, err := dialer.DialContext(dialCtx, "tcp", p.address)
+	if err != nil {
+		return err
+	}
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+	if err := conn.SetDeadline(deadline(ctx)); err != nil {
+		return err
+	}
+
+	info, err := p.reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(info, "INFO") {
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(info))
+	}
+
+	options, err := json.Marshal(map[string]any{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     natsClientName,
+		"user":     p.user,
+		"pass":     p.password,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", options); err != nil {
+		return err
+	}
+	return p.awaitPong()
+}
+
+// awaitPong читает ответы сервера до PONG, отвечая на его PING и пропуская служебные сообщения.
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *NATSPublisher) closeConn() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	p.reader = nil
+	return err
+}
+
+// deadline возвращает срок операции: срок контекста, если он меньше natsTimeout.
+func deadline(ctx context.Context) time.Time {
+	limit := time.Now().Add(natsTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(limit) {
+		return ctxDeadline
+	}
+	return limit
+}
@@ -0,0 +1,97 @@
+package publishers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type natsMessage struct {
+	subject string
+	payload []byte
+}
+
+// serveNATS принимает одно подключение и отвечает на него как сервер NATS, передавая
+// опубликованные сообщения в messages.
+func serveNATS(t *testing.T, listener net.Listener, messages chan<- natsMessage) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "PUB":
+			var size int
+			fmt.Sscan(fields[len(fields)-1], &size)
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				t.Errorf("failed to read payload: %v", err)
+				return
+			}
+			messages <- natsMessage{subject: fields[1], payload: payload[:size]}
+		}
+	}
+}
+
+func TestNATSPublisherPublish(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	messages := make(chan natsMessage, 1)
+	go serveNATS(t, listener, messages)
+
+	publisher, err := NewNATSPublisher("nats://"+listener.Addr().String(), "events")
+	assert.NoError(t, err)
+	defer publisher.Close()
+
+	event := &models.OutboxEvent{ID: 5, WalletID: 7, Sequence: 3, Type: "wallet.exchange_in", Payload: json.RawMessage(`{"amount":90}`)}
+	assert.NoError(t, publisher.Publish(context.Background(), event))
+
+	message := <-messages
+	assert.Equal(t, "events.wallet.exchange_in", message.subject)
+	var published models.OutboxEvent
+	assert.NoError(t, json.Unmarshal(message.payload, &published))
+	assert.Equal(t, uint64(5), published.ID)
+	assert.Equal(t, uint64(3), published.Sequence)
+}
+
+func TestNATSPublisherUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	publisher, err := NewNATSPublisher("nats://"+address, "events")
+	assert.NoError(t, err)
+	assert.Error(t, publisher.Publish(context.Background(), &models.OutboxEvent{ID: 1, Type: "wallet.deposit"}))
+}
+
+func TestNewNATSPublisherInvalidURL(t *testing.T) {
+	_, err := NewNATSPublisher("http://localhost:4222", "events")
+	assert.Error(t, err)
+	_, err = NewNATSPublisher("nats://localhost:4222", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,15 @@
+// Пакет publishers содержит реализации брокеров, в которые публикуются доменные события
+// из outbox: NATS, файл в формате JSON Lines и память процесса.
+package publishers
+
+import (
+	"encoding/json"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// Encode возвращает тело сообщения с событием: JSON с ID, тенантом, кошельком, номером
+// в последовательности кошелька, типом, данными и временем события.
+func Encode(event *models.OutboxEvent) ([]byte, error) {
+	return json.Marshal(event)
+}
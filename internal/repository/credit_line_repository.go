@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+const creditLineColumns = `
+	c.wallet_id, w.user_id, w.currency, c.credit_limit, c.annual_rate, c.daily_fee,
+	c.last_accrued_on, c.created_at, c.updated_at`
+
+func scanCreditLine(row interface{ Scan(dest ...any) error }) (*models.CreditLine, error) {
+	line := &models.CreditLine{}
+	err := row.Scan(
+		&line.WalletID,
+		&line.UserID,
+		&line.Currency,
+		&line.Limit,
+		&line.AnnualRate,
+		&line.DailyFee,
+		&line.LastAccruedOn,
+		&line.CreatedAt,
+		&line.UpdatedAt,
+	)
+	return line, err
+}
+
+func (r *repo) getCreditLine(ctx context.Context, walletID uint64, lock string) (*models.CreditLine, error) {
+	query := `SELECT ` + creditLineColumns + `
+		FROM credit_lines c
+		JOIN wallets w ON w.id = c.wallet_id
+		WHERE c.wallet_id = $1` + lock
+	line, err := scanCreditLine(r.db.QueryRowContext(ctx, query, walletID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching credit line:", err)
+		return nil, err
+	}
+	return line, nil
+}
+
+// GetCreditLine получает кредитную линию кошелька. Возвращает nil, если линии нет.
+func (r *repo) GetCreditLine(ctx context.Context, walletID uint64) (*models.CreditLine, error) {
+	return r.getCreditLine(ctx, walletID, "")
+}
+
+// GetCreditLineForUpdate получает кредитную линию кошелька и блокирует строку до конца транзакции.
+// Возвращает nil, если линии нет.
+func (r *repo) GetCreditLineForUpdate(ctx context.Context, walletID uint64) (*models.CreditLine, error) {
+	return r.getCreditLine(ctx, walletID, " FOR UPDATE OF c")
+}
+
+// GetCreditLimitsByUserID возвращает лимиты овердрафта пользователя по валютам.
+func (r *repo) GetCreditLimitsByUserID(ctx context.Context, userID uint64) (map[string]float64, error) {
+	query := `
+		SELECT w.currency, c.credit_limit
+		FROM credit_lines c
+		JOIN wallets w ON w.id = c.wallet_id
+		WHERE w.user_id = $1`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits := make(map[string]float64)
+	for rows.Next() {
+		var currency string
+		var limit float64
+		if err := rows.Scan(&currency, &limit); err != nil {
+			return nil, err
+		}
+		limits[currency] = limit
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return limits, nil
+}
+
+// UpsertCreditLine открывает кредитную линию кошелька или изменяет её условия.
+// Дата последнего начисления при изменении условий сохраняется.
+func (r *repo) UpsertCreditLine(ctx context.Context, line *models.CreditLine) error {
+	query := `
+		INSERT INTO credit_lines (wallet_id, credit_limit, annual_rate, daily_fee)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (wallet_id) DO UPDATE
+		SET credit_limit = EXCLUDED.credit_limit,
+			annual_rate = EXCLUDED.annual_rate,
+			daily_fee = EXCLUDED.daily_fee,
+			updated_at = NOW()
+		RETURNING last_accrued_on, created_at, updated_at`
+	err := r.db.QueryRowContext(ctx, query,
+		line.WalletID,
+		line.Limit,
+		line.AnnualRate,
+		line.DailyFee,
+	).Scan(&line.LastAccruedOn, &line.CreatedAt, &line.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Error upserting credit line:", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteCreditLine закрывает кредитную линию кошелька.
+func (r *repo) DeleteCreditLine(ctx context.Context, walletID uint64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM credit_lines WHERE wallet_id = $1`, walletID)
+	return err
+}
+
+// GetCreditLinesDueForAccrual возвращает до limit ID кошельков, начисления по кредитным линиям
+// которых не выполнялись за дату today.
+func (r *repo) GetCreditLinesDueForAccrual(ctx context.Context, today time.Time, limit int) ([]uint64, error) {
+	query := `
+		SELECT wallet_id
+		FROM credit_lines
+		WHERE last_accrued_on < $1
+		ORDER BY last_accrued_on, wallet_id
+		LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, today, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var walletIDs []uint64
+	for rows.Next() {
+		var walletID uint64
+		if err := rows.Scan(&walletID); err != nil {
+			return nil, err
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return walletIDs, nil
+}
+
+// UpdateCreditLineAccrual сохраняет дату последнего начисления по кредитной линии.
+func (r *repo) UpdateCreditLineAccrual(ctx context.Context, walletID uint64, accruedOn time.Time) error {
+	query := `UPDATE credit_lines SET last_accrued_on = $1, updated_at = NOW() WHERE wallet_id = $2`
+	_, err := r.db.ExecContext(ctx, query, accruedOn, walletID)
+	return err
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const currencyColumns = "code, name, minor_units, symbol, enabled, interest_rate, max_amount, created_at, updated_at"
+
+func scanCurrency(row interface{ Scan(dest ...any) error }) (*models.Currency, error) {
+	currency := &models.Currency{}
+	err := row.Scan(
+		&currency.Code,
+		&currency.Name,
+		&currency.MinorUnits,
+		&currency.Symbol,
+		&currency.Enabled,
+		&currency.InterestRate,
+		&currency.MaxAmount,
+		&currency.CreatedAt,
+		&currency.UpdatedAt,
+	)
+	return currency, err
+}
+
+// Справочник валют ведётся отдельно для каждого тенанта: все запросы выполняются
+// для тенанта контекста (tenant.ID).
+
+// GetCurrencies получает валюты справочника; если onlyEnabled, то только включённые.
+func (r *repo) GetCurrencies(ctx context.Context, onlyEnabled bool) ([]*models.Currency, error) {
+	query := `SELECT ` + currencyColumns + ` FROM currencies WHERE tenant_id = $1 AND (enabled OR NOT $2) ORDER BY code`
+	rows, err := r.db.QueryContext(ctx, query, tenant.ID(ctx), onlyEnabled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []*models.Currency
+	for rows.Next() {
+		currency, err := scanCurrency(rows)
+		if err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, currency)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return currencies, nil
+}
+
+// GetCurrencyByCode получает валюту по коду. Возвращает nil, если валюта не найдена.
+func (r *repo) GetCurrencyByCode(ctx context.Context, code string) (*models.Currency, error) {
+	query := `SELECT ` + currencyColumns + ` FROM currencies WHERE tenant_id = $1 AND code = $2`
+	currency, err := scanCurrency(r.db.QueryRowContext(ctx, query, tenant.ID(ctx), code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching currency:", err)
+		return nil, err
+	}
+	return currency, nil
+}
+
+// CreateCurrency добавляет валюту в справочник.
+func (r *repo) CreateCurrency(ctx context.Context, currency *models.Currency) error {
+	query := `
+		INSERT INTO currencies (tenant_id, code, name, minor_units, symbol, enabled, interest_rate, max_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at`
+	err := r.db.QueryRowContext(ctx, query,
+		tenant.ID(ctx),
+		currency.Code,
+		currency.Name,
+		currency.MinorUnits,
+		currency.Symbol,
+		currency.Enabled,
+		currency.InterestRate,
+		currency.MaxAmount,
+	).Scan(&currency.CreatedAt, &currency.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting currency:", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateCurrency сохраняет изменения валюты справочника.
+func (r *repo) UpdateCurrency(ctx context.Context, currency *models.Currency) error {
+	query := `
+		UPDATE currencies
+		SET name = $1, minor_units = $2, symbol = $3, enabled = $4, interest_rate = $5, max_amount = $6, updated_at = NOW()
+		WHERE tenant_id = $7 AND code = $8
+		RETURNING updated_at`
+	err := r.db.QueryRowContext(ctx, query,
+		currency.Name,
+		currency.MinorUnits,
+		currency.Symbol,
+		currency.Enabled,
+		currency.InterestRate,
+		currency.MaxAmount,
+		tenant.ID(ctx),
+		currency.Code,
+	).Scan(&currency.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Error updating currency:", err)
+		return err
+	}
+	return nil
+}
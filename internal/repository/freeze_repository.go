@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// CreateFreezeEvent сохраняет запись журнала заморозок.
+func (r *repo) CreateFreezeEvent(ctx context.Context, event *models.FreezeEvent) error {
+	query := `
+		INSERT INTO freeze_events (target_type, target_id, previous_state, state, reason, operator_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		event.TargetType,
+		event.TargetID,
+		event.PreviousState,
+		event.State,
+		event.Reason,
+		event.OperatorID,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting freeze event:", err)
+		return err
+	}
+	return nil
+}
+
+// GetFreezeEvents получает журнал заморозок пользователя или кошелька в хронологическом порядке.
+func (r *repo) GetFreezeEvents(ctx context.Context, targetType string, targetID uint64) ([]*models.FreezeEvent, error) {
+	query := `
+		SELECT id, target_type, target_id, previous_state, state, reason, operator_id, created_at
+		FROM freeze_events
+		WHERE target_type = $1 AND target_id = $2
+		ORDER BY created_at, id`
+	rows, err := r.db.QueryContext(ctx, query, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.FreezeEvent
+	for rows.Next() {
+		event := &models.FreezeEvent{}
+		if err := rows.Scan(
+			&event.ID,
+			&event.TargetType,
+			&event.TargetID,
+			&event.PreviousState,
+			&event.State,
+			&event.Reason,
+			&event.OperatorID,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const holdColumns = `
+	h.id, h.wallet_id, w.user_id, w.currency, h.amount, h.captured_amount,
+	h.kind, h.status, h.reference, h.expires_at, h.created_at, h.updated_at, h.initiated_by`
+
+func scanHold(row interface{ Scan(dest ...any) error }) (*models.Hold, error) {
+	hold := &models.Hold{}
+	var initiatedBy sql.NullInt64
+	err := row.Scan(
+		&hold.ID,
+		&hold.WalletID,
+		&hold.UserID,
+		&hold.Currency,
+		&hold.Amount,
+		&hold.CapturedAmount,
+		&hold.Kind,
+		&hold.Status,
+		&hold.Reference,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+		&initiatedBy,
+	)
+	hold.InitiatedBy = uint64(initiatedBy.Int64)
+	return hold, err
+}
+
+// CreateHold создаёт новую блокировку средств.
+func (r *repo) CreateHold(ctx context.Context, hold *models.Hold) (uint64, error) {
+	query := `
+		INSERT INTO holds (wallet_id, amount, kind, status, reference, expires_at, initiated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+	var initiatedBy sql.NullInt64
+	if hold.InitiatedBy != 0 {
+		initiatedBy = sql.NullInt64{Int64: int64(hold.InitiatedBy), Valid: true}
+	}
+	var holdID uint64
+	err := r.db.QueryRowContext(ctx, query,
+		hold.WalletID,
+		hold.Amount,
+		hold.Kind,
+		hold.Status,
+		hold.Reference,
+		hold.ExpiresAt,
+		initiatedBy,
+	).Scan(&holdID)
+	if err != nil {
+		r.logger.Error("Error inserting hold:", err)
+		return 0, err
+	}
+	return holdID, nil
+}
+
+// GetHoldByID получает блокировку по ID. Возвращает nil, если блокировка не найдена.
+func (r *repo) GetHoldByID(ctx context.Context, holdID uint64) (*models.Hold, error) {
+	query := `SELECT ` + holdColumns + `
+		FROM holds h
+		JOIN wallets w ON w.id = h.wallet_id
+		WHERE h.id = $1 AND ($2 = 0 OR w.tenant_id = $2)`
+	hold, err := scanHold(r.db.QueryRowContext(ctx, query, holdID, tenant.Filter(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching hold:", err)
+		return nil, err
+	}
+	return hold, nil
+}
+
+// GetHoldByIDForUpdate получает блокировку по ID и блокирует строку до конца транзакции.
+func (r *repo) GetHoldByIDForUpdate(ctx context.Context, holdID uint64) (*models.Hold, error) {
+	query := `SELECT ` + holdColumns + `
+		FROM holds h
+		JOIN wallets w ON w.id = h.wallet_id
+		WHERE h.id = $1 AND ($2 = 0 OR w.tenant_id = $2)
+		FOR UPDATE OF h`
+	hold, err := scanHold(r.db.QueryRowContext(ctx, query, holdID, tenant.Filter(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching hold:", err)
+		return nil, err
+	}
+	return hold, nil
+}
+
+// GetHoldsByUserID получает все блокировки пользователя, начиная с последних.
+func (r *repo) GetHoldsByUserID(ctx context.Context, userID uint64) ([]*models.Hold, error) {
+	query := `SELECT ` + holdColumns + `
+		FROM holds h
+		JOIN wallets w ON w.id = h.wallet_id
+		WHERE w.user_id = $1
+		ORDER BY h.created_at DESC, h.id DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []*models.Hold
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+// GetHeldAmount возвращает сумму действующих блокировок кошелька.
+func (r *repo) GetHeldAmount(ctx context.Context, walletID uint64) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM holds
+		WHERE wallet_id = $1 AND status = $2 AND expires_at > NOW()`
+	var held float64
+	err := r.db.QueryRowContext(ctx, query, walletID, models.HoldStatusActive).Scan(&held)
+	return held, err
+}
+
+// GetMemberHeldAmount возвращает сумму действующих блокировок кошелька, созданных пользователем userID.
+func (r *repo) GetMemberHeldAmount(ctx context.Context, walletID, userID uint64) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM holds
+		WHERE wallet_id = $1 AND initiated_by = $2 AND status = $3 AND expires_at > NOW()`
+	var held float64
+	if err := r.db.QueryRowContext(ctx, query, walletID, userID, models.HoldStatusActive).Scan(&held); err != nil {
+		r.logger.Error("Error summing member holds:", err)
+		return 0, err
+	}
+	return held, nil
+}
+
+// GetHeldAmountsByUserID возвращает суммы действующих блокировок пользователя по валютам.
+func (r *repo) GetHeldAmountsByUserID(ctx context.Context, userID uint64) (map[string]float64, error) {
+	query := `
+		SELECT w.currency, COALESCE(SUM(h.amount), 0)
+		FROM holds h
+		JOIN wallets w ON w.id = h.wallet_id
+		WHERE w.user_id = $1 AND h.status = $2 AND h.expires_at > NOW()
+		GROUP BY w.currency`
+	rows, err := r.db.QueryContext(ctx, query, userID, models.HoldStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	held := make(map[string]float64)
+	for rows.Next() {
+		var currency string
+		var amount float64
+		if err := rows.Scan(&currency, &amount); err != nil {
+			return nil, err
+		}
+		held[currency] = amount
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return held, nil
+}
+
+// UpdateHold сохраняет статус и списанную сумму блокировки.
+func (r *repo) UpdateHold(ctx context.Context, hold *models.Hold) error {
+	query := `
+		UPDATE holds
+		SET status = $1, captured_amount = $2, updated_at = NOW()
+		WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, hold.Status, hold.CapturedAmount, hold.ID)
+	if err != nil {
+		r.logger.Error("Error updating hold:", err)
+		return err
+	}
+	return nil
+}
+
+// ExpireHolds переводит просроченные действующие блокировки вида user в статус expired.
+// Блокировки выводов и лимитных заявок снимаются вместе со своими операциями.
+func (r *repo) ExpireHolds(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE holds
+		SET status = $1, updated_at = NOW()
+		WHERE status = $2 AND expires_at <= $3 AND kind = $4`
+	res, err := r.db.ExecContext(ctx, query, models.HoldStatusExpired, models.HoldStatusActive, now, models.HoldKindUser)
+	if err != nil {
+		r.logger.Error("Error expiring holds:", err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
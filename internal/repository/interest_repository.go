@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// GetWalletsDueForInterest возвращает до limit кошельков в валютах с положительной ставкой,
+// по которым ещё нет начисления за дату day, вместе с датой последнего начисления.
+func (r *repo) GetWalletsDueForInterest(ctx context.Context, day time.Time, limit int) ([]*models.InterestDue, error) {
+	query := `
+		SELECT w.id, w.currency, c.interest_rate, MAX(a.accrual_date)
+		FROM wallets w
+		JOIN currencies c ON c.tenant_id = w.tenant_id AND c.code = w.currency
+		LEFT JOIN interest_accruals a ON a.wallet_id = w.id
+		WHERE c.interest_rate > 0
+		GROUP BY w.id, w.currency, c.interest_rate
+		HAVING MAX(a.accrual_date) IS NULL OR MAX(a.accrual_date) < $1
+		ORDER BY w.id
+		LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, day, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []*models.InterestDue
+	for rows.Next() {
+		item := &models.InterestDue{}
+		if err := rows.Scan(&item.WalletID, &item.Currency, &item.AnnualRate, &item.LastAccrualOn); err != nil {
+			return nil, err
+		}
+		due = append(due, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return due, nil
+}
+
+// CreateInterestAccrual сохраняет начисление за сутки. Повторное начисление за ту же дату
+// игнорируется; возвращает false, если запись уже существовала.
+func (r *repo) CreateInterestAccrual(ctx context.Context, accrual *models.InterestAccrual) (bool, error) {
+	query := `
+		INSERT INTO interest_accruals (wallet_id, accrual_date, balance, annual_rate, amount)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (wallet_id, accrual_date) DO NOTHING`
+	result, err := r.db.ExecContext(ctx, query,
+		accrual.WalletID,
+		accrual.AccrualDate,
+		accrual.Balance,
+		accrual.AnnualRate,
+		accrual.Amount,
+	)
+	if err != nil {
+		r.logger.Error("Error inserting interest accrual:", err)
+		return false, err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted > 0, nil
+}
+
+// GetWalletsWithUnpaidInterest возвращает до limit ID кошельков с невыплаченными
+// начислениями за даты раньше before.
+func (r *repo) GetWalletsWithUnpaidInterest(ctx context.Context, before time.Time, limit int) ([]uint64, error) {
+	query := `
+		SELECT DISTINCT wallet_id
+		FROM interest_accruals
+		WHERE paid_at IS NULL AND accrual_date < $1
+		ORDER BY wallet_id
+		LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var walletIDs []uint64
+	for rows.Next() {
+		var walletID uint64
+		if err := rows.Scan(&walletID); err != nil {
+			return nil, err
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return walletIDs, nil
+}
+
+// PayInterestAccruals отмечает невыплаченные начисления кошелька за даты раньше before
+// выплаченными операцией operationID и возвращает их сумму.
+func (r *repo) PayInterestAccruals(ctx context.Context, walletID uint64, before time.Time, operationID string) (float64, error) {
+	query := `
+		WITH paid AS (
+			UPDATE interest_accruals
+			SET paid_at = NOW(), operation_id = $3
+			WHERE wallet_id = $1 AND accrual_date < $2 AND paid_at IS NULL
+			RETURNING amount
+		)
+		SELECT COALESCE(SUM(amount), 0) FROM paid`
+	var total float64
+	if err := r.db.QueryRowContext(ctx, query, walletID, before, operationID).Scan(&total); err != nil {
+		r.logger.Error("Error paying interest accruals:", err)
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetUnpaidInterestByUserID возвращает по каждому кошельку пользователя текущую ставку
+// и сумму начисленных, но не выплаченных процентов.
+func (r *repo) GetUnpaidInterestByUserID(ctx context.Context, userID uint64) ([]*models.InterestSummary, error) {
+	query := `
+		SELECT w.id, w.currency, COALESCE(c.interest_rate, 0),
+			COALESCE(SUM(a.amount), 0), MIN(a.accrual_date), MAX(a.accrual_date)
+		FROM wallets w
+		LEFT JOIN currencies c ON c.tenant_id = w.tenant_id AND c.code = w.currency
+		LEFT JOIN interest_accruals a ON a.wallet_id = w.id AND a.paid_at IS NULL
+		WHERE w.user_id = $1
+		GROUP BY w.id, w.currency, c.interest_rate
+		ORDER BY w.currency`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*models.InterestSummary
+	for rows.Next() {
+		summary := &models.InterestSummary{}
+		if err := rows.Scan(
+			&summary.WalletID,
+			&summary.Currency,
+			&summary.AnnualRate,
+			&summary.Accrued,
+			&summary.From,
+			&summary.To,
+		); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
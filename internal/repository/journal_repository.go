@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// GetJournalHeads возвращает ID последней проводки, созданной до before, и хеши последних
+// проводок каждого кошелька с ID не больше него.
+func (r *repo) GetJournalHeads(ctx context.Context, before time.Time) (uint64, map[uint64]string, error) {
+	var lastID uint64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(id), 0) FROM transactions WHERE created_at < $1`, before,
+	).Scan(&lastID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query := `
+		SELECT DISTINCT ON (wallet_id) wallet_id, hash
+		FROM transactions
+		WHERE id <= $1
+		ORDER BY wallet_id, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, lastID)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	heads := make(map[uint64]string)
+	for rows.Next() {
+		var walletID uint64
+		var hash string
+		if err := rows.Scan(&walletID, &hash); err != nil {
+			return 0, nil, err
+		}
+		heads[walletID] = hash
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	return lastID, heads, nil
+}
+
+// CreateJournalCheckpoint сохраняет контрольную точку журнала.
+func (r *repo) CreateJournalCheckpoint(ctx context.Context, checkpoint *models.JournalCheckpoint) error {
+	query := `
+		INSERT INTO journal_checkpoints (last_transaction_id, wallets, digest)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		checkpoint.LastTransactionID,
+		checkpoint.Wallets,
+		checkpoint.Digest,
+	).Scan(&checkpoint.ID, &checkpoint.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting journal checkpoint:", err)
+		return err
+	}
+	return nil
+}
+
+// GetJournalCheckpoints получает все контрольные точки в порядке возрастания LastTransactionID.
+func (r *repo) GetJournalCheckpoints(ctx context.Context) ([]*models.JournalCheckpoint, error) {
+	query := `
+		SELECT id, last_transaction_id, wallets, digest, created_at
+		FROM journal_checkpoints
+		ORDER BY last_transaction_id, id`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checkpoints []*models.JournalCheckpoint
+	for rows.Next() {
+		checkpoint := &models.JournalCheckpoint{}
+		if err := rows.Scan(
+			&checkpoint.ID,
+			&checkpoint.LastTransactionID,
+			&checkpoint.Wallets,
+			&checkpoint.Digest,
+			&checkpoint.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return checkpoints, nil
+}
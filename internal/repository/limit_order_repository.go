@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const limitOrderColumns = `
+	id, user_id, from_currency, to_currency, amount, target_rate, hold_id, status,
+	filled_rate, filled_amount, operation_id, expires_at, filled_at, created_at, updated_at`
+
+func scanLimitOrder(row interface{ Scan(dest ...any) error }) (*models.LimitOrder, error) {
+	order := &models.LimitOrder{}
+	err := row.Scan(
+		&order.ID,
+		&order.UserID,
+		&order.FromCurrency,
+		&order.ToCurrency,
+		&order.Amount,
+		&order.TargetRate,
+		&order.HoldID,
+		&order.Status,
+		&order.FilledRate,
+		&order.FilledAmount,
+		&order.OperationID,
+		&order.ExpiresAt,
+		&order.FilledAt,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	return order, err
+}
+
+func scanLimitOrders(rows *sql.Rows) ([]*models.LimitOrder, error) {
+	defer rows.Close()
+
+	var orders []*models.LimitOrder
+	for rows.Next() {
+		order, err := scanLimitOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// CreateLimitOrder сохраняет новую лимитную заявку.
+func (r *repo) CreateLimitOrder(ctx context.Context, order *models.LimitOrder) (uint64, error) {
+	query := `
+		INSERT INTO limit_orders (user_id, from_currency, to_currency, amount, target_rate, hold_id, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+	var orderID uint64
+	err := r.db.QueryRowContext(ctx, query,
+		order.UserID,
+		order.FromCurrency,
+		order.ToCurrency,
+		order.Amount,
+		order.TargetRate,
+		order.HoldID,
+		order.Status,
+		order.ExpiresAt,
+	).Scan(&orderID)
+	if err != nil {
+		r.logger.Error("Error inserting limit order:", err)
+		return 0, err
+	}
+	return orderID, nil
+}
+
+// GetLimitOrderByIDForUpdate получает лимитную заявку по ID и блокирует строку до конца транзакции.
+// Возвращает nil, если заявка не найдена.
+func (r *repo) GetLimitOrderByIDForUpdate(ctx context.Context, orderID uint64) (*models.LimitOrder, error) {
+	query := `SELECT ` + limitOrderColumns + ` FROM limit_orders
+		WHERE id = $1 AND ($2 = 0 OR user_id IN (SELECT id FROM users WHERE tenant_id = $2))
+		FOR UPDATE`
+	order, err := scanLimitOrder(r.db.QueryRowContext(ctx, query, orderID, tenant.Filter(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching limit order:", err)
+		return nil, err
+	}
+	return order, nil
+}
+
+// GetLimitOrdersByUserID получает все лимитные заявки пользователя, начиная с последних.
+func (r *repo) GetLimitOrdersByUserID(ctx context.Context, userID uint64) ([]*models.LimitOrder, error) {
+	query := `SELECT ` + limitOrderColumns + ` FROM limit_orders WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanLimitOrders(rows)
+}
+
+// GetOpenLimitOrders получает до limit открытых заявок, срок действия которых не истёк, начиная с самых старых.
+func (r *repo) GetOpenLimitOrders(ctx context.Context, now time.Time, limit int) ([]*models.LimitOrder, error) {
+	query := `SELECT ` + limitOrderColumns + `
+		FROM limit_orders
+		WHERE status = $1 AND expires_at > $2
+		ORDER BY created_at, id
+		LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, query, models.LimitOrderStatusOpen, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLimitOrders(rows)
+}
+
+// UpdateLimitOrder сохраняет статус и результат исполнения лимитной заявки.
+func (r *repo) UpdateLimitOrder(ctx context.Context, order *models.LimitOrder) error {
+	query := `
+		UPDATE limit_orders
+		SET status = $1, filled_rate = $2, filled_amount = $3, operation_id = $4, filled_at = $5, updated_at = NOW()
+		WHERE id = $6`
+	_, err := r.db.ExecContext(ctx, query,
+		order.Status,
+		order.FilledRate,
+		order.FilledAmount,
+		order.OperationID,
+		order.FilledAt,
+		order.ID,
+	)
+	if err != nil {
+		r.logger.Error("Error updating limit order:", err)
+		return err
+	}
+	return nil
+}
+
+// ExpireLimitOrders переводит просроченные открытые заявки в статус expired
+// и освобождает их блокировки средств.
+func (r *repo) ExpireLimitOrders(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		WITH expired AS (
+			UPDATE limit_orders
+			SET status = $1, updated_at = NOW()
+			WHERE status = $2 AND expires_at <= $3
+			RETURNING hold_id
+		), released AS (
+			UPDATE holds
+			SET status = $4, updated_at = NOW()
+			WHERE id IN (SELECT hold_id FROM expired) AND status = $5
+		)
+		SELECT COUNT(*) FROM expired`
+	var expired int64
+	err := r.db.QueryRowContext(ctx, query,
+		models.LimitOrderStatusExpired,
+		models.LimitOrderStatusOpen,
+		now,
+		models.HoldStatusExpired,
+		models.HoldStatusActive,
+	).Scan(&expired)
+	if err != nil {
+		r.logger.Error("Error expiring limit orders:", err)
+		return 0, err
+	}
+	return expired, nil
+}
@@ -6,9 +6,12 @@ package mocks
 
 import (
 	context "context"
+	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	repository "github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,164 +38,2376 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
-// CreateUser mocks base method.
-func (m *MockRepository) CreateUser(user *models.User) (int64, error) {
+// ActivateWalletMember mocks base method.
+func (m *MockRepository) ActivateWalletMember(ctx context.Context, walletID, userID uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", user)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "ActivateWalletMember", ctx, walletID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ActivateWalletMember indicates an expected call of ActivateWalletMember.
+func (mr *MockRepositoryMockRecorder) ActivateWalletMember(ctx, walletID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateWalletMember", reflect.TypeOf((*MockRepository)(nil).ActivateWalletMember), ctx, walletID, userID)
+}
+
+// ClaimDueNotificationEmails mocks base method.
+func (m *MockRepository) ClaimDueNotificationEmails(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueNotificationEmails", ctx, now, limit, lease)
+	ret0, _ := ret[0].([]*models.Notification)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockRepositoryMockRecorder) CreateUser(user interface{}) *gomock.Call {
+// ClaimDueNotificationEmails indicates an expected call of ClaimDueNotificationEmails.
+func (mr *MockRepositoryMockRecorder) ClaimDueNotificationEmails(ctx, now, limit, lease interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueNotificationEmails", reflect.TypeOf((*MockRepository)(nil).ClaimDueNotificationEmails), ctx, now, limit, lease)
 }
 
-// CreateWallet mocks base method.
-func (m *MockRepository) CreateWallet(wallet *models.Wallet) (int, error) {
+// ClaimDueScheduledTransfers mocks base method.
+func (m *MockRepository) ClaimDueScheduledTransfers(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.ScheduledTransfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWallet", wallet)
+	ret := m.ctrl.Call(m, "ClaimDueScheduledTransfers", ctx, now, limit, lease)
+	ret0, _ := ret[0].([]*models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueScheduledTransfers indicates an expected call of ClaimDueScheduledTransfers.
+func (mr *MockRepositoryMockRecorder) ClaimDueScheduledTransfers(ctx, now, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueScheduledTransfers", reflect.TypeOf((*MockRepository)(nil).ClaimDueScheduledTransfers), ctx, now, limit, lease)
+}
+
+// ClaimDueWebhookDeliveries mocks base method.
+func (m *MockRepository) ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueWebhookDeliveries", ctx, now, limit, lease)
+	ret0, _ := ret[0].([]*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueWebhookDeliveries indicates an expected call of ClaimDueWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) ClaimDueWebhookDeliveries(ctx, now, limit, lease interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).ClaimDueWebhookDeliveries), ctx, now, limit, lease)
+}
+
+// CompleteScheduledTransferRun mocks base method.
+func (m *MockRepository) CompleteScheduledTransferRun(ctx context.Context, schedule *models.ScheduledTransfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteScheduledTransferRun", ctx, schedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteScheduledTransferRun indicates an expected call of CompleteScheduledTransferRun.
+func (mr *MockRepositoryMockRecorder) CompleteScheduledTransferRun(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteScheduledTransferRun", reflect.TypeOf((*MockRepository)(nil).CompleteScheduledTransferRun), ctx, schedule)
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockRepository) CountUnreadNotifications(ctx context.Context, userID uint64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", ctx, userID)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateWallet indicates an expected call of CreateWallet.
-func (mr *MockRepositoryMockRecorder) CreateWallet(wallet interface{}) *gomock.Call {
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockRepositoryMockRecorder) CountUnreadNotifications(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockRepository)(nil).CreateWallet), wallet)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockRepository)(nil).CountUnreadNotifications), ctx, userID)
 }
 
-// DeleteRefreshTokenModel mocks base method.
-func (m *MockRepository) DeleteRefreshTokenModel(ctx context.Context, refreshToken *models.RefreshToken) error {
+// CreateCurrency mocks base method.
+func (m *MockRepository) CreateCurrency(ctx context.Context, currency *models.Currency) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteRefreshTokenModel", ctx, refreshToken)
+	ret := m.ctrl.Call(m, "CreateCurrency", ctx, currency)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteRefreshTokenModel indicates an expected call of DeleteRefreshTokenModel.
-func (mr *MockRepositoryMockRecorder) DeleteRefreshTokenModel(ctx, refreshToken interface{}) *gomock.Call {
+// CreateCurrency indicates an expected call of CreateCurrency.
+func (mr *MockRepositoryMockRecorder) CreateCurrency(ctx, currency interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshTokenModel", reflect.TypeOf((*MockRepository)(nil).DeleteRefreshTokenModel), ctx, refreshToken)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCurrency", reflect.TypeOf((*MockRepository)(nil).CreateCurrency), ctx, currency)
 }
 
-// GetRefreshTokenModelByID mocks base method.
-func (m *MockRepository) GetRefreshTokenModelByID(ctx context.Context, userID uint64, deviceID string) (*models.RefreshToken, error) {
+// CreateFreezeEvent mocks base method.
+func (m *MockRepository) CreateFreezeEvent(ctx context.Context, event *models.FreezeEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRefreshTokenModelByID", ctx, userID, deviceID)
-	ret0, _ := ret[0].(*models.RefreshToken)
+	ret := m.ctrl.Call(m, "CreateFreezeEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFreezeEvent indicates an expected call of CreateFreezeEvent.
+func (mr *MockRepositoryMockRecorder) CreateFreezeEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFreezeEvent", reflect.TypeOf((*MockRepository)(nil).CreateFreezeEvent), ctx, event)
+}
+
+// CreateHold mocks base method.
+func (m *MockRepository) CreateHold(ctx context.Context, hold *models.Hold) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, hold)
+	ret0, _ := ret[0].(uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRefreshTokenModelByID indicates an expected call of GetRefreshTokenModelByID.
-func (mr *MockRepositoryMockRecorder) GetRefreshTokenModelByID(ctx, userID, deviceID interface{}) *gomock.Call {
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockRepositoryMockRecorder) CreateHold(ctx, hold interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenModelByID", reflect.TypeOf((*MockRepository)(nil).GetRefreshTokenModelByID), ctx, userID, deviceID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockRepository)(nil).CreateHold), ctx, hold)
 }
 
-// GetUserByID mocks base method.
-func (m *MockRepository) GetUserByID(userID uint64) (*models.User, error) {
+// CreateInterestAccrual mocks base method.
+func (m *MockRepository) CreateInterestAccrual(ctx context.Context, accrual *models.InterestAccrual) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", userID)
-	ret0, _ := ret[0].(*models.User)
+	ret := m.ctrl.Call(m, "CreateInterestAccrual", ctx, accrual)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByID indicates an expected call of GetUserByID.
-func (mr *MockRepositoryMockRecorder) GetUserByID(userID interface{}) *gomock.Call {
+// CreateInterestAccrual indicates an expected call of CreateInterestAccrual.
+func (mr *MockRepositoryMockRecorder) CreateInterestAccrual(ctx, accrual interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockRepository)(nil).GetUserByID), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestAccrual", reflect.TypeOf((*MockRepository)(nil).CreateInterestAccrual), ctx, accrual)
 }
 
-// GetUserByUsername mocks base method.
-func (m *MockRepository) GetUserByUsername(username string) (*models.User, error) {
+// CreateJournalCheckpoint mocks base method.
+func (m *MockRepository) CreateJournalCheckpoint(ctx context.Context, checkpoint *models.JournalCheckpoint) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUsername", username)
-	ret0, _ := ret[0].(*models.User)
+	ret := m.ctrl.Call(m, "CreateJournalCheckpoint", ctx, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJournalCheckpoint indicates an expected call of CreateJournalCheckpoint.
+func (mr *MockRepositoryMockRecorder) CreateJournalCheckpoint(ctx, checkpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJournalCheckpoint", reflect.TypeOf((*MockRepository)(nil).CreateJournalCheckpoint), ctx, checkpoint)
+}
+
+// CreateLimitOrder mocks base method.
+func (m *MockRepository) CreateLimitOrder(ctx context.Context, order *models.LimitOrder) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLimitOrder", ctx, order)
+	ret0, _ := ret[0].(uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByUsername indicates an expected call of GetUserByUsername.
-func (mr *MockRepositoryMockRecorder) GetUserByUsername(username interface{}) *gomock.Call {
+// CreateLimitOrder indicates an expected call of CreateLimitOrder.
+func (mr *MockRepositoryMockRecorder) CreateLimitOrder(ctx, order interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockRepository)(nil).GetUserByUsername), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLimitOrder", reflect.TypeOf((*MockRepository)(nil).CreateLimitOrder), ctx, order)
 }
 
-// GetWalletByID mocks base method.
-func (m *MockRepository) GetWalletByID(walletID uint64) (*models.Wallet, error) {
+// CreateNotification mocks base method.
+func (m *MockRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWalletByID", walletID)
-	ret0, _ := ret[0].(*models.Wallet)
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockRepositoryMockRecorder) CreateNotification(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockRepository)(nil).CreateNotification), ctx, notification)
+}
+
+// CreateOutboxEvent mocks base method.
+func (m *MockRepository) CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockRepositoryMockRecorder) CreateOutboxEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockRepository)(nil).CreateOutboxEvent), ctx, event)
+}
+
+// CreatePaymentRequest mocks base method.
+func (m *MockRepository) CreatePaymentRequest(ctx context.Context, request *models.PaymentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentRequest", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePaymentRequest indicates an expected call of CreatePaymentRequest.
+func (mr *MockRepositoryMockRecorder) CreatePaymentRequest(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentRequest", reflect.TypeOf((*MockRepository)(nil).CreatePaymentRequest), ctx, request)
+}
+
+// CreatePot mocks base method.
+func (m *MockRepository) CreatePot(ctx context.Context, pot *models.Pot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePot", ctx, pot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePot indicates an expected call of CreatePot.
+func (mr *MockRepositoryMockRecorder) CreatePot(ctx, pot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePot", reflect.TypeOf((*MockRepository)(nil).CreatePot), ctx, pot)
+}
+
+// CreatePotMovement mocks base method.
+func (m *MockRepository) CreatePotMovement(ctx context.Context, movement *models.PotMovement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePotMovement", ctx, movement)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePotMovement indicates an expected call of CreatePotMovement.
+func (mr *MockRepositoryMockRecorder) CreatePotMovement(ctx, movement interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePotMovement", reflect.TypeOf((*MockRepository)(nil).CreatePotMovement), ctx, movement)
+}
+
+// CreateProviderDeposit mocks base method.
+func (m *MockRepository) CreateProviderDeposit(ctx context.Context, deposit *models.ProviderDeposit) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProviderDeposit", ctx, deposit)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetWalletByID indicates an expected call of GetWalletByID.
-func (mr *MockRepositoryMockRecorder) GetWalletByID(walletID interface{}) *gomock.Call {
+// CreateProviderDeposit indicates an expected call of CreateProviderDeposit.
+func (mr *MockRepositoryMockRecorder) CreateProviderDeposit(ctx, deposit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByID", reflect.TypeOf((*MockRepository)(nil).GetWalletByID), walletID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProviderDeposit", reflect.TypeOf((*MockRepository)(nil).CreateProviderDeposit), ctx, deposit)
 }
 
-// GetWalletByUserAndCurrency mocks base method.
-func (m *MockRepository) GetWalletByUserAndCurrency(userID uint64, currency string) (*models.Wallet, error) {
+// CreateRebalancePlan mocks base method.
+func (m *MockRepository) CreateRebalancePlan(ctx context.Context, plan *models.RebalancePlan) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWalletByUserAndCurrency", userID, currency)
-	ret0, _ := ret[0].(*models.Wallet)
+	ret := m.ctrl.Call(m, "CreateRebalancePlan", ctx, plan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRebalancePlan indicates an expected call of CreateRebalancePlan.
+func (mr *MockRepositoryMockRecorder) CreateRebalancePlan(ctx, plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRebalancePlan", reflect.TypeOf((*MockRepository)(nil).CreateRebalancePlan), ctx, plan)
+}
+
+// CreateReconciliationRun mocks base method.
+func (m *MockRepository) CreateReconciliationRun(ctx context.Context, date time.Time) (*models.ReconciliationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReconciliationRun", ctx, date)
+	ret0, _ := ret[0].(*models.ReconciliationRun)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetWalletByUserAndCurrency indicates an expected call of GetWalletByUserAndCurrency.
-func (mr *MockRepositoryMockRecorder) GetWalletByUserAndCurrency(userID, currency interface{}) *gomock.Call {
+// CreateReconciliationRun indicates an expected call of CreateReconciliationRun.
+func (mr *MockRepositoryMockRecorder) CreateReconciliationRun(ctx, date interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByUserAndCurrency", reflect.TypeOf((*MockRepository)(nil).GetWalletByUserAndCurrency), userID, currency)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReconciliationRun", reflect.TypeOf((*MockRepository)(nil).CreateReconciliationRun), ctx, date)
 }
 
-// GetWalletsByUserID mocks base method.
-func (m *MockRepository) GetWalletsByUserID(userID uint64) ([]*models.Wallet, error) {
+// CreateReversal mocks base method.
+func (m *MockRepository) CreateReversal(ctx context.Context, reversal *models.Reversal) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWalletsByUserID", userID)
-	ret0, _ := ret[0].([]*models.Wallet)
+	ret := m.ctrl.Call(m, "CreateReversal", ctx, reversal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateReversal indicates an expected call of CreateReversal.
+func (mr *MockRepositoryMockRecorder) CreateReversal(ctx, reversal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReversal", reflect.TypeOf((*MockRepository)(nil).CreateReversal), ctx, reversal)
+}
+
+// CreateScheduleExecution mocks base method.
+func (m *MockRepository) CreateScheduleExecution(ctx context.Context, execution *models.ScheduleExecution) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduleExecution", ctx, execution)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetWalletsByUserID indicates an expected call of GetWalletsByUserID.
-func (mr *MockRepositoryMockRecorder) GetWalletsByUserID(userID interface{}) *gomock.Call {
+// CreateScheduleExecution indicates an expected call of CreateScheduleExecution.
+func (mr *MockRepositoryMockRecorder) CreateScheduleExecution(ctx, execution interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletsByUserID", reflect.TypeOf((*MockRepository)(nil).GetWalletsByUserID), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduleExecution", reflect.TypeOf((*MockRepository)(nil).CreateScheduleExecution), ctx, execution)
 }
 
-// SetRefreshTokenModel mocks base method.
-func (m *MockRepository) SetRefreshTokenModel(ctx context.Context, refreshToken *models.RefreshToken) error {
+// CreateScheduledTransfer mocks base method.
+func (m *MockRepository) CreateScheduledTransfer(ctx context.Context, schedule *models.ScheduledTransfer) (uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetRefreshTokenModel", ctx, refreshToken)
+	ret := m.ctrl.Call(m, "CreateScheduledTransfer", ctx, schedule)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransfer indicates an expected call of CreateScheduledTransfer.
+func (mr *MockRepositoryMockRecorder) CreateScheduledTransfer(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransfer", reflect.TypeOf((*MockRepository)(nil).CreateScheduledTransfer), ctx, schedule)
+}
+
+// CreateTransaction mocks base method.
+func (m *MockRepository) CreateTransaction(ctx context.Context, transaction *models.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransaction", ctx, transaction)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetRefreshTokenModel indicates an expected call of SetRefreshTokenModel.
-func (mr *MockRepositoryMockRecorder) SetRefreshTokenModel(ctx, refreshToken interface{}) *gomock.Call {
+// CreateTransaction indicates an expected call of CreateTransaction.
+func (mr *MockRepositoryMockRecorder) CreateTransaction(ctx, transaction interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRefreshTokenModel", reflect.TypeOf((*MockRepository)(nil).SetRefreshTokenModel), ctx, refreshToken)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockRepository)(nil).CreateTransaction), ctx, transaction)
 }
 
-// UpdateWalletBalance mocks base method.
-func (m *MockRepository) UpdateWalletBalance(walletID uint64, balance float64) error {
+// CreateUser mocks base method.
+func (m *MockRepository) CreateUser(user *models.User) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateWalletBalance", walletID, balance)
+	ret := m.ctrl.Call(m, "CreateUser", user)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockRepositoryMockRecorder) CreateUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), user)
+}
+
+// CreateVoucherBatch mocks base method.
+func (m *MockRepository) CreateVoucherBatch(ctx context.Context, batch *models.VoucherBatch) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVoucherBatch", ctx, batch)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVoucherBatch indicates an expected call of CreateVoucherBatch.
+func (mr *MockRepositoryMockRecorder) CreateVoucherBatch(ctx, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVoucherBatch", reflect.TypeOf((*MockRepository)(nil).CreateVoucherBatch), ctx, batch)
+}
+
+// CreateVoucherRedemption mocks base method.
+func (m *MockRepository) CreateVoucherRedemption(ctx context.Context, redemption *models.VoucherRedemption) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVoucherRedemption", ctx, redemption)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateVoucherRedemption indicates an expected call of CreateVoucherRedemption.
+func (mr *MockRepositoryMockRecorder) CreateVoucherRedemption(ctx, redemption interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVoucherRedemption", reflect.TypeOf((*MockRepository)(nil).CreateVoucherRedemption), ctx, redemption)
+}
+
+// CreateWallet mocks base method.
+func (m *MockRepository) CreateWallet(wallet *models.Wallet) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", wallet)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockRepositoryMockRecorder) CreateWallet(wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockRepository)(nil).CreateWallet), wallet)
+}
+
+// CreateWalletMember mocks base method.
+func (m *MockRepository) CreateWalletMember(ctx context.Context, member *models.WalletMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWalletMember", ctx, member)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateWalletBalance indicates an expected call of UpdateWalletBalance.
-func (mr *MockRepositoryMockRecorder) UpdateWalletBalance(walletID, balance interface{}) *gomock.Call {
+// CreateWalletMember indicates an expected call of CreateWalletMember.
+func (mr *MockRepositoryMockRecorder) CreateWalletMember(ctx, member interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletBalance", reflect.TypeOf((*MockRepository)(nil).UpdateWalletBalance), walletID, balance)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWalletMember", reflect.TypeOf((*MockRepository)(nil).CreateWalletMember), ctx, member)
+}
+
+// CreateWebhookAttempt mocks base method.
+func (m *MockRepository) CreateWebhookAttempt(ctx context.Context, attempt *models.WebhookAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookAttempt", ctx, attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookAttempt indicates an expected call of CreateWebhookAttempt.
+func (mr *MockRepositoryMockRecorder) CreateWebhookAttempt(ctx, attempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookAttempt", reflect.TypeOf((*MockRepository)(nil).CreateWebhookAttempt), ctx, attempt)
+}
+
+// CreateWebhookDeliveries mocks base method.
+func (m *MockRepository) CreateWebhookDeliveries(ctx context.Context, userID uint64, event *models.OutboxEvent, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDeliveries", ctx, userID, event, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDeliveries indicates an expected call of CreateWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) CreateWebhookDeliveries(ctx, userID, event, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).CreateWebhookDeliveries), ctx, userID, event, payload)
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockRepository) CreateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockRepositoryMockRecorder) CreateWebhookSubscription(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockRepository)(nil).CreateWebhookSubscription), ctx, subscription)
+}
+
+// CreateWithdrawal mocks base method.
+func (m *MockRepository) CreateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithdrawal", ctx, withdrawal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWithdrawal indicates an expected call of CreateWithdrawal.
+func (mr *MockRepositoryMockRecorder) CreateWithdrawal(ctx, withdrawal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdrawal", reflect.TypeOf((*MockRepository)(nil).CreateWithdrawal), ctx, withdrawal)
+}
+
+// CreateWithdrawalEvent mocks base method.
+func (m *MockRepository) CreateWithdrawalEvent(ctx context.Context, event *models.WithdrawalEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithdrawalEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWithdrawalEvent indicates an expected call of CreateWithdrawalEvent.
+func (mr *MockRepositoryMockRecorder) CreateWithdrawalEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdrawalEvent", reflect.TypeOf((*MockRepository)(nil).CreateWithdrawalEvent), ctx, event)
+}
+
+// DeleteCreditLine mocks base method.
+func (m *MockRepository) DeleteCreditLine(ctx context.Context, walletID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCreditLine", ctx, walletID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCreditLine indicates an expected call of DeleteCreditLine.
+func (mr *MockRepositoryMockRecorder) DeleteCreditLine(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCreditLine", reflect.TypeOf((*MockRepository)(nil).DeleteCreditLine), ctx, walletID)
+}
+
+// DeleteRefreshTokenModel mocks base method.
+func (m *MockRepository) DeleteRefreshTokenModel(ctx context.Context, refreshToken *models.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRefreshTokenModel", ctx, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRefreshTokenModel indicates an expected call of DeleteRefreshTokenModel.
+func (mr *MockRepositoryMockRecorder) DeleteRefreshTokenModel(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshTokenModel", reflect.TypeOf((*MockRepository)(nil).DeleteRefreshTokenModel), ctx, refreshToken)
+}
+
+// DeleteWalletMember mocks base method.
+func (m *MockRepository) DeleteWalletMember(ctx context.Context, walletID, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWalletMember", ctx, walletID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWalletMember indicates an expected call of DeleteWalletMember.
+func (mr *MockRepositoryMockRecorder) DeleteWalletMember(ctx, walletID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWalletMember", reflect.TypeOf((*MockRepository)(nil).DeleteWalletMember), ctx, walletID, userID)
+}
+
+// DeleteWebhookSubscription mocks base method.
+func (m *MockRepository) DeleteWebhookSubscription(ctx context.Context, subscriptionID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockRepositoryMockRecorder) DeleteWebhookSubscription(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockRepository)(nil).DeleteWebhookSubscription), ctx, subscriptionID)
+}
+
+// ExpireHolds mocks base method.
+func (m *MockRepository) ExpireHolds(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds.
+func (mr *MockRepositoryMockRecorder) ExpireHolds(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockRepository)(nil).ExpireHolds), ctx, now)
+}
+
+// ExpireLimitOrders mocks base method.
+func (m *MockRepository) ExpireLimitOrders(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireLimitOrders", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireLimitOrders indicates an expected call of ExpireLimitOrders.
+func (mr *MockRepositoryMockRecorder) ExpireLimitOrders(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireLimitOrders", reflect.TypeOf((*MockRepository)(nil).ExpireLimitOrders), ctx, now)
+}
+
+// ExpirePaymentRequests mocks base method.
+func (m *MockRepository) ExpirePaymentRequests(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePaymentRequests", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePaymentRequests indicates an expected call of ExpirePaymentRequests.
+func (mr *MockRepositoryMockRecorder) ExpirePaymentRequests(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePaymentRequests", reflect.TypeOf((*MockRepository)(nil).ExpirePaymentRequests), ctx, now)
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockRepository) GetBalanceAt(ctx context.Context, walletID uint64, at time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", ctx, walletID, at)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockRepositoryMockRecorder) GetBalanceAt(ctx, walletID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockRepository)(nil).GetBalanceAt), ctx, walletID, at)
+}
+
+// GetBalanceSnapshots mocks base method.
+func (m *MockRepository) GetBalanceSnapshots(ctx context.Context, runID uint64, onlyMismatches bool) ([]*models.BalanceSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceSnapshots", ctx, runID, onlyMismatches)
+	ret0, _ := ret[0].([]*models.BalanceSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceSnapshots indicates an expected call of GetBalanceSnapshots.
+func (mr *MockRepositoryMockRecorder) GetBalanceSnapshots(ctx, runID, onlyMismatches interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceSnapshots", reflect.TypeOf((*MockRepository)(nil).GetBalanceSnapshots), ctx, runID, onlyMismatches)
+}
+
+// GetCreditLimitsByUserID mocks base method.
+func (m *MockRepository) GetCreditLimitsByUserID(ctx context.Context, userID uint64) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCreditLimitsByUserID", ctx, userID)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCreditLimitsByUserID indicates an expected call of GetCreditLimitsByUserID.
+func (mr *MockRepositoryMockRecorder) GetCreditLimitsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCreditLimitsByUserID", reflect.TypeOf((*MockRepository)(nil).GetCreditLimitsByUserID), ctx, userID)
+}
+
+// GetCreditLine mocks base method.
+func (m *MockRepository) GetCreditLine(ctx context.Context, walletID uint64) (*models.CreditLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCreditLine", ctx, walletID)
+	ret0, _ := ret[0].(*models.CreditLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCreditLine indicates an expected call of GetCreditLine.
+func (mr *MockRepositoryMockRecorder) GetCreditLine(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCreditLine", reflect.TypeOf((*MockRepository)(nil).GetCreditLine), ctx, walletID)
+}
+
+// GetCreditLineForUpdate mocks base method.
+func (m *MockRepository) GetCreditLineForUpdate(ctx context.Context, walletID uint64) (*models.CreditLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCreditLineForUpdate", ctx, walletID)
+	ret0, _ := ret[0].(*models.CreditLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCreditLineForUpdate indicates an expected call of GetCreditLineForUpdate.
+func (mr *MockRepositoryMockRecorder) GetCreditLineForUpdate(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCreditLineForUpdate", reflect.TypeOf((*MockRepository)(nil).GetCreditLineForUpdate), ctx, walletID)
+}
+
+// GetCreditLinesDueForAccrual mocks base method.
+func (m *MockRepository) GetCreditLinesDueForAccrual(ctx context.Context, today time.Time, limit int) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCreditLinesDueForAccrual", ctx, today, limit)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCreditLinesDueForAccrual indicates an expected call of GetCreditLinesDueForAccrual.
+func (mr *MockRepositoryMockRecorder) GetCreditLinesDueForAccrual(ctx, today, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCreditLinesDueForAccrual", reflect.TypeOf((*MockRepository)(nil).GetCreditLinesDueForAccrual), ctx, today, limit)
+}
+
+// GetCurrencies mocks base method.
+func (m *MockRepository) GetCurrencies(ctx context.Context, onlyEnabled bool) ([]*models.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrencies", ctx, onlyEnabled)
+	ret0, _ := ret[0].([]*models.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrencies indicates an expected call of GetCurrencies.
+func (mr *MockRepositoryMockRecorder) GetCurrencies(ctx, onlyEnabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencies", reflect.TypeOf((*MockRepository)(nil).GetCurrencies), ctx, onlyEnabled)
+}
+
+// GetCurrencyByCode mocks base method.
+func (m *MockRepository) GetCurrencyByCode(ctx context.Context, code string) (*models.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrencyByCode", ctx, code)
+	ret0, _ := ret[0].(*models.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrencyByCode indicates an expected call of GetCurrencyByCode.
+func (mr *MockRepositoryMockRecorder) GetCurrencyByCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrencyByCode", reflect.TypeOf((*MockRepository)(nil).GetCurrencyByCode), ctx, code)
+}
+
+// GetFreezeEvents mocks base method.
+func (m *MockRepository) GetFreezeEvents(ctx context.Context, targetType string, targetID uint64) ([]*models.FreezeEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFreezeEvents", ctx, targetType, targetID)
+	ret0, _ := ret[0].([]*models.FreezeEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFreezeEvents indicates an expected call of GetFreezeEvents.
+func (mr *MockRepositoryMockRecorder) GetFreezeEvents(ctx, targetType, targetID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFreezeEvents", reflect.TypeOf((*MockRepository)(nil).GetFreezeEvents), ctx, targetType, targetID)
+}
+
+// GetHeldAmount mocks base method.
+func (m *MockRepository) GetHeldAmount(ctx context.Context, walletID uint64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeldAmount", ctx, walletID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeldAmount indicates an expected call of GetHeldAmount.
+func (mr *MockRepositoryMockRecorder) GetHeldAmount(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeldAmount", reflect.TypeOf((*MockRepository)(nil).GetHeldAmount), ctx, walletID)
+}
+
+// GetHeldAmountsByUserID mocks base method.
+func (m *MockRepository) GetHeldAmountsByUserID(ctx context.Context, userID uint64) (map[string]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeldAmountsByUserID", ctx, userID)
+	ret0, _ := ret[0].(map[string]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeldAmountsByUserID indicates an expected call of GetHeldAmountsByUserID.
+func (mr *MockRepositoryMockRecorder) GetHeldAmountsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeldAmountsByUserID", reflect.TypeOf((*MockRepository)(nil).GetHeldAmountsByUserID), ctx, userID)
+}
+
+// GetHoldByID mocks base method.
+func (m *MockRepository) GetHoldByID(ctx context.Context, holdID uint64) (*models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldByID", ctx, holdID)
+	ret0, _ := ret[0].(*models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldByID indicates an expected call of GetHoldByID.
+func (mr *MockRepositoryMockRecorder) GetHoldByID(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldByID", reflect.TypeOf((*MockRepository)(nil).GetHoldByID), ctx, holdID)
+}
+
+// GetHoldByIDForUpdate mocks base method.
+func (m *MockRepository) GetHoldByIDForUpdate(ctx context.Context, holdID uint64) (*models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldByIDForUpdate", ctx, holdID)
+	ret0, _ := ret[0].(*models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldByIDForUpdate indicates an expected call of GetHoldByIDForUpdate.
+func (mr *MockRepositoryMockRecorder) GetHoldByIDForUpdate(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldByIDForUpdate", reflect.TypeOf((*MockRepository)(nil).GetHoldByIDForUpdate), ctx, holdID)
+}
+
+// GetHoldsByUserID mocks base method.
+func (m *MockRepository) GetHoldsByUserID(ctx context.Context, userID uint64) ([]*models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldsByUserID indicates an expected call of GetHoldsByUserID.
+func (mr *MockRepositoryMockRecorder) GetHoldsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldsByUserID", reflect.TypeOf((*MockRepository)(nil).GetHoldsByUserID), ctx, userID)
+}
+
+// GetJournalCheckpoints mocks base method.
+func (m *MockRepository) GetJournalCheckpoints(ctx context.Context) ([]*models.JournalCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJournalCheckpoints", ctx)
+	ret0, _ := ret[0].([]*models.JournalCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJournalCheckpoints indicates an expected call of GetJournalCheckpoints.
+func (mr *MockRepositoryMockRecorder) GetJournalCheckpoints(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJournalCheckpoints", reflect.TypeOf((*MockRepository)(nil).GetJournalCheckpoints), ctx)
+}
+
+// GetJournalHeads mocks base method.
+func (m *MockRepository) GetJournalHeads(ctx context.Context, before time.Time) (uint64, map[uint64]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJournalHeads", ctx, before)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(map[uint64]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetJournalHeads indicates an expected call of GetJournalHeads.
+func (mr *MockRepositoryMockRecorder) GetJournalHeads(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJournalHeads", reflect.TypeOf((*MockRepository)(nil).GetJournalHeads), ctx, before)
+}
+
+// GetLastOutboxEventID mocks base method.
+func (m *MockRepository) GetLastOutboxEventID(ctx context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastOutboxEventID", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastOutboxEventID indicates an expected call of GetLastOutboxEventID.
+func (mr *MockRepositoryMockRecorder) GetLastOutboxEventID(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastOutboxEventID", reflect.TypeOf((*MockRepository)(nil).GetLastOutboxEventID), ctx)
+}
+
+// GetLastTransactionHash mocks base method.
+func (m *MockRepository) GetLastTransactionHash(ctx context.Context, walletID uint64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastTransactionHash", ctx, walletID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastTransactionHash indicates an expected call of GetLastTransactionHash.
+func (mr *MockRepositoryMockRecorder) GetLastTransactionHash(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastTransactionHash", reflect.TypeOf((*MockRepository)(nil).GetLastTransactionHash), ctx, walletID)
+}
+
+// GetLatestNotification mocks base method.
+func (m *MockRepository) GetLatestNotification(ctx context.Context, userID uint64, eventType string) (*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestNotification", ctx, userID, eventType)
+	ret0, _ := ret[0].(*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestNotification indicates an expected call of GetLatestNotification.
+func (mr *MockRepositoryMockRecorder) GetLatestNotification(ctx, userID, eventType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestNotification", reflect.TypeOf((*MockRepository)(nil).GetLatestNotification), ctx, userID, eventType)
+}
+
+// GetLimitOrderByIDForUpdate mocks base method.
+func (m *MockRepository) GetLimitOrderByIDForUpdate(ctx context.Context, orderID uint64) (*models.LimitOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimitOrderByIDForUpdate", ctx, orderID)
+	ret0, _ := ret[0].(*models.LimitOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimitOrderByIDForUpdate indicates an expected call of GetLimitOrderByIDForUpdate.
+func (mr *MockRepositoryMockRecorder) GetLimitOrderByIDForUpdate(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimitOrderByIDForUpdate", reflect.TypeOf((*MockRepository)(nil).GetLimitOrderByIDForUpdate), ctx, orderID)
+}
+
+// GetLimitOrdersByUserID mocks base method.
+func (m *MockRepository) GetLimitOrdersByUserID(ctx context.Context, userID uint64) ([]*models.LimitOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimitOrdersByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.LimitOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimitOrdersByUserID indicates an expected call of GetLimitOrdersByUserID.
+func (mr *MockRepositoryMockRecorder) GetLimitOrdersByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimitOrdersByUserID", reflect.TypeOf((*MockRepository)(nil).GetLimitOrdersByUserID), ctx, userID)
+}
+
+// GetMemberDebits mocks base method.
+func (m *MockRepository) GetMemberDebits(ctx context.Context, walletID, userID uint64, since time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberDebits", ctx, walletID, userID, since)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberDebits indicates an expected call of GetMemberDebits.
+func (mr *MockRepositoryMockRecorder) GetMemberDebits(ctx, walletID, userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberDebits", reflect.TypeOf((*MockRepository)(nil).GetMemberDebits), ctx, walletID, userID, since)
+}
+
+// GetMemberHeldAmount mocks base method.
+func (m *MockRepository) GetMemberHeldAmount(ctx context.Context, walletID, userID uint64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberHeldAmount", ctx, walletID, userID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberHeldAmount indicates an expected call of GetMemberHeldAmount.
+func (mr *MockRepositoryMockRecorder) GetMemberHeldAmount(ctx, walletID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberHeldAmount", reflect.TypeOf((*MockRepository)(nil).GetMemberHeldAmount), ctx, walletID, userID)
+}
+
+// GetNotificationPreference mocks base method.
+func (m *MockRepository) GetNotificationPreference(ctx context.Context, userID uint64, eventType string) (*models.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreference", ctx, userID, eventType)
+	ret0, _ := ret[0].(*models.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreference indicates an expected call of GetNotificationPreference.
+func (mr *MockRepositoryMockRecorder) GetNotificationPreference(ctx, userID, eventType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreference", reflect.TypeOf((*MockRepository)(nil).GetNotificationPreference), ctx, userID, eventType)
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MockRepository) GetNotificationPreferences(ctx context.Context, userID uint64) ([]*models.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", ctx, userID)
+	ret0, _ := ret[0].([]*models.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockRepositoryMockRecorder) GetNotificationPreferences(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockRepository)(nil).GetNotificationPreferences), ctx, userID)
+}
+
+// GetNotifications mocks base method.
+func (m *MockRepository) GetNotifications(ctx context.Context, userID uint64, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifications", ctx, userID, unreadOnly, limit)
+	ret0, _ := ret[0].([]*models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifications indicates an expected call of GetNotifications.
+func (mr *MockRepositoryMockRecorder) GetNotifications(ctx, userID, unreadOnly, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifications", reflect.TypeOf((*MockRepository)(nil).GetNotifications), ctx, userID, unreadOnly, limit)
+}
+
+// GetOpenLimitOrders mocks base method.
+func (m *MockRepository) GetOpenLimitOrders(ctx context.Context, now time.Time, limit int) ([]*models.LimitOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenLimitOrders", ctx, now, limit)
+	ret0, _ := ret[0].([]*models.LimitOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpenLimitOrders indicates an expected call of GetOpenLimitOrders.
+func (mr *MockRepositoryMockRecorder) GetOpenLimitOrders(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenLimitOrders", reflect.TypeOf((*MockRepository)(nil).GetOpenLimitOrders), ctx, now, limit)
+}
+
+// GetPaymentRequestByID mocks base method.
+func (m *MockRepository) GetPaymentRequestByID(ctx context.Context, requestID uint64) (*models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequestByID", ctx, requestID)
+	ret0, _ := ret[0].(*models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequestByID indicates an expected call of GetPaymentRequestByID.
+func (mr *MockRepositoryMockRecorder) GetPaymentRequestByID(ctx, requestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequestByID", reflect.TypeOf((*MockRepository)(nil).GetPaymentRequestByID), ctx, requestID)
+}
+
+// GetPaymentRequestByIDForUpdate mocks base method.
+func (m *MockRepository) GetPaymentRequestByIDForUpdate(ctx context.Context, requestID uint64) (*models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequestByIDForUpdate", ctx, requestID)
+	ret0, _ := ret[0].(*models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequestByIDForUpdate indicates an expected call of GetPaymentRequestByIDForUpdate.
+func (mr *MockRepositoryMockRecorder) GetPaymentRequestByIDForUpdate(ctx, requestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequestByIDForUpdate", reflect.TypeOf((*MockRepository)(nil).GetPaymentRequestByIDForUpdate), ctx, requestID)
+}
+
+// GetPaymentRequestsByUserID mocks base method.
+func (m *MockRepository) GetPaymentRequestsByUserID(ctx context.Context, userID uint64, direction string) ([]*models.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequestsByUserID", ctx, userID, direction)
+	ret0, _ := ret[0].([]*models.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequestsByUserID indicates an expected call of GetPaymentRequestsByUserID.
+func (mr *MockRepositoryMockRecorder) GetPaymentRequestsByUserID(ctx, userID, direction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequestsByUserID", reflect.TypeOf((*MockRepository)(nil).GetPaymentRequestsByUserID), ctx, userID, direction)
+}
+
+// GetPendingOutboxEvents mocks base method.
+func (m *MockRepository) GetPendingOutboxEvents(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingOutboxEvents", ctx, limit)
+	ret0, _ := ret[0].([]*models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingOutboxEvents indicates an expected call of GetPendingOutboxEvents.
+func (mr *MockRepositoryMockRecorder) GetPendingOutboxEvents(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingOutboxEvents", reflect.TypeOf((*MockRepository)(nil).GetPendingOutboxEvents), ctx, limit)
+}
+
+// GetPotByID mocks base method.
+func (m *MockRepository) GetPotByID(ctx context.Context, potID uint64) (*models.Pot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPotByID", ctx, potID)
+	ret0, _ := ret[0].(*models.Pot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPotByID indicates an expected call of GetPotByID.
+func (mr *MockRepositoryMockRecorder) GetPotByID(ctx, potID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPotByID", reflect.TypeOf((*MockRepository)(nil).GetPotByID), ctx, potID)
+}
+
+// GetPotByIDForUpdate mocks base method.
+func (m *MockRepository) GetPotByIDForUpdate(ctx context.Context, potID uint64) (*models.Pot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPotByIDForUpdate", ctx, potID)
+	ret0, _ := ret[0].(*models.Pot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPotByIDForUpdate indicates an expected call of GetPotByIDForUpdate.
+func (mr *MockRepositoryMockRecorder) GetPotByIDForUpdate(ctx, potID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPotByIDForUpdate", reflect.TypeOf((*MockRepository)(nil).GetPotByIDForUpdate), ctx, potID)
+}
+
+// GetPotMovements mocks base method.
+func (m *MockRepository) GetPotMovements(ctx context.Context, potID uint64) ([]*models.PotMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPotMovements", ctx, potID)
+	ret0, _ := ret[0].([]*models.PotMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPotMovements indicates an expected call of GetPotMovements.
+func (mr *MockRepositoryMockRecorder) GetPotMovements(ctx, potID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPotMovements", reflect.TypeOf((*MockRepository)(nil).GetPotMovements), ctx, potID)
+}
+
+// GetPotsByUserID mocks base method.
+func (m *MockRepository) GetPotsByUserID(ctx context.Context, userID uint64, onlyActive bool) ([]*models.Pot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPotsByUserID", ctx, userID, onlyActive)
+	ret0, _ := ret[0].([]*models.Pot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPotsByUserID indicates an expected call of GetPotsByUserID.
+func (mr *MockRepositoryMockRecorder) GetPotsByUserID(ctx, userID, onlyActive interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPotsByUserID", reflect.TypeOf((*MockRepository)(nil).GetPotsByUserID), ctx, userID, onlyActive)
+}
+
+// GetPotsByWalletID mocks base method.
+func (m *MockRepository) GetPotsByWalletID(ctx context.Context, walletID uint64) ([]*models.Pot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPotsByWalletID", ctx, walletID)
+	ret0, _ := ret[0].([]*models.Pot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPotsByWalletID indicates an expected call of GetPotsByWalletID.
+func (mr *MockRepositoryMockRecorder) GetPotsByWalletID(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPotsByWalletID", reflect.TypeOf((*MockRepository)(nil).GetPotsByWalletID), ctx, walletID)
+}
+
+// GetPottedAmount mocks base method.
+func (m *MockRepository) GetPottedAmount(ctx context.Context, walletID uint64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPottedAmount", ctx, walletID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPottedAmount indicates an expected call of GetPottedAmount.
+func (mr *MockRepositoryMockRecorder) GetPottedAmount(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPottedAmount", reflect.TypeOf((*MockRepository)(nil).GetPottedAmount), ctx, walletID)
+}
+
+// GetProviderDeposit mocks base method.
+func (m *MockRepository) GetProviderDeposit(ctx context.Context, provider, eventID string) (*models.ProviderDeposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProviderDeposit", ctx, provider, eventID)
+	ret0, _ := ret[0].(*models.ProviderDeposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProviderDeposit indicates an expected call of GetProviderDeposit.
+func (mr *MockRepositoryMockRecorder) GetProviderDeposit(ctx, provider, eventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProviderDeposit", reflect.TypeOf((*MockRepository)(nil).GetProviderDeposit), ctx, provider, eventID)
+}
+
+// GetProviderDeposits mocks base method.
+func (m *MockRepository) GetProviderDeposits(ctx context.Context, status string, limit int) ([]*models.ProviderDeposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProviderDeposits", ctx, status, limit)
+	ret0, _ := ret[0].([]*models.ProviderDeposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProviderDeposits indicates an expected call of GetProviderDeposits.
+func (mr *MockRepositoryMockRecorder) GetProviderDeposits(ctx, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProviderDeposits", reflect.TypeOf((*MockRepository)(nil).GetProviderDeposits), ctx, status, limit)
+}
+
+// GetRebalancePlanByIDForUpdate mocks base method.
+func (m *MockRepository) GetRebalancePlanByIDForUpdate(ctx context.Context, planID uint64) (*models.RebalancePlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRebalancePlanByIDForUpdate", ctx, planID)
+	ret0, _ := ret[0].(*models.RebalancePlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRebalancePlanByIDForUpdate indicates an expected call of GetRebalancePlanByIDForUpdate.
+func (mr *MockRepositoryMockRecorder) GetRebalancePlanByIDForUpdate(ctx, planID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRebalancePlanByIDForUpdate", reflect.TypeOf((*MockRepository)(nil).GetRebalancePlanByIDForUpdate), ctx, planID)
+}
+
+// GetReconciliationRunByID mocks base method.
+func (m *MockRepository) GetReconciliationRunByID(ctx context.Context, runID uint64) (*models.ReconciliationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReconciliationRunByID", ctx, runID)
+	ret0, _ := ret[0].(*models.ReconciliationRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReconciliationRunByID indicates an expected call of GetReconciliationRunByID.
+func (mr *MockRepositoryMockRecorder) GetReconciliationRunByID(ctx, runID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliationRunByID", reflect.TypeOf((*MockRepository)(nil).GetReconciliationRunByID), ctx, runID)
+}
+
+// GetReconciliationRuns mocks base method.
+func (m *MockRepository) GetReconciliationRuns(ctx context.Context, limit int) ([]*models.ReconciliationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReconciliationRuns", ctx, limit)
+	ret0, _ := ret[0].([]*models.ReconciliationRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReconciliationRuns indicates an expected call of GetReconciliationRuns.
+func (mr *MockRepositoryMockRecorder) GetReconciliationRuns(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliationRuns", reflect.TypeOf((*MockRepository)(nil).GetReconciliationRuns), ctx, limit)
+}
+
+// GetRefreshTokenModelByID mocks base method.
+func (m *MockRepository) GetRefreshTokenModelByID(ctx context.Context, userID uint64, deviceID string) (*models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenModelByID", ctx, userID, deviceID)
+	ret0, _ := ret[0].(*models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenModelByID indicates an expected call of GetRefreshTokenModelByID.
+func (mr *MockRepositoryMockRecorder) GetRefreshTokenModelByID(ctx, userID, deviceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenModelByID", reflect.TypeOf((*MockRepository)(nil).GetRefreshTokenModelByID), ctx, userID, deviceID)
+}
+
+// GetReversalByOperationID mocks base method.
+func (m *MockRepository) GetReversalByOperationID(ctx context.Context, operationID string) (*models.Reversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReversalByOperationID", ctx, operationID)
+	ret0, _ := ret[0].(*models.Reversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReversalByOperationID indicates an expected call of GetReversalByOperationID.
+func (mr *MockRepositoryMockRecorder) GetReversalByOperationID(ctx, operationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReversalByOperationID", reflect.TypeOf((*MockRepository)(nil).GetReversalByOperationID), ctx, operationID)
+}
+
+// GetScheduleExecutions mocks base method.
+func (m *MockRepository) GetScheduleExecutions(ctx context.Context, scheduleID uint64) ([]*models.ScheduleExecution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduleExecutions", ctx, scheduleID)
+	ret0, _ := ret[0].([]*models.ScheduleExecution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduleExecutions indicates an expected call of GetScheduleExecutions.
+func (mr *MockRepositoryMockRecorder) GetScheduleExecutions(ctx, scheduleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduleExecutions", reflect.TypeOf((*MockRepository)(nil).GetScheduleExecutions), ctx, scheduleID)
+}
+
+// GetScheduledTransferByID mocks base method.
+func (m *MockRepository) GetScheduledTransferByID(ctx context.Context, scheduleID uint64) (*models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransferByID", ctx, scheduleID)
+	ret0, _ := ret[0].(*models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransferByID indicates an expected call of GetScheduledTransferByID.
+func (mr *MockRepositoryMockRecorder) GetScheduledTransferByID(ctx, scheduleID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransferByID", reflect.TypeOf((*MockRepository)(nil).GetScheduledTransferByID), ctx, scheduleID)
+}
+
+// GetScheduledTransfersByUserID mocks base method.
+func (m *MockRepository) GetScheduledTransfersByUserID(ctx context.Context, userID uint64) ([]*models.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransfersByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransfersByUserID indicates an expected call of GetScheduledTransfersByUserID.
+func (mr *MockRepositoryMockRecorder) GetScheduledTransfersByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransfersByUserID", reflect.TypeOf((*MockRepository)(nil).GetScheduledTransfersByUserID), ctx, userID)
+}
+
+// GetStreamEventsAfter mocks base method.
+func (m *MockRepository) GetStreamEventsAfter(ctx context.Context, afterID uint64, limit int) ([]*models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStreamEventsAfter", ctx, afterID, limit)
+	ret0, _ := ret[0].([]*models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStreamEventsAfter indicates an expected call of GetStreamEventsAfter.
+func (mr *MockRepositoryMockRecorder) GetStreamEventsAfter(ctx, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStreamEventsAfter", reflect.TypeOf((*MockRepository)(nil).GetStreamEventsAfter), ctx, afterID, limit)
+}
+
+// GetStreamEventsByIDs mocks base method.
+func (m *MockRepository) GetStreamEventsByIDs(ctx context.Context, ids []uint64) ([]*models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStreamEventsByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStreamEventsByIDs indicates an expected call of GetStreamEventsByIDs.
+func (mr *MockRepositoryMockRecorder) GetStreamEventsByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStreamEventsByIDs", reflect.TypeOf((*MockRepository)(nil).GetStreamEventsByIDs), ctx, ids)
+}
+
+// GetTenantByHost mocks base method.
+func (m *MockRepository) GetTenantByHost(ctx context.Context, host string) (*models.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantByHost", ctx, host)
+	ret0, _ := ret[0].(*models.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantByHost indicates an expected call of GetTenantByHost.
+func (mr *MockRepositoryMockRecorder) GetTenantByHost(ctx, host interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantByHost", reflect.TypeOf((*MockRepository)(nil).GetTenantByHost), ctx, host)
+}
+
+// GetTenantBySlug mocks base method.
+func (m *MockRepository) GetTenantBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantBySlug", ctx, slug)
+	ret0, _ := ret[0].(*models.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantBySlug indicates an expected call of GetTenantBySlug.
+func (mr *MockRepositoryMockRecorder) GetTenantBySlug(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantBySlug", reflect.TypeOf((*MockRepository)(nil).GetTenantBySlug), ctx, slug)
+}
+
+// GetTenants mocks base method.
+func (m *MockRepository) GetTenants(ctx context.Context) ([]*models.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenants", ctx)
+	ret0, _ := ret[0].([]*models.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenants indicates an expected call of GetTenants.
+func (mr *MockRepositoryMockRecorder) GetTenants(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockRepository)(nil).GetTenants), ctx)
+}
+
+// GetTransactionByID mocks base method.
+func (m *MockRepository) GetTransactionByID(ctx context.Context, transactionID uint64) (*models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionByID", ctx, transactionID)
+	ret0, _ := ret[0].(*models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionByID indicates an expected call of GetTransactionByID.
+func (mr *MockRepositoryMockRecorder) GetTransactionByID(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionByID", reflect.TypeOf((*MockRepository)(nil).GetTransactionByID), ctx, transactionID)
+}
+
+// GetTransactionsByOperationID mocks base method.
+func (m *MockRepository) GetTransactionsByOperationID(ctx context.Context, operationID string) ([]*models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionsByOperationID", ctx, operationID)
+	ret0, _ := ret[0].([]*models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionsByOperationID indicates an expected call of GetTransactionsByOperationID.
+func (mr *MockRepositoryMockRecorder) GetTransactionsByOperationID(ctx, operationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsByOperationID", reflect.TypeOf((*MockRepository)(nil).GetTransactionsByOperationID), ctx, operationID)
+}
+
+// GetUnpaidInterestByUserID mocks base method.
+func (m *MockRepository) GetUnpaidInterestByUserID(ctx context.Context, userID uint64) ([]*models.InterestSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnpaidInterestByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.InterestSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnpaidInterestByUserID indicates an expected call of GetUnpaidInterestByUserID.
+func (mr *MockRepositoryMockRecorder) GetUnpaidInterestByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnpaidInterestByUserID", reflect.TypeOf((*MockRepository)(nil).GetUnpaidInterestByUserID), ctx, userID)
+}
+
+// GetUserByDepositReference mocks base method.
+func (m *MockRepository) GetUserByDepositReference(ctx context.Context, reference string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByDepositReference", ctx, reference)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByDepositReference indicates an expected call of GetUserByDepositReference.
+func (mr *MockRepositoryMockRecorder) GetUserByDepositReference(ctx, reference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByDepositReference", reflect.TypeOf((*MockRepository)(nil).GetUserByDepositReference), ctx, reference)
+}
+
+// GetUserByID mocks base method.
+func (m *MockRepository) GetUserByID(ctx context.Context, userID uint64) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockRepositoryMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockRepository)(nil).GetUserByID), ctx, userID)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockRepositoryMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockRepository)(nil).GetUserByUsername), ctx, username)
+}
+
+// GetUserStreamEventsAfter mocks base method.
+func (m *MockRepository) GetUserStreamEventsAfter(ctx context.Context, userID, afterID uint64, limit int) ([]*models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserStreamEventsAfter", ctx, userID, afterID, limit)
+	ret0, _ := ret[0].([]*models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserStreamEventsAfter indicates an expected call of GetUserStreamEventsAfter.
+func (mr *MockRepositoryMockRecorder) GetUserStreamEventsAfter(ctx, userID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserStreamEventsAfter", reflect.TypeOf((*MockRepository)(nil).GetUserStreamEventsAfter), ctx, userID, afterID, limit)
+}
+
+// GetVoucherBatchByID mocks base method.
+func (m *MockRepository) GetVoucherBatchByID(ctx context.Context, batchID uint64) (*models.VoucherBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVoucherBatchByID", ctx, batchID)
+	ret0, _ := ret[0].(*models.VoucherBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVoucherBatchByID indicates an expected call of GetVoucherBatchByID.
+func (mr *MockRepositoryMockRecorder) GetVoucherBatchByID(ctx, batchID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVoucherBatchByID", reflect.TypeOf((*MockRepository)(nil).GetVoucherBatchByID), ctx, batchID)
+}
+
+// GetVoucherBatches mocks base method.
+func (m *MockRepository) GetVoucherBatches(ctx context.Context, limit int) ([]*models.VoucherBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVoucherBatches", ctx, limit)
+	ret0, _ := ret[0].([]*models.VoucherBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVoucherBatches indicates an expected call of GetVoucherBatches.
+func (mr *MockRepositoryMockRecorder) GetVoucherBatches(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVoucherBatches", reflect.TypeOf((*MockRepository)(nil).GetVoucherBatches), ctx, limit)
+}
+
+// GetVoucherByCodeForUpdate mocks base method.
+func (m *MockRepository) GetVoucherByCodeForUpdate(ctx context.Context, code string) (*models.Voucher, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVoucherByCodeForUpdate", ctx, code)
+	ret0, _ := ret[0].(*models.Voucher)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVoucherByCodeForUpdate indicates an expected call of GetVoucherByCodeForUpdate.
+func (mr *MockRepositoryMockRecorder) GetVoucherByCodeForUpdate(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVoucherByCodeForUpdate", reflect.TypeOf((*MockRepository)(nil).GetVoucherByCodeForUpdate), ctx, code)
+}
+
+// GetVouchersByBatchID mocks base method.
+func (m *MockRepository) GetVouchersByBatchID(ctx context.Context, batchID uint64) ([]*models.Voucher, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVouchersByBatchID", ctx, batchID)
+	ret0, _ := ret[0].([]*models.Voucher)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVouchersByBatchID indicates an expected call of GetVouchersByBatchID.
+func (mr *MockRepositoryMockRecorder) GetVouchersByBatchID(ctx, batchID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVouchersByBatchID", reflect.TypeOf((*MockRepository)(nil).GetVouchersByBatchID), ctx, batchID)
+}
+
+// GetWalletByID mocks base method.
+func (m *MockRepository) GetWalletByID(ctx context.Context, walletID uint64) (*models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletByID", ctx, walletID)
+	ret0, _ := ret[0].(*models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletByID indicates an expected call of GetWalletByID.
+func (mr *MockRepositoryMockRecorder) GetWalletByID(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByID", reflect.TypeOf((*MockRepository)(nil).GetWalletByID), ctx, walletID)
+}
+
+// GetWalletByIDForUpdate mocks base method.
+func (m *MockRepository) GetWalletByIDForUpdate(ctx context.Context, walletID uint64) (*models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletByIDForUpdate", ctx, walletID)
+	ret0, _ := ret[0].(*models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletByIDForUpdate indicates an expected call of GetWalletByIDForUpdate.
+func (mr *MockRepositoryMockRecorder) GetWalletByIDForUpdate(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByIDForUpdate", reflect.TypeOf((*MockRepository)(nil).GetWalletByIDForUpdate), ctx, walletID)
+}
+
+// GetWalletByUserAndCurrency mocks base method.
+func (m *MockRepository) GetWalletByUserAndCurrency(userID uint64, currency string) (*models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletByUserAndCurrency", userID, currency)
+	ret0, _ := ret[0].(*models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletByUserAndCurrency indicates an expected call of GetWalletByUserAndCurrency.
+func (mr *MockRepositoryMockRecorder) GetWalletByUserAndCurrency(userID, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByUserAndCurrency", reflect.TypeOf((*MockRepository)(nil).GetWalletByUserAndCurrency), userID, currency)
+}
+
+// GetWalletByUserAndCurrencyForUpdate mocks base method.
+func (m *MockRepository) GetWalletByUserAndCurrencyForUpdate(ctx context.Context, userID uint64, currency string) (*models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletByUserAndCurrencyForUpdate", ctx, userID, currency)
+	ret0, _ := ret[0].(*models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletByUserAndCurrencyForUpdate indicates an expected call of GetWalletByUserAndCurrencyForUpdate.
+func (mr *MockRepositoryMockRecorder) GetWalletByUserAndCurrencyForUpdate(ctx, userID, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletByUserAndCurrencyForUpdate", reflect.TypeOf((*MockRepository)(nil).GetWalletByUserAndCurrencyForUpdate), ctx, userID, currency)
+}
+
+// GetWalletMember mocks base method.
+func (m *MockRepository) GetWalletMember(ctx context.Context, walletID, userID uint64) (*models.WalletMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletMember", ctx, walletID, userID)
+	ret0, _ := ret[0].(*models.WalletMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletMember indicates an expected call of GetWalletMember.
+func (mr *MockRepositoryMockRecorder) GetWalletMember(ctx, walletID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletMember", reflect.TypeOf((*MockRepository)(nil).GetWalletMember), ctx, walletID, userID)
+}
+
+// GetWalletMembers mocks base method.
+func (m *MockRepository) GetWalletMembers(ctx context.Context, walletID uint64) ([]*models.WalletMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletMembers", ctx, walletID)
+	ret0, _ := ret[0].([]*models.WalletMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletMembers indicates an expected call of GetWalletMembers.
+func (mr *MockRepositoryMockRecorder) GetWalletMembers(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletMembers", reflect.TypeOf((*MockRepository)(nil).GetWalletMembers), ctx, walletID)
+}
+
+// GetWalletMembershipsByUserID mocks base method.
+func (m *MockRepository) GetWalletMembershipsByUserID(ctx context.Context, userID uint64, status string) ([]*models.WalletMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletMembershipsByUserID", ctx, userID, status)
+	ret0, _ := ret[0].([]*models.WalletMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletMembershipsByUserID indicates an expected call of GetWalletMembershipsByUserID.
+func (mr *MockRepositoryMockRecorder) GetWalletMembershipsByUserID(ctx, userID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletMembershipsByUserID", reflect.TypeOf((*MockRepository)(nil).GetWalletMembershipsByUserID), ctx, userID, status)
+}
+
+// GetWalletsByUserID mocks base method.
+func (m *MockRepository) GetWalletsByUserID(userID uint64) ([]*models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletsByUserID", userID)
+	ret0, _ := ret[0].([]*models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletsByUserID indicates an expected call of GetWalletsByUserID.
+func (mr *MockRepositoryMockRecorder) GetWalletsByUserID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletsByUserID", reflect.TypeOf((*MockRepository)(nil).GetWalletsByUserID), userID)
+}
+
+// GetWalletsDueForInterest mocks base method.
+func (m *MockRepository) GetWalletsDueForInterest(ctx context.Context, day time.Time, limit int) ([]*models.InterestDue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletsDueForInterest", ctx, day, limit)
+	ret0, _ := ret[0].([]*models.InterestDue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletsDueForInterest indicates an expected call of GetWalletsDueForInterest.
+func (mr *MockRepositoryMockRecorder) GetWalletsDueForInterest(ctx, day, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletsDueForInterest", reflect.TypeOf((*MockRepository)(nil).GetWalletsDueForInterest), ctx, day, limit)
+}
+
+// GetWalletsWithUnpaidInterest mocks base method.
+func (m *MockRepository) GetWalletsWithUnpaidInterest(ctx context.Context, before time.Time, limit int) ([]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletsWithUnpaidInterest", ctx, before, limit)
+	ret0, _ := ret[0].([]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletsWithUnpaidInterest indicates an expected call of GetWalletsWithUnpaidInterest.
+func (mr *MockRepositoryMockRecorder) GetWalletsWithUnpaidInterest(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletsWithUnpaidInterest", reflect.TypeOf((*MockRepository)(nil).GetWalletsWithUnpaidInterest), ctx, before, limit)
+}
+
+// GetWebhookAttempts mocks base method.
+func (m *MockRepository) GetWebhookAttempts(ctx context.Context, deliveryID uint64) ([]*models.WebhookAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookAttempts", ctx, deliveryID)
+	ret0, _ := ret[0].([]*models.WebhookAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookAttempts indicates an expected call of GetWebhookAttempts.
+func (mr *MockRepositoryMockRecorder) GetWebhookAttempts(ctx, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookAttempts", reflect.TypeOf((*MockRepository)(nil).GetWebhookAttempts), ctx, deliveryID)
+}
+
+// GetWebhookDeliveriesBySubscriptionID mocks base method.
+func (m *MockRepository) GetWebhookDeliveriesBySubscriptionID(ctx context.Context, subscriptionID uint64, limit int) ([]*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveriesBySubscriptionID", ctx, subscriptionID, limit)
+	ret0, _ := ret[0].([]*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveriesBySubscriptionID indicates an expected call of GetWebhookDeliveriesBySubscriptionID.
+func (mr *MockRepositoryMockRecorder) GetWebhookDeliveriesBySubscriptionID(ctx, subscriptionID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveriesBySubscriptionID", reflect.TypeOf((*MockRepository)(nil).GetWebhookDeliveriesBySubscriptionID), ctx, subscriptionID, limit)
+}
+
+// GetWebhookDeliveryByID mocks base method.
+func (m *MockRepository) GetWebhookDeliveryByID(ctx context.Context, deliveryID uint64) (*models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveryByID", ctx, deliveryID)
+	ret0, _ := ret[0].(*models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveryByID indicates an expected call of GetWebhookDeliveryByID.
+func (mr *MockRepositoryMockRecorder) GetWebhookDeliveryByID(ctx, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveryByID", reflect.TypeOf((*MockRepository)(nil).GetWebhookDeliveryByID), ctx, deliveryID)
+}
+
+// GetWebhookSubscriptionByID mocks base method.
+func (m *MockRepository) GetWebhookSubscriptionByID(ctx context.Context, subscriptionID uint64) (*models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscriptionByID", ctx, subscriptionID)
+	ret0, _ := ret[0].(*models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscriptionByID indicates an expected call of GetWebhookSubscriptionByID.
+func (mr *MockRepositoryMockRecorder) GetWebhookSubscriptionByID(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscriptionByID", reflect.TypeOf((*MockRepository)(nil).GetWebhookSubscriptionByID), ctx, subscriptionID)
+}
+
+// GetWebhookSubscriptionsByUserID mocks base method.
+func (m *MockRepository) GetWebhookSubscriptionsByUserID(ctx context.Context, userID uint64) ([]*models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscriptionsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscriptionsByUserID indicates an expected call of GetWebhookSubscriptionsByUserID.
+func (mr *MockRepositoryMockRecorder) GetWebhookSubscriptionsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscriptionsByUserID", reflect.TypeOf((*MockRepository)(nil).GetWebhookSubscriptionsByUserID), ctx, userID)
+}
+
+// GetWithdrawalByID mocks base method.
+func (m *MockRepository) GetWithdrawalByID(ctx context.Context, withdrawalID uint64) (*models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalByID", ctx, withdrawalID)
+	ret0, _ := ret[0].(*models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalByID indicates an expected call of GetWithdrawalByID.
+func (mr *MockRepositoryMockRecorder) GetWithdrawalByID(ctx, withdrawalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalByID", reflect.TypeOf((*MockRepository)(nil).GetWithdrawalByID), ctx, withdrawalID)
+}
+
+// GetWithdrawalByIDForUpdate mocks base method.
+func (m *MockRepository) GetWithdrawalByIDForUpdate(ctx context.Context, withdrawalID uint64) (*models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalByIDForUpdate", ctx, withdrawalID)
+	ret0, _ := ret[0].(*models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalByIDForUpdate indicates an expected call of GetWithdrawalByIDForUpdate.
+func (mr *MockRepositoryMockRecorder) GetWithdrawalByIDForUpdate(ctx, withdrawalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalByIDForUpdate", reflect.TypeOf((*MockRepository)(nil).GetWithdrawalByIDForUpdate), ctx, withdrawalID)
+}
+
+// GetWithdrawalEvents mocks base method.
+func (m *MockRepository) GetWithdrawalEvents(ctx context.Context, withdrawalID uint64) ([]*models.WithdrawalEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalEvents", ctx, withdrawalID)
+	ret0, _ := ret[0].([]*models.WithdrawalEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalEvents indicates an expected call of GetWithdrawalEvents.
+func (mr *MockRepositoryMockRecorder) GetWithdrawalEvents(ctx, withdrawalID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalEvents", reflect.TypeOf((*MockRepository)(nil).GetWithdrawalEvents), ctx, withdrawalID)
+}
+
+// GetWithdrawalsByStatus mocks base method.
+func (m *MockRepository) GetWithdrawalsByStatus(ctx context.Context, status string, since time.Time, limit int) ([]*models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalsByStatus", ctx, status, since, limit)
+	ret0, _ := ret[0].([]*models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalsByStatus indicates an expected call of GetWithdrawalsByStatus.
+func (mr *MockRepositoryMockRecorder) GetWithdrawalsByStatus(ctx, status, since, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalsByStatus", reflect.TypeOf((*MockRepository)(nil).GetWithdrawalsByStatus), ctx, status, since, limit)
+}
+
+// GetWithdrawalsByUserID mocks base method.
+func (m *MockRepository) GetWithdrawalsByUserID(ctx context.Context, userID uint64) ([]*models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalsByUserID indicates an expected call of GetWithdrawalsByUserID.
+func (mr *MockRepositoryMockRecorder) GetWithdrawalsByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalsByUserID", reflect.TypeOf((*MockRepository)(nil).GetWithdrawalsByUserID), ctx, userID)
+}
+
+// LockOutboxRelay mocks base method.
+func (m *MockRepository) LockOutboxRelay(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockOutboxRelay", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockOutboxRelay indicates an expected call of LockOutboxRelay.
+func (mr *MockRepositoryMockRecorder) LockOutboxRelay(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOutboxRelay", reflect.TypeOf((*MockRepository)(nil).LockOutboxRelay), ctx)
+}
+
+// LockUserNotifications mocks base method.
+func (m *MockRepository) LockUserNotifications(ctx context.Context, userID uint64, eventType string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUserNotifications", ctx, userID, eventType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUserNotifications indicates an expected call of LockUserNotifications.
+func (mr *MockRepositoryMockRecorder) LockUserNotifications(ctx, userID, eventType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUserNotifications", reflect.TypeOf((*MockRepository)(nil).LockUserNotifications), ctx, userID, eventType)
+}
+
+// MarkNotificationsRead mocks base method.
+func (m *MockRepository) MarkNotificationsRead(ctx context.Context, userID, notificationID uint64, readAt time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationsRead", ctx, userID, notificationID, readAt)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationsRead indicates an expected call of MarkNotificationsRead.
+func (mr *MockRepositoryMockRecorder) MarkNotificationsRead(ctx, userID, notificationID, readAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationsRead", reflect.TypeOf((*MockRepository)(nil).MarkNotificationsRead), ctx, userID, notificationID, readAt)
+}
+
+// MarkOutboxEventFailed mocks base method.
+func (m *MockRepository) MarkOutboxEventFailed(ctx context.Context, eventID uint64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventFailed", ctx, eventID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventFailed indicates an expected call of MarkOutboxEventFailed.
+func (mr *MockRepositoryMockRecorder) MarkOutboxEventFailed(ctx, eventID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventFailed", reflect.TypeOf((*MockRepository)(nil).MarkOutboxEventFailed), ctx, eventID, reason)
+}
+
+// MarkOutboxEventPublished mocks base method.
+func (m *MockRepository) MarkOutboxEventPublished(ctx context.Context, eventID uint64, publishedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventPublished", ctx, eventID, publishedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventPublished indicates an expected call of MarkOutboxEventPublished.
+func (mr *MockRepositoryMockRecorder) MarkOutboxEventPublished(ctx, eventID, publishedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventPublished", reflect.TypeOf((*MockRepository)(nil).MarkOutboxEventPublished), ctx, eventID, publishedAt)
+}
+
+// PayInterestAccruals mocks base method.
+func (m *MockRepository) PayInterestAccruals(ctx context.Context, walletID uint64, before time.Time, operationID string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayInterestAccruals", ctx, walletID, before, operationID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PayInterestAccruals indicates an expected call of PayInterestAccruals.
+func (mr *MockRepositoryMockRecorder) PayInterestAccruals(ctx, walletID, before, operationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayInterestAccruals", reflect.TypeOf((*MockRepository)(nil).PayInterestAccruals), ctx, walletID, before, operationID)
+}
+
+// SetRefreshTokenModel mocks base method.
+func (m *MockRepository) SetRefreshTokenModel(ctx context.Context, refreshToken *models.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRefreshTokenModel", ctx, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRefreshTokenModel indicates an expected call of SetRefreshTokenModel.
+func (mr *MockRepositoryMockRecorder) SetRefreshTokenModel(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRefreshTokenModel", reflect.TypeOf((*MockRepository)(nil).SetRefreshTokenModel), ctx, refreshToken)
+}
+
+// SnapshotBalances mocks base method.
+func (m *MockRepository) SnapshotBalances(ctx context.Context, run *models.ReconciliationRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotBalances", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SnapshotBalances indicates an expected call of SnapshotBalances.
+func (mr *MockRepositoryMockRecorder) SnapshotBalances(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotBalances", reflect.TypeOf((*MockRepository)(nil).SnapshotBalances), ctx, run)
+}
+
+// StreamJournal mocks base method.
+func (m *MockRepository) StreamJournal(ctx context.Context, fn func(*models.Transaction) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamJournal", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamJournal indicates an expected call of StreamJournal.
+func (mr *MockRepositoryMockRecorder) StreamJournal(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamJournal", reflect.TypeOf((*MockRepository)(nil).StreamJournal), ctx, fn)
+}
+
+// StreamTransactions mocks base method.
+func (m *MockRepository) StreamTransactions(ctx context.Context, walletID uint64, from, to time.Time, fn func(*models.Transaction) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamTransactions", ctx, walletID, from, to, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamTransactions indicates an expected call of StreamTransactions.
+func (mr *MockRepositoryMockRecorder) StreamTransactions(ctx, walletID, from, to, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTransactions", reflect.TypeOf((*MockRepository)(nil).StreamTransactions), ctx, walletID, from, to, fn)
+}
+
+// UpdateCreditLineAccrual mocks base method.
+func (m *MockRepository) UpdateCreditLineAccrual(ctx context.Context, walletID uint64, accruedOn time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCreditLineAccrual", ctx, walletID, accruedOn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCreditLineAccrual indicates an expected call of UpdateCreditLineAccrual.
+func (mr *MockRepositoryMockRecorder) UpdateCreditLineAccrual(ctx, walletID, accruedOn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCreditLineAccrual", reflect.TypeOf((*MockRepository)(nil).UpdateCreditLineAccrual), ctx, walletID, accruedOn)
+}
+
+// UpdateCurrency mocks base method.
+func (m *MockRepository) UpdateCurrency(ctx context.Context, currency *models.Currency) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCurrency", ctx, currency)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCurrency indicates an expected call of UpdateCurrency.
+func (mr *MockRepositoryMockRecorder) UpdateCurrency(ctx, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrency", reflect.TypeOf((*MockRepository)(nil).UpdateCurrency), ctx, currency)
+}
+
+// UpdateHold mocks base method.
+func (m *MockRepository) UpdateHold(ctx context.Context, hold *models.Hold) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHold", ctx, hold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHold indicates an expected call of UpdateHold.
+func (mr *MockRepositoryMockRecorder) UpdateHold(ctx, hold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHold", reflect.TypeOf((*MockRepository)(nil).UpdateHold), ctx, hold)
+}
+
+// UpdateLimitOrder mocks base method.
+func (m *MockRepository) UpdateLimitOrder(ctx context.Context, order *models.LimitOrder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLimitOrder", ctx, order)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLimitOrder indicates an expected call of UpdateLimitOrder.
+func (mr *MockRepositoryMockRecorder) UpdateLimitOrder(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLimitOrder", reflect.TypeOf((*MockRepository)(nil).UpdateLimitOrder), ctx, order)
+}
+
+// UpdateNotificationEmail mocks base method.
+func (m *MockRepository) UpdateNotificationEmail(ctx context.Context, notification *models.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationEmail", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotificationEmail indicates an expected call of UpdateNotificationEmail.
+func (mr *MockRepositoryMockRecorder) UpdateNotificationEmail(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationEmail", reflect.TypeOf((*MockRepository)(nil).UpdateNotificationEmail), ctx, notification)
+}
+
+// UpdatePaymentRequest mocks base method.
+func (m *MockRepository) UpdatePaymentRequest(ctx context.Context, request *models.PaymentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentRequest", ctx, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePaymentRequest indicates an expected call of UpdatePaymentRequest.
+func (mr *MockRepositoryMockRecorder) UpdatePaymentRequest(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentRequest", reflect.TypeOf((*MockRepository)(nil).UpdatePaymentRequest), ctx, request)
+}
+
+// UpdatePot mocks base method.
+func (m *MockRepository) UpdatePot(ctx context.Context, pot *models.Pot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePot", ctx, pot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePot indicates an expected call of UpdatePot.
+func (mr *MockRepositoryMockRecorder) UpdatePot(ctx, pot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePot", reflect.TypeOf((*MockRepository)(nil).UpdatePot), ctx, pot)
+}
+
+// UpdateProviderDeposit mocks base method.
+func (m *MockRepository) UpdateProviderDeposit(ctx context.Context, deposit *models.ProviderDeposit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProviderDeposit", ctx, deposit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProviderDeposit indicates an expected call of UpdateProviderDeposit.
+func (mr *MockRepositoryMockRecorder) UpdateProviderDeposit(ctx, deposit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProviderDeposit", reflect.TypeOf((*MockRepository)(nil).UpdateProviderDeposit), ctx, deposit)
+}
+
+// UpdateRebalancePlan mocks base method.
+func (m *MockRepository) UpdateRebalancePlan(ctx context.Context, plan *models.RebalancePlan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRebalancePlan", ctx, plan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRebalancePlan indicates an expected call of UpdateRebalancePlan.
+func (mr *MockRepositoryMockRecorder) UpdateRebalancePlan(ctx, plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRebalancePlan", reflect.TypeOf((*MockRepository)(nil).UpdateRebalancePlan), ctx, plan)
+}
+
+// UpdateScheduledTransferStatus mocks base method.
+func (m *MockRepository) UpdateScheduledTransferStatus(ctx context.Context, scheduleID uint64, status string, nextRunAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledTransferStatus", ctx, scheduleID, status, nextRunAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateScheduledTransferStatus indicates an expected call of UpdateScheduledTransferStatus.
+func (mr *MockRepositoryMockRecorder) UpdateScheduledTransferStatus(ctx, scheduleID, status, nextRunAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledTransferStatus", reflect.TypeOf((*MockRepository)(nil).UpdateScheduledTransferStatus), ctx, scheduleID, status, nextRunAt)
+}
+
+// UpdateUserFreezeState mocks base method.
+func (m *MockRepository) UpdateUserFreezeState(ctx context.Context, userID uint64, state string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserFreezeState", ctx, userID, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserFreezeState indicates an expected call of UpdateUserFreezeState.
+func (mr *MockRepositoryMockRecorder) UpdateUserFreezeState(ctx, userID, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserFreezeState", reflect.TypeOf((*MockRepository)(nil).UpdateUserFreezeState), ctx, userID, state)
+}
+
+// UpdateWalletBalance mocks base method.
+func (m *MockRepository) UpdateWalletBalance(walletID uint64, balance float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletBalance", walletID, balance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWalletBalance indicates an expected call of UpdateWalletBalance.
+func (mr *MockRepositoryMockRecorder) UpdateWalletBalance(walletID, balance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletBalance", reflect.TypeOf((*MockRepository)(nil).UpdateWalletBalance), walletID, balance)
+}
+
+// UpdateWalletFreezeState mocks base method.
+func (m *MockRepository) UpdateWalletFreezeState(ctx context.Context, walletID uint64, state string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWalletFreezeState", ctx, walletID, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWalletFreezeState indicates an expected call of UpdateWalletFreezeState.
+func (mr *MockRepositoryMockRecorder) UpdateWalletFreezeState(ctx, walletID, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWalletFreezeState", reflect.TypeOf((*MockRepository)(nil).UpdateWalletFreezeState), ctx, walletID, state)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockRepository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) UpdateWebhookDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).UpdateWebhookDelivery), ctx, delivery)
+}
+
+// UpdateWithdrawal mocks base method.
+func (m *MockRepository) UpdateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithdrawal", ctx, withdrawal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWithdrawal indicates an expected call of UpdateWithdrawal.
+func (mr *MockRepositoryMockRecorder) UpdateWithdrawal(ctx, withdrawal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithdrawal", reflect.TypeOf((*MockRepository)(nil).UpdateWithdrawal), ctx, withdrawal)
+}
+
+// UpsertCreditLine mocks base method.
+func (m *MockRepository) UpsertCreditLine(ctx context.Context, line *models.CreditLine) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCreditLine", ctx, line)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertCreditLine indicates an expected call of UpsertCreditLine.
+func (mr *MockRepositoryMockRecorder) UpsertCreditLine(ctx, line interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCreditLine", reflect.TypeOf((*MockRepository)(nil).UpsertCreditLine), ctx, line)
+}
+
+// UpsertNotificationPreference mocks base method.
+func (m *MockRepository) UpsertNotificationPreference(ctx context.Context, preference *models.NotificationPreference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertNotificationPreference", ctx, preference)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertNotificationPreference indicates an expected call of UpsertNotificationPreference.
+func (mr *MockRepositoryMockRecorder) UpsertNotificationPreference(ctx, preference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertNotificationPreference", reflect.TypeOf((*MockRepository)(nil).UpsertNotificationPreference), ctx, preference)
+}
+
+// WithinTransaction mocks base method.
+func (m *MockRepository) WithinTransaction(ctx context.Context, fn func(repository.Repository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MockRepositoryMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockRepository)(nil).WithinTransaction), ctx, fn)
+}
+
+// MockDBTX is a mock of DBTX interface.
+type MockDBTX struct {
+	ctrl     *gomock.Controller
+	recorder *MockDBTXMockRecorder
+}
+
+// MockDBTXMockRecorder is the mock recorder for MockDBTX.
+type MockDBTXMockRecorder struct {
+	mock *MockDBTX
+}
+
+// NewMockDBTX creates a new mock instance.
+func NewMockDBTX(ctrl *gomock.Controller) *MockDBTX {
+	mock := &MockDBTX{ctrl: ctrl}
+	mock.recorder = &MockDBTXMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDBTX) EXPECT() *MockDBTXMockRecorder {
+	return m.recorder
+}
+
+// Exec mocks base method.
+func (m *MockDBTX) Exec(query string, args ...any) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Exec", varargs...)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exec indicates an expected call of Exec.
+func (mr *MockDBTXMockRecorder) Exec(query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockDBTX)(nil).Exec), varargs...)
+}
+
+// ExecContext mocks base method.
+func (m *MockDBTX) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecContext", varargs...)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecContext indicates an expected call of ExecContext.
+func (mr *MockDBTXMockRecorder) ExecContext(ctx, query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecContext", reflect.TypeOf((*MockDBTX)(nil).ExecContext), varargs...)
+}
+
+// Query mocks base method.
+func (m *MockDBTX) Query(query string, args ...any) (*sql.Rows, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Query", varargs...)
+	ret0, _ := ret[0].(*sql.Rows)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockDBTXMockRecorder) Query(query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockDBTX)(nil).Query), varargs...)
+}
+
+// QueryContext mocks base method.
+func (m *MockDBTX) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryContext", varargs...)
+	ret0, _ := ret[0].(*sql.Rows)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryContext indicates an expected call of QueryContext.
+func (mr *MockDBTXMockRecorder) QueryContext(ctx, query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryContext", reflect.TypeOf((*MockDBTX)(nil).QueryContext), varargs...)
+}
+
+// QueryRow mocks base method.
+func (m *MockDBTX) QueryRow(query string, args ...any) *sql.Row {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryRow", varargs...)
+	ret0, _ := ret[0].(*sql.Row)
+	return ret0
+}
+
+// QueryRow indicates an expected call of QueryRow.
+func (mr *MockDBTXMockRecorder) QueryRow(query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRow", reflect.TypeOf((*MockDBTX)(nil).QueryRow), varargs...)
+}
+
+// QueryRowContext mocks base method.
+func (m *MockDBTX) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryRowContext", varargs...)
+	ret0, _ := ret[0].(*sql.Row)
+	return ret0
+}
+
+// QueryRowContext indicates an expected call of QueryRowContext.
+func (mr *MockDBTXMockRecorder) QueryRowContext(ctx, query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRowContext", reflect.TypeOf((*MockDBTX)(nil).QueryRowContext), varargs...)
 }
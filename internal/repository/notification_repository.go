@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+const notificationPreferenceColumns = "user_id, event_type, in_app, email, threshold"
+
+func scanNotificationPreference(row interface{ Scan(dest ...any) error }) (*models.NotificationPreference, error) {
+	preference := &models.NotificationPreference{}
+	err := row.Scan(
+		&preference.UserID,
+		&preference.EventType,
+		&preference.InApp,
+		&preference.Email,
+		&preference.Threshold,
+	)
+	return preference, err
+}
+
+const notificationColumns = "id, tenant_id, user_id, event_type, subject, body, in_app, email_status, email_attempts, email_error, next_attempt_at, read_at, created_at"
+
+func scanNotification(row interface{ Scan(dest ...any) error }, extra ...any) (*models.Notification, error) {
+	notification := &models.Notification{}
+	dest := []any{
+		&notification.ID,
+		&notification.TenantID,
+		&notification.UserID,
+		&notification.EventType,
+		&notification.Subject,
+		&notification.Body,
+		&notification.InApp,
+		&notification.EmailStatus,
+		&notification.EmailAttempts,
+		&notification.EmailError,
+		&notification.NextAttemptAt,
+		&notification.ReadAt,
+		&notification.CreatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return notification, err
+}
+
+// GetNotificationPreferences возвращает сохранённые настройки уведомлений пользователя.
+func (r *repo) GetNotificationPreferences(ctx context.Context, userID uint64) ([]*models.NotificationPreference, error) {
+	query := `SELECT ` + notificationPreferenceColumns + ` FROM notification_preferences WHERE user_id = $1 ORDER BY event_type`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var preferences []*models.NotificationPreference
+	for rows.Next() {
+		preference, err := scanNotificationPreference(rows)
+		if err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, preference)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// GetNotificationPreference получает настройки уведомлений пользователя о событиях типа eventType.
+// Возвращает nil, если пользователь их не менял.
+func (r *repo) GetNotificationPreference(ctx context.Context, userID uint64, eventType string) (*models.NotificationPreference, error) {
+	query := `SELECT ` + notificationPreferenceColumns + ` FROM notification_preferences WHERE user_id = $1 AND event_type = $2`
+	preference, err := scanNotificationPreference(r.db.QueryRowContext(ctx, query, userID, eventType))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching notification preference:", err)
+		return nil, err
+	}
+	return preference, nil
+}
+
+// UpsertNotificationPreference сохраняет настройки уведомлений пользователя о событиях одного типа.
+func (r *repo) UpsertNotificationPreference(ctx context.Context, preference *models.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, event_type, in_app, email, threshold)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, event_type)
+		DO UPDATE SET in_app = EXCLUDED.in_app, email = EXCLUDED.email, threshold = EXCLUDED.threshold, updated_at = NOW()`
+	_, err := r.db.ExecContext(ctx, query,
+		preference.UserID,
+		preference.EventType,
+		preference.InApp,
+		preference.Email,
+		preference.Threshold,
+	)
+	if err != nil {
+		r.logger.Error("Error saving notification preference:", err)
+		return err
+	}
+	return nil
+}
+
+// CreateNotification сохраняет уведомление.
+func (r *repo) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	query := `
+		INSERT INTO notifications (tenant_id, user_id, event_type, subject, body, in_app, email_status, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		notification.TenantID,
+		notification.UserID,
+		notification.EventType,
+		notification.Subject,
+		notification.Body,
+		notification.InApp,
+		notification.EmailStatus,
+		notification.NextAttemptAt,
+	).Scan(&notification.ID, &notification.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting notification:", err)
+		return err
+	}
+	return nil
+}
+
+// LockUserNotifications блокирует до конца транзакции создание уведомлений пользователя
+// о событиях типа eventType другими транзакциями, вызвавшими эту же блокировку.
+func (r *repo) LockUserNotifications(ctx context.Context, userID uint64, eventType string) error {
+	if _, err := r.db.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, userID, eventType); err != nil {
+		r.logger.Error("Error locking user notifications:", err)
+		return err
+	}
+	return nil
+}
+
+// GetLatestNotification получает последнее уведомление пользователя о событии типа eventType.
+// Возвращает nil, если таких уведомлений не было.
+func (r *repo) GetLatestNotification(ctx context.Context, userID uint64, eventType string) (*models.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = $1 AND event_type = $2 ORDER BY id DESC LIMIT 1`
+	notification, err := scanNotification(r.db.QueryRowContext(ctx, query, userID, eventType))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching latest notification:", err)
+		return nil, err
+	}
+	return notification, nil
+}
+
+// GetNotifications возвращает последние limit входящих уведомлений пользователя,
+// только непрочитанные, если unreadOnly.
+func (r *repo) GetNotifications(ctx context.Context, userID uint64, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + ` FROM notifications
+		WHERE user_id = $1 AND in_app AND (NOT $2 OR read_at IS NULL)
+		ORDER BY id DESC LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// CountUnreadNotifications возвращает количество непрочитанных входящих уведомлений пользователя.
+func (r *repo) CountUnreadNotifications(ctx context.Context, userID uint64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND in_app AND read_at IS NULL`
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		r.logger.Error("Error counting unread notifications:", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkNotificationsRead отмечает прочитанными входящие уведомления пользователя: одно с ID
+// notificationID или все, если notificationID равен 0. Возвращает количество найденных уведомлений.
+func (r *repo) MarkNotificationsRead(ctx context.Context, userID, notificationID uint64, readAt time.Time) (int, error) {
+	query := `
+		UPDATE notifications SET read_at = COALESCE(read_at, $3)
+		WHERE user_id = $1 AND in_app AND ($2 = 0 OR id = $2) AND ($2 <> 0 OR read_at IS NULL)`
+	result, err := r.db.ExecContext(ctx, query, userID, notificationID, readAt)
+	if err != nil {
+		r.logger.Error("Error marking notifications read:", err)
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
+
+// ClaimDueNotificationEmails выбирает до limit уведомлений, письма о которых пора отправить,
+// вместе с адресом пользователя и откладывает их следующую попытку на lease, чтобы другие
+// экземпляры сервиса не отправили те же письма.
+func (r *repo) ClaimDueNotificationEmails(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.Notification, error) {
+	query := `
+		UPDATE notifications n
+		SET next_attempt_at = $1
+		FROM users u
+		WHERE u.id = n.user_id AND n.id IN (
+			SELECT id FROM notifications
+			WHERE email_status = $2 AND next_attempt_at <= $3
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING n.id, n.tenant_id, n.user_id, n.event_type, n.subject, n.body, n.in_app, n.email_status,
+			n.email_attempts, n.email_error, n.next_attempt_at, n.read_at, n.created_at, u.email`
+	rows, err := r.db.QueryContext(ctx, query, now.Add(lease), models.NotificationEmailPending, now, limit)
+	if err != nil {
+		r.logger.Error("Error claiming notification emails:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		var email string
+		notification, err := scanNotification(rows, &email)
+		if err != nil {
+			return nil, err
+		}
+		notification.Email = email
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// UpdateNotificationEmail сохраняет статус, число попыток и время следующей попытки отправки письма.
+func (r *repo) UpdateNotificationEmail(ctx context.Context, notification *models.Notification) error {
+	query := `
+		UPDATE notifications
+		SET email_status = $1, email_attempts = $2, email_error = $3, next_attempt_at = $4
+		WHERE id = $5`
+	_, err := r.db.ExecContext(ctx, query,
+		notification.EmailStatus,
+		notification.EmailAttempts,
+		notification.EmailError,
+		notification.NextAttemptAt,
+		notification.ID,
+	)
+	if err != nil {
+		r.logger.Error("Error updating notification email:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// outboxRelayLockID - ключ advisory-блокировки, под которой публикуются события outbox.
+// Одновременно события публикует только один экземпляр сервиса, что сохраняет их порядок.
+const outboxRelayLockID = 0x6f7574626f78
+
+const outboxEventColumns = "id, tenant_id, wallet_id, sequence, event_type, payload, created_at, attempts, last_error, published_at"
+
+func scanOutboxEvent(row interface{ Scan(dest ...any) error }, extra ...any) (*models.OutboxEvent, error) {
+	event := &models.OutboxEvent{}
+	var payload []byte
+	dest := []any{
+		&event.ID,
+		&event.TenantID,
+		&event.WalletID,
+		&event.Sequence,
+		&event.Type,
+		&payload,
+		&event.CreatedAt,
+		&event.Attempts,
+		&event.LastError,
+		&event.PublishedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	event.Payload = payload
+	return event, err
+}
+
+// CreateOutboxEvent записывает событие кошелька в outbox и присваивает ему следующий номер
+// в последовательности кошелька. Кошелёк должен быть заблокирован до конца транзакции.
+func (r *repo) CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	query := `
+		INSERT INTO outbox_events (tenant_id, wallet_id, sequence, event_type, payload)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(sequence), 0) + 1 FROM outbox_events WHERE wallet_id = $2), $3, $4)
+		RETURNING id, sequence, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		event.TenantID,
+		event.WalletID,
+		event.Type,
+		[]byte(event.Payload),
+	).Scan(&event.ID, &event.Sequence, &event.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting outbox event:", err)
+		return err
+	}
+	return nil
+}
+
+// LockOutboxRelay пытается захватить блокировку публикации outbox до конца транзакции.
+// Возвращает false, если события уже публикует другой экземпляр.
+func (r *repo) LockOutboxRelay(ctx context.Context) (bool, error) {
+	var locked bool
+	if err := r.db.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxRelayLockID).Scan(&locked); err != nil {
+		r.logger.Error("Error locking outbox relay:", err)
+		return false, err
+	}
+	return locked, nil
+}
+
+// GetPendingOutboxEvents возвращает до limit неопубликованных событий в порядке записи.
+func (r *repo) GetPendingOutboxEvents(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	query := `SELECT ` + outboxEventColumns + ` FROM outbox_events WHERE published_at IS NULL ORDER BY id LIMIT $1`
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.OutboxEvent
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// MarkOutboxEventPublished отмечает событие опубликованным.
+func (r *repo) MarkOutboxEventPublished(ctx context.Context, eventID uint64, publishedAt time.Time) error {
+	query := `UPDATE outbox_events SET attempts = attempts + 1, last_error = '', published_at = $2 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, eventID, publishedAt); err != nil {
+		r.logger.Error("Error marking outbox event published:", err)
+		return err
+	}
+	return nil
+}
+
+// MarkOutboxEventFailed сохраняет ошибку неудачной попытки публикации события.
+func (r *repo) MarkOutboxEventFailed(ctx context.Context, eventID uint64, reason string) error {
+	query := `UPDATE outbox_events SET attempts = attempts + 1, last_error = $2 WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, eventID, reason); err != nil {
+		r.logger.Error("Error marking outbox event failed:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+const paymentRequestColumns = `
+	id, requester_id, payer_id, amount, currency, description, status, paid_currency,
+	paid_amount, rate, operation_id, expires_at, resolved_at, created_at, updated_at`
+
+func scanPaymentRequest(row interface{ Scan(dest ...any) error }) (*models.PaymentRequest, error) {
+	request := &models.PaymentRequest{}
+	err := row.Scan(
+		&request.ID,
+		&request.RequesterID,
+		&request.PayerID,
+		&request.Amount,
+		&request.Currency,
+		&request.Description,
+		&request.Status,
+		&request.PaidCurrency,
+		&request.PaidAmount,
+		&request.Rate,
+		&request.OperationID,
+		&request.ExpiresAt,
+		&request.ResolvedAt,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+	)
+	return request, err
+}
+
+// CreatePaymentRequest сохраняет новый запрос на оплату.
+func (r *repo) CreatePaymentRequest(ctx context.Context, request *models.PaymentRequest) error {
+	query := `
+		INSERT INTO payment_requests (requester_id, payer_id, amount, currency, description, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`
+	err := r.db.QueryRowContext(ctx, query,
+		request.RequesterID,
+		request.PayerID,
+		request.Amount,
+		request.Currency,
+		request.Description,
+		request.Status,
+		request.ExpiresAt,
+	).Scan(&request.ID, &request.CreatedAt, &request.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting payment request:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repo) getPaymentRequest(ctx context.Context, requestID uint64, lock string) (*models.PaymentRequest, error) {
+	query := `SELECT ` + paymentRequestColumns + ` FROM payment_requests WHERE id = $1` + lock
+	request, err := scanPaymentRequest(r.db.QueryRowContext(ctx, query, requestID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching payment request:", err)
+		return nil, err
+	}
+	return request, nil
+}
+
+// GetPaymentRequestByID получает запрос на оплату по ID. Возвращает nil, если запрос не найден.
+func (r *repo) GetPaymentRequestByID(ctx context.Context, requestID uint64) (*models.PaymentRequest, error) {
+	return r.getPaymentRequest(ctx, requestID, "")
+}
+
+// GetPaymentRequestByIDForUpdate получает запрос на оплату по ID и блокирует строку до конца транзакции.
+// Возвращает nil, если запрос не найден.
+func (r *repo) GetPaymentRequestByIDForUpdate(ctx context.Context, requestID uint64) (*models.PaymentRequest, error) {
+	return r.getPaymentRequest(ctx, requestID, " FOR UPDATE")
+}
+
+// GetPaymentRequestsByUserID получает входящие (пользователь - плательщик) или исходящие
+// запросы на оплату пользователя, начиная с последних.
+func (r *repo) GetPaymentRequestsByUserID(ctx context.Context, userID uint64, direction string) ([]*models.PaymentRequest, error) {
+	column := "payer_id"
+	if direction == models.PaymentRequestDirectionOutgoing {
+		column = "requester_id"
+	}
+	query := `SELECT ` + paymentRequestColumns + `
+		FROM payment_requests
+		WHERE ` + column + ` = $1
+		ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*models.PaymentRequest
+	for rows.Next() {
+		request, err := scanPaymentRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// UpdatePaymentRequest сохраняет статус и результат оплаты запроса.
+func (r *repo) UpdatePaymentRequest(ctx context.Context, request *models.PaymentRequest) error {
+	query := `
+		UPDATE payment_requests
+		SET status = $1, paid_currency = $2, paid_amount = $3, rate = $4, operation_id = $5,
+			resolved_at = $6, updated_at = NOW()
+		WHERE id = $7`
+	_, err := r.db.ExecContext(ctx, query,
+		request.Status,
+		request.PaidCurrency,
+		request.PaidAmount,
+		request.Rate,
+		request.OperationID,
+		request.ResolvedAt,
+		request.ID,
+	)
+	if err != nil {
+		r.logger.Error("Error updating payment request:", err)
+		return err
+	}
+	return nil
+}
+
+// ExpirePaymentRequests переводит просроченные запросы на оплату в статус expired.
+func (r *repo) ExpirePaymentRequests(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE payment_requests
+		SET status = $1, resolved_at = $2, updated_at = NOW()
+		WHERE status = $3 AND expires_at <= $2`
+	result, err := r.db.ExecContext(ctx, query, models.PaymentRequestStatusExpired, now, models.PaymentRequestStatusPending)
+	if err != nil {
+		r.logger.Error("Error expiring payment requests:", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const potColumns = `
+	p.id, p.wallet_id, w.user_id, w.currency, p.name, p.goal_amount, p.goal_date,
+	p.balance, p.status, p.created_at, p.updated_at, p.closed_at`
+
+func scanPot(row interface{ Scan(dest ...any) error }) (*models.Pot, error) {
+	pot := &models.Pot{}
+	err := row.Scan(
+		&pot.ID,
+		&pot.WalletID,
+		&pot.UserID,
+		&pot.Currency,
+		&pot.Name,
+		&pot.GoalAmount,
+		&pot.GoalDate,
+		&pot.Balance,
+		&pot.Status,
+		&pot.CreatedAt,
+		&pot.UpdatedAt,
+		&pot.ClosedAt,
+	)
+	return pot, err
+}
+
+// CreatePot создаёт копилку и заполняет её ID и время создания.
+func (r *repo) CreatePot(ctx context.Context, pot *models.Pot) error {
+	query := `
+		INSERT INTO pots (wallet_id, name, goal_amount, goal_date, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at`
+	err := r.db.QueryRowContext(ctx, query,
+		pot.WalletID,
+		pot.Name,
+		pot.GoalAmount,
+		pot.GoalDate,
+		pot.Status,
+	).Scan(&pot.ID, &pot.CreatedAt, &pot.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting pot:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repo) getPot(ctx context.Context, potID uint64, lock string) (*models.Pot, error) {
+	query := `SELECT ` + potColumns + `
+		FROM pots p
+		JOIN wallets w ON w.id = p.wallet_id
+		WHERE p.id = $1 AND ($2 = 0 OR w.tenant_id = $2)` + lock
+	pot, err := scanPot(r.db.QueryRowContext(ctx, query, potID, tenant.Filter(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching pot:", err)
+		return nil, err
+	}
+	return pot, nil
+}
+
+// GetPotByID получает копилку по ID. Возвращает nil, если копилка не найдена.
+func (r *repo) GetPotByID(ctx context.Context, potID uint64) (*models.Pot, error) {
+	return r.getPot(ctx, potID, "")
+}
+
+// GetPotByIDForUpdate получает копилку по ID и блокирует строку до конца транзакции.
+// Возвращает nil, если копилка не найдена.
+func (r *repo) GetPotByIDForUpdate(ctx context.Context, potID uint64) (*models.Pot, error) {
+	return r.getPot(ctx, potID, " FOR UPDATE OF p")
+}
+
+// GetPotsByUserID возвращает копилки пользователя; onlyActive исключает закрытые.
+func (r *repo) GetPotsByUserID(ctx context.Context, userID uint64, onlyActive bool) ([]*models.Pot, error) {
+	query := `SELECT ` + potColumns + `
+		FROM pots p
+		JOIN wallets w ON w.id = p.wallet_id
+		WHERE w.user_id = $1 AND (NOT $2 OR p.status = $3)
+		ORDER BY p.id`
+	rows, err := r.db.QueryContext(ctx, query, userID, onlyActive, models.PotStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pots []*models.Pot
+	for rows.Next() {
+		pot, err := scanPot(rows)
+		if err != nil {
+			return nil, err
+		}
+		pots = append(pots, pot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pots, nil
+}
+
+// GetPotsByWalletID получает все копилки кошелька, включая закрытые.
+func (r *repo) GetPotsByWalletID(ctx context.Context, walletID uint64) ([]*models.Pot, error) {
+	query := `SELECT ` + potColumns + `
+		FROM pots p
+		JOIN wallets w ON w.id = p.wallet_id
+		WHERE p.wallet_id = $1
+		ORDER BY p.id`
+	rows, err := r.db.QueryContext(ctx, query, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pots []*models.Pot
+	for rows.Next() {
+		pot, err := scanPot(rows)
+		if err != nil {
+			return nil, err
+		}
+		pots = append(pots, pot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pots, nil
+}
+
+// GetPottedAmount возвращает сумму средств в действующих копилках кошелька.
+func (r *repo) GetPottedAmount(ctx context.Context, walletID uint64) (float64, error) {
+	query := `SELECT COALESCE(SUM(balance), 0) FROM pots WHERE wallet_id = $1 AND status = $2`
+	var amount float64
+	if err := r.db.QueryRowContext(ctx, query, walletID, models.PotStatusActive).Scan(&amount); err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+// UpdatePot сохраняет баланс и статус копилки.
+func (r *repo) UpdatePot(ctx context.Context, pot *models.Pot) error {
+	query := `
+		UPDATE pots
+		SET balance = $1, status = $2, closed_at = $3, updated_at = NOW()
+		WHERE id = $4`
+	_, err := r.db.ExecContext(ctx, query, pot.Balance, pot.Status, pot.ClosedAt, pot.ID)
+	return err
+}
+
+// CreatePotMovement записывает перемещение средств копилки.
+func (r *repo) CreatePotMovement(ctx context.Context, movement *models.PotMovement) error {
+	query := `
+		INSERT INTO pot_movements (pot_id, amount, balance_after)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+	return r.db.QueryRowContext(ctx, query,
+		movement.PotID,
+		movement.Amount,
+		movement.BalanceAfter,
+	).Scan(&movement.ID, &movement.CreatedAt)
+}
+
+// GetPotMovements возвращает историю перемещений копилки в хронологическом порядке.
+func (r *repo) GetPotMovements(ctx context.Context, potID uint64) ([]*models.PotMovement, error) {
+	query := `
+		SELECT id, pot_id, amount, balance_after, created_at
+		FROM pot_movements
+		WHERE pot_id = $1
+		ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, potID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []*models.PotMovement
+	for rows.Next() {
+		movement := &models.PotMovement{}
+		if err := rows.Scan(
+			&movement.ID,
+			&movement.PotID,
+			&movement.Amount,
+			&movement.BalanceAfter,
+			&movement.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const providerDepositColumns = `
+	id, tenant_id, provider, event_id, reference, amount, currency, status, user_id, wallet_id,
+	operation_id, reason, created_at, updated_at`
+
+func scanProviderDeposit(row interface{ Scan(dest ...any) error }) (*models.ProviderDeposit, error) {
+	deposit := &models.ProviderDeposit{}
+	err := row.Scan(
+		&deposit.ID,
+		&deposit.TenantID,
+		&deposit.Provider,
+		&deposit.EventID,
+		&deposit.Reference,
+		&deposit.Amount,
+		&deposit.Currency,
+		&deposit.Status,
+		&deposit.UserID,
+		&deposit.WalletID,
+		&deposit.OperationID,
+		&deposit.Reason,
+		&deposit.CreatedAt,
+		&deposit.UpdatedAt,
+	)
+	return deposit, err
+}
+
+// CreateProviderDeposit сохраняет уведомление провайдера о пополнении. Возвращает false, если
+// событие с тем же provider и event_id уже сохранено: повторная доставка не создаёт новую запись.
+// Параллельная вставка того же события ожидает завершения первой транзакции.
+func (r *repo) CreateProviderDeposit(ctx context.Context, deposit *models.ProviderDeposit) (bool, error) {
+	query := `
+		INSERT INTO provider_deposits (tenant_id, provider, event_id, reference, amount, currency, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (provider, event_id) DO NOTHING
+		RETURNING id, created_at, updated_at`
+	err := r.db.QueryRowContext(ctx, query,
+		deposit.TenantID,
+		deposit.Provider,
+		deposit.EventID,
+		deposit.Reference,
+		deposit.Amount,
+		deposit.Currency,
+		deposit.Status,
+	).Scan(&deposit.ID, &deposit.CreatedAt, &deposit.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		r.logger.Error("Error inserting provider deposit:", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// GetProviderDeposit получает пополнение по провайдеру и ID события. Возвращает nil, если оно не найдено.
+func (r *repo) GetProviderDeposit(ctx context.Context, provider, eventID string) (*models.ProviderDeposit, error) {
+	query := `SELECT ` + providerDepositColumns + ` FROM provider_deposits WHERE provider = $1 AND event_id = $2`
+	deposit, err := scanProviderDeposit(r.db.QueryRowContext(ctx, query, provider, eventID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching provider deposit:", err)
+		return nil, err
+	}
+	return deposit, nil
+}
+
+// GetProviderDeposits получает последние limit пополнений от провайдеров, при непустом status - только в этом статусе.
+// В контексте тенанта возвращаются только его пополнения.
+func (r *repo) GetProviderDeposits(ctx context.Context, status string, limit int) ([]*models.ProviderDeposit, error) {
+	query := `SELECT ` + providerDepositColumns + `
+		FROM provider_deposits
+		WHERE ($1 = '' OR status = $1) AND ($3 = 0 OR tenant_id = $3)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, status, limit, tenant.Filter(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deposits []*models.ProviderDeposit
+	for rows.Next() {
+		deposit, err := scanProviderDeposit(rows)
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, deposit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deposits, nil
+}
+
+// UpdateProviderDeposit сохраняет результат обработки пополнения.
+func (r *repo) UpdateProviderDeposit(ctx context.Context, deposit *models.ProviderDeposit) error {
+	query := `
+		UPDATE provider_deposits
+		SET status = $1, user_id = $2, wallet_id = $3, operation_id = $4, reason = $5, updated_at = NOW()
+		WHERE id = $6`
+	_, err := r.db.ExecContext(ctx, query,
+		deposit.Status,
+		deposit.UserID,
+		deposit.WalletID,
+		deposit.OperationID,
+		deposit.Reason,
+		deposit.ID,
+	)
+	if err != nil {
+		r.logger.Error("Error updating provider deposit:", err)
+		return err
+	}
+	return nil
+}
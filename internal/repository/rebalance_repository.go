@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// CreateRebalancePlan сохраняет план ребалансировки вместе с его обменами.
+func (r *repo) CreateRebalancePlan(ctx context.Context, plan *models.RebalancePlan) error {
+	query := `
+		INSERT INTO rebalance_plans (user_id, base, total, status, rates_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		plan.UserID,
+		plan.Base,
+		plan.Total,
+		plan.Status,
+		plan.RatesAt,
+		plan.ExpiresAt,
+	).Scan(&plan.ID, &plan.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting rebalance plan:", err)
+		return err
+	}
+
+	legQuery := `
+		INSERT INTO rebalance_legs (plan_id, from_currency, to_currency, amount, rate, exchanged_amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+	for _, leg := range plan.Legs {
+		leg.PlanID = plan.ID
+		err := r.db.QueryRowContext(ctx, legQuery,
+			leg.PlanID,
+			leg.FromCurrency,
+			leg.ToCurrency,
+			leg.Amount,
+			leg.Rate,
+			leg.ExchangedAmount,
+		).Scan(&leg.ID)
+		if err != nil {
+			r.logger.Error("Error inserting rebalance leg:", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRebalancePlanByIDForUpdate получает план ребалансировки с его обменами и блокирует строку плана
+// до конца транзакции. Возвращает nil, если план не найден.
+func (r *repo) GetRebalancePlanByIDForUpdate(ctx context.Context, planID uint64) (*models.RebalancePlan, error) {
+	query := `
+		SELECT id, user_id, base, total, status, operation_id, rates_at, expires_at, executed_at, created_at
+		FROM rebalance_plans
+		WHERE id = $1
+		FOR UPDATE`
+	plan := &models.RebalancePlan{}
+	err := r.db.QueryRowContext(ctx, query, planID).Scan(
+		&plan.ID,
+		&plan.UserID,
+		&plan.Base,
+		&plan.Total,
+		&plan.Status,
+		&plan.OperationID,
+		&plan.RatesAt,
+		&plan.ExpiresAt,
+		&plan.ExecutedAt,
+		&plan.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching rebalance plan:", err)
+		return nil, err
+	}
+
+	legQuery := `
+		SELECT id, plan_id, from_currency, to_currency, amount, rate, exchanged_amount
+		FROM rebalance_legs
+		WHERE plan_id = $1
+		ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, legQuery, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		leg := &models.RebalanceLeg{}
+		if err := rows.Scan(&leg.ID, &leg.PlanID, &leg.FromCurrency, &leg.ToCurrency, &leg.Amount, &leg.Rate, &leg.ExchangedAmount); err != nil {
+			return nil, err
+		}
+		plan.Legs = append(plan.Legs, leg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// UpdateRebalancePlan сохраняет статус и результат исполнения плана ребалансировки.
+func (r *repo) UpdateRebalancePlan(ctx context.Context, plan *models.RebalancePlan) error {
+	query := `
+		UPDATE rebalance_plans
+		SET status = $1, operation_id = $2, executed_at = $3
+		WHERE id = $4`
+	_, err := r.db.ExecContext(ctx, query, plan.Status, plan.OperationID, plan.ExecutedAt, plan.ID)
+	if err != nil {
+		r.logger.Error("Error updating rebalance plan:", err)
+		return err
+	}
+	return nil
+}
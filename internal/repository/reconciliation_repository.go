@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const reconciliationRunColumns = `id, snapshot_date, wallets_checked, mismatches, created_at`
+
+func scanReconciliationRun(row interface{ Scan(dest ...any) error }) (*models.ReconciliationRun, error) {
+	run := &models.ReconciliationRun{}
+	err := row.Scan(&run.ID, &run.SnapshotDate, &run.WalletsChecked, &run.Mismatches, &run.CreatedAt)
+	return run, err
+}
+
+// CreateReconciliationRun создаёт сверку за дату date. Возвращает nil, если сверка за эту дату уже есть.
+func (r *repo) CreateReconciliationRun(ctx context.Context, date time.Time) (*models.ReconciliationRun, error) {
+	query := `
+		INSERT INTO reconciliation_runs (snapshot_date)
+		VALUES ($1)
+		ON CONFLICT (snapshot_date) DO NOTHING
+		RETURNING ` + reconciliationRunColumns
+	run, err := scanReconciliationRun(r.db.QueryRowContext(ctx, query, date))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error creating reconciliation run:", err)
+		return nil, err
+	}
+	return run, nil
+}
+
+// SnapshotBalances сохраняет снимки балансов всех кошельков вместе с балансами, пересчитанными
+// по журналу транзакций, и обновляет итоги сверки. Снимок делается одним запросом,
+// поэтому балансы и журнал согласованы на момент его начала.
+func (r *repo) SnapshotBalances(ctx context.Context, run *models.ReconciliationRun) error {
+	query := `
+		WITH computed AS (
+			SELECT w.id AS wallet_id, w.balance, COALESCE(SUM(t.amount), 0) AS computed_balance
+			FROM wallets w
+			LEFT JOIN transactions t ON t.wallet_id = w.id
+			GROUP BY w.id, w.balance
+		), inserted AS (
+			INSERT INTO balance_snapshots (run_id, wallet_id, snapshot_date, balance, computed_balance, difference, status)
+			SELECT $1, wallet_id, $2, balance, computed_balance, balance - computed_balance,
+				CASE WHEN balance = computed_balance THEN $3 ELSE $4 END
+			FROM computed
+			RETURNING status
+		)
+		UPDATE reconciliation_runs
+		SET wallets_checked = (SELECT COUNT(*) FROM inserted),
+			mismatches = (SELECT COUNT(*) FROM inserted WHERE status = $4)
+		WHERE id = $1
+		RETURNING wallets_checked, mismatches`
+	err := r.db.QueryRowContext(ctx, query,
+		run.ID,
+		run.SnapshotDate,
+		models.SnapshotStatusMatched,
+		models.SnapshotStatusMismatch,
+	).Scan(&run.WalletsChecked, &run.Mismatches)
+	if err != nil {
+		r.logger.Error("Error snapshotting balances:", err)
+		return err
+	}
+	return nil
+}
+
+// GetReconciliationRuns получает последние limit сверок, начиная с самой свежей.
+func (r *repo) GetReconciliationRuns(ctx context.Context, limit int) ([]*models.ReconciliationRun, error) {
+	query := `SELECT ` + reconciliationRunColumns + ` FROM reconciliation_runs ORDER BY snapshot_date DESC LIMIT $1`
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*models.ReconciliationRun
+	for rows.Next() {
+		run, err := scanReconciliationRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetReconciliationRunByID получает сверку по ID. Возвращает nil, если сверка не найдена.
+func (r *repo) GetReconciliationRunByID(ctx context.Context, runID uint64) (*models.ReconciliationRun, error) {
+	query := `SELECT ` + reconciliationRunColumns + ` FROM reconciliation_runs WHERE id = $1`
+	run, err := scanReconciliationRun(r.db.QueryRowContext(ctx, query, runID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching reconciliation run:", err)
+		return nil, err
+	}
+	return run, nil
+}
+
+// GetBalanceSnapshots получает снимки балансов сверки; если onlyMismatches - только расхождения.
+// В контексте тенанта возвращаются только снимки его кошельков.
+func (r *repo) GetBalanceSnapshots(ctx context.Context, runID uint64, onlyMismatches bool) ([]*models.BalanceSnapshot, error) {
+	query := `
+		SELECT s.id, s.run_id, s.wallet_id, w.user_id, w.currency, s.snapshot_date,
+			s.balance, s.computed_balance, s.difference, s.status, s.created_at
+		FROM balance_snapshots s
+		JOIN wallets w ON w.id = s.wallet_id
+		WHERE s.run_id = $1 AND (NOT $2 OR s.status = $3) AND ($4 = 0 OR w.tenant_id = $4)
+		ORDER BY s.wallet_id`
+	rows, err := r.db.QueryContext(ctx, query, runID, onlyMismatches, models.SnapshotStatusMismatch, tenant.Filter(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []*models.BalanceSnapshot
+	for rows.Next() {
+		snapshot := &models.BalanceSnapshot{}
+		if err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.RunID,
+			&snapshot.WalletID,
+			&snapshot.UserID,
+			&snapshot.Currency,
+			&snapshot.SnapshotDate,
+			&snapshot.Balance,
+			&snapshot.ComputedBalance,
+			&snapshot.Difference,
+			&snapshot.Status,
+			&snapshot.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
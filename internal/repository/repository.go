@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
 	"github.com/sirupsen/logrus"
@@ -10,17 +11,207 @@ import (
 
 // Repository определяет интерфейс для работы с хранилищем данных.
 type Repository interface {
+	// WithinTransaction выполняет fn в рамках одной транзакции БД.
+	// Репозиторий, переданный в fn, работает внутри этой транзакции.
+	WithinTransaction(ctx context.Context, fn func(repo Repository) error) error
+
 	// User methods
 	CreateUser(user *models.User) (int64, error)
-	GetUserByID(userID uint64) (*models.User, error)
-	GetUserByUsername(username string) (*models.User, error)
+	GetUserByID(ctx context.Context, userID uint64) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	GetUserByDepositReference(ctx context.Context, reference string) (*models.User, error)
+	UpdateUserFreezeState(ctx context.Context, userID uint64, state string) error
+
+	// Tenant methods
+	GetTenants(ctx context.Context) ([]*models.Tenant, error)
+	GetTenantBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	GetTenantByHost(ctx context.Context, host string) (*models.Tenant, error)
 
 	// Wallet methods
 	CreateWallet(wallet *models.Wallet) (int, error)
-	GetWalletByID(walletID uint64) (*models.Wallet, error)
+	GetWalletByID(ctx context.Context, walletID uint64) (*models.Wallet, error)
 	UpdateWalletBalance(walletID uint64, balance float64) error
 	GetWalletsByUserID(userID uint64) ([]*models.Wallet, error)
 	GetWalletByUserAndCurrency(userID uint64, currency string) (*models.Wallet, error)
+	GetWalletByIDForUpdate(ctx context.Context, walletID uint64) (*models.Wallet, error)
+	GetWalletByUserAndCurrencyForUpdate(ctx context.Context, userID uint64, currency string) (*models.Wallet, error)
+	UpdateWalletFreezeState(ctx context.Context, walletID uint64, state string) error
+
+	// Wallet member methods
+	CreateWalletMember(ctx context.Context, member *models.WalletMember) error
+	GetWalletMember(ctx context.Context, walletID, userID uint64) (*models.WalletMember, error)
+	GetWalletMembers(ctx context.Context, walletID uint64) ([]*models.WalletMember, error)
+	GetWalletMembershipsByUserID(ctx context.Context, userID uint64, status string) ([]*models.WalletMember, error)
+	ActivateWalletMember(ctx context.Context, walletID, userID uint64) error
+	DeleteWalletMember(ctx context.Context, walletID, userID uint64) error
+
+	// Credit line methods
+	GetCreditLine(ctx context.Context, walletID uint64) (*models.CreditLine, error)
+	GetCreditLineForUpdate(ctx context.Context, walletID uint64) (*models.CreditLine, error)
+	GetCreditLimitsByUserID(ctx context.Context, userID uint64) (map[string]float64, error)
+	UpsertCreditLine(ctx context.Context, line *models.CreditLine) error
+	DeleteCreditLine(ctx context.Context, walletID uint64) error
+	GetCreditLinesDueForAccrual(ctx context.Context, today time.Time, limit int) ([]uint64, error)
+	UpdateCreditLineAccrual(ctx context.Context, walletID uint64, accruedOn time.Time) error
+
+	// Interest methods
+	GetWalletsDueForInterest(ctx context.Context, day time.Time, limit int) ([]*models.InterestDue, error)
+	CreateInterestAccrual(ctx context.Context, accrual *models.InterestAccrual) (bool, error)
+	GetWalletsWithUnpaidInterest(ctx context.Context, before time.Time, limit int) ([]uint64, error)
+	PayInterestAccruals(ctx context.Context, walletID uint64, before time.Time, operationID string) (float64, error)
+	GetUnpaidInterestByUserID(ctx context.Context, userID uint64) ([]*models.InterestSummary, error)
+
+	// Pot methods
+	CreatePot(ctx context.Context, pot *models.Pot) error
+	GetPotByID(ctx context.Context, potID uint64) (*models.Pot, error)
+	GetPotByIDForUpdate(ctx context.Context, potID uint64) (*models.Pot, error)
+	GetPotsByUserID(ctx context.Context, userID uint64, onlyActive bool) ([]*models.Pot, error)
+	GetPotsByWalletID(ctx context.Context, walletID uint64) ([]*models.Pot, error)
+	GetPottedAmount(ctx context.Context, walletID uint64) (float64, error)
+	UpdatePot(ctx context.Context, pot *models.Pot) error
+	CreatePotMovement(ctx context.Context, movement *models.PotMovement) error
+	GetPotMovements(ctx context.Context, potID uint64) ([]*models.PotMovement, error)
+
+	// Freeze audit methods
+	CreateFreezeEvent(ctx context.Context, event *models.FreezeEvent) error
+	GetFreezeEvents(ctx context.Context, targetType string, targetID uint64) ([]*models.FreezeEvent, error)
+
+	// Currency methods
+	GetCurrencies(ctx context.Context, onlyEnabled bool) ([]*models.Currency, error)
+	GetCurrencyByCode(ctx context.Context, code string) (*models.Currency, error)
+	CreateCurrency(ctx context.Context, currency *models.Currency) error
+	UpdateCurrency(ctx context.Context, currency *models.Currency) error
+
+	// Transaction journal methods
+	CreateTransaction(ctx context.Context, transaction *models.Transaction) error
+	GetTransactionByID(ctx context.Context, transactionID uint64) (*models.Transaction, error)
+	GetTransactionsByOperationID(ctx context.Context, operationID string) ([]*models.Transaction, error)
+	GetBalanceAt(ctx context.Context, walletID uint64, at time.Time) (float64, error)
+	StreamTransactions(ctx context.Context, walletID uint64, from, to time.Time, fn func(transaction *models.Transaction) error) error
+	GetLastTransactionHash(ctx context.Context, walletID uint64) (string, error)
+	GetMemberDebits(ctx context.Context, walletID, userID uint64, since time.Time) (float64, error)
+	StreamJournal(ctx context.Context, fn func(transaction *models.Transaction) error) error
+
+	// Journal checkpoint methods
+	GetJournalHeads(ctx context.Context, before time.Time) (uint64, map[uint64]string, error)
+	CreateJournalCheckpoint(ctx context.Context, checkpoint *models.JournalCheckpoint) error
+	GetJournalCheckpoints(ctx context.Context) ([]*models.JournalCheckpoint, error)
+
+	// Reconciliation methods
+	CreateReconciliationRun(ctx context.Context, date time.Time) (*models.ReconciliationRun, error)
+	SnapshotBalances(ctx context.Context, run *models.ReconciliationRun) error
+	GetReconciliationRuns(ctx context.Context, limit int) ([]*models.ReconciliationRun, error)
+	GetReconciliationRunByID(ctx context.Context, runID uint64) (*models.ReconciliationRun, error)
+	GetBalanceSnapshots(ctx context.Context, runID uint64, onlyMismatches bool) ([]*models.BalanceSnapshot, error)
+
+	// Limit order methods
+	CreateLimitOrder(ctx context.Context, order *models.LimitOrder) (uint64, error)
+	GetLimitOrderByIDForUpdate(ctx context.Context, orderID uint64) (*models.LimitOrder, error)
+	GetLimitOrdersByUserID(ctx context.Context, userID uint64) ([]*models.LimitOrder, error)
+	GetOpenLimitOrders(ctx context.Context, now time.Time, limit int) ([]*models.LimitOrder, error)
+	UpdateLimitOrder(ctx context.Context, order *models.LimitOrder) error
+	ExpireLimitOrders(ctx context.Context, now time.Time) (int64, error)
+
+	// Rebalance methods
+	CreateRebalancePlan(ctx context.Context, plan *models.RebalancePlan) error
+	GetRebalancePlanByIDForUpdate(ctx context.Context, planID uint64) (*models.RebalancePlan, error)
+	UpdateRebalancePlan(ctx context.Context, plan *models.RebalancePlan) error
+
+	// Payment request methods
+	CreatePaymentRequest(ctx context.Context, request *models.PaymentRequest) error
+	GetPaymentRequestByID(ctx context.Context, requestID uint64) (*models.PaymentRequest, error)
+	GetPaymentRequestByIDForUpdate(ctx context.Context, requestID uint64) (*models.PaymentRequest, error)
+	GetPaymentRequestsByUserID(ctx context.Context, userID uint64, direction string) ([]*models.PaymentRequest, error)
+	UpdatePaymentRequest(ctx context.Context, request *models.PaymentRequest) error
+	ExpirePaymentRequests(ctx context.Context, now time.Time) (int64, error)
+
+	// Provider deposit methods
+	CreateProviderDeposit(ctx context.Context, deposit *models.ProviderDeposit) (bool, error)
+	GetProviderDeposit(ctx context.Context, provider, eventID string) (*models.ProviderDeposit, error)
+	GetProviderDeposits(ctx context.Context, status string, limit int) ([]*models.ProviderDeposit, error)
+	UpdateProviderDeposit(ctx context.Context, deposit *models.ProviderDeposit) error
+
+	// Withdrawal methods
+	CreateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error
+	GetWithdrawalByID(ctx context.Context, withdrawalID uint64) (*models.Withdrawal, error)
+	GetWithdrawalByIDForUpdate(ctx context.Context, withdrawalID uint64) (*models.Withdrawal, error)
+	GetWithdrawalsByUserID(ctx context.Context, userID uint64) ([]*models.Withdrawal, error)
+	GetWithdrawalsByStatus(ctx context.Context, status string, since time.Time, limit int) ([]*models.Withdrawal, error)
+	UpdateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error
+	CreateWithdrawalEvent(ctx context.Context, event *models.WithdrawalEvent) error
+	GetWithdrawalEvents(ctx context.Context, withdrawalID uint64) ([]*models.WithdrawalEvent, error)
+
+	// Voucher methods
+	CreateVoucherBatch(ctx context.Context, batch *models.VoucherBatch) (bool, error)
+	GetVoucherBatches(ctx context.Context, limit int) ([]*models.VoucherBatch, error)
+	GetVoucherBatchByID(ctx context.Context, batchID uint64) (*models.VoucherBatch, error)
+	GetVouchersByBatchID(ctx context.Context, batchID uint64) ([]*models.Voucher, error)
+	GetVoucherByCodeForUpdate(ctx context.Context, code string) (*models.Voucher, error)
+	CreateVoucherRedemption(ctx context.Context, redemption *models.VoucherRedemption) (bool, error)
+
+	// Reversal methods
+	CreateReversal(ctx context.Context, reversal *models.Reversal) error
+	GetReversalByOperationID(ctx context.Context, operationID string) (*models.Reversal, error)
+
+	// Hold methods
+	CreateHold(ctx context.Context, hold *models.Hold) (uint64, error)
+	GetHoldByID(ctx context.Context, holdID uint64) (*models.Hold, error)
+	GetHoldByIDForUpdate(ctx context.Context, holdID uint64) (*models.Hold, error)
+	GetHoldsByUserID(ctx context.Context, userID uint64) ([]*models.Hold, error)
+	GetHeldAmount(ctx context.Context, walletID uint64) (float64, error)
+	GetMemberHeldAmount(ctx context.Context, walletID, userID uint64) (float64, error)
+	GetHeldAmountsByUserID(ctx context.Context, userID uint64) (map[string]float64, error)
+	UpdateHold(ctx context.Context, hold *models.Hold) error
+	ExpireHolds(ctx context.Context, now time.Time) (int64, error)
+
+	// Scheduled transfer methods
+	CreateScheduledTransfer(ctx context.Context, schedule *models.ScheduledTransfer) (uint64, error)
+	GetScheduledTransferByID(ctx context.Context, scheduleID uint64) (*models.ScheduledTransfer, error)
+	GetScheduledTransfersByUserID(ctx context.Context, userID uint64) ([]*models.ScheduledTransfer, error)
+	UpdateScheduledTransferStatus(ctx context.Context, scheduleID uint64, status string, nextRunAt *time.Time) error
+	ClaimDueScheduledTransfers(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.ScheduledTransfer, error)
+	CompleteScheduledTransferRun(ctx context.Context, schedule *models.ScheduledTransfer) error
+	CreateScheduleExecution(ctx context.Context, execution *models.ScheduleExecution) (bool, error)
+	GetScheduleExecutions(ctx context.Context, scheduleID uint64) ([]*models.ScheduleExecution, error)
+
+	// Outbox methods
+	CreateOutboxEvent(ctx context.Context, event *models.OutboxEvent) error
+	LockOutboxRelay(ctx context.Context) (bool, error)
+	GetPendingOutboxEvents(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+	MarkOutboxEventPublished(ctx context.Context, eventID uint64, publishedAt time.Time) error
+	MarkOutboxEventFailed(ctx context.Context, eventID uint64, reason string) error
+
+	// Notification methods
+	GetNotificationPreferences(ctx context.Context, userID uint64) ([]*models.NotificationPreference, error)
+	GetNotificationPreference(ctx context.Context, userID uint64, eventType string) (*models.NotificationPreference, error)
+	UpsertNotificationPreference(ctx context.Context, preference *models.NotificationPreference) error
+	CreateNotification(ctx context.Context, notification *models.Notification) error
+	LockUserNotifications(ctx context.Context, userID uint64, eventType string) error
+	GetLatestNotification(ctx context.Context, userID uint64, eventType string) (*models.Notification, error)
+	GetNotifications(ctx context.Context, userID uint64, unreadOnly bool, limit int) ([]*models.Notification, error)
+	CountUnreadNotifications(ctx context.Context, userID uint64) (int, error)
+	MarkNotificationsRead(ctx context.Context, userID, notificationID uint64, readAt time.Time) (int, error)
+	ClaimDueNotificationEmails(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.Notification, error)
+	UpdateNotificationEmail(ctx context.Context, notification *models.Notification) error
+
+	// Stream methods
+	GetLastOutboxEventID(ctx context.Context) (uint64, error)
+	GetStreamEventsAfter(ctx context.Context, afterID uint64, limit int) ([]*models.OutboxEvent, error)
+	GetStreamEventsByIDs(ctx context.Context, ids []uint64) ([]*models.OutboxEvent, error)
+	GetUserStreamEventsAfter(ctx context.Context, userID, afterID uint64, limit int) ([]*models.OutboxEvent, error)
+
+	// Webhook methods
+	CreateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+	GetWebhookSubscriptionsByUserID(ctx context.Context, userID uint64) ([]*models.WebhookSubscription, error)
+	GetWebhookSubscriptionByID(ctx context.Context, subscriptionID uint64) (*models.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, subscriptionID uint64) error
+	CreateWebhookDeliveries(ctx context.Context, userID uint64, event *models.OutboxEvent, payload []byte) error
+	GetWebhookDeliveriesBySubscriptionID(ctx context.Context, subscriptionID uint64, limit int) ([]*models.WebhookDelivery, error)
+	GetWebhookDeliveryByID(ctx context.Context, deliveryID uint64) (*models.WebhookDelivery, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	CreateWebhookAttempt(ctx context.Context, attempt *models.WebhookAttempt) error
+	GetWebhookAttempts(ctx context.Context, deliveryID uint64) ([]*models.WebhookAttempt, error)
 
 	// RefreshToken methods
 	GetRefreshTokenModelByID(ctx context.Context, userID uint64, deviceID string) (*models.RefreshToken, error)
@@ -28,12 +219,45 @@ type Repository interface {
 	DeleteRefreshTokenModel(ctx context.Context, refreshToken *models.RefreshToken) error
 }
 
+// DBTX описывает общие методы *sql.DB и *sql.Tx, которые использует репозиторий.
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type repo struct {
-	db     *sql.DB
+	db     DBTX
+	conn   *sql.DB
 	logger *logrus.Logger
 }
 
 // NewRepository создаёт новый экземпляр репозитория.
 func NewRepository(db *sql.DB, logger *logrus.Logger) Repository {
-	return &repo{db: db, logger: logger}
+	return &repo{db: db, conn: db, logger: logger}
+}
+
+func (r *repo) WithinTransaction(ctx context.Context, fn func(repo Repository) error) error {
+	// Уже находимся внутри транзакции — переиспользуем её.
+	if _, ok := r.db.(*sql.Tx); ok {
+		return fn(r)
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Error starting transaction:", err)
+		return err
+	}
+
+	if err := fn(&repo{db: tx, conn: r.conn, logger: r.logger}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			r.logger.Error("Error rolling back transaction:", rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
 }
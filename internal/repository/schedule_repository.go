@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const scheduleColumns = `
+	id, user_id, type, from_currency, to_currency, to_user_id, amount, kind,
+	cron_expr, interval_seconds, status, next_run_at, last_run_at, created_at, updated_at`
+
+func scanSchedule(row interface{ Scan(dest ...any) error }) (*models.ScheduledTransfer, error) {
+	schedule := &models.ScheduledTransfer{}
+	var toUserID sql.NullInt64
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.UserID,
+		&schedule.Type,
+		&schedule.FromCurrency,
+		&schedule.ToCurrency,
+		&toUserID,
+		&schedule.Amount,
+		&schedule.Kind,
+		&schedule.CronExpr,
+		&schedule.IntervalSeconds,
+		&schedule.Status,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	schedule.ToUserID = uint64(toUserID.Int64)
+	return schedule, err
+}
+
+func scanSchedules(rows *sql.Rows) ([]*models.ScheduledTransfer, error) {
+	defer rows.Close()
+
+	var schedules []*models.ScheduledTransfer
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// CreateScheduledTransfer сохраняет новую запланированную операцию.
+func (r *repo) CreateScheduledTransfer(ctx context.Context, schedule *models.ScheduledTransfer) (uint64, error) {
+	query := `
+		INSERT INTO scheduled_transfers
+			(user_id, type, from_currency, to_currency, to_user_id, amount, kind, cron_expr, interval_seconds, status, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at, updated_at`
+	var toUserID sql.NullInt64
+	if schedule.ToUserID != 0 {
+		toUserID = sql.NullInt64{Int64: int64(schedule.ToUserID), Valid: true}
+	}
+	err := r.db.QueryRowContext(ctx, query,
+		schedule.UserID,
+		schedule.Type,
+		schedule.FromCurrency,
+		schedule.ToCurrency,
+		toUserID,
+		schedule.Amount,
+		schedule.Kind,
+		schedule.CronExpr,
+		schedule.IntervalSeconds,
+		schedule.Status,
+		schedule.NextRunAt,
+	).Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting scheduled transfer:", err)
+		return 0, err
+	}
+	return schedule.ID, nil
+}
+
+// GetScheduledTransferByID получает запланированную операцию по ID. Возвращает nil, если она не найдена.
+func (r *repo) GetScheduledTransferByID(ctx context.Context, scheduleID uint64) (*models.ScheduledTransfer, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM scheduled_transfers
+		WHERE id = $1 AND ($2 = 0 OR user_id IN (SELECT id FROM users WHERE tenant_id = $2))`
+	schedule, err := scanSchedule(r.db.QueryRowContext(ctx, query, scheduleID, tenant.Filter(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching scheduled transfer:", err)
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// GetScheduledTransfersByUserID получает все запланированные операции пользователя.
+func (r *repo) GetScheduledTransfersByUserID(ctx context.Context, userID uint64) ([]*models.ScheduledTransfer, error) {
+	query := `SELECT ` + scheduleColumns + `
+		FROM scheduled_transfers
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSchedules(rows)
+}
+
+// UpdateScheduledTransferStatus изменяет статус и время следующего запуска операции.
+func (r *repo) UpdateScheduledTransferStatus(ctx context.Context, scheduleID uint64, status string, nextRunAt *time.Time) error {
+	query := `
+		UPDATE scheduled_transfers
+		SET status = $1, next_run_at = $2, updated_at = NOW()
+		WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, status, nextRunAt, scheduleID)
+	if err != nil {
+		r.logger.Error("Error updating scheduled transfer status:", err)
+		return err
+	}
+	return nil
+}
+
+// ClaimDueScheduledTransfers захватывает до limit активных операций, время запуска которых наступило,
+// продлевая их аренду на lease. Захваченные операции не выдаются другим экземплярам сервиса до окончания аренды.
+func (r *repo) ClaimDueScheduledTransfers(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.ScheduledTransfer, error) {
+	query := `
+		UPDATE scheduled_transfers
+		SET locked_until = $1
+		WHERE id IN (
+			SELECT id FROM scheduled_transfers
+			WHERE status = $2
+				AND next_run_at <= $3
+				AND (locked_until IS NULL OR locked_until < $3)
+			ORDER BY next_run_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scheduleColumns
+	rows, err := r.db.QueryContext(ctx, query, now.Add(lease), models.ScheduleStatusActive, now, limit)
+	if err != nil {
+		r.logger.Error("Error claiming scheduled transfers:", err)
+		return nil, err
+	}
+	return scanSchedules(rows)
+}
+
+// CompleteScheduledTransferRun фиксирует результат запуска: время следующего запуска и снятие аренды.
+// Статус меняется только у активных операций, чтобы не затереть паузу или отмену, сделанные во время выполнения.
+func (r *repo) CompleteScheduledTransferRun(ctx context.Context, schedule *models.ScheduledTransfer) error {
+	query := `
+		UPDATE scheduled_transfers
+		SET next_run_at = $1,
+			last_run_at = $2,
+			status = CASE WHEN status = $3 THEN $4 ELSE status END,
+			locked_until = NULL,
+			updated_at = NOW()
+		WHERE id = $5`
+	_, err := r.db.ExecContext(ctx, query,
+		schedule.NextRunAt,
+		schedule.LastRunAt,
+		models.ScheduleStatusActive,
+		schedule.Status,
+		schedule.ID,
+	)
+	if err != nil {
+		r.logger.Error("Error completing scheduled transfer run:", err)
+		return err
+	}
+	return nil
+}
+
+// CreateScheduleExecution сохраняет запись истории выполнения. Возвращает false, если выполнение
+// запуска с тем же schedule_id и scheduled_for уже сохранено: один запуск не выполняется дважды.
+// Параллельная вставка того же запуска ожидает завершения первой транзакции.
+func (r *repo) CreateScheduleExecution(ctx context.Context, execution *models.ScheduleExecution) (bool, error) {
+	query := `
+		INSERT INTO scheduled_transfer_executions (schedule_id, status, error, amount, exchanged_amount, scheduled_for, executed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (schedule_id, scheduled_for) DO NOTHING
+		RETURNING id`
+	err := r.db.QueryRowContext(ctx, query,
+		execution.ScheduleID,
+		execution.Status,
+		execution.Error,
+		execution.Amount,
+		execution.ExchangedAmount,
+		execution.ScheduledFor,
+		execution.ExecutedAt,
+	).Scan(&execution.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		r.logger.Error("Error inserting schedule execution:", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// GetScheduleExecutions получает историю выполнения операции, начиная с последних запусков.
+func (r *repo) GetScheduleExecutions(ctx context.Context, scheduleID uint64) ([]*models.ScheduleExecution, error) {
+	query := `
+		SELECT id, schedule_id, status, error, amount, exchanged_amount, scheduled_for, executed_at
+		FROM scheduled_transfer_executions
+		WHERE schedule_id = $1
+		ORDER BY executed_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*models.ScheduleExecution
+	for rows.Next() {
+		execution := &models.ScheduleExecution{}
+		if err := rows.Scan(
+			&execution.ID,
+			&execution.ScheduleID,
+			&execution.Status,
+			&execution.Error,
+			&execution.Amount,
+			&execution.ExchangedAmount,
+			&execution.ScheduledFor,
+			&execution.ExecutedAt,
+		); err != nil {
+			return nil, err
+		}
+		executions = append(executions, execution)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// streamEventsQuery выбирает события outbox вместе со списком пользователей, которым доступен
+// кошелёк события: владельцем и активными участниками через запятую.
+const streamEventsQuery = `
+	SELECT ` + outboxEventColumns + `, recipients FROM (
+		SELECT e.*, w.user_id::text || COALESCE((
+			SELECT ',' || string_agg(m.user_id::text, ',')
+			FROM wallet_members m
+			WHERE m.wallet_id = e.wallet_id AND m.status = '` + models.WalletMemberStatusActive + `'
+		), '') AS recipients
+		FROM outbox_events e
+		JOIN wallets w ON w.id = e.wallet_id
+	) events`
+
+// GetLastOutboxEventID возвращает ID последнего записанного события outbox или 0.
+func (r *repo) GetLastOutboxEventID(ctx context.Context) (uint64, error) {
+	var id uint64
+	if err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM outbox_events`).Scan(&id); err != nil {
+		r.logger.Error("Error fetching last outbox event ID:", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetStreamEventsAfter возвращает до limit событий outbox с ID больше afterID в порядке ID
+// с заполненными получателями UserIDs.
+func (r *repo) GetStreamEventsAfter(ctx context.Context, afterID uint64, limit int) ([]*models.OutboxEvent, error) {
+	query := streamEventsQuery + ` WHERE id > $1 ORDER BY id LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanStreamEvents(rows)
+}
+
+// GetStreamEventsByIDs возвращает найденные события outbox из ids с заполненными получателями UserIDs.
+func (r *repo) GetStreamEventsByIDs(ctx context.Context, ids []uint64) ([]*models.OutboxEvent, error) {
+	list := make([]string, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, strconv.FormatUint(id, 10))
+	}
+
+	query := streamEventsQuery + ` WHERE id = ANY(string_to_array($1, ',')::bigint[]) ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, strings.Join(list, ","))
+	if err != nil {
+		return nil, err
+	}
+	return scanStreamEvents(rows)
+}
+
+// GetUserStreamEventsAfter возвращает до limit событий outbox с ID больше afterID по кошелькам,
+// которые принадлежат пользователю userID или в которых он активный участник.
+func (r *repo) GetUserStreamEventsAfter(ctx context.Context, userID, afterID uint64, limit int) ([]*models.OutboxEvent, error) {
+	query := streamEventsQuery + `
+		WHERE id > $2 AND wallet_id IN (
+			SELECT id FROM wallets WHERE user_id = $1
+			UNION
+			SELECT wallet_id FROM wallet_members WHERE user_id = $1 AND status = $3
+		)
+		ORDER BY id LIMIT $4`
+	rows, err := r.db.QueryContext(ctx, query, userID, afterID, models.WalletMemberStatusActive, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanStreamEvents(rows)
+}
+
+func scanStreamEvents(rows *sql.Rows) ([]*models.OutboxEvent, error) {
+	defer rows.Close()
+
+	var events []*models.OutboxEvent
+	for rows.Next() {
+		var recipients string
+		event, err := scanOutboxEvent(rows, &recipients)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range strings.Split(recipients, ",") {
+			userID, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			event.UserIDs = append(event.UserIDs, userID)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+const tenantColumns = "id, slug, name, host, jwt_public_key_path, jwt_private_key_path, created_at"
+
+func scanTenant(row interface{ Scan(dest ...any) error }) (*models.Tenant, error) {
+	t := &models.Tenant{}
+	err := row.Scan(
+		&t.ID,
+		&t.Slug,
+		&t.Name,
+		&t.Host,
+		&t.JWTPublicKeyPath,
+		&t.JWTPrivateKeyPath,
+		&t.CreatedAt,
+	)
+	return t, err
+}
+
+// GetTenants возвращает всех тенантов.
+func (r *repo) GetTenants(ctx context.Context) ([]*models.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []*models.Tenant
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tenants, nil
+}
+
+func (r *repo) getTenant(ctx context.Context, column, value string) (*models.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants WHERE ` + column + ` = $1`
+	t, err := scanTenant(r.db.QueryRowContext(ctx, query, value))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching tenant:", err)
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetTenantBySlug получает тенанта по slug. Возвращает nil, если тенант не найден.
+func (r *repo) GetTenantBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	return r.getTenant(ctx, "slug", slug)
+}
+
+// GetTenantByHost получает тенанта по хосту. Возвращает nil, если тенант не найден.
+func (r *repo) GetTenantByHost(ctx context.Context, host string) (*models.Tenant, error) {
+	return r.getTenant(ctx, "host", host)
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+const transactionColumns = `
+	id, wallet_id, operation_id, type, amount, balance_after, description, reversed_transaction_id, created_at,
+	prev_hash, hash, initiated_by`
+
+func scanTransaction(row interface{ Scan(dest ...any) error }) (*models.Transaction, error) {
+	transaction := &models.Transaction{}
+	var reversedID, initiatedBy sql.NullInt64
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.WalletID,
+		&transaction.OperationID,
+		&transaction.Type,
+		&transaction.Amount,
+		&transaction.BalanceAfter,
+		&transaction.Description,
+		&reversedID,
+		&transaction.CreatedAt,
+		&transaction.PrevHash,
+		&transaction.Hash,
+		&initiatedBy,
+	)
+	transaction.ReversedTransactionID = uint64(reversedID.Int64)
+	transaction.InitiatedBy = uint64(initiatedBy.Int64)
+	return transaction, err
+}
+
+// CreateTransaction сохраняет проводку в журнале транзакций. Время и хеши проводки задаёт вызывающий код.
+func (r *repo) CreateTransaction(ctx context.Context, transaction *models.Transaction) error {
+	query := `
+		INSERT INTO transactions
+			(wallet_id, operation_id, type, amount, balance_after, description, reversed_transaction_id, created_at, prev_hash, hash, initiated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`
+	var reversedID, initiatedBy sql.NullInt64
+	if transaction.ReversedTransactionID != 0 {
+		reversedID = sql.NullInt64{Int64: int64(transaction.ReversedTransactionID), Valid: true}
+	}
+	if transaction.InitiatedBy != 0 {
+		initiatedBy = sql.NullInt64{Int64: int64(transaction.InitiatedBy), Valid: true}
+	}
+	err := r.db.QueryRowContext(ctx, query,
+		transaction.WalletID,
+		transaction.OperationID,
+		transaction.Type,
+		transaction.Amount,
+		transaction.BalanceAfter,
+		transaction.Description,
+		reversedID,
+		transaction.CreatedAt,
+		transaction.PrevHash,
+		transaction.Hash,
+		initiatedBy,
+	).Scan(&transaction.ID)
+	if err != nil {
+		r.logger.Error("Error inserting transaction:", err)
+		return err
+	}
+	return nil
+}
+
+// GetTransactionByID получает проводку по ID. Возвращает nil, если проводка не найдена.
+func (r *repo) GetTransactionByID(ctx context.Context, transactionID uint64) (*models.Transaction, error) {
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE id = $1`
+	transaction, err := scanTransaction(r.db.QueryRowContext(ctx, query, transactionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching transaction:", err)
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// GetTransactionsByOperationID получает все проводки одной бизнес-операции.
+func (r *repo) GetTransactionsByOperationID(ctx context.Context, operationID string) ([]*models.Transaction, error) {
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE operation_id = $1 ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, operationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []*models.Transaction
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// GetMemberDebits возвращает сумму списаний с кошелька, выполненных пользователем userID
+// начиная с since.
+func (r *repo) GetMemberDebits(ctx context.Context, walletID, userID uint64, since time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(-SUM(amount), 0)
+		FROM transactions
+		WHERE wallet_id = $1 AND initiated_by = $2 AND amount < 0 AND created_at >= $3`
+	var debits float64
+	if err := r.db.QueryRowContext(ctx, query, walletID, userID, since).Scan(&debits); err != nil {
+		r.logger.Error("Error summing member debits:", err)
+		return 0, err
+	}
+	return debits, nil
+}
+
+// GetLastTransactionHash возвращает хеш последней проводки кошелька или пустую строку, если проводок нет.
+func (r *repo) GetLastTransactionHash(ctx context.Context, walletID uint64) (string, error) {
+	query := `SELECT hash FROM transactions WHERE wallet_id = $1 ORDER BY id DESC LIMIT 1`
+	var hash string
+	err := r.db.QueryRowContext(ctx, query, walletID).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return hash, err
+}
+
+// StreamJournal передаёт в fn все проводки журнала в порядке возрастания ID,
+// не загружая их в память целиком.
+func (r *repo) StreamJournal(ctx context.Context, fn func(transaction *models.Transaction) error) error {
+	query := `SELECT ` + transactionColumns + ` FROM transactions ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetBalanceAt возвращает баланс кошелька на момент at по журналу транзакций.
+func (r *repo) GetBalanceAt(ctx context.Context, walletID uint64, at time.Time) (float64, error) {
+	query := `
+		SELECT balance_after
+		FROM transactions
+		WHERE wallet_id = $1 AND created_at < $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1`
+	var balance float64
+	err := r.db.QueryRowContext(ctx, query, walletID, at).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return balance, err
+}
+
+// StreamTransactions передаёт в fn проводки кошелька за период [from, to) в хронологическом порядке,
+// не загружая их в память целиком.
+func (r *repo) StreamTransactions(ctx context.Context, walletID uint64, from, to time.Time, fn func(transaction *models.Transaction) error) error {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at, id`
+	rows, err := r.db.QueryContext(ctx, query, walletID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// CreateReversal сохраняет запись о сторнировании операции.
+func (r *repo) CreateReversal(ctx context.Context, reversal *models.Reversal) error {
+	query := `
+		INSERT INTO reversals (original_operation_id, reversal_operation_id, operator_id, reason)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		reversal.OriginalOperationID,
+		reversal.ReversalOperationID,
+		reversal.OperatorID,
+		reversal.Reason,
+	).Scan(&reversal.ID, &reversal.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting reversal:", err)
+		return err
+	}
+	return nil
+}
+
+// GetReversalByOperationID получает сторнирование исходной операции. Возвращает nil, если операция не сторнирована.
+func (r *repo) GetReversalByOperationID(ctx context.Context, operationID string) (*models.Reversal, error) {
+	query := `
+		SELECT id, original_operation_id, reversal_operation_id, operator_id, reason, created_at
+		FROM reversals
+		WHERE original_operation_id = $1`
+	reversal := &models.Reversal{}
+	err := r.db.QueryRowContext(ctx, query, operationID).Scan(
+		&reversal.ID,
+		&reversal.OriginalOperationID,
+		&reversal.ReversalOperationID,
+		&reversal.OperatorID,
+		&reversal.Reason,
+		&reversal.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching reversal:", err)
+		return nil, err
+	}
+	return reversal, nil
+}
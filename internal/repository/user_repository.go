@@ -6,33 +6,71 @@ import (
 	"errors"
 
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
 )
 
+const userColumns = "id, tenant_id, username, password, email, role, freeze_state, deposit_reference"
+
+func scanUser(row interface{ Scan(dest ...any) error }) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID,
+		&user.TenantID,
+		&user.Username,
+		&user.Password,
+		&user.Email,
+		&user.Role,
+		&user.FreezeState,
+		&user.DepositReference,
+	)
+	return user, err
+}
+
 func (r *repo) CreateUser(user *models.User) (int64, error) {
-	query := "INSERT INTO users (username, password, email) VALUES ($1, $2, $3) RETURNING id"
+	query := "INSERT INTO users (tenant_id, username, password, email, role) VALUES ($1, $2, $3, $4, $5) RETURNING id"
 	var userID int64
-	err := r.db.QueryRow(query, user.Username, user.Password, user.Email).Scan(&userID)
+	err := r.db.QueryRow(query, user.TenantID, user.Username, user.Password, user.Email, user.Role).Scan(&userID)
 	return userID, err
 }
 
-func (r *repo) GetUserByID(userID uint64) (*models.User, error) {
-	query := "SELECT id, username, password, email FROM users WHERE id = $1"
-	user := &models.User{}
-	err := r.db.QueryRow(query, userID).Scan(&user.ID, &user.Username, &user.Password, &user.Email)
-	return user, err
+// GetUserByID получает пользователя по ID; в контексте тенанта - только среди его пользователей.
+func (r *repo) GetUserByID(ctx context.Context, userID uint64) (*models.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE id = $1 AND ($2 = 0 OR tenant_id = $2)"
+	return scanUser(r.db.QueryRowContext(ctx, query, userID, tenant.Filter(ctx)))
 }
 
-func (r *repo) GetUserByUsername(username string) (*models.User, error) {
-	query := "SELECT id, username, password, email FROM users WHERE username = $1"
-	user := &models.User{}
-	err := r.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Password, &user.Email)
-	return user, err
+// GetUserByUsername получает пользователя по логину среди пользователей тенанта контекста.
+func (r *repo) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE username = $1 AND tenant_id = $2"
+	return scanUser(r.db.QueryRowContext(ctx, query, username, tenant.ID(ctx)))
+}
+
+// GetUserByDepositReference получает пользователя по коду пополнения; в контексте тенанта -
+// только среди его пользователей. Возвращает nil, если пользователь не найден.
+func (r *repo) GetUserByDepositReference(ctx context.Context, reference string) (*models.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE deposit_reference = $1 AND ($2 = 0 OR tenant_id = $2)"
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, reference, tenant.Filter(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching user by deposit reference:", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUserFreezeState изменяет состояние заморозки пользователя.
+func (r *repo) UpdateUserFreezeState(ctx context.Context, userID uint64, state string) error {
+	query := "UPDATE users SET freeze_state = $1 WHERE id = $2"
+	_, err := r.db.ExecContext(ctx, query, state, userID)
+	return err
 }
 
 // Получение RefreshToken по userID и deviceID
 func (r *repo) GetRefreshTokenModelByID(ctx context.Context, userID uint64, deviceID string) (*models.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, device_id, token, created_at, expires_at 
+		SELECT id, tenant_id, user_id, device_id, token, created_at, expires_at 
 		FROM refresh_tokens 
 		WHERE user_id = $1 AND device_id = $2`
 	row := r.db.QueryRowContext(ctx, query, userID, deviceID)
@@ -40,6 +78,7 @@ func (r *repo) GetRefreshTokenModelByID(ctx context.Context, userID uint64, devi
 	var refreshToken models.RefreshToken
 	if err := row.Scan(
 		&refreshToken.ID,
+		&refreshToken.TenantID,
 		&refreshToken.UserID,
 		&refreshToken.DeviceID,
 		&refreshToken.Token,
@@ -58,9 +97,10 @@ func (r *repo) GetRefreshTokenModelByID(ctx context.Context, userID uint64, devi
 // Добавление нового RefreshToken
 func (r *repo) SetRefreshTokenModel(ctx context.Context, refreshToken *models.RefreshToken) error {
 	query := `
-		INSERT INTO refresh_tokens (user_id, device_id, token, created_at, expires_at) 
-		VALUES ($1, $2, $3, $4, $5)`
+		INSERT INTO refresh_tokens (tenant_id, user_id, device_id, token, created_at, expires_at) 
+		VALUES ($1, $2, $3, $4, $5, $6)`
 	_, err := r.db.ExecContext(ctx, query,
+		refreshToken.TenantID,
 		refreshToken.UserID,
 		refreshToken.DeviceID,
 		refreshToken.Token,
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+// voucherBatchSelect выбирает партии ваучеров вместе с числом кодов и погашений.
+const voucherBatchSelect = `
+	SELECT b.id, b.tenant_id, b.name, b.amount, b.currency, b.max_redemptions, b.expires_at, b.created_by, b.created_at,
+		COUNT(v.id), COALESCE(SUM(v.redemptions), 0)
+	FROM voucher_batches b
+	LEFT JOIN vouchers v ON v.batch_id = b.id`
+
+func scanVoucherBatch(row interface{ Scan(dest ...any) error }) (*models.VoucherBatch, error) {
+	batch := &models.VoucherBatch{}
+	err := row.Scan(
+		&batch.ID,
+		&batch.TenantID,
+		&batch.Name,
+		&batch.Amount,
+		&batch.Currency,
+		&batch.MaxRedemptions,
+		&batch.ExpiresAt,
+		&batch.CreatedBy,
+		&batch.CreatedAt,
+		&batch.Codes,
+		&batch.Redemptions,
+	)
+	return batch, err
+}
+
+const voucherColumns = "id, batch_id, code, redemptions, created_at"
+
+func scanVoucher(row interface{ Scan(dest ...any) error }) (*models.Voucher, error) {
+	voucher := &models.Voucher{}
+	err := row.Scan(
+		&voucher.ID,
+		&voucher.BatchID,
+		&voucher.Code,
+		&voucher.Redemptions,
+		&voucher.CreatedAt,
+	)
+	return voucher, err
+}
+
+// CreateVoucherBatch сохраняет партию ваучеров вместе с её кодами. Возвращает false, если один
+// из кодов уже есть у тенанта: уникальность обеспечивает индекс (tenant_id, code), поэтому
+// параллельная вставка того же кода ожидает завершения первой транзакции. Партию в этом
+// случае нужно откатить вместе с транзакцией.
+func (r *repo) CreateVoucherBatch(ctx context.Context, batch *models.VoucherBatch) (bool, error) {
+	query := `
+		INSERT INTO voucher_batches (tenant_id, name, amount, currency, max_redemptions, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		batch.TenantID,
+		batch.Name,
+		batch.Amount,
+		batch.Currency,
+		batch.MaxRedemptions,
+		batch.ExpiresAt,
+		batch.CreatedBy,
+	).Scan(&batch.ID, &batch.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting voucher batch:", err)
+		return false, err
+	}
+
+	voucherQuery := `
+		INSERT INTO vouchers (tenant_id, batch_id, code)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, code) DO NOTHING
+		RETURNING id, created_at`
+	for _, voucher := range batch.Vouchers {
+		voucher.BatchID = batch.ID
+		err := r.db.QueryRowContext(ctx, voucherQuery, batch.TenantID, voucher.BatchID, voucher.Code).Scan(&voucher.ID, &voucher.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			r.logger.Error("Error inserting voucher:", err)
+			return false, err
+		}
+	}
+	batch.Codes = len(batch.Vouchers)
+	return true, nil
+}
+
+// GetVoucherBatches возвращает последние limit партий ваучеров со статистикой погашений.
+// В контексте тенанта возвращаются только его партии.
+func (r *repo) GetVoucherBatches(ctx context.Context, limit int) ([]*models.VoucherBatch, error) {
+	query := voucherBatchSelect + `
+		WHERE $2 = 0 OR b.tenant_id = $2
+		GROUP BY b.id
+		ORDER BY b.created_at DESC, b.id DESC
+		LIMIT $1`
+	rows, err := r.db.QueryContext(ctx, query, limit, tenant.Filter(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batches []*models.VoucherBatch
+	for rows.Next() {
+		batch, err := scanVoucherBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+// GetVoucherBatchByID получает партию ваучеров со статистикой погашений.
+// Возвращает nil, если партия не найдена.
+func (r *repo) GetVoucherBatchByID(ctx context.Context, batchID uint64) (*models.VoucherBatch, error) {
+	query := voucherBatchSelect + `
+		WHERE b.id = $1 AND ($2 = 0 OR b.tenant_id = $2)
+		GROUP BY b.id`
+	batch, err := scanVoucherBatch(r.db.QueryRowContext(ctx, query, batchID, tenant.Filter(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching voucher batch:", err)
+		return nil, err
+	}
+	return batch, nil
+}
+
+// GetVouchersByBatchID возвращает коды партии с числом погашений каждого.
+func (r *repo) GetVouchersByBatchID(ctx context.Context, batchID uint64) ([]*models.Voucher, error) {
+	query := `SELECT ` + voucherColumns + ` FROM vouchers WHERE batch_id = $1 ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vouchers []*models.Voucher
+	for rows.Next() {
+		voucher, err := scanVoucher(rows)
+		if err != nil {
+			return nil, err
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return vouchers, nil
+}
+
+// GetVoucherByCodeForUpdate получает ваучер тенанта контекста по коду и блокирует строку
+// до конца транзакции. Возвращает nil, если код не найден.
+func (r *repo) GetVoucherByCodeForUpdate(ctx context.Context, code string) (*models.Voucher, error) {
+	query := `SELECT ` + voucherColumns + ` FROM vouchers WHERE tenant_id = $1 AND code = $2 FOR UPDATE`
+	voucher, err := scanVoucher(r.db.QueryRowContext(ctx, query, tenant.ID(ctx), code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching voucher:", err)
+		return nil, err
+	}
+	return voucher, nil
+}
+
+// CreateVoucherRedemption сохраняет погашение ваучера и увеличивает счётчик погашений кода.
+// Возвращает false, если пользователь уже погашал этот ваучер.
+func (r *repo) CreateVoucherRedemption(ctx context.Context, redemption *models.VoucherRedemption) (bool, error) {
+	query := `
+		INSERT INTO voucher_redemptions (voucher_id, user_id, wallet_id, amount, currency, operation_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (voucher_id, user_id) DO NOTHING
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		redemption.VoucherID,
+		redemption.UserID,
+		redemption.WalletID,
+		redemption.Amount,
+		redemption.Currency,
+		redemption.OperationID,
+	).Scan(&redemption.ID, &redemption.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		r.logger.Error("Error inserting voucher redemption:", err)
+		return false, err
+	}
+
+	if _, err := r.db.ExecContext(ctx, `UPDATE vouchers SET redemptions = redemptions + 1 WHERE id = $1`, redemption.VoucherID); err != nil {
+		r.logger.Error("Error updating voucher redemptions:", err)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+const walletMemberColumns = `
+	m.wallet_id, m.user_id, u.username, w.currency, m.role, m.spend_limit,
+	m.status, m.invited_by, m.created_at, m.accepted_at`
+
+const walletMemberJoins = `
+	FROM wallet_members m
+	JOIN wallets w ON w.id = m.wallet_id
+	JOIN users u ON u.id = m.user_id`
+
+func scanWalletMember(row interface{ Scan(dest ...any) error }) (*models.WalletMember, error) {
+	member := &models.WalletMember{}
+	err := row.Scan(
+		&member.WalletID,
+		&member.UserID,
+		&member.Username,
+		&member.Currency,
+		&member.Role,
+		&member.SpendLimit,
+		&member.Status,
+		&member.InvitedBy,
+		&member.CreatedAt,
+		&member.AcceptedAt,
+	)
+	return member, err
+}
+
+func (r *repo) queryWalletMembers(ctx context.Context, query string, args ...any) ([]*models.WalletMember, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*models.WalletMember
+	for rows.Next() {
+		member, err := scanWalletMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// CreateWalletMember добавляет участника (приглашение) в совместный кошелёк.
+func (r *repo) CreateWalletMember(ctx context.Context, member *models.WalletMember) error {
+	query := `
+		INSERT INTO wallet_members (wallet_id, user_id, role, spend_limit, status, invited_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		member.WalletID,
+		member.UserID,
+		member.Role,
+		member.SpendLimit,
+		member.Status,
+		member.InvitedBy,
+	).Scan(&member.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting wallet member:", err)
+		return err
+	}
+	return nil
+}
+
+// GetWalletMember получает участника кошелька. Возвращает nil, если пользователь не участник.
+func (r *repo) GetWalletMember(ctx context.Context, walletID, userID uint64) (*models.WalletMember, error) {
+	query := `SELECT ` + walletMemberColumns + walletMemberJoins + `
+		WHERE m.wallet_id = $1 AND m.user_id = $2`
+	member, err := scanWalletMember(r.db.QueryRowContext(ctx, query, walletID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching wallet member:", err)
+		return nil, err
+	}
+	return member, nil
+}
+
+// GetWalletMembers возвращает участников и приглашения кошелька.
+func (r *repo) GetWalletMembers(ctx context.Context, walletID uint64) ([]*models.WalletMember, error) {
+	query := `SELECT ` + walletMemberColumns + walletMemberJoins + `
+		WHERE m.wallet_id = $1
+		ORDER BY m.created_at`
+	return r.queryWalletMembers(ctx, query, walletID)
+}
+
+// GetWalletMembershipsByUserID возвращает участия пользователя в чужих кошельках с указанным статусом.
+func (r *repo) GetWalletMembershipsByUserID(ctx context.Context, userID uint64, status string) ([]*models.WalletMember, error) {
+	query := `SELECT ` + walletMemberColumns + walletMemberJoins + `
+		WHERE m.user_id = $1 AND m.status = $2
+		ORDER BY m.created_at`
+	return r.queryWalletMembers(ctx, query, userID, status)
+}
+
+// ActivateWalletMember переводит приглашение в статус active.
+func (r *repo) ActivateWalletMember(ctx context.Context, walletID, userID uint64) error {
+	query := `
+		UPDATE wallet_members
+		SET status = $1, accepted_at = NOW()
+		WHERE wallet_id = $2 AND user_id = $3`
+	_, err := r.db.ExecContext(ctx, query, models.WalletMemberStatusActive, walletID, userID)
+	return err
+}
+
+// DeleteWalletMember удаляет участника или приглашение из кошелька.
+func (r *repo) DeleteWalletMember(ctx context.Context, walletID, userID uint64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM wallet_members WHERE wallet_id = $1 AND user_id = $2`, walletID, userID)
+	return err
+}
@@ -1,45 +1,51 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
 )
 
+const walletColumns = "id, tenant_id, user_id, balance, currency, freeze_state, version"
+
 func (r *repo) CreateWallet(wallet *models.Wallet) (int, error) {
-	query := "INSERT INTO wallets (user_id, balance, currency) VALUES ($1, $2, $3) RETURNING id"
+	query := "INSERT INTO wallets (tenant_id, user_id, balance, currency) VALUES ($1, $2, $3, $4) RETURNING id"
 	var walletID int
-	err := r.db.QueryRow(query, wallet.UserID, wallet.Balance, wallet.Currency).Scan(&walletID)
+	err := r.db.QueryRow(query, wallet.TenantID, wallet.UserID, wallet.Balance, wallet.Currency).Scan(&walletID)
 	return walletID, err
 }
 
-func (r *repo) GetWalletByID(walletID uint64) (*models.Wallet, error) {
-	query := "SELECT id, user_id, balance, currency FROM wallets WHERE id = $1"
+// GetWalletByID получает кошелёк по ID; в контексте тенанта - только среди его кошельков.
+func (r *repo) GetWalletByID(ctx context.Context, walletID uint64) (*models.Wallet, error) {
+	query := "SELECT " + walletColumns + " FROM wallets WHERE id = $1 AND ($2 = 0 OR tenant_id = $2)"
 	wallet := &models.Wallet{}
-	err := r.db.QueryRow(query, walletID).Scan(&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.Currency)
+	err := r.db.QueryRowContext(ctx, query, walletID, tenant.Filter(ctx)).Scan(&wallet.ID, &wallet.TenantID, &wallet.UserID, &wallet.Balance, &wallet.Currency, &wallet.FreezeState, &wallet.Version)
 	return wallet, err
 }
 
 // GetWalletByUserAndCurrency получает кошелёк пользователя по валюте.
 func (r *repo) GetWalletByUserAndCurrency(userID uint64, currency string) (*models.Wallet, error) {
-	query := "SELECT id, user_id, balance, currency FROM wallets WHERE user_id = $1 AND currency = $2"
+	query := "SELECT " + walletColumns + " FROM wallets WHERE user_id = $1 AND currency = $2"
 	wallet := &models.Wallet{}
-	err := r.db.QueryRow(query, userID, currency).Scan(&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.Currency)
+	err := r.db.QueryRow(query, userID, currency).Scan(&wallet.ID, &wallet.TenantID, &wallet.UserID, &wallet.Balance, &wallet.Currency, &wallet.FreezeState, &wallet.Version)
 	if err == sql.ErrNoRows {
 		return nil, err
 	}
 	return wallet, err
 }
 
+// UpdateWalletBalance изменяет баланс кошелька и увеличивает его версию.
 func (r *repo) UpdateWalletBalance(walletID uint64, balance float64) error {
-	query := "UPDATE wallets SET balance = $1 WHERE id = $2"
+	query := "UPDATE wallets SET balance = $1, version = version + 1 WHERE id = $2"
 	_, err := r.db.Exec(query, balance, walletID)
 	return err
 }
 
 // GetWalletsByUserID получает все кошельки пользователя.
 func (r *repo) GetWalletsByUserID(userID uint64) ([]*models.Wallet, error) {
-	query := "SELECT id, user_id, balance, currency FROM wallets WHERE user_id = $1"
+	query := "SELECT " + walletColumns + " FROM wallets WHERE user_id = $1"
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
 		return nil, err
@@ -49,7 +55,7 @@ func (r *repo) GetWalletsByUserID(userID uint64) ([]*models.Wallet, error) {
 	var wallets []*models.Wallet
 	for rows.Next() {
 		wallet := &models.Wallet{}
-		err := rows.Scan(&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.Currency)
+		err := rows.Scan(&wallet.ID, &wallet.TenantID, &wallet.UserID, &wallet.Balance, &wallet.Currency, &wallet.FreezeState, &wallet.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -62,3 +68,30 @@ func (r *repo) GetWalletsByUserID(userID uint64) ([]*models.Wallet, error) {
 
 	return wallets, nil
 }
+
+// GetWalletByIDForUpdate получает кошелёк по ID и блокирует строку до конца транзакции;
+// в контексте тенанта - только среди его кошельков.
+func (r *repo) GetWalletByIDForUpdate(ctx context.Context, walletID uint64) (*models.Wallet, error) {
+	query := "SELECT " + walletColumns + " FROM wallets WHERE id = $1 AND ($2 = 0 OR tenant_id = $2) FOR UPDATE"
+	wallet := &models.Wallet{}
+	err := r.db.QueryRowContext(ctx, query, walletID, tenant.Filter(ctx)).Scan(&wallet.ID, &wallet.TenantID, &wallet.UserID, &wallet.Balance, &wallet.Currency, &wallet.FreezeState, &wallet.Version)
+	return wallet, err
+}
+
+// GetWalletByUserAndCurrencyForUpdate получает кошелёк пользователя по валюте и блокирует строку до конца транзакции.
+func (r *repo) GetWalletByUserAndCurrencyForUpdate(ctx context.Context, userID uint64, currency string) (*models.Wallet, error) {
+	query := "SELECT " + walletColumns + " FROM wallets WHERE user_id = $1 AND currency = $2 FOR UPDATE"
+	wallet := &models.Wallet{}
+	err := r.db.QueryRowContext(ctx, query, userID, currency).Scan(&wallet.ID, &wallet.TenantID, &wallet.UserID, &wallet.Balance, &wallet.Currency, &wallet.FreezeState, &wallet.Version)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	return wallet, err
+}
+
+// UpdateWalletFreezeState изменяет состояние заморозки кошелька и увеличивает его версию.
+func (r *repo) UpdateWalletFreezeState(ctx context.Context, walletID uint64, state string) error {
+	query := "UPDATE wallets SET freeze_state = $1, version = version + 1 WHERE id = $2"
+	_, err := r.db.ExecContext(ctx, query, state, walletID)
+	return err
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+const webhookSubscriptionColumns = "id, tenant_id, user_id, url, event_types, secret, created_at"
+
+func scanWebhookSubscription(row interface{ Scan(dest ...any) error }) (*models.WebhookSubscription, error) {
+	subscription := &models.WebhookSubscription{}
+	var eventTypes string
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.TenantID,
+		&subscription.UserID,
+		&subscription.URL,
+		&eventTypes,
+		&subscription.Secret,
+		&subscription.CreatedAt,
+	)
+	subscription.EventTypes = strings.Split(eventTypes, ",")
+	return subscription, err
+}
+
+const webhookDeliveryColumns = "id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at"
+
+func scanWebhookDelivery(row interface{ Scan(dest ...any) error }, extra ...any) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	var payload []byte
+	dest := []any{
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+		&delivery.DeliveredAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	delivery.Payload = payload
+	return delivery, err
+}
+
+// CreateWebhookSubscription сохраняет подписку на вебхуки.
+func (r *repo) CreateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (tenant_id, user_id, url, event_types, secret)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		subscription.TenantID,
+		subscription.UserID,
+		subscription.URL,
+		strings.Join(subscription.EventTypes, ","),
+		subscription.Secret,
+	).Scan(&subscription.ID, &subscription.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting webhook subscription:", err)
+		return err
+	}
+	return nil
+}
+
+// GetWebhookSubscriptionsByUserID возвращает подписки пользователя на вебхуки.
+func (r *repo) GetWebhookSubscriptionsByUserID(ctx context.Context, userID uint64) ([]*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE user_id = $1 ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*models.WebhookSubscription
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// GetWebhookSubscriptionByID получает подписку на вебхуки. Возвращает nil, если подписка не найдена.
+func (r *repo) GetWebhookSubscriptionByID(ctx context.Context, subscriptionID uint64) (*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+	subscription, err := scanWebhookSubscription(r.db.QueryRowContext(ctx, query, subscriptionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching webhook subscription:", err)
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// DeleteWebhookSubscription удаляет подписку вместе с её доставками.
+func (r *repo) DeleteWebhookSubscription(ctx context.Context, subscriptionID uint64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, subscriptionID); err != nil {
+		r.logger.Error("Error deleting webhook subscription:", err)
+		return err
+	}
+	return nil
+}
+
+// CreateWebhookDeliveries создаёт доставки события event с телом payload во все подписки
+// пользователя userID на этот тип событий.
+func (r *repo) CreateWebhookDeliveries(ctx context.Context, userID uint64, event *models.OutboxEvent, payload []byte) error {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, next_attempt_at)
+		SELECT id, $2, $3, $4, NOW()
+		FROM webhook_subscriptions
+		WHERE user_id = $1 AND ($3 = ANY(string_to_array(event_types, ',')) OR $5 = ANY(string_to_array(event_types, ',')))`
+	if _, err := r.db.ExecContext(ctx, query, userID, event.ID, event.Type, payload, models.WebhookEventAll); err != nil {
+		r.logger.Error("Error inserting webhook deliveries:", err)
+		return err
+	}
+	return nil
+}
+
+// GetWebhookDeliveriesBySubscriptionID возвращает последние limit доставок подписки.
+func (r *repo) GetWebhookDeliveriesBySubscriptionID(ctx context.Context, subscriptionID uint64, limit int) ([]*models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE subscription_id = $1 ORDER BY id DESC LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// GetWebhookDeliveryByID получает доставку вебхука. Возвращает nil, если доставка не найдена.
+func (r *repo) GetWebhookDeliveryByID(ctx context.Context, deliveryID uint64) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, deliveryID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching webhook delivery:", err)
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ClaimDueWebhookDeliveries выбирает до limit доставок, время попытки которых наступило, вместе
+// с URL и секретом подписки и откладывает их следующую попытку на lease, чтобы параллельные
+// обработчики не отправили их повторно.
+func (r *repo) ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $1
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $2 AND next_attempt_at <= $3
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+			d.last_status_code, d.last_error, d.created_at, d.delivered_at, s.url, s.secret`
+	rows, err := r.db.QueryContext(ctx, query, now.Add(lease), models.WebhookDeliveryPending, now, limit)
+	if err != nil {
+		r.logger.Error("Error claiming webhook deliveries:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var url, secret string
+		delivery, err := scanWebhookDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		delivery.URL = url
+		delivery.Secret = secret
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery сохраняет статус, число попыток и время следующей попытки доставки.
+func (r *repo) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5, delivered_at = $6
+		WHERE id = $7`
+	_, err := r.db.ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+	if err != nil {
+		r.logger.Error("Error updating webhook delivery:", err)
+		return err
+	}
+	return nil
+}
+
+// CreateWebhookAttempt сохраняет попытку доставки вебхука.
+func (r *repo) CreateWebhookAttempt(ctx context.Context, attempt *models.WebhookAttempt) error {
+	query := `
+		INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query,
+		attempt.DeliveryID,
+		attempt.Attempt,
+		attempt.StatusCode,
+		attempt.Error,
+		attempt.DurationMS,
+	).Scan(&attempt.ID, &attempt.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting webhook attempt:", err)
+		return err
+	}
+	return nil
+}
+
+// GetWebhookAttempts возвращает попытки доставки вебхука в порядке выполнения.
+func (r *repo) GetWebhookAttempts(ctx context.Context, deliveryID uint64) ([]*models.WebhookAttempt, error) {
+	query := `
+		SELECT id, delivery_id, attempt, status_code, error, duration_ms, created_at
+		FROM webhook_attempts
+		WHERE delivery_id = $1
+		ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []*models.WebhookAttempt
+	for rows.Next() {
+		attempt := &models.WebhookAttempt{}
+		if err := rows.Scan(
+			&attempt.ID,
+			&attempt.DeliveryID,
+			&attempt.Attempt,
+			&attempt.StatusCode,
+			&attempt.Error,
+			&attempt.DurationMS,
+			&attempt.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const withdrawalColumns = `
+	id, user_id, wallet_id, amount, currency, destination, status, hold_id, provider,
+	provider_reference, operation_id, failure_reason, created_at, updated_at`
+
+func scanWithdrawal(row interface{ Scan(dest ...any) error }) (*models.Withdrawal, error) {
+	withdrawal := &models.Withdrawal{}
+	err := row.Scan(
+		&withdrawal.ID,
+		&withdrawal.UserID,
+		&withdrawal.WalletID,
+		&withdrawal.Amount,
+		&withdrawal.Currency,
+		&withdrawal.Destination,
+		&withdrawal.Status,
+		&withdrawal.HoldID,
+		&withdrawal.Provider,
+		&withdrawal.ProviderReference,
+		&withdrawal.OperationID,
+		&withdrawal.FailureReason,
+		&withdrawal.CreatedAt,
+		&withdrawal.UpdatedAt,
+	)
+	return withdrawal, err
+}
+
+// CreateWithdrawal сохраняет новый вывод средств.
+func (r *repo) CreateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	query := `
+		INSERT INTO withdrawals (user_id, wallet_id, amount, currency, destination, status, hold_id, provider)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at`
+	err := r.db.QueryRowContext(ctx, query,
+		withdrawal.UserID,
+		withdrawal.WalletID,
+		withdrawal.Amount,
+		withdrawal.Currency,
+		withdrawal.Destination,
+		withdrawal.Status,
+		withdrawal.HoldID,
+		withdrawal.Provider,
+	).Scan(&withdrawal.ID, &withdrawal.CreatedAt, &withdrawal.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting withdrawal:", err)
+		return err
+	}
+	return nil
+}
+
+func (r *repo) getWithdrawal(ctx context.Context, withdrawalID uint64, lock string) (*models.Withdrawal, error) {
+	query := `SELECT ` + withdrawalColumns + ` FROM withdrawals
+		WHERE id = $1 AND ($2 = 0 OR wallet_id IN (SELECT id FROM wallets WHERE tenant_id = $2))` + lock
+	withdrawal, err := scanWithdrawal(r.db.QueryRowContext(ctx, query, withdrawalID, tenant.Filter(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Error("Error fetching withdrawal:", err)
+		return nil, err
+	}
+	return withdrawal, nil
+}
+
+// GetWithdrawalByID получает вывод средств по ID. Возвращает nil, если вывод не найден.
+func (r *repo) GetWithdrawalByID(ctx context.Context, withdrawalID uint64) (*models.Withdrawal, error) {
+	return r.getWithdrawal(ctx, withdrawalID, "")
+}
+
+// GetWithdrawalByIDForUpdate получает вывод средств по ID и блокирует строку до конца транзакции.
+// Возвращает nil, если вывод не найден.
+func (r *repo) GetWithdrawalByIDForUpdate(ctx context.Context, withdrawalID uint64) (*models.Withdrawal, error) {
+	return r.getWithdrawal(ctx, withdrawalID, " FOR UPDATE")
+}
+
+func (r *repo) queryWithdrawals(ctx context.Context, query string, args ...any) ([]*models.Withdrawal, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var withdrawals []*models.Withdrawal
+	for rows.Next() {
+		withdrawal, err := scanWithdrawal(rows)
+		if err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, withdrawal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+// GetWithdrawalsByUserID получает выводы средств пользователя, начиная с последних.
+func (r *repo) GetWithdrawalsByUserID(ctx context.Context, userID uint64) ([]*models.Withdrawal, error) {
+	query := `SELECT ` + withdrawalColumns + `
+		FROM withdrawals
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`
+	return r.queryWithdrawals(ctx, query, userID)
+}
+
+// GetWithdrawalsByStatus получает до limit выводов средств в статусе status, изменённых не раньше since,
+// начиная с самых давних. В контексте тенанта возвращаются только выводы его пользователей.
+func (r *repo) GetWithdrawalsByStatus(ctx context.Context, status string, since time.Time, limit int) ([]*models.Withdrawal, error) {
+	query := `SELECT ` + withdrawalColumns + `
+		FROM withdrawals
+		WHERE status = $1 AND updated_at >= $2
+			AND ($4 = 0 OR wallet_id IN (SELECT id FROM wallets WHERE tenant_id = $4))
+		ORDER BY updated_at, id
+		LIMIT $3`
+	return r.queryWithdrawals(ctx, query, status, since, limit, tenant.Filter(ctx))
+}
+
+// UpdateWithdrawal сохраняет статус и результат выплаты.
+func (r *repo) UpdateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	query := `
+		UPDATE withdrawals
+		SET status = $1, provider_reference = $2, operation_id = $3, failure_reason = $4, updated_at = NOW()
+		WHERE id = $5`
+	_, err := r.db.ExecContext(ctx, query,
+		withdrawal.Status,
+		withdrawal.ProviderReference,
+		withdrawal.OperationID,
+		withdrawal.FailureReason,
+		withdrawal.ID,
+	)
+	if err != nil {
+		r.logger.Error("Error updating withdrawal:", err)
+		return err
+	}
+	return nil
+}
+
+// CreateWithdrawalEvent записывает переход вывода средств в новый статус.
+func (r *repo) CreateWithdrawalEvent(ctx context.Context, event *models.WithdrawalEvent) error {
+	query := `
+		INSERT INTO withdrawal_events (withdrawal_id, status, note)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query, event.WithdrawalID, event.Status, event.Note).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		r.logger.Error("Error inserting withdrawal event:", err)
+		return err
+	}
+	return nil
+}
+
+// GetWithdrawalEvents получает историю статусов вывода средств в порядке изменения.
+func (r *repo) GetWithdrawalEvents(ctx context.Context, withdrawalID uint64) ([]*models.WithdrawalEvent, error) {
+	query := `
+		SELECT id, withdrawal_id, status, note, created_at
+		FROM withdrawal_events
+		WHERE withdrawal_id = $1
+		ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, withdrawalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.WithdrawalEvent
+	for rows.Next() {
+		event := &models.WithdrawalEvent{}
+		if err := rows.Scan(&event.ID, &event.WithdrawalID, &event.Status, &event.Note, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// creditLineBatchSize - количество кредитных линий, обрабатываемых за один проход воркера.
+	creditLineBatchSize = 200
+	// daysInYear - база для пересчёта годовой ставки в дневную.
+	daysInYear = 365
+)
+
+// GetCreditLine возвращает кредитную линию кошелька.
+func (s *service) GetCreditLine(ctx context.Context, walletID uint64) (*models.CreditLine, error) {
+	ok, err := walletInTenant(ctx, s.repo, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrWalletNotFound
+	}
+
+	line, err := s.repo.GetCreditLine(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if line == nil {
+		return nil, ErrCreditLineNotFound
+	}
+	return line, nil
+}
+
+// SetCreditLine открывает кредитную линию кошелька или изменяет её условия.
+// Снижение лимита ниже текущей задолженности допустимо: новые списания будут отклоняться,
+// пока баланс не вернётся в пределы лимита.
+func (s *service) SetCreditLine(ctx context.Context, walletID uint64, request *models.CreditLineRequest) (*models.CreditLine, error) {
+	if request.Limit < 0 || request.AnnualRate < 0 || request.AnnualRate > 1 || request.DailyFee < 0 {
+		return nil, ErrInvalidCreditLine
+	}
+
+	line := &models.CreditLine{
+		WalletID:   walletID,
+		Limit:      roundAmount(request.Limit),
+		AnnualRate: request.AnnualRate,
+		DailyFee:   roundAmount(request.DailyFee),
+	}
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, walletID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+		if !sameTenant(ctx, wallet.TenantID) {
+			return ErrWalletNotFound
+		}
+		line.UserID = wallet.UserID
+		line.Currency = wallet.Currency
+		return repo.UpsertCreditLine(ctx, line)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Credit line of wallet %d set: limit %.2f, rate %g, daily fee %.2f",
+		walletID, line.Limit, line.AnnualRate, line.DailyFee)
+	return line, nil
+}
+
+// DeleteCreditLine закрывает кредитную линию кошелька. Линию нельзя закрыть,
+// пока по ней есть задолженность.
+func (s *service) DeleteCreditLine(ctx context.Context, walletID uint64) error {
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, walletID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+		if !sameTenant(ctx, wallet.TenantID) {
+			return ErrWalletNotFound
+		}
+
+		line, err := repo.GetCreditLineForUpdate(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		if line == nil {
+			return ErrCreditLineNotFound
+		}
+		if wallet.Balance < 0 {
+			return ErrCreditLineInUse
+		}
+		return repo.DeleteCreditLine(ctx, walletID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("Credit line of wallet %d closed", walletID)
+	return nil
+}
+
+// AccrueOverdraftCharges начисляет проценты и плату за пользование овердрафтом по кредитным
+// линиям, которые ещё не обработаны за текущие сутки (UTC). Начисление выполняется за каждый
+// пропущенный день от остатка на момент запуска. Возвращает количество обработанных линий.
+func (s *service) AccrueOverdraftCharges(ctx context.Context) (int, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	walletIDs, err := s.repo.GetCreditLinesDueForAccrual(ctx, today, creditLineBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, walletID := range walletIDs {
+		if err := s.accrueCreditLine(ctx, walletID, today); err != nil {
+			s.logger.Errorf("Failed to accrue overdraft charges for wallet %d: %v", walletID, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// accrueCreditLine начисляет проценты и плату по одной кредитной линии за дни до today
+// и сдвигает дату последнего начисления.
+func (s *service) accrueCreditLine(ctx context.Context, walletID uint64, today time.Time) error {
+	return s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		// Порядок блокировок совпадает с DeleteCreditLine: сначала кошелёк, затем линия.
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		line, err := repo.GetCreditLineForUpdate(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		// Линию закрыли или уже обработали параллельно
+		if line == nil || !line.LastAccruedOn.Before(today) {
+			return nil
+		}
+
+		days := int(today.Sub(line.LastAccruedOn.UTC().Truncate(24*time.Hour)).Hours() / 24)
+		if wallet.Balance < 0 && days > 0 {
+			interest := roundAmount(-wallet.Balance * line.AnnualRate / daysInYear * float64(days))
+			fee := roundAmount(line.DailyFee * float64(days))
+
+			operationID := uuid.NewString()
+			if interest > 0 {
+				description := fmt.Sprintf("Overdraft interest for %d day(s)", days)
+				if _, err := s.postEntry(ctx, repo, wallet, -interest, models.TransactionTypeOverdraftInterest, operationID, description); err != nil {
+					return err
+				}
+			}
+			if fee > 0 {
+				description := fmt.Sprintf("Overdraft fee for %d day(s)", days)
+				if _, err := s.postEntry(ctx, repo, wallet, -fee, models.TransactionTypeOverdraftFee, operationID, description); err != nil {
+					return err
+				}
+			}
+		}
+
+		return repo.UpdateCreditLineAccrual(ctx, walletID, today)
+	})
+}
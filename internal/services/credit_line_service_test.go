@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureAvailableWithCreditLine(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	wallet := &models.Wallet{ID: 3, Balance: 50, Currency: "USD"}
+	mockRepo.EXPECT().GetHeldAmount(ctx, uint64(3)).Return(20.0, nil).Times(2)
+	mockRepo.EXPECT().GetPottedAmount(ctx, uint64(3)).Return(0.0, nil).Times(2)
+	mockRepo.EXPECT().GetCreditLine(ctx, uint64(3)).Return(&models.CreditLine{WalletID: 3, Limit: 100}, nil).Times(2)
+
+	// Доступно 50 - 20 + 100 = 130
+	assert.NoError(t, service.ensureAvailable(ctx, mockRepo, wallet, 130))
+	assert.ErrorIs(t, service.ensureAvailable(ctx, mockRepo, wallet, 130.01), ErrInsufficientFunds)
+}
+
+func TestAccrueOverdraftCharges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	line := &models.CreditLine{WalletID: 3, Limit: 2000, AnnualRate: 0.365, DailyFee: 1.5, LastAccruedOn: today.AddDate(0, 0, -2)}
+
+	mockRepo.EXPECT().GetCreditLinesDueForAccrual(ctx, today, creditLineBatchSize).Return([]uint64{3}, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(3)).Return(&models.Wallet{ID: 3, Balance: -1000, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetCreditLineForUpdate(ctx, uint64(3)).Return(line, nil)
+
+	// За два дня: проценты 1000 * 0.365 / 365 * 2 = 2, плата 1.5 * 2 = 3
+	gomock.InOrder(
+		mockRepo.EXPECT().UpdateWalletBalance(uint64(3), -1002.0).Return(nil),
+		mockRepo.EXPECT().UpdateWalletBalance(uint64(3), -1005.0).Return(nil),
+	)
+	var entries []*models.Transaction
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).
+		Do(func(_ context.Context, transaction *models.Transaction) {
+			entries = append(entries, transaction)
+		}).Return(nil).Times(2)
+	mockRepo.EXPECT().UpdateCreditLineAccrual(ctx, uint64(3), today).Return(nil)
+
+	processed, err := service.AccrueOverdraftCharges(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, models.TransactionTypeOverdraftInterest, entries[0].Type)
+		assert.Equal(t, -2.0, entries[0].Amount)
+		assert.Equal(t, models.TransactionTypeOverdraftFee, entries[1].Type)
+		assert.Equal(t, -3.0, entries[1].Amount)
+		assert.Equal(t, entries[0].OperationID, entries[1].OperationID)
+	}
+}
+
+func TestAccrueOverdraftChargesPositiveBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	line := &models.CreditLine{WalletID: 3, Limit: 2000, AnnualRate: 0.2, DailyFee: 1, LastAccruedOn: today.AddDate(0, 0, -1)}
+
+	mockRepo.EXPECT().GetCreditLinesDueForAccrual(ctx, today, creditLineBatchSize).Return([]uint64{3}, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(3)).Return(&models.Wallet{ID: 3, Balance: 10, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetCreditLineForUpdate(ctx, uint64(3)).Return(line, nil)
+	// Задолженности нет - сдвигается только дата начисления
+	mockRepo.EXPECT().UpdateCreditLineAccrual(ctx, uint64(3), today).Return(nil)
+
+	processed, err := service.AccrueOverdraftCharges(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+}
+
+func TestDeleteCreditLineWithDebt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(3)).Return(&models.Wallet{ID: 3, Balance: -5}, nil)
+	mockRepo.EXPECT().GetCreditLineForUpdate(ctx, uint64(3)).Return(&models.CreditLine{WalletID: 3, Limit: 100}, nil)
+
+	err := service.DeleteCreditLine(ctx, 3)
+	assert.ErrorIs(t, err, ErrCreditLineInUse)
+}
+
+func TestSetCreditLineInvalid(t *testing.T) {
+	service := &service{logger: logrus.New()}
+
+	_, err := service.SetCreditLine(context.Background(), 3, &models.CreditLineRequest{Limit: 100, AnnualRate: 1.5})
+	assert.ErrorIs(t, err, ErrInvalidCreditLine)
+}
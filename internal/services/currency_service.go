@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// currencyCodePattern - формат буквенного кода валюты ISO 4217.
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// maxMinorUnits ограничено точностью хранения сумм в БД (NUMERIC(18, 2)).
+const maxMinorUnits = 2
+
+// GetCurrencies возвращает валюты справочника; отключённые - только если includeDisabled.
+func (s *service) GetCurrencies(ctx context.Context, includeDisabled bool) ([]*models.Currency, error) {
+	return s.repo.GetCurrencies(ctx, !includeDisabled)
+}
+
+// CreateCurrency добавляет валюту в справочник.
+func (s *service) CreateCurrency(ctx context.Context, request *models.CurrencyRequest) (*models.Currency, error) {
+	if !currencyCodePattern.MatchString(request.Code) {
+		return nil, fmt.Errorf("%w: code must be three uppercase letters", ErrInvalidCurrency)
+	}
+
+	existing, err := s.repo.GetCurrencyByCode(ctx, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrCurrencyExists
+	}
+
+	currency := &models.Currency{
+		Code:       request.Code,
+		Name:       request.Code,
+		MinorUnits: maxMinorUnits,
+		Enabled:    true,
+	}
+	applyCurrencyRequest(currency, request)
+	if err := validateCurrency(currency); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateCurrency(ctx, currency); err != nil {
+		return nil, err
+	}
+	return currency, nil
+}
+
+// UpdateCurrency изменяет метаданные валюты или включает/отключает её.
+func (s *service) UpdateCurrency(ctx context.Context, code string, request *models.CurrencyRequest) (*models.Currency, error) {
+	currency, err := s.repo.GetCurrencyByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if currency == nil {
+		return nil, ErrCurrencyNotFound
+	}
+
+	applyCurrencyRequest(currency, request)
+	if err := validateCurrency(currency); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateCurrency(ctx, currency); err != nil {
+		return nil, err
+	}
+	return currency, nil
+}
+
+// GetEnabledRates возвращает курсы только для включённых валют справочника.
+func (s *service) GetEnabledRates(ctx context.Context) (map[string]float64, error) {
+	rates, err := s.GetAllRates()
+	if err != nil {
+		return nil, err
+	}
+
+	currencies, err := s.repo.GetCurrencies(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[string]float64, len(currencies))
+	for _, currency := range currencies {
+		if rate, ok := rates[currency.Code]; ok {
+			enabled[currency.Code] = rate
+		}
+	}
+	return enabled, nil
+}
+
+// requireCurrency возвращает валюту справочника, если она существует и включена.
+func (s *service) requireCurrency(ctx context.Context, code string) (*models.Currency, error) {
+	currency, err := s.repo.GetCurrencyByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency %s: %v", code, err)
+	}
+	if currency == nil || !currency.Enabled {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, code)
+	}
+	return currency, nil
+}
+
+// validateAmount проверяет, что валюта включена, а сумма положительна, не превышает
+// лимит операции тенанта и не точнее минимальной единицы валюты.
+func (s *service) validateAmount(ctx context.Context, code string, amount float64) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	currency, err := s.requireCurrency(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	if currency.MaxAmount > 0 && amount > currency.MaxAmount {
+		return fmt.Errorf("%w: %s amount exceeds the limit of %g", ErrInvalidAmount, code, currency.MaxAmount)
+	}
+
+	scaled := amount * math.Pow10(currency.MinorUnits)
+	if math.Abs(scaled-math.Round(scaled)) > 1e-6 {
+		return fmt.Errorf("%w: %s allows at most %d decimal places", ErrInvalidAmount, code, currency.MinorUnits)
+	}
+	return nil
+}
+
+func applyCurrencyRequest(currency *models.Currency, request *models.CurrencyRequest) {
+	if request.Name != nil {
+		currency.Name = *request.Name
+	}
+	if request.MinorUnits != nil {
+		currency.MinorUnits = *request.MinorUnits
+	}
+	if request.Symbol != nil {
+		currency.Symbol = *request.Symbol
+	}
+	if request.Enabled != nil {
+		currency.Enabled = *request.Enabled
+	}
+	if request.InterestRate != nil {
+		currency.InterestRate = *request.InterestRate
+	}
+	if request.MaxAmount != nil {
+		currency.MaxAmount = *request.MaxAmount
+	}
+}
+
+func validateCurrency(currency *models.Currency) error {
+	if currency.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCurrency)
+	}
+	if currency.MinorUnits < 0 || currency.MinorUnits > maxMinorUnits {
+		return fmt.Errorf("%w: minor_units must be between 0 and %d", ErrInvalidCurrency, maxMinorUnits)
+	}
+	if currency.InterestRate < 0 || currency.InterestRate > 1 {
+		return fmt.Errorf("%w: interest_rate must be between 0 and 1", ErrInvalidCurrency)
+	}
+	if currency.MaxAmount < 0 {
+		return fmt.Errorf("%w: max_amount must not be negative", ErrInvalidCurrency)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAmount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil).AnyTimes()
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "JPY").Return(&models.Currency{Code: "JPY", MinorUnits: 0, Enabled: true}, nil).AnyTimes()
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "XXX").Return(&models.Currency{Code: "XXX", Enabled: false}, nil).AnyTimes()
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "ABC").Return(nil, nil).AnyTimes()
+
+	assert.NoError(t, service.validateAmount(ctx, "USD", 10.25))
+	assert.NoError(t, service.validateAmount(ctx, "USD", 0.1+0.2))
+	assert.ErrorIs(t, service.validateAmount(ctx, "USD", 10.255), ErrInvalidAmount)
+	assert.ErrorIs(t, service.validateAmount(ctx, "USD", -1), ErrInvalidAmount)
+	assert.NoError(t, service.validateAmount(ctx, "JPY", 1500))
+	assert.ErrorIs(t, service.validateAmount(ctx, "JPY", 1500.5), ErrInvalidAmount)
+	assert.ErrorIs(t, service.validateAmount(ctx, "XXX", 1), ErrUnsupportedCurrency)
+	assert.ErrorIs(t, service.validateAmount(ctx, "ABC", 1), ErrUnsupportedCurrency)
+}
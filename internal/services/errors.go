@@ -0,0 +1,107 @@
+package services
+
+import "errors"
+
+// Ошибки бизнес-логики, которые обработчики сопоставляют с HTTP-статусами.
+var (
+	ErrInvalidAmount     = errors.New("invalid amount")
+	ErrWalletNotFound    = errors.New("wallet for the specified currency does not exist")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrConversionFailed  = errors.New("currency conversion failed")
+	ErrRateUnavailable   = errors.New("exchange rate is unavailable")
+	ErrInvalidPeriod     = errors.New("invalid period")
+	ErrSelfTransfer      = errors.New("cannot transfer to yourself")
+	ErrSameCurrency      = errors.New("source and target currencies must differ")
+
+	ErrRecipientNotFound       = errors.New("recipient not found")
+	ErrRecipientWalletNotFound = errors.New("recipient has no wallet in the specified currency")
+
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidCurrency     = errors.New("invalid currency")
+	ErrCurrencyNotFound    = errors.New("currency not found")
+	ErrCurrencyExists      = errors.New("currency already exists")
+
+	ErrHoldNotFound      = errors.New("hold not found")
+	ErrHoldNotActive     = errors.New("hold is not active")
+	ErrHoldExpired       = errors.New("hold has expired")
+	ErrHoldSystemManaged = errors.New("hold is managed by a withdrawal or limit order")
+	ErrCaptureExceedHold = errors.New("capture amount exceeds held amount")
+
+	ErrScheduleNotFound   = errors.New("scheduled transfer not found")
+	ErrInvalidSchedule    = errors.New("invalid schedule")
+	ErrScheduleNotAllowed = errors.New("operation is not allowed in the current schedule status")
+
+	ErrOrderNotFound = errors.New("limit order not found")
+	ErrOrderNotOpen  = errors.New("limit order is not open")
+	ErrInvalidOrder  = errors.New("invalid limit order")
+
+	ErrReconciliationNotFound = errors.New("reconciliation run not found")
+
+	ErrPaymentRequestNotFound   = errors.New("payment request not found")
+	ErrPaymentRequestNotPending = errors.New("payment request is not pending")
+	ErrPaymentRequestExpired    = errors.New("payment request has expired")
+	ErrInvalidPaymentRequest    = errors.New("invalid payment request")
+
+	ErrUnknownProvider      = errors.New("unknown deposit provider")
+	ErrInvalidSignature     = errors.New("invalid webhook signature")
+	ErrInvalidDepositEvent  = errors.New("invalid deposit event")
+	ErrInvalidDepositStatus = errors.New("invalid deposit status")
+
+	ErrRebalanceNotFound   = errors.New("rebalance plan not found")
+	ErrRebalanceNotPending = errors.New("rebalance plan has already been executed")
+	ErrRebalanceExpired    = errors.New("rebalance plan has expired")
+	ErrInvalidRebalance    = errors.New("invalid rebalance targets")
+	ErrNothingToRebalance  = errors.New("no available funds to rebalance")
+
+	ErrWithdrawalNotFound      = errors.New("withdrawal not found")
+	ErrWithdrawalStateConflict = errors.New("withdrawal is not in the required state")
+	ErrInvalidWithdrawal       = errors.New("invalid withdrawal")
+	ErrPayoutUnavailable       = errors.New("payout provider is not configured")
+
+	ErrWalletForbidden    = errors.New("operation is not permitted for this wallet role")
+	ErrSpendLimitExceeded = errors.New("amount exceeds member spend limit")
+	ErrInvalidMember      = errors.New("invalid wallet member")
+	ErrMemberExists       = errors.New("user is already a wallet member")
+	ErrMemberNotFound     = errors.New("wallet member not found")
+	ErrInvitationNotFound = errors.New("wallet invitation not found")
+
+	ErrVoucherNotFound        = errors.New("voucher not found")
+	ErrVoucherExpired         = errors.New("voucher has expired")
+	ErrVoucherExhausted       = errors.New("voucher has no redemptions left")
+	ErrVoucherAlreadyRedeemed = errors.New("voucher has already been redeemed by this user")
+	ErrVoucherCodeExists      = errors.New("voucher code already exists")
+	ErrInvalidVoucherBatch    = errors.New("invalid voucher batch")
+
+	ErrPotNotFound          = errors.New("pot not found")
+	ErrPotClosed            = errors.New("pot is closed")
+	ErrInvalidPot           = errors.New("invalid pot")
+	ErrPotInsufficientFunds = errors.New("insufficient funds in pot")
+
+	ErrCreditLineNotFound = errors.New("credit line not found")
+	ErrCreditLineInUse    = errors.New("credit line has outstanding debt")
+	ErrInvalidCreditLine  = errors.New("invalid credit line")
+
+	ErrUserNotFound    = errors.New("user not found")
+	ErrAccountFrozen   = errors.New("account is frozen")
+	ErrWalletFrozen    = errors.New("wallet is frozen")
+	ErrInvalidFreeze   = errors.New("invalid freeze state")
+	ErrFreezeUnchanged = errors.New("freeze state is already set")
+
+	ErrTenantNotFound = errors.New("tenant not found")
+
+	ErrPreconditionFailed = errors.New("wallet has changed since it was read")
+
+	ErrWebhookNotFound = errors.New("webhook subscription or delivery not found")
+	ErrInvalidWebhook  = errors.New("invalid webhook subscription")
+
+	ErrInvalidStream = errors.New("invalid stream subscription")
+	ErrStreamClosed  = errors.New("stream closed")
+
+	ErrNotificationNotFound          = errors.New("notification not found")
+	ErrInvalidNotificationPreference = errors.New("invalid notification preference")
+
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrAlreadyReversed     = errors.New("operation has already been reversed")
+	ErrNotReversible       = errors.New("operation cannot be reversed")
+	ErrReasonRequired      = errors.New("reason is required")
+)
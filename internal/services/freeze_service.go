@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+)
+
+// SetUserFreeze устанавливает состояние заморозки пользователя и записывает изменение в журнал.
+// Состояние none снимает заморозку.
+func (s *service) SetUserFreeze(ctx context.Context, operatorID, userID uint64, state, reason string) (*models.FreezeEvent, error) {
+	reason, err := validateFreeze(state, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &models.FreezeEvent{
+		TargetType: models.FreezeTargetUser,
+		TargetID:   userID,
+		State:      state,
+		Reason:     reason,
+		OperatorID: operatorID,
+	}
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		user, err := repo.GetUserByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if !sameTenant(ctx, user.TenantID) {
+			return ErrUserNotFound
+		}
+		if user.FreezeState == state {
+			return ErrFreezeUnchanged
+		}
+
+		event.PreviousState = user.FreezeState
+		if err := repo.UpdateUserFreezeState(ctx, userID, state); err != nil {
+			return err
+		}
+		return repo.CreateFreezeEvent(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("User %d freeze state changed from %s to %s by operator %d: %s",
+		userID, event.PreviousState, state, operatorID, reason)
+	return event, nil
+}
+
+// SetWalletFreeze устанавливает состояние заморозки кошелька и записывает изменение в журнал.
+// Состояние none снимает заморозку.
+func (s *service) SetWalletFreeze(ctx context.Context, operatorID, walletID uint64, state, reason string) (*models.FreezeEvent, error) {
+	reason, err := validateFreeze(state, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &models.FreezeEvent{
+		TargetType: models.FreezeTargetWallet,
+		TargetID:   walletID,
+		State:      state,
+		Reason:     reason,
+		OperatorID: operatorID,
+	}
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, walletID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+		if !sameTenant(ctx, wallet.TenantID) {
+			return ErrWalletNotFound
+		}
+		if wallet.FreezeState == state {
+			return ErrFreezeUnchanged
+		}
+
+		event.PreviousState = wallet.FreezeState
+		if err := repo.UpdateWalletFreezeState(ctx, walletID, state); err != nil {
+			return err
+		}
+		return repo.CreateFreezeEvent(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Wallet %d freeze state changed from %s to %s by operator %d: %s",
+		walletID, event.PreviousState, state, operatorID, reason)
+	return event, nil
+}
+
+// GetFreezeEvents возвращает журнал заморозок пользователя или кошелька.
+func (s *service) GetFreezeEvents(ctx context.Context, targetType string, targetID uint64) ([]*models.FreezeEvent, error) {
+	if targetType == models.FreezeTargetUser {
+		ok, err := userInTenant(ctx, s.repo, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrUserNotFound
+		}
+	} else {
+		ok, err := walletInTenant(ctx, s.repo, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrWalletNotFound
+		}
+	}
+	return s.repo.GetFreezeEvents(ctx, targetType, targetID)
+}
+
+// ensureDebitAllowed проверяет, что ни кошелёк, ни его владелец, ни выполняющий операцию
+// участник не заморожены для списаний.
+func (s *service) ensureDebitAllowed(ctx context.Context, repo repository.Repository, wallet *models.Wallet) error {
+	return s.ensureNotFrozen(ctx, repo, wallet, models.FreezeStateDebit, models.FreezeStateFull)
+}
+
+// ensureCreditAllowed проверяет, что ни кошелёк, ни его владелец, ни выполняющий операцию
+// участник не заморожены полностью.
+func (s *service) ensureCreditAllowed(ctx context.Context, repo repository.Repository, wallet *models.Wallet) error {
+	return s.ensureNotFrozen(ctx, repo, wallet, models.FreezeStateFull)
+}
+
+// ensureNotFrozen возвращает ошибку, если состояние кошелька, его владельца или участника
+// совместного кошелька, от имени которого выполняется операция (withActor), входит в blocking.
+func (s *service) ensureNotFrozen(ctx context.Context, repo repository.Repository, wallet *models.Wallet, blocking ...string) error {
+	if containsState(blocking, wallet.FreezeState) {
+		return fmt.Errorf("%w: %s", ErrWalletFrozen, wallet.Currency)
+	}
+
+	user, err := repo.GetUserByID(ctx, wallet.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve wallet owner: %v", err)
+	}
+	if containsState(blocking, user.FreezeState) {
+		return ErrAccountFrozen
+	}
+
+	if actorID := actorFromContext(ctx); actorID != 0 && actorID != wallet.UserID {
+		actor, err := repo.GetUserByID(ctx, actorID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve wallet member: %v", err)
+		}
+		if containsState(blocking, actor.FreezeState) {
+			return ErrAccountFrozen
+		}
+	}
+	return nil
+}
+
+// validateFreeze проверяет состояние заморозки и возвращает очищенную причину.
+func validateFreeze(state, reason string) (string, error) {
+	switch state {
+	case models.FreezeStateNone, models.FreezeStateDebit, models.FreezeStateFull:
+	default:
+		return "", ErrInvalidFreeze
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", ErrReasonRequired
+	}
+	return reason, nil
+}
+
+func containsState(states []string, state string) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebitFreezeBlocksWithdrawOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").
+		Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil).AnyTimes()
+	mockRepo.EXPECT().GetWalletByUserAndCurrencyForUpdate(ctx, uint64(1), "USD").
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 100, Currency: "USD", FreezeState: models.FreezeStateNone}, nil).AnyTimes()
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).
+		Return(&models.User{ID: 1, FreezeState: models.FreezeStateDebit}, nil).AnyTimes()
+
+	_, err := service.Withdraw(ctx, 1, 10, "USD")
+	assert.ErrorIs(t, err, ErrAccountFrozen)
+
+	// Зачисление при заморозке списаний разрешено
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(7), 110.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().GetWalletsByUserID(uint64(1)).
+		Return([]*models.Wallet{{ID: 7, UserID: 1, Balance: 110, Currency: "USD"}}, nil)
+
+	balances, err := service.Deposit(ctx, 1, 10, "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, 110.0, balances["USD"])
+}
+
+func TestFullWalletFreezeBlocksDeposit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").
+		Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrencyForUpdate(ctx, uint64(1), "USD").
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 100, Currency: "USD", FreezeState: models.FreezeStateFull}, nil)
+
+	_, err := service.Deposit(ctx, 1, 10, "USD")
+	assert.ErrorIs(t, err, ErrWalletFrozen)
+}
+
+func TestSetWalletFreeze(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().UpdateWalletFreezeState(ctx, uint64(7), models.FreezeStateDebit).Return(nil)
+	mockRepo.EXPECT().CreateFreezeEvent(ctx, gomock.Any()).Return(nil)
+
+	event, err := service.SetWalletFreeze(ctx, 99, 7, models.FreezeStateDebit, "under investigation")
+	assert.NoError(t, err)
+	assert.Equal(t, models.FreezeTargetWallet, event.TargetType)
+	assert.Equal(t, models.FreezeStateNone, event.PreviousState)
+	assert.Equal(t, uint64(99), event.OperatorID)
+
+	_, err = service.SetWalletFreeze(ctx, 99, 7, "partial", "under investigation")
+	assert.ErrorIs(t, err, ErrInvalidFreeze)
+
+	_, err = service.SetWalletFreeze(ctx, 99, 7, models.FreezeStateFull, " ")
+	assert.ErrorIs(t, err, ErrReasonRequired)
+}
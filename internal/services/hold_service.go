@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultHoldTTL - срок действия блокировки, если клиент его не указал.
+	DefaultHoldTTL = 7 * 24 * time.Hour
+	// MaxHoldTTL - максимально допустимый срок действия блокировки.
+	MaxHoldTTL = 30 * 24 * time.Hour
+)
+
+// AuthorizeHold блокирует сумму на кошельке пользователя, уменьшая доступный баланс.
+func (s *service) AuthorizeHold(ctx context.Context, userID uint64, amount float64, currency, reference string, ttl time.Duration) (*models.Hold, error) {
+	if err := s.validateAmount(ctx, currency, amount); err != nil {
+		return nil, err
+	}
+
+	var hold *models.Hold
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		wallet, err := s.lockWallet(ctx, repo, userID, currency)
+		if err != nil {
+			return err
+		}
+
+		hold, err = s.createHold(ctx, repo, wallet, models.HoldKindUser, amount, reference, time.Now().Add(holdTTL(ttl)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// AuthorizeWalletHold блокирует сумму на кошельке по ID. Доступно владельцам и участникам
+// с ролью spender; сумма блокировки участника spender должна укладываться в его лимит.
+func (s *service) AuthorizeWalletHold(ctx context.Context, userID, walletID uint64, amount float64, reference string, ttl time.Duration) (*models.Hold, error) {
+	roles := []string{models.WalletRoleOwner, models.WalletRoleSpender}
+	ctx = withActor(ctx, userID)
+
+	var hold *models.Hold
+	_, err := s.operateWallet(ctx, userID, walletID, amount, roles, func(repo repository.Repository, wallet *models.Wallet, member *models.WalletMember) error {
+		if err := ensureSpendLimit(ctx, repo, member, amount); err != nil {
+			return err
+		}
+		var err error
+		hold, err = s.createHold(ctx, repo, wallet, models.HoldKindUser, amount, reference, time.Now().Add(holdTTL(ttl)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// CaptureHold списывает заблокированные средства с кошелька. Если amount равен нулю,
+// списывается вся сумма блокировки; при частичном списании остаток освобождается.
+// Доступно владельцам кошелька и участникам с ролью spender в пределах их лимита.
+func (s *service) CaptureHold(ctx context.Context, userID, holdID uint64, amount float64) (*models.Hold, error) {
+	if amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	hold, err := s.getHold(ctx, s.repo, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = withActor(ctx, userID)
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		// Кошелёк блокируется раньше блокировки средств, как и при её создании.
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, hold.WalletID)
+		if err != nil {
+			return fmt.Errorf("failed to lock wallet %d: %v", hold.WalletID, err)
+		}
+		member, err := s.authorizeHold(ctx, repo, userID, wallet)
+		if err != nil {
+			return err
+		}
+
+		hold, err = s.lockActiveHold(ctx, repo, holdID)
+		if err != nil {
+			return err
+		}
+		if err := s.ensureDebitAllowed(ctx, repo, wallet); err != nil {
+			return err
+		}
+
+		captured := amount
+		if captured == 0 {
+			captured = hold.Amount
+		}
+		if captured > hold.Amount {
+			return ErrCaptureExceedHold
+		}
+		// Собственная блокировка участника уже учтена в его лимите и заменяется списанием.
+		reserved := 0.0
+		if hold.InitiatedBy == member.UserID {
+			reserved = hold.Amount
+		}
+		if err := ensureSpendLimit(ctx, repo, member, captured-reserved); err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("Capture of hold %d", hold.ID)
+		if hold.Reference != "" {
+			description += ": " + hold.Reference
+		}
+		if _, err := s.postEntry(ctx, repo, wallet, -captured, models.TransactionTypeHoldCapture, uuid.NewString(), description); err != nil {
+			return err
+		}
+
+		hold.Status = models.HoldStatusCaptured
+		hold.CapturedAmount = captured
+		return repo.UpdateHold(ctx, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ReleaseHold снимает блокировку, возвращая средства в доступный баланс.
+// Доступно владельцам кошелька и участникам с ролью spender.
+func (s *service) ReleaseHold(ctx context.Context, userID, holdID uint64) (*models.Hold, error) {
+	var hold *models.Hold
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		hold, err = s.getHold(ctx, repo, holdID)
+		if err != nil {
+			return err
+		}
+		wallet, err := repo.GetWalletByID(ctx, hold.WalletID)
+		if err != nil {
+			return fmt.Errorf("failed to get wallet %d: %v", hold.WalletID, err)
+		}
+		if _, err := s.authorizeHold(ctx, repo, userID, wallet); err != nil {
+			return err
+		}
+
+		hold, err = s.lockActiveHold(ctx, repo, holdID)
+		if err != nil {
+			return err
+		}
+
+		hold.Status = models.HoldStatusReleased
+		return repo.UpdateHold(ctx, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// GetHolds возвращает все блокировки пользователя.
+func (s *service) GetHolds(ctx context.Context, userID uint64) ([]*models.Hold, error) {
+	return s.repo.GetHoldsByUserID(ctx, userID)
+}
+
+// ExpireHolds освобождает все блокировки с истёкшим сроком действия.
+func (s *service) ExpireHolds(ctx context.Context) (int64, error) {
+	return s.repo.ExpireHolds(ctx, time.Now())
+}
+
+// createHold блокирует amount на заблокированном кошельке после проверки заморозки и доступного баланса.
+// kind задаёт вид блокировки. Должен вызываться внутри транзакции.
+func (s *service) createHold(ctx context.Context, repo repository.Repository, wallet *models.Wallet, kind string, amount float64, reference string, expiresAt time.Time) (*models.Hold, error) {
+	if err := s.ensureDebitAllowed(ctx, repo, wallet); err != nil {
+		return nil, err
+	}
+	if err := s.ensureAvailable(ctx, repo, wallet, amount); err != nil {
+		return nil, err
+	}
+
+	hold := &models.Hold{
+		WalletID:    wallet.ID,
+		UserID:      wallet.UserID,
+		Currency:    wallet.Currency,
+		Amount:      amount,
+		Kind:        kind,
+		Status:      models.HoldStatusActive,
+		Reference:   reference,
+		ExpiresAt:   expiresAt,
+		InitiatedBy: actorFromContext(ctx),
+	}
+	var err error
+	hold.ID, err = repo.CreateHold(ctx, hold)
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// getHold возвращает блокировку по ID.
+func (s *service) getHold(ctx context.Context, repo repository.Repository, holdID uint64) (*models.Hold, error) {
+	hold, err := repo.GetHoldByID(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if hold == nil {
+		return nil, ErrHoldNotFound
+	}
+	return hold, nil
+}
+
+// authorizeHold проверяет, что пользователь может списывать и освобождать блокировки кошелька.
+// Блокировки кошельков, к которым у пользователя нет доступа, для него не существуют.
+func (s *service) authorizeHold(ctx context.Context, repo repository.Repository, userID uint64, wallet *models.Wallet) (*models.WalletMember, error) {
+	member, err := s.authorizeWallet(ctx, repo, userID, wallet, models.WalletRoleOwner, models.WalletRoleSpender)
+	if errors.Is(err, ErrWalletNotFound) {
+		return nil, ErrHoldNotFound
+	}
+	return member, err
+}
+
+// holdTTL ограничивает запрошенный срок действия блокировки; нулевой срок заменяется значением по умолчанию.
+func holdTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return DefaultHoldTTL
+	}
+	if ttl > MaxHoldTTL {
+		return MaxHoldTTL
+	}
+	return ttl
+}
+
+// lockActiveHold блокирует строку блокировки и проверяет, что её ещё можно списать или освободить.
+// Блокировки выводов и лимитных заявок снимает только сам сервис.
+func (s *service) lockActiveHold(ctx context.Context, repo repository.Repository, holdID uint64) (*models.Hold, error) {
+	hold, err := repo.GetHoldByIDForUpdate(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if hold == nil {
+		return nil, ErrHoldNotFound
+	}
+	if hold.Kind != models.HoldKindUser {
+		return nil, ErrHoldSystemManaged
+	}
+	if hold.Status != models.HoldStatusActive {
+		return nil, ErrHoldNotActive
+	}
+	if !hold.ExpiresAt.After(time.Now()) {
+		return nil, ErrHoldExpired
+	}
+	return hold, nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectTransaction настраивает мок так, чтобы WithinTransaction выполнял функцию на том же моке.
+func expectTransaction(mockRepo *mocks.MockRepository) {
+	mockRepo.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(repo repository.Repository) error) error {
+			return fn(mockRepo)
+		}).AnyTimes()
+}
+
+// expectJournalHead настраивает мок так, чтобы у всех кошельков ещё не было проводок в журнале,
+// а события о новых проводках записывались в outbox, вебхуки и уведомления без ошибок.
+func expectJournalHead(mockRepo *mocks.MockRepository) {
+	mockRepo.EXPECT().GetLastTransactionHash(gomock.Any(), gomock.Any()).Return("", nil).AnyTimes()
+	mockRepo.EXPECT().CreateOutboxEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().CreateWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().GetNotificationPreference(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockRepo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+}
+
+func TestAuthorizeHoldInsufficientFunds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	wallet := &models.Wallet{ID: 7, UserID: 1, Balance: 100, Currency: "USD"}
+	mockRepo.EXPECT().GetWalletByUserAndCurrencyForUpdate(gomock.Any(), uint64(1), "USD").Return(wallet, nil)
+	mockRepo.EXPECT().GetHeldAmount(gomock.Any(), uint64(7)).Return(80.0, nil)
+	mockRepo.EXPECT().GetPottedAmount(gomock.Any(), uint64(7)).Return(0.0, nil)
+	mockRepo.EXPECT().GetCreditLine(gomock.Any(), uint64(7)).Return(nil, nil)
+
+	hold, err := service.AuthorizeHold(ctx, 1, 30, "USD", "order-1", 0)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	assert.Nil(t, hold)
+}
+
+func TestCaptureHoldPartial(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	active := &models.Hold{
+		ID:        3,
+		WalletID:  7,
+		UserID:    1,
+		Amount:    50,
+		Kind:      models.HoldKindUser,
+		Status:    models.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil).AnyTimes()
+	mockRepo.EXPECT().GetHoldByID(gomock.Any(), uint64(3)).Return(active, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 100, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetHoldByIDForUpdate(gomock.Any(), uint64(3)).Return(active, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(7), 80.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, transaction *models.Transaction) {
+			assert.Equal(t, models.TransactionTypeHoldCapture, transaction.Type)
+			assert.Equal(t, -20.0, transaction.Amount)
+			assert.Equal(t, 80.0, transaction.BalanceAfter)
+		}).Return(nil)
+	mockRepo.EXPECT().UpdateHold(gomock.Any(), gomock.Any()).Return(nil)
+
+	hold, err := service.CaptureHold(ctx, 1, 3, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HoldStatusCaptured, hold.Status)
+	assert.Equal(t, 20.0, hold.CapturedAmount)
+
+	// Повторное списание сверх заблокированной суммы недопустимо
+	exceeding := *active
+	exceeding.Status = models.HoldStatusActive
+	mockRepo.EXPECT().GetHoldByID(gomock.Any(), uint64(3)).Return(&exceeding, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 100, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetHoldByIDForUpdate(gomock.Any(), uint64(3)).Return(&exceeding, nil)
+
+	_, err = service.CaptureHold(ctx, 1, 3, 60)
+	assert.ErrorIs(t, err, ErrCaptureExceedHold)
+}
+
+func TestReleaseHoldWithdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	hold := &models.Hold{
+		ID:        3,
+		WalletID:  7,
+		UserID:    1,
+		Amount:    50,
+		Kind:      models.HoldKindWithdrawal,
+		Status:    models.HoldStatusActive,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	mockRepo.EXPECT().GetHoldByID(gomock.Any(), uint64(3)).Return(hold, nil)
+	mockRepo.EXPECT().GetWalletByID(gomock.Any(), uint64(7)).Return(&models.Wallet{ID: 7, UserID: 1, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetHoldByIDForUpdate(gomock.Any(), uint64(3)).Return(hold, nil)
+
+	// Резерв вывода снимается только при его завершении, иначе средства можно потратить дважды
+	_, err := service.ReleaseHold(ctx, 1, 3)
+	assert.ErrorIs(t, err, ErrHoldSystemManaged)
+	assert.Equal(t, models.HoldStatusActive, hold.Status)
+}
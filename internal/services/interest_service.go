@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// interestBatchSize - количество кошельков, обрабатываемых за один проход воркера.
+	interestBatchSize = 200
+	// interestCatchUpDays - сколько пропущенных дней догоняется при простое воркера.
+	interestCatchUpDays = 31
+)
+
+// GetAccruedInterest возвращает по кошелькам пользователя начисленные, но ещё не выплаченные проценты.
+func (s *service) GetAccruedInterest(ctx context.Context, userID uint64) ([]*models.InterestSummary, error) {
+	return s.repo.GetUnpaidInterestByUserID(ctx, userID)
+}
+
+// AccrueInterest начисляет проценты на положительные остатки кошельков за прошедшие сутки (UTC)
+// и выплачивает накопленные за прошлые месяцы начисления проводкой в журнале.
+// Возвращает количество кошельков, по которым были начисления или выплаты.
+func (s *service) AccrueInterest(ctx context.Context) (int, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	accrued, err := s.accrueDailyInterest(ctx, today.AddDate(0, 0, -1))
+	if err != nil {
+		return accrued, err
+	}
+
+	paid, err := s.payMonthlyInterest(ctx, time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC))
+	return accrued + paid, err
+}
+
+// accrueDailyInterest записывает начисления по дням до day включительно от остатка на конец дня.
+// Пропущенные дни догоняются по текущей ставке валюты, но не больше чем за interestCatchUpDays.
+// Дни с неположительным остатком фиксируются нулевым начислением, чтобы не пересчитываться повторно.
+func (s *service) accrueDailyInterest(ctx context.Context, day time.Time) (int, error) {
+	due, err := s.repo.GetWalletsDueForInterest(ctx, day, interestBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	earliest := day.AddDate(0, 0, -(interestCatchUpDays - 1))
+	processed := 0
+	for _, item := range due {
+		from := day
+		if item.LastAccrualOn != nil {
+			from = item.LastAccrualOn.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+		}
+		if from.Before(earliest) {
+			from = earliest
+		}
+
+		if err := s.accrueWalletInterest(ctx, item, from, day); err != nil {
+			s.logger.Errorf("Failed to accrue interest for wallet %d: %v", item.WalletID, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// accrueWalletInterest записывает начисления по кошельку за дни [from, to].
+func (s *service) accrueWalletInterest(ctx context.Context, item *models.InterestDue, from, to time.Time) error {
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		balance, err := s.repo.GetBalanceAt(ctx, item.WalletID, day.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+
+		accrual := &models.InterestAccrual{
+			WalletID:    item.WalletID,
+			AccrualDate: day,
+			Balance:     balance,
+			AnnualRate:  item.AnnualRate,
+		}
+		if balance > 0 {
+			accrual.Amount = balance * item.AnnualRate / daysInYear
+		}
+		if _, err := s.repo.CreateInterestAccrual(ctx, accrual); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// payMonthlyInterest выплачивает начисления за даты раньше monthStart.
+func (s *service) payMonthlyInterest(ctx context.Context, monthStart time.Time) (int, error) {
+	walletIDs, err := s.repo.GetWalletsWithUnpaidInterest(ctx, monthStart, interestBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, walletID := range walletIDs {
+		if err := s.payWalletInterest(ctx, walletID, monthStart); err != nil {
+			s.logger.Errorf("Failed to pay interest for wallet %d: %v", walletID, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// payWalletInterest зачисляет на кошелёк сумму невыплаченных начислений, округлённую до копеек,
+// и отмечает начисления выплаченными. Остаток меньше минимальной единицы не переносится.
+// Зачисление процентов не проверяет заморозку: это обязательство сервиса, а не операция клиента.
+func (s *service) payWalletInterest(ctx context.Context, walletID uint64, monthStart time.Time) error {
+	return s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, walletID)
+		if err != nil {
+			return err
+		}
+
+		operationID := uuid.NewString()
+		total, err := repo.PayInterestAccruals(ctx, walletID, monthStart, operationID)
+		if err != nil {
+			return err
+		}
+
+		amount := roundAmount(total)
+		if amount <= 0 {
+			return nil
+		}
+		description := fmt.Sprintf("Interest accrued before %s", monthStart.Format("2006-01-02"))
+		_, err = s.postEntry(ctx, repo, wallet, amount, models.TransactionTypeInterest, operationID, description)
+		return err
+	})
+}
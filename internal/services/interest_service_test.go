@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccrueInterestCatchUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+	last := today.AddDate(0, 0, -3)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().GetWalletsDueForInterest(ctx, yesterday, interestBatchSize).
+		Return([]*models.InterestDue{{WalletID: 5, Currency: "USD", AnnualRate: 0.0365, LastAccrualOn: &last}}, nil)
+	// Пропущены два дня: остаток на конец первого положительный, второго - отрицательный
+	mockRepo.EXPECT().GetBalanceAt(ctx, uint64(5), today.AddDate(0, 0, -1)).Return(1000.0, nil)
+	mockRepo.EXPECT().GetBalanceAt(ctx, uint64(5), today).Return(-50.0, nil)
+
+	var accruals []*models.InterestAccrual
+	mockRepo.EXPECT().CreateInterestAccrual(ctx, gomock.Any()).
+		Do(func(_ context.Context, accrual *models.InterestAccrual) {
+			accruals = append(accruals, accrual)
+		}).Return(true, nil).Times(2)
+	mockRepo.EXPECT().GetWalletsWithUnpaidInterest(ctx, monthStart, interestBatchSize).Return(nil, nil)
+
+	processed, err := service.AccrueInterest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	if assert.Len(t, accruals, 2) {
+		assert.Equal(t, today.AddDate(0, 0, -2), accruals[0].AccrualDate)
+		assert.InDelta(t, 0.1, accruals[0].Amount, 1e-9)
+		assert.Equal(t, yesterday, accruals[1].AccrualDate)
+		assert.Equal(t, 0.0, accruals[1].Amount)
+	}
+}
+
+func TestAccrueInterestMonthlyPayout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().GetWalletsDueForInterest(ctx, today.AddDate(0, 0, -1), interestBatchSize).Return(nil, nil)
+	mockRepo.EXPECT().GetWalletsWithUnpaidInterest(ctx, monthStart, interestBatchSize).Return([]uint64{5, 6}, nil)
+
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(5)).Return(&models.Wallet{ID: 5, Balance: 1000, Currency: "USD"}, nil)
+	var operationID string
+	mockRepo.EXPECT().PayInterestAccruals(ctx, uint64(5), monthStart, gomock.Any()).
+		Do(func(_ context.Context, _ uint64, _ time.Time, id string) {
+			operationID = id
+		}).Return(3.04666667, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(5), 1003.05).Return(nil)
+	var entry *models.Transaction
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).
+		Do(func(_ context.Context, transaction *models.Transaction) {
+			entry = transaction
+		}).Return(nil)
+
+	// Сумма меньше копейки отмечается выплаченной без проводки
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(6)).Return(&models.Wallet{ID: 6, Balance: 1, Currency: "EUR"}, nil)
+	mockRepo.EXPECT().PayInterestAccruals(ctx, uint64(6), monthStart, gomock.Any()).Return(0.004, nil)
+
+	processed, err := service.AccrueInterest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, models.TransactionTypeInterest, entry.Type)
+		assert.Equal(t, 3.05, entry.Amount)
+		assert.Equal(t, operationID, entry.OperationID)
+	}
+}
+
+func TestUpdateCurrencyInvalidInterestRate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", Name: "US Dollar", MinorUnits: 2, Enabled: true}, nil)
+
+	rate := 1.5
+	_, err := service.UpdateCurrency(ctx, "USD", &models.CurrencyRequest{Code: "USD", InterestRate: &rate})
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/journal"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// checkpointLag - возраст проводок, попадающих в контрольную точку. Проводки моложе могут
+// принадлежать ещё не зафиксированным транзакциям с меньшими ID, поэтому в точку не включаются.
+const checkpointLag = 5 * time.Minute
+
+// errVerificationStopped прерывает чтение журнала после первого найденного нарушения.
+var errVerificationStopped = errors.New("journal verification stopped")
+
+// CreateJournalCheckpoint фиксирует контрольную точку: хеш последних проводок всех кошельков.
+// Если с прошлой точки новых проводок не было, точка не создаётся и возвращается nil.
+// Хеш точки пишется в лог приложения, чтобы он хранился и вне БД.
+func (s *service) CreateJournalCheckpoint(ctx context.Context) (*models.JournalCheckpoint, error) {
+	// created_at проводок хранится в UTC (journal.Timestamp), поэтому граница считается так же
+	lastID, heads, err := s.repo.GetJournalHeads(ctx, journal.Timestamp(time.Now().Add(-checkpointLag)))
+	if err != nil {
+		return nil, err
+	}
+	if lastID == 0 {
+		return nil, nil
+	}
+
+	checkpoints, err := s.repo.GetJournalCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(checkpoints) > 0 && checkpoints[len(checkpoints)-1].LastTransactionID >= lastID {
+		return nil, nil
+	}
+
+	checkpoint := &models.JournalCheckpoint{
+		LastTransactionID: lastID,
+		Wallets:           int64(len(heads)),
+		Digest:            journal.Digest(heads),
+	}
+	if err := s.repo.CreateJournalCheckpoint(ctx, checkpoint); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Journal checkpoint %d: last transaction %d, %d wallets, digest %s",
+		checkpoint.ID, checkpoint.LastTransactionID, checkpoint.Wallets, checkpoint.Digest)
+	return checkpoint, nil
+}
+
+// VerifyJournal проходит весь журнал, проверяя цепочки хешей кошельков и контрольные точки,
+// и сообщает о первом нарушении.
+func (s *service) VerifyJournal(ctx context.Context) (*models.JournalVerification, error) {
+	checkpoints, err := s.repo.GetJournalCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier := journal.NewVerifier(checkpoints)
+	err = s.repo.StreamJournal(ctx, func(transaction *models.Transaction) error {
+		if !verifier.Add(transaction) {
+			return errVerificationStopped
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errVerificationStopped) {
+		return nil, err
+	}
+
+	result := verifier.Result()
+	if !result.Valid {
+		s.logger.Errorf("Journal verification failed at transaction %d: %s",
+			result.BrokenLink.TransactionID, result.BrokenLink.Reason)
+	}
+	return result, nil
+}
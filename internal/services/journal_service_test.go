@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateJournalCheckpointCutoffInUTC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Часовой пояс хоста не должен сдвигать границу относительно created_at в UTC
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	defer func() { time.Local = local }()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetJournalHeads(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, before time.Time) (uint64, map[uint64]string, error) {
+		assert.Equal(t, time.UTC, before.Location())
+		assert.WithinDuration(t, time.Now().Add(-checkpointLag), before, time.Second)
+		return 0, nil, nil
+	})
+
+	checkpoint, err := service.CreateJournalCheckpoint(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultLimitOrderTTL - срок действия лимитной заявки, если клиент его не указал.
+	DefaultLimitOrderTTL = 7 * 24 * time.Hour
+	// limitOrderBatchSize - количество заявок, проверяемых за один проход воркера.
+	limitOrderBatchSize = 200
+)
+
+// PlaceLimitOrder создаёт лимитную заявку на обмен и резервирует её сумму блокировкой средств.
+// Срок действия заявки ограничен MaxHoldTTL, так как резерв не может жить дольше блокировки.
+func (s *service) PlaceLimitOrder(ctx context.Context, userID uint64, request *models.LimitOrderRequest) (*models.LimitOrder, error) {
+	if err := s.validateAmount(ctx, request.FromCurrency, request.Amount); err != nil {
+		return nil, err
+	}
+	if _, err := s.requireCurrency(ctx, request.ToCurrency); err != nil {
+		return nil, err
+	}
+	if request.FromCurrency == request.ToCurrency {
+		return nil, ErrSameCurrency
+	}
+	if request.TargetRate <= 0 || request.ExpiresIn < 0 {
+		return nil, ErrInvalidOrder
+	}
+
+	ttl := time.Duration(request.ExpiresIn) * time.Second
+	if ttl == 0 {
+		ttl = DefaultLimitOrderTTL
+	}
+	if ttl > MaxHoldTTL {
+		ttl = MaxHoldTTL
+	}
+
+	order := &models.LimitOrder{
+		UserID:       userID,
+		FromCurrency: request.FromCurrency,
+		ToCurrency:   request.ToCurrency,
+		Amount:       request.Amount,
+		TargetRate:   request.TargetRate,
+		Status:       models.LimitOrderStatusOpen,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		// Кошелёк зачисления должен существовать к моменту исполнения, проверяем его сразу.
+		if _, err := s.findWallet(repo, userID, request.ToCurrency); err != nil {
+			return err
+		}
+
+		wallet, err := s.lockWallet(ctx, repo, userID, request.FromCurrency)
+		if err != nil {
+			return err
+		}
+
+		reference := fmt.Sprintf("Limit order %s/%s @ %g", order.FromCurrency, order.ToCurrency, order.TargetRate)
+		hold, err := s.createHold(ctx, repo, wallet, models.HoldKindLimitOrder, order.Amount, reference, order.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		order.HoldID = hold.ID
+		order.ID, err = repo.CreateLimitOrder(ctx, order)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetLimitOrders возвращает все лимитные заявки пользователя.
+func (s *service) GetLimitOrders(ctx context.Context, userID uint64) ([]*models.LimitOrder, error) {
+	return s.repo.GetLimitOrdersByUserID(ctx, userID)
+}
+
+// CancelLimitOrder отменяет открытую заявку и освобождает зарезервированные средства.
+func (s *service) CancelLimitOrder(ctx context.Context, userID, orderID uint64) (*models.LimitOrder, error) {
+	var order *models.LimitOrder
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		order, err = repo.GetLimitOrderByIDForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order == nil || order.UserID != userID {
+			return ErrOrderNotFound
+		}
+		if order.Status != models.LimitOrderStatusOpen {
+			return ErrOrderNotOpen
+		}
+
+		if err := s.releaseOrderHold(ctx, repo, order); err != nil {
+			return err
+		}
+
+		order.Status = models.LimitOrderStatusCancelled
+		return repo.UpdateLimitOrder(ctx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ExpireLimitOrders переводит просроченные заявки в статус expired и освобождает их резервы.
+func (s *service) ExpireLimitOrders(ctx context.Context) (int64, error) {
+	return s.repo.ExpireLimitOrders(ctx, time.Now())
+}
+
+// MatchLimitOrders проверяет открытые заявки по текущим курсам и исполняет те,
+// для которых курс достиг целевого. Возвращает количество исполненных заявок.
+func (s *service) MatchLimitOrders(ctx context.Context) (int, error) {
+	orders, err := s.repo.GetOpenLimitOrders(ctx, time.Now(), limitOrderBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Курс каждой пары запрашивается один раз за проход.
+	rates := make(map[string]float64)
+	failedPairs := make(map[string]bool)
+	filled := 0
+	for _, order := range orders {
+		pair := order.FromCurrency + "/" + order.ToCurrency
+		if failedPairs[pair] {
+			continue
+		}
+		rate, ok := rates[pair]
+		if !ok {
+			rate, err = s.GetRate(order.FromCurrency, order.ToCurrency)
+			if err != nil {
+				failedPairs[pair] = true
+				continue
+			}
+			rates[pair] = rate
+		}
+
+		if rate < order.TargetRate {
+			continue
+		}
+
+		executed, err := s.fillLimitOrder(ctx, order.ID, rate)
+		if err != nil {
+			s.logger.Errorf("Failed to fill limit order %d: %v", order.ID, err)
+			continue
+		}
+		if executed {
+			filled++
+		}
+	}
+
+	return filled, nil
+}
+
+// fillLimitOrder исполняет заявку по курсу rate: списывает зарезервированную сумму и зачисляет
+// результат обмена в рамках одной операции журнала. Возвращает false, если заявка уже не открыта.
+func (s *service) fillLimitOrder(ctx context.Context, orderID uint64, rate float64) (bool, error) {
+	executed := false
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		order, err := repo.GetLimitOrderByIDForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		// Заявку могли отменить или исполнить параллельно
+		if order == nil || order.Status != models.LimitOrderStatusOpen || !order.ExpiresAt.After(time.Now()) {
+			return nil
+		}
+
+		hold, err := repo.GetHoldByID(ctx, order.HoldID)
+		if err != nil {
+			return err
+		}
+		if hold == nil {
+			return ErrHoldNotFound
+		}
+		toWallet, err := s.findWallet(repo, order.UserID, order.ToCurrency)
+		if err != nil {
+			return err
+		}
+
+		fromWallet, toWallet, err := s.lockWalletsByID(ctx, repo, hold.WalletID, toWallet.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.ensureDebitAllowed(ctx, repo, fromWallet); err != nil {
+			return err
+		}
+		if err := s.ensureCreditAllowed(ctx, repo, toWallet); err != nil {
+			return err
+		}
+
+		hold, err = repo.GetHoldByIDForUpdate(ctx, order.HoldID)
+		if err != nil {
+			return err
+		}
+		// Резерв истёк или снят в обход заявки - заявка больше не обеспечена.
+		if hold.Status != models.HoldStatusActive || !hold.ExpiresAt.After(time.Now()) {
+			order.Status = models.LimitOrderStatusCancelled
+			return repo.UpdateLimitOrder(ctx, order)
+		}
+
+		exchangedAmount := roundAmount(order.Amount * rate)
+		operationID := uuid.NewString()
+		description := fmt.Sprintf("Limit order %d: exchange %s to %s", order.ID, order.FromCurrency, order.ToCurrency)
+		if _, err := s.postEntry(ctx, repo, fromWallet, -order.Amount, models.TransactionTypeExchangeOut, operationID, description); err != nil {
+			return err
+		}
+		if _, err := s.postEntry(ctx, repo, toWallet, exchangedAmount, models.TransactionTypeExchangeIn, operationID, description); err != nil {
+			return err
+		}
+
+		hold.Status = models.HoldStatusCaptured
+		hold.CapturedAmount = order.Amount
+		if err := repo.UpdateHold(ctx, hold); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		order.Status = models.LimitOrderStatusFilled
+		order.FilledRate = rate
+		order.FilledAmount = exchangedAmount
+		order.OperationID = operationID
+		order.FilledAt = &now
+		if err := repo.UpdateLimitOrder(ctx, order); err != nil {
+			return err
+		}
+
+		executed = true
+		return nil
+	})
+	return executed, err
+}
+
+// releaseOrderHold освобождает блокировку заявки, если она ещё действует.
+func (s *service) releaseOrderHold(ctx context.Context, repo repository.Repository, order *models.LimitOrder) error {
+	hold, err := repo.GetHoldByIDForUpdate(ctx, order.HoldID)
+	if err != nil {
+		return err
+	}
+	if hold == nil || hold.Status != models.HoldStatusActive {
+		return nil
+	}
+
+	hold.Status = models.HoldStatusReleased
+	return repo.UpdateHold(ctx, hold)
+}
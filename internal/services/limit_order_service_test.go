@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchLimitOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	client := &fakeCurrencyClient{rates: map[string]float64{"USD": 1, "RUB": 0.01}}
+	service := &service{repo: mockRepo, currencyClient: client, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	expiresAt := time.Now().Add(time.Hour)
+	fillable := &models.LimitOrder{ID: 1, UserID: 1, FromCurrency: "USD", ToCurrency: "RUB", Amount: 10,
+		TargetRate: 95, HoldID: 5, Status: models.LimitOrderStatusOpen, ExpiresAt: expiresAt}
+	waiting := &models.LimitOrder{ID: 2, UserID: 1, FromCurrency: "USD", ToCurrency: "RUB", Amount: 10,
+		TargetRate: 120, HoldID: 6, Status: models.LimitOrderStatusOpen, ExpiresAt: expiresAt}
+	mockRepo.EXPECT().GetOpenLimitOrders(ctx, gomock.Any(), limitOrderBatchSize).
+		Return([]*models.LimitOrder{fillable, waiting}, nil)
+
+	// Исполняется только заявка, целевой курс которой достигнут
+	hold := &models.Hold{ID: 5, WalletID: 3, UserID: 1, Amount: 10, Status: models.HoldStatusActive, ExpiresAt: expiresAt}
+	mockRepo.EXPECT().GetLimitOrderByIDForUpdate(ctx, uint64(1)).Return(fillable, nil)
+	mockRepo.EXPECT().GetHoldByID(ctx, uint64(5)).Return(hold, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrency(uint64(1), "RUB").Return(&models.Wallet{ID: 4, UserID: 1, Currency: "RUB"}, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(3)).Return(&models.Wallet{ID: 3, UserID: 1, Balance: 10, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(4)).Return(&models.Wallet{ID: 4, UserID: 1, Balance: 0, Currency: "RUB"}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil).AnyTimes()
+	mockRepo.EXPECT().GetHoldByIDForUpdate(ctx, uint64(5)).Return(hold, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(3), 0.0).Return(nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(4), 1000.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).Return(nil).Times(2)
+	mockRepo.EXPECT().UpdateHold(ctx, gomock.Any()).
+		Do(func(_ context.Context, hold *models.Hold) {
+			assert.Equal(t, models.HoldStatusCaptured, hold.Status)
+			assert.Equal(t, 10.0, hold.CapturedAmount)
+		}).Return(nil)
+	mockRepo.EXPECT().UpdateLimitOrder(ctx, gomock.Any()).
+		Do(func(_ context.Context, order *models.LimitOrder) {
+			assert.Equal(t, models.LimitOrderStatusFilled, order.Status)
+			assert.Equal(t, 100.0, order.FilledRate)
+			assert.Equal(t, 1000.0, order.FilledAmount)
+		}).Return(nil)
+
+	filled, err := service.MatchLimitOrders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, filled)
+}
+
+func TestCancelLimitOrderNotOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetLimitOrderByIDForUpdate(ctx, uint64(1)).
+		Return(&models.LimitOrder{ID: 1, UserID: 1, Status: models.LimitOrderStatusFilled}, nil).Times(2)
+
+	_, err := service.CancelLimitOrder(ctx, 1, 1)
+	assert.ErrorIs(t, err, ErrOrderNotOpen)
+
+	// Чужая заявка не раскрывается
+	_, err = service.CancelLimitOrder(ctx, 2, 1)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"text/template"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+)
+
+const (
+	// DefaultLargeWithdrawalThreshold - минимальная сумма вывода в валюте кошелька, о которой
+	// приходит уведомление, если пользователь не задал свой порог.
+	DefaultLargeWithdrawalThreshold = 1000.0
+	// NotificationsLimit - максимальное количество уведомлений в ответе входящих.
+	NotificationsLimit = 100
+	// MaxNotificationEmailAttempts - количество попыток отправки письма, после которого
+	// уведомление получает статус failed.
+	MaxNotificationEmailAttempts = 5
+
+	// notificationBatchSize - количество писем, отправляемых за один запуск фоновой задачи.
+	notificationBatchSize = 100
+	// notificationLease - на сколько откладывается выбранное письмо, чтобы его не отправил
+	// другой экземпляр сервиса.
+	notificationLease = time.Minute
+	// Задержка перед повторной отправкой письма: notificationRetryBase, удваивается после
+	// каждой неудачи, но не больше notificationRetryMax.
+	notificationRetryBase = time.Minute
+	notificationRetryMax  = time.Hour
+	// failedLoginNotifyInterval - не чаще одного уведомления о неудачных входах за интервал,
+	// чтобы подбор пароля не превращался в рассылку.
+	failedLoginNotifyInterval = 15 * time.Minute
+)
+
+// notificationDefaults - настройки уведомлений по умолчанию для всех типов событий в порядке
+// их вывода в API.
+var notificationDefaults = []models.NotificationPreference{
+	{EventType: models.NotificationLargeWithdrawal, InApp: true, Email: true, Threshold: DefaultLargeWithdrawalThreshold},
+	{EventType: models.NotificationExchange, InApp: true, Email: false},
+	{EventType: models.NotificationFailedLogin, InApp: true, Email: true},
+	{EventType: models.NotificationNewSession, InApp: true, Email: true},
+}
+
+// notificationData - данные для шаблонов уведомлений.
+type notificationData struct {
+	Amount      float64
+	Currency    string
+	Balance     float64
+	Description string
+	IP          string
+	UserAgent   string
+	Time        time.Time
+}
+
+// notificationTemplate - шаблоны темы и текста уведомления одного типа.
+type notificationTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+var notificationFuncs = template.FuncMap{
+	"money": func(amount float64) string { return fmt.Sprintf("%.2f", amount) },
+	"time":  func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+}
+
+var notificationTemplates = map[string]notificationTemplate{
+	models.NotificationLargeWithdrawal: newNotificationTemplate(
+		`Withdrawal of {{money .Amount}} {{.Currency}}`,
+		`{{money .Amount}} {{.Currency}} was withdrawn from your wallet at {{time .Time}}.{{if .Description}} {{.Description}}.{{end}} `+
+			`Balance after the withdrawal: {{money .Balance}} {{.Currency}}. If this wasn't you, contact support.`,
+	),
+	models.NotificationExchange: newNotificationTemplate(
+		`Exchange of {{money .Amount}} {{.Currency}}`,
+		`{{money .Amount}} {{.Currency}} was exchanged at {{time .Time}}.{{if .Description}} {{.Description}}.{{end}} `+
+			`Balance after the exchange: {{money .Balance}} {{.Currency}}.`,
+	),
+	models.NotificationFailedLogin: newNotificationTemplate(
+		`Failed login attempt`,
+		`Someone tried to log in to your account with a wrong password at {{time .Time}} from {{.IP}}`+
+			`{{if .UserAgent}} ({{.UserAgent}}){{end}}. If this wasn't you, change your password.`,
+	),
+	models.NotificationNewSession: newNotificationTemplate(
+		`New login to your account`,
+		`Your account was accessed from a new device at {{time .Time}} from {{.IP}}`+
+			`{{if .UserAgent}} ({{.UserAgent}}){{end}}. If this wasn't you, change your password.`,
+	),
+}
+
+func newNotificationTemplate(subject, body string) notificationTemplate {
+	return notificationTemplate{
+		subject: template.Must(template.New("subject").Funcs(notificationFuncs).Parse(subject)),
+		body:    template.Must(template.New("body").Funcs(notificationFuncs).Parse(body)),
+	}
+}
+
+type clientKey struct{}
+
+// loginClient - адрес и клиент, с которых выполняется вход.
+type loginClient struct {
+	IP        string
+	UserAgent string
+}
+
+// WithClient возвращает контекст с адресом и User-Agent клиента для уведомлений о входе.
+func WithClient(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, clientKey{}, loginClient{IP: ip, UserAgent: userAgent})
+}
+
+// GetNotifications возвращает последние входящие уведомления пользователя (только непрочитанные,
+// если unreadOnly) и количество непрочитанных.
+func (s *service) GetNotifications(ctx context.Context, userID uint64, unreadOnly bool) ([]*models.Notification, int, error) {
+	notifications, err := s.repo.GetNotifications(ctx, userID, unreadOnly, NotificationsLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	unread, err := s.repo.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, unread, nil
+}
+
+// MarkNotificationRead отмечает уведомление пользователя прочитанным.
+func (s *service) MarkNotificationRead(ctx context.Context, userID, notificationID uint64) error {
+	marked, err := s.repo.MarkNotificationsRead(ctx, userID, notificationID, time.Now())
+	if err != nil {
+		return err
+	}
+	if marked == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead отмечает прочитанными все уведомления пользователя и возвращает
+// количество отмеченных.
+func (s *service) MarkAllNotificationsRead(ctx context.Context, userID uint64) (int, error) {
+	return s.repo.MarkNotificationsRead(ctx, userID, 0, time.Now())
+}
+
+// GetNotificationPreferences возвращает настройки уведомлений пользователя по всем типам событий,
+// подставляя настройки по умолчанию для типов, которые пользователь не менял.
+func (s *service) GetNotificationPreferences(ctx context.Context, userID uint64) ([]*models.NotificationPreference, error) {
+	saved, err := s.repo.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]*models.NotificationPreference, len(saved))
+	for _, preference := range saved {
+		byType[preference.EventType] = preference
+	}
+
+	preferences := make([]*models.NotificationPreference, 0, len(notificationDefaults))
+	for _, def := range notificationDefaults {
+		preference, ok := byType[def.EventType]
+		if !ok {
+			preference = defaultNotificationPreference(userID, def.EventType)
+		}
+		preferences = append(preferences, preference)
+	}
+	return preferences, nil
+}
+
+// UpdateNotificationPreferences сохраняет настройки уведомлений пользователя для указанных
+// типов событий и возвращает настройки по всем типам.
+func (s *service) UpdateNotificationPreferences(ctx context.Context, userID uint64, request *models.NotificationPreferencesRequest) ([]*models.NotificationPreference, error) {
+	if len(request.Preferences) == 0 {
+		return nil, fmt.Errorf("%w: no preferences", ErrInvalidNotificationPreference)
+	}
+	for _, preference := range request.Preferences {
+		if preference == nil || defaultNotificationPreference(userID, preference.EventType) == nil {
+			return nil, fmt.Errorf("%w: unknown event type", ErrInvalidNotificationPreference)
+		}
+		if preference.EventType != models.NotificationLargeWithdrawal {
+			preference.Threshold = 0
+		}
+		if preference.Threshold < 0 || math.IsNaN(preference.Threshold) {
+			return nil, fmt.Errorf("%w: threshold must not be negative", ErrInvalidNotificationPreference)
+		}
+		preference.Threshold = roundAmount(preference.Threshold)
+		preference.UserID = userID
+	}
+
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		for _, preference := range request.Preferences {
+			if err := repo.UpsertNotificationPreference(ctx, preference); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetNotificationPreferences(ctx, userID)
+}
+
+// DeliverNotificationEmails отправляет письма уведомлений, время попытки которых наступило.
+// Неудачная отправка повторяется с экспоненциальной задержкой до MaxNotificationEmailAttempts
+// попыток. Возвращает количество отправленных писем.
+func (s *service) DeliverNotificationEmails(ctx context.Context) (int, error) {
+	if s.mailer == nil {
+		return 0, nil
+	}
+
+	notifications, err := s.repo.ClaimDueNotificationEmails(ctx, time.Now(), notificationBatchSize, notificationLease)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, notification := range notifications {
+		s.sendNotificationEmail(ctx, notification)
+		if err := s.repo.UpdateNotificationEmail(ctx, notification); err != nil {
+			s.logger.Errorf("Failed to record notification %d email: %v", notification.ID, err)
+			continue
+		}
+		if notification.EmailStatus == models.NotificationEmailSent {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendNotificationEmail отправляет письмо уведомления и отражает результат в его статусе.
+func (s *service) sendNotificationEmail(ctx context.Context, notification *models.Notification) {
+	notification.NextAttemptAt = nil
+	if notification.Email == "" {
+		notification.EmailStatus = models.NotificationEmailSkipped
+		return
+	}
+
+	err := s.mailer.Send(ctx, notification.Email, notification.Subject, notification.Body)
+	notification.EmailAttempts++
+	switch {
+	case err == nil:
+		notification.EmailStatus = models.NotificationEmailSent
+		notification.EmailError = ""
+	case notification.EmailAttempts >= MaxNotificationEmailAttempts:
+		notification.EmailStatus = models.NotificationEmailFailed
+		notification.EmailError = err.Error()
+		s.logger.Warnf("Notification %d email failed after %d attempts: %v", notification.ID, notification.EmailAttempts, err)
+	default:
+		next := time.Now().Add(notificationBackoff(notification.EmailAttempts))
+		notification.EmailError = err.Error()
+		notification.NextAttemptAt = &next
+	}
+}
+
+// recordNotifications создаёт уведомление владельцу кошелька о крупном выводе или обмене.
+// Вызывается в транзакции изменения баланса вместе с записью события кошелька.
+func (s *service) recordNotifications(ctx context.Context, repo repository.Repository, wallet *models.Wallet, transaction *models.Transaction) error {
+	var eventType string
+	switch transaction.Type {
+	case models.TransactionTypeWithdrawal:
+		eventType = models.NotificationLargeWithdrawal
+	case models.TransactionTypeExchangeOut:
+		eventType = models.NotificationExchange
+	default:
+		return nil
+	}
+
+	preference, err := s.notificationPreference(ctx, repo, wallet.UserID, eventType)
+	if err != nil {
+		return err
+	}
+	amount := math.Abs(transaction.Amount)
+	if eventType == models.NotificationLargeWithdrawal && amount < preference.Threshold {
+		return nil
+	}
+
+	return s.createNotification(ctx, repo, wallet.TenantID, preference, notificationData{
+		Amount:      amount,
+		Currency:    wallet.Currency,
+		Balance:     transaction.BalanceAfter,
+		Description: transaction.Description,
+		Time:        transaction.CreatedAt,
+	})
+}
+
+// notifyLogin уведомляет пользователя о неудачном входе или входе с нового устройства
+// с адреса из контекста. Уведомления о неудачных входах не чаще failedLoginNotifyInterval.
+func (s *service) notifyLogin(ctx context.Context, userID, tenantID uint64, eventType string) error {
+	if eventType != models.NotificationFailedLogin {
+		return s.createLoginNotification(ctx, s.repo, userID, tenantID, eventType)
+	}
+
+	// Проверка частоты и запись выполняются под блокировкой, чтобы параллельные
+	// неудачные входы не создали несколько уведомлений за один интервал.
+	return s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		if err := repo.LockUserNotifications(ctx, userID, eventType); err != nil {
+			return err
+		}
+		latest, err := repo.GetLatestNotification(ctx, userID, eventType)
+		if err != nil {
+			return err
+		}
+		if latest != nil && time.Since(latest.CreatedAt) < failedLoginNotifyInterval {
+			return nil
+		}
+		return s.createLoginNotification(ctx, repo, userID, tenantID, eventType)
+	})
+}
+
+// createLoginNotification создаёт уведомление о входе с адреса и клиента из контекста.
+func (s *service) createLoginNotification(ctx context.Context, repo repository.Repository, userID, tenantID uint64, eventType string) error {
+	preference, err := s.notificationPreference(ctx, repo, userID, eventType)
+	if err != nil {
+		return err
+	}
+
+	client, _ := ctx.Value(clientKey{}).(loginClient)
+	return s.createNotification(ctx, repo, tenantID, preference, notificationData{
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Time:      time.Now(),
+	})
+}
+
+// notificationPreference возвращает настройки уведомлений пользователя о событиях типа eventType.
+func (s *service) notificationPreference(ctx context.Context, repo repository.Repository, userID uint64, eventType string) (*models.NotificationPreference, error) {
+	preference, err := repo.GetNotificationPreference(ctx, userID, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preference: %v", err)
+	}
+	if preference == nil {
+		preference = defaultNotificationPreference(userID, eventType)
+	}
+	return preference, nil
+}
+
+// createNotification формирует уведомление по шаблону и сохраняет его для каналов, включённых
+// в preference. Если все каналы выключены, уведомление не создаётся.
+func (s *service) createNotification(ctx context.Context, repo repository.Repository, tenantID uint64, preference *models.NotificationPreference, data notificationData) error {
+	if !preference.InApp && !preference.Email {
+		return nil
+	}
+
+	tmpl := notificationTemplates[preference.EventType]
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return fmt.Errorf("failed to render notification: %v", err)
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return fmt.Errorf("failed to render notification: %v", err)
+	}
+
+	notification := &models.Notification{
+		TenantID:    tenantID,
+		UserID:      preference.UserID,
+		EventType:   preference.EventType,
+		Subject:     subject.String(),
+		Body:        body.String(),
+		InApp:       preference.InApp,
+		EmailStatus: models.NotificationEmailSkipped,
+	}
+	if preference.Email {
+		now := time.Now()
+		notification.EmailStatus = models.NotificationEmailPending
+		notification.NextAttemptAt = &now
+	}
+
+	if err := repo.CreateNotification(ctx, notification); err != nil {
+		return fmt.Errorf("failed to record notification: %v", err)
+	}
+	return nil
+}
+
+// defaultNotificationPreference возвращает настройки по умолчанию для типа eventType
+// или nil для неизвестного типа.
+func defaultNotificationPreference(userID uint64, eventType string) *models.NotificationPreference {
+	for _, def := range notificationDefaults {
+		if def.EventType == eventType {
+			preference := def
+			preference.UserID = userID
+			return &preference
+		}
+	}
+	return nil
+}
+
+// notificationBackoff возвращает задержку после attempts неудачных попыток отправки письма.
+func notificationBackoff(attempts int) time.Duration {
+	delay := notificationRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= notificationRetryMax {
+			return notificationRetryMax
+		}
+	}
+	return delay
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubMailer запоминает отправленные письма и возвращает err.
+type stubMailer struct {
+	sent []string
+	err  error
+}
+
+func (m *stubMailer) Send(_ context.Context, to, subject, _ string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, to+": "+subject)
+	return nil
+}
+
+func TestRecordNotificationsLargeWithdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+	wallet := &models.Wallet{ID: 3, UserID: 1, TenantID: 2, Currency: "USD"}
+
+	// Вывод меньше порога по умолчанию не уведомляет
+	mockRepo.EXPECT().GetNotificationPreference(ctx, uint64(1), models.NotificationLargeWithdrawal).Return(nil, nil)
+	assert.NoError(t, service.recordNotifications(ctx, mockRepo, wallet, &models.Transaction{Type: models.TransactionTypeWithdrawal, Amount: -999.99}))
+
+	var created *models.Notification
+	mockRepo.EXPECT().GetNotificationPreference(ctx, uint64(1), models.NotificationLargeWithdrawal).Return(nil, nil)
+	mockRepo.EXPECT().CreateNotification(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, notification *models.Notification) error {
+		created = notification
+		return nil
+	})
+	assert.NoError(t, service.recordNotifications(ctx, mockRepo, wallet, &models.Transaction{Type: models.TransactionTypeWithdrawal, Amount: -1500, BalanceAfter: 250}))
+	assert.Equal(t, uint64(2), created.TenantID)
+	assert.Equal(t, "Withdrawal of 1500.00 USD", created.Subject)
+	assert.Contains(t, created.Body, "Balance after the withdrawal: 250.00 USD")
+	assert.True(t, created.InApp)
+	assert.Equal(t, models.NotificationEmailPending, created.EmailStatus)
+	assert.NotNil(t, created.NextAttemptAt)
+
+	// Свой порог пользователя
+	mockRepo.EXPECT().GetNotificationPreference(ctx, uint64(1), models.NotificationLargeWithdrawal).Return(&models.NotificationPreference{
+		UserID: 1, EventType: models.NotificationLargeWithdrawal, InApp: true, Email: false, Threshold: 5000,
+	}, nil)
+	assert.NoError(t, service.recordNotifications(ctx, mockRepo, wallet, &models.Transaction{Type: models.TransactionTypeWithdrawal, Amount: -1500}))
+
+	// Пополнения не уведомляют
+	assert.NoError(t, service.recordNotifications(ctx, mockRepo, wallet, &models.Transaction{Type: models.TransactionTypeDeposit, Amount: 1e6}))
+}
+
+func TestRecordNotificationsExchange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+	wallet := &models.Wallet{ID: 3, UserID: 1, Currency: "EUR"}
+
+	mockRepo.EXPECT().GetNotificationPreference(ctx, uint64(1), models.NotificationExchange).Return(nil, nil)
+	mockRepo.EXPECT().CreateNotification(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, notification *models.Notification) error {
+		assert.Equal(t, "Exchange of 10.00 EUR", notification.Subject)
+		// По умолчанию об обменах не пишем на почту
+		assert.Equal(t, models.NotificationEmailSkipped, notification.EmailStatus)
+		assert.Nil(t, notification.NextAttemptAt)
+		return nil
+	})
+	assert.NoError(t, service.recordNotifications(ctx, mockRepo, wallet, &models.Transaction{Type: models.TransactionTypeExchangeOut, Amount: -10}))
+
+	// Оба канала выключены - уведомление не создаётся
+	mockRepo.EXPECT().GetNotificationPreference(ctx, uint64(1), models.NotificationExchange).Return(&models.NotificationPreference{
+		UserID: 1, EventType: models.NotificationExchange,
+	}, nil)
+	assert.NoError(t, service.recordNotifications(ctx, mockRepo, wallet, &models.Transaction{Type: models.TransactionTypeExchangeOut, Amount: -10}))
+}
+
+func TestNotifyFailedLoginThrottled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := WithClient(context.Background(), "203.0.113.7", "curl/8.0")
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().LockUserNotifications(ctx, uint64(1), models.NotificationFailedLogin).Return(nil).Times(2)
+	mockRepo.EXPECT().GetLatestNotification(ctx, uint64(1), models.NotificationFailedLogin).Return(nil, nil)
+	mockRepo.EXPECT().GetNotificationPreference(ctx, uint64(1), models.NotificationFailedLogin).Return(nil, nil)
+	mockRepo.EXPECT().CreateNotification(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, notification *models.Notification) error {
+		assert.Contains(t, notification.Body, "from 203.0.113.7 (curl/8.0)")
+		return nil
+	})
+	assert.NoError(t, service.notifyLogin(ctx, 1, 0, models.NotificationFailedLogin))
+
+	// Недавнее уведомление о неудачном входе - новое не создаётся
+	mockRepo.EXPECT().GetLatestNotification(ctx, uint64(1), models.NotificationFailedLogin).Return(&models.Notification{CreatedAt: time.Now().Add(-time.Minute)}, nil)
+	assert.NoError(t, service.notifyLogin(ctx, 1, 0, models.NotificationFailedLogin))
+}
+
+func TestCreateRefreshTokenModelNotifiesNewSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+	token := &models.RefreshToken{UserID: 1, DeviceID: "198.51.100.1"}
+
+	mockRepo.EXPECT().GetRefreshTokenModelByID(ctx, uint64(1), "198.51.100.1").Return(nil, nil)
+	mockRepo.EXPECT().SetRefreshTokenModel(ctx, token).Return(nil)
+	mockRepo.EXPECT().GetNotificationPreference(ctx, uint64(1), models.NotificationNewSession).Return(nil, nil)
+	mockRepo.EXPECT().CreateNotification(ctx, gomock.Any()).Return(nil)
+	assert.NoError(t, service.CreateRefreshTokenModel(ctx, token))
+
+	// Повторный вход с того же устройства - без уведомления
+	mockRepo.EXPECT().GetRefreshTokenModelByID(ctx, uint64(1), "198.51.100.1").Return(&models.RefreshToken{ID: 5}, nil)
+	mockRepo.EXPECT().SetRefreshTokenModel(ctx, token).Return(nil)
+	assert.NoError(t, service.CreateRefreshTokenModel(ctx, token))
+}
+
+func TestDeliverNotificationEmails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mailer := &stubMailer{}
+	service := &service{repo: mockRepo, mailer: mailer, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().ClaimDueNotificationEmails(ctx, gomock.Any(), notificationBatchSize, notificationLease).Return([]*models.Notification{
+		{ID: 1, Subject: "Hello", Email: "user@example.com", EmailStatus: models.NotificationEmailPending},
+		{ID: 2, Subject: "No address", EmailStatus: models.NotificationEmailPending},
+	}, nil)
+	mockRepo.EXPECT().UpdateNotificationEmail(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, notification *models.Notification) error {
+		assert.Equal(t, models.NotificationEmailSent, notification.EmailStatus)
+		assert.Equal(t, 1, notification.EmailAttempts)
+		return nil
+	})
+	mockRepo.EXPECT().UpdateNotificationEmail(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, notification *models.Notification) error {
+		assert.Equal(t, models.NotificationEmailSkipped, notification.EmailStatus)
+		return nil
+	})
+
+	sent, err := service.DeliverNotificationEmails(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []string{"user@example.com: Hello"}, mailer.sent)
+}
+
+func TestDeliverNotificationEmailsRetryAndFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, mailer: &stubMailer{err: errors.New("connection refused")}, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().ClaimDueNotificationEmails(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return([]*models.Notification{
+		{ID: 1, Email: "user@example.com", EmailStatus: models.NotificationEmailPending, EmailAttempts: 1},
+		{ID: 2, Email: "user@example.com", EmailStatus: models.NotificationEmailPending, EmailAttempts: MaxNotificationEmailAttempts - 1},
+	}, nil)
+	mockRepo.EXPECT().UpdateNotificationEmail(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, notification *models.Notification) error {
+		assert.Equal(t, models.NotificationEmailPending, notification.EmailStatus)
+		assert.Equal(t, 2, notification.EmailAttempts)
+		assert.Equal(t, "connection refused", notification.EmailError)
+		assert.WithinDuration(t, time.Now().Add(2*notificationRetryBase), *notification.NextAttemptAt, time.Second)
+		return nil
+	})
+	mockRepo.EXPECT().UpdateNotificationEmail(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, notification *models.Notification) error {
+		assert.Equal(t, models.NotificationEmailFailed, notification.EmailStatus)
+		assert.Nil(t, notification.NextAttemptAt)
+		return nil
+	})
+
+	sent, err := service.DeliverNotificationEmails(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestNotificationBackoff(t *testing.T) {
+	assert.Equal(t, notificationRetryBase, notificationBackoff(1))
+	assert.Equal(t, 4*notificationRetryBase, notificationBackoff(3))
+	assert.Equal(t, notificationRetryMax, notificationBackoff(20))
+}
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	for _, request := range []*models.NotificationPreferencesRequest{
+		{},
+		{Preferences: []*models.NotificationPreference{{EventType: "deposit", InApp: true}}},
+		{Preferences: []*models.NotificationPreference{{EventType: models.NotificationLargeWithdrawal, Threshold: -1}}},
+	} {
+		_, err := service.UpdateNotificationPreferences(ctx, 1, request)
+		assert.ErrorIs(t, err, ErrInvalidNotificationPreference)
+	}
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().UpsertNotificationPreference(ctx, &models.NotificationPreference{
+		UserID: 1, EventType: models.NotificationLargeWithdrawal, Email: true, Threshold: 250.5,
+	}).Return(nil)
+	mockRepo.EXPECT().GetNotificationPreferences(ctx, uint64(1)).Return([]*models.NotificationPreference{
+		{UserID: 1, EventType: models.NotificationLargeWithdrawal, Email: true, Threshold: 250.5},
+	}, nil)
+
+	preferences, err := service.UpdateNotificationPreferences(ctx, 1, &models.NotificationPreferencesRequest{
+		Preferences: []*models.NotificationPreference{{EventType: models.NotificationLargeWithdrawal, Email: true, Threshold: 250.5}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, preferences, len(notificationDefaults))
+	assert.Equal(t, 250.5, preferences[0].Threshold)
+	assert.False(t, preferences[0].InApp)
+	// Остальные типы - по умолчанию
+	assert.Equal(t, models.NotificationExchange, preferences[1].EventType)
+	assert.True(t, preferences[1].InApp)
+	assert.False(t, preferences[1].Email)
+}
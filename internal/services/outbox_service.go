@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+)
+
+// outboxBatchSize - количество событий, публикуемых за один запуск фоновой задачи.
+const outboxBatchSize = 500
+
+// recordWalletEvent записывает в outbox событие о проводке transaction по кошельку wallet.
+// Там же создаются доставки события в вебхуки владельца кошелька. Вызывается в транзакции
+// изменения баланса, поэтому событие появляется тогда и только тогда, когда изменение
+// зафиксировано.
+func (s *service) recordWalletEvent(ctx context.Context, repo repository.Repository, wallet *models.Wallet, transaction *models.Transaction) error {
+	payload, err := json.Marshal(models.WalletEventData{
+		TransactionID: transaction.ID,
+		UserID:        wallet.UserID,
+		OperationID:   transaction.OperationID,
+		Amount:        transaction.Amount,
+		Currency:      wallet.Currency,
+		BalanceAfter:  transaction.BalanceAfter,
+		Description:   transaction.Description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode wallet event: %v", err)
+	}
+
+	event := &models.OutboxEvent{
+		TenantID: wallet.TenantID,
+		WalletID: wallet.ID,
+		Type:     models.EventTypePrefix + transaction.Type,
+		Payload:  payload,
+	}
+	if err := repo.CreateOutboxEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to record wallet event: %v", err)
+	}
+	if err := s.recordWebhookDeliveries(ctx, repo, wallet.UserID, event); err != nil {
+		return err
+	}
+	return s.recordNotifications(ctx, repo, wallet, transaction)
+}
+
+// RelayOutbox публикует неопубликованные события outbox в порядке записи. Событие отмечается
+// опубликованным только после успешной публикации, поэтому при сбое оно будет отправлено
+// повторно (доставка "хотя бы один раз"). Если событие кошелька не удалось опубликовать,
+// следующие события этого кошелька откладываются до следующего запуска, чтобы сохранить порядок.
+func (s *service) RelayOutbox(ctx context.Context) (int, error) {
+	if s.publisher == nil {
+		return 0, nil
+	}
+
+	published := 0
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		locked, err := repo.LockOutboxRelay(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err := repo.GetPendingOutboxEvents(ctx, outboxBatchSize)
+		if err != nil {
+			return err
+		}
+
+		blocked := make(map[uint64]bool)
+		for _, event := range events {
+			if blocked[event.WalletID] {
+				continue
+			}
+
+			if err := s.publisher.Publish(ctx, event); err != nil {
+				s.logger.Errorf("Failed to publish event %d of wallet %d: %v", event.ID, event.WalletID, err)
+				blocked[event.WalletID] = true
+				if err := repo.MarkOutboxEventFailed(ctx, event.ID, err.Error()); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := repo.MarkOutboxEventPublished(ctx, event.ID, time.Now()); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, nil
+}
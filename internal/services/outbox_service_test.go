@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubPublisher публикует события в память и отклоняет события из failing.
+type stubPublisher struct {
+	failing   map[uint64]bool
+	published []uint64
+}
+
+func (p *stubPublisher) Name() string { return "stub" }
+
+func (p *stubPublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	if p.failing[event.ID] {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, event.ID)
+	return nil
+}
+
+func TestPostTransactionRecordsEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+	wallet := &models.Wallet{ID: 7, TenantID: 2, UserID: 1, Balance: 100, Currency: "EUR"}
+
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(7), 125.0).Return(nil)
+	mockRepo.EXPECT().GetLastTransactionHash(ctx, uint64(7)).Return("", nil)
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).
+		Do(func(_ context.Context, transaction *models.Transaction) {
+			transaction.ID = 42
+		}).Return(nil)
+	var event *models.OutboxEvent
+	mockRepo.EXPECT().CreateOutboxEvent(ctx, gomock.Any()).
+		Do(func(_ context.Context, created *models.OutboxEvent) {
+			event = created
+		}).Return(nil)
+	mockRepo.EXPECT().CreateWebhookDeliveries(ctx, uint64(1), gomock.Any(), gomock.Any()).Return(nil)
+
+	_, err := service.postEntry(ctx, mockRepo, wallet, 25, models.TransactionTypeDeposit, "op-1", "Deposit")
+	assert.NoError(t, err)
+	if assert.NotNil(t, event) {
+		assert.Equal(t, "wallet.deposit", event.Type)
+		assert.Equal(t, uint64(2), event.TenantID)
+		assert.Equal(t, uint64(7), event.WalletID)
+
+		var data models.WalletEventData
+		assert.NoError(t, json.Unmarshal(event.Payload, &data))
+		assert.Equal(t, uint64(42), data.TransactionID)
+		assert.Equal(t, "op-1", data.OperationID)
+		assert.Equal(t, 125.0, data.BalanceAfter)
+		assert.Equal(t, "EUR", data.Currency)
+	}
+}
+
+func TestRelayOutboxKeepsWalletOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	// Событие 2 кошелька 7 не публикуется, поэтому событие 4 того же кошелька ждёт повтора
+	publisher := &stubPublisher{failing: map[uint64]bool{2: true}}
+	service := &service{repo: mockRepo, publisher: publisher, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().LockOutboxRelay(ctx).Return(true, nil)
+	mockRepo.EXPECT().GetPendingOutboxEvents(ctx, outboxBatchSize).Return([]*models.OutboxEvent{
+		{ID: 1, WalletID: 7, Sequence: 1},
+		{ID: 2, WalletID: 7, Sequence: 2},
+		{ID: 3, WalletID: 8, Sequence: 1},
+		{ID: 4, WalletID: 7, Sequence: 3},
+	}, nil)
+	mockRepo.EXPECT().MarkOutboxEventPublished(ctx, uint64(1), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().MarkOutboxEventFailed(ctx, uint64(2), "broker unavailable").Return(nil)
+	mockRepo.EXPECT().MarkOutboxEventPublished(ctx, uint64(3), gomock.Any()).Return(nil)
+
+	published, err := service.RelayOutbox(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []uint64{1, 3}, publisher.published)
+}
+
+func TestRelayOutboxLockedByAnotherInstance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, publisher: &stubPublisher{}, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().LockOutboxRelay(ctx).Return(false, nil)
+
+	published, err := service.RelayOutbox(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultPaymentRequestTTL - срок действия запроса на оплату, если клиент его не указал.
+	DefaultPaymentRequestTTL = 7 * 24 * time.Hour
+	// MaxPaymentRequestTTL - максимальный срок действия запроса на оплату.
+	MaxPaymentRequestTTL = 30 * 24 * time.Hour
+	// maxPaymentDescriptionLength - максимальная длина описания запроса на оплату.
+	maxPaymentDescriptionLength = 255
+)
+
+// CreatePaymentRequest выставляет пользователю payer_username запрос на оплату.
+// У получателя должен быть кошелёк в валюте запроса.
+func (s *service) CreatePaymentRequest(ctx context.Context, requesterID uint64, request *models.CreatePaymentRequest) (*models.PaymentRequest, error) {
+	if err := s.validateAmount(ctx, request.Currency, request.Amount); err != nil {
+		return nil, err
+	}
+	description := strings.TrimSpace(request.Description)
+	if utf8.RuneCountInString(description) > maxPaymentDescriptionLength || request.ExpiresIn < 0 {
+		return nil, ErrInvalidPaymentRequest
+	}
+
+	ttl := time.Duration(request.ExpiresIn) * time.Second
+	if ttl == 0 {
+		ttl = DefaultPaymentRequestTTL
+	}
+	if ttl > MaxPaymentRequestTTL {
+		ttl = MaxPaymentRequestTTL
+	}
+
+	payer, err := s.repo.GetUserByUsername(ctx, strings.TrimSpace(request.PayerUsername))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if payer.ID == requesterID {
+		return nil, ErrSelfTransfer
+	}
+
+	paymentRequest := &models.PaymentRequest{
+		RequesterID: requesterID,
+		PayerID:     payer.ID,
+		Amount:      request.Amount,
+		Currency:    request.Currency,
+		Description: description,
+		Status:      models.PaymentRequestStatusPending,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		if _, err := s.lockWallet(ctx, repo, requesterID, request.Currency); err != nil {
+			return err
+		}
+		return repo.CreatePaymentRequest(ctx, paymentRequest)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentRequest, nil
+}
+
+// GetPaymentRequests возвращает входящие или исходящие запросы на оплату пользователя.
+func (s *service) GetPaymentRequests(ctx context.Context, userID uint64, direction string) ([]*models.PaymentRequest, error) {
+	switch direction {
+	case models.PaymentRequestDirectionIncoming, models.PaymentRequestDirectionOutgoing:
+	default:
+		return nil, ErrInvalidPaymentRequest
+	}
+	return s.repo.GetPaymentRequestsByUserID(ctx, userID, direction)
+}
+
+// PayPaymentRequest оплачивает входящий запрос переводом получателю. Если payCurrency отличается
+// от валюты запроса, сумма списания пересчитывается по текущему курсу, а получатель
+// получает ровно запрошенную сумму.
+func (s *service) PayPaymentRequest(ctx context.Context, payerID, requestID uint64, payCurrency string) (*models.PaymentRequest, error) {
+	paymentRequest, err := s.repo.GetPaymentRequestByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if paymentRequest == nil || paymentRequest.PayerID != payerID {
+		return nil, ErrPaymentRequestNotFound
+	}
+
+	if payCurrency == "" {
+		payCurrency = paymentRequest.Currency
+	}
+	// Курс запрашивается до транзакции, чтобы не держать блокировки на время сетевого вызова.
+	rate := 1.0
+	if payCurrency != paymentRequest.Currency {
+		if _, err := s.requireCurrency(ctx, payCurrency); err != nil {
+			return nil, err
+		}
+		rate, err = s.GetRate(payCurrency, paymentRequest.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get exchange rate: %v", err)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %g for %s/%s", rate, payCurrency, paymentRequest.Currency)
+		}
+	}
+
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		paymentRequest, err = repo.GetPaymentRequestByIDForUpdate(ctx, requestID)
+		if err != nil {
+			return err
+		}
+		if paymentRequest == nil || paymentRequest.PayerID != payerID {
+			return ErrPaymentRequestNotFound
+		}
+		if paymentRequest.Status != models.PaymentRequestStatusPending {
+			return ErrPaymentRequestNotPending
+		}
+		if !paymentRequest.ExpiresAt.After(time.Now()) {
+			return ErrPaymentRequestExpired
+		}
+
+		payerWallet, err := s.findWallet(repo, payerID, payCurrency)
+		if err != nil {
+			return err
+		}
+		requesterWallet, err := s.findWallet(repo, paymentRequest.RequesterID, paymentRequest.Currency)
+		if err != nil {
+			if errors.Is(err, ErrWalletNotFound) {
+				return fmt.Errorf("%w: %s", ErrRecipientWalletNotFound, paymentRequest.Currency)
+			}
+			return err
+		}
+
+		payerWallet, requesterWallet, err = s.lockWalletsByID(ctx, repo, payerWallet.ID, requesterWallet.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.ensureDebitAllowed(ctx, repo, payerWallet); err != nil {
+			return err
+		}
+		if err := s.ensureCreditAllowed(ctx, repo, requesterWallet); err != nil {
+			return fmt.Errorf("recipient %w", err)
+		}
+
+		paidAmount := roundAmount(paymentRequest.Amount / rate)
+		if err := s.ensureAvailable(ctx, repo, payerWallet, paidAmount); err != nil {
+			return err
+		}
+
+		operationID := uuid.NewString()
+		if _, err := s.postEntry(ctx, repo, payerWallet, -paidAmount, models.TransactionTypeTransferOut, operationID,
+			fmt.Sprintf("Payment request %d to user %d", paymentRequest.ID, paymentRequest.RequesterID)); err != nil {
+			return err
+		}
+		if _, err := s.postEntry(ctx, repo, requesterWallet, paymentRequest.Amount, models.TransactionTypeTransferIn, operationID,
+			fmt.Sprintf("Payment request %d from user %d", paymentRequest.ID, payerID)); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		paymentRequest.Status = models.PaymentRequestStatusPaid
+		paymentRequest.PaidCurrency = payCurrency
+		paymentRequest.PaidAmount = paidAmount
+		paymentRequest.Rate = rate
+		paymentRequest.OperationID = operationID
+		paymentRequest.ResolvedAt = &now
+		return repo.UpdatePaymentRequest(ctx, paymentRequest)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentRequest, nil
+}
+
+// DeclinePaymentRequest отклоняет входящий запрос на оплату.
+func (s *service) DeclinePaymentRequest(ctx context.Context, payerID, requestID uint64) (*models.PaymentRequest, error) {
+	return s.resolvePaymentRequest(ctx, requestID, models.PaymentRequestStatusDeclined, func(request *models.PaymentRequest) bool {
+		return request.PayerID == payerID
+	})
+}
+
+// CancelPaymentRequest отзывает исходящий запрос на оплату.
+func (s *service) CancelPaymentRequest(ctx context.Context, requesterID, requestID uint64) (*models.PaymentRequest, error) {
+	return s.resolvePaymentRequest(ctx, requestID, models.PaymentRequestStatusCancelled, func(request *models.PaymentRequest) bool {
+		return request.RequesterID == requesterID
+	})
+}
+
+// ExpirePaymentRequests переводит просроченные запросы на оплату в статус expired.
+func (s *service) ExpirePaymentRequests(ctx context.Context) (int64, error) {
+	return s.repo.ExpirePaymentRequests(ctx, time.Now())
+}
+
+// resolvePaymentRequest закрывает ожидающий запрос со статусом status, если allowed разрешает
+// это пользователю.
+func (s *service) resolvePaymentRequest(ctx context.Context, requestID uint64, status string, allowed func(request *models.PaymentRequest) bool) (*models.PaymentRequest, error) {
+	var paymentRequest *models.PaymentRequest
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		paymentRequest, err = repo.GetPaymentRequestByIDForUpdate(ctx, requestID)
+		if err != nil {
+			return err
+		}
+		if paymentRequest == nil || !allowed(paymentRequest) {
+			return ErrPaymentRequestNotFound
+		}
+		if paymentRequest.Status != models.PaymentRequestStatusPending {
+			return ErrPaymentRequestNotPending
+		}
+
+		now := time.Now()
+		paymentRequest.Status = status
+		paymentRequest.ResolvedAt = &now
+		return repo.UpdatePaymentRequest(ctx, paymentRequest)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentRequest, nil
+}
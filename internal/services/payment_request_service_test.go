@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayPaymentRequestWithExchange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	client := &fakeCurrencyClient{rates: map[string]float64{"USD": 1, "EUR": 1.1}}
+	service := &service{repo: mockRepo, currencyClient: client, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	request := &models.PaymentRequest{
+		ID: 9, RequesterID: 1, PayerID: 2, Amount: 110, Currency: "USD",
+		Status: models.PaymentRequestStatusPending, ExpiresAt: time.Now().Add(time.Hour),
+	}
+	mockRepo.EXPECT().GetPaymentRequestByID(ctx, uint64(9)).Return(request, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "EUR").Return(&models.Currency{Code: "EUR", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetPaymentRequestByIDForUpdate(ctx, uint64(9)).Return(request, nil)
+
+	payerWallet := &models.Wallet{ID: 5, UserID: 2, Balance: 200, Currency: "EUR"}
+	requesterWallet := &models.Wallet{ID: 3, UserID: 1, Balance: 0, Currency: "USD"}
+	mockRepo.EXPECT().GetWalletByUserAndCurrency(uint64(2), "EUR").Return(payerWallet, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrency(uint64(1), "USD").Return(requesterWallet, nil)
+	gomock.InOrder(
+		mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(3)).Return(requesterWallet, nil),
+		mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(5)).Return(payerWallet, nil),
+	)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(2)).Return(&models.User{ID: 2, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetHeldAmount(ctx, uint64(5)).Return(0.0, nil)
+	mockRepo.EXPECT().GetPottedAmount(ctx, uint64(5)).Return(0.0, nil)
+	mockRepo.EXPECT().GetCreditLine(ctx, uint64(5)).Return(nil, nil)
+
+	// 110 USD по курсу 1.1 USD за EUR - списывается 100 EUR
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(5), 100.0).Return(nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(3), 110.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).Return(nil).Times(2)
+	mockRepo.EXPECT().UpdatePaymentRequest(ctx, request).Return(nil)
+
+	paid, err := service.PayPaymentRequest(ctx, 2, 9, "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentRequestStatusPaid, paid.Status)
+	assert.Equal(t, "EUR", paid.PaidCurrency)
+	assert.Equal(t, 100.0, paid.PaidAmount)
+	assert.InDelta(t, 1.1, paid.Rate, 1e-9)
+	assert.NotEmpty(t, paid.OperationID)
+	assert.NotNil(t, paid.ResolvedAt)
+}
+
+func TestPayPaymentRequestExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	request := &models.PaymentRequest{
+		ID: 9, RequesterID: 1, PayerID: 2, Amount: 10, Currency: "USD",
+		Status: models.PaymentRequestStatusPending, ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	mockRepo.EXPECT().GetPaymentRequestByID(ctx, uint64(9)).Return(request, nil)
+	mockRepo.EXPECT().GetPaymentRequestByIDForUpdate(ctx, uint64(9)).Return(request, nil)
+
+	_, err := service.PayPaymentRequest(ctx, 2, 9, "")
+	assert.ErrorIs(t, err, ErrPaymentRequestExpired)
+}
+
+func TestDeclinePaymentRequestByRequester(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetPaymentRequestByIDForUpdate(ctx, uint64(9)).Return(&models.PaymentRequest{
+		ID: 9, RequesterID: 1, PayerID: 2, Status: models.PaymentRequestStatusPending,
+	}, nil)
+
+	// Отклонить запрос может только плательщик; получатель его отзывает
+	_, err := service.DeclinePaymentRequest(ctx, 1, 9)
+	assert.ErrorIs(t, err, ErrPaymentRequestNotFound)
+}
+
+func TestCreatePaymentRequestSelf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{ID: 1, Username: "alice"}, nil)
+
+	_, err := service.CreatePaymentRequest(ctx, 1, &models.CreatePaymentRequest{PayerUsername: "alice", Amount: 10, Currency: "USD"})
+	assert.ErrorIs(t, err, ErrSelfTransfer)
+}
+
+func TestCreatePaymentRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{ID: 2, Username: "bob"}, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrencyForUpdate(ctx, uint64(1), "USD").Return(&models.Wallet{ID: 11, UserID: 1, Currency: "USD"}, nil)
+	mockRepo.EXPECT().CreatePaymentRequest(ctx, gomock.Any()).Return(nil)
+
+	created, err := service.CreatePaymentRequest(ctx, 1, &models.CreatePaymentRequest{PayerUsername: "bob", Amount: 10, Currency: "USD"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), created.PayerID)
+	assert.Equal(t, models.PaymentRequestStatusPending, created.Status)
+}
+
+func TestPayPaymentRequestMissingOnLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	// Запрос перечитывается под блокировкой и проверяется заново: строки уже может не быть
+	expectTransaction(mockRepo)
+	request := &models.PaymentRequest{
+		ID: 9, RequesterID: 1, PayerID: 2, Amount: 10, Currency: "USD",
+		Status: models.PaymentRequestStatusPending, ExpiresAt: time.Now().Add(time.Hour),
+	}
+	mockRepo.EXPECT().GetPaymentRequestByID(ctx, uint64(9)).Return(request, nil)
+	mockRepo.EXPECT().GetPaymentRequestByIDForUpdate(ctx, uint64(9)).Return(nil, nil)
+
+	_, err := service.PayPaymentRequest(ctx, 2, 9, "")
+	assert.ErrorIs(t, err, ErrPaymentRequestNotFound)
+}
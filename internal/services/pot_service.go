@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+)
+
+// maxPotNameLength - максимальная длина названия копилки.
+const maxPotNameLength = 100
+
+// CreatePot создаёт копилку в кошельке пользователя в указанной валюте.
+func (s *service) CreatePot(ctx context.Context, userID uint64, request *models.PotRequest) (*models.Pot, error) {
+	if err := validatePot(request); err != nil {
+		return nil, err
+	}
+	if _, err := s.requireCurrency(ctx, request.Currency); err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.findWallet(s.repo, userID, request.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return s.createPot(ctx, wallet, request)
+}
+
+// CreateWalletPot создаёт копилку в кошельке по ID. Доступно владельцам кошелька.
+func (s *service) CreateWalletPot(ctx context.Context, userID, walletID uint64, request *models.PotRequest) (*models.Pot, error) {
+	if err := validatePot(request); err != nil {
+		return nil, err
+	}
+
+	wallet, _, err := s.getAuthorizedWallet(ctx, userID, walletID, models.WalletRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	return s.createPot(ctx, wallet, request)
+}
+
+// GetPots возвращает все копилки пользователя, включая закрытые.
+func (s *service) GetPots(ctx context.Context, userID uint64) ([]*models.Pot, error) {
+	return s.repo.GetPotsByUserID(ctx, userID, false)
+}
+
+// GetWalletPots возвращает все копилки кошелька по ID, включая закрытые. Доступно любому участнику.
+func (s *service) GetWalletPots(ctx context.Context, userID, walletID uint64) ([]*models.Pot, error) {
+	if _, _, err := s.getAuthorizedWallet(ctx, userID, walletID, models.WalletRoleOwner, models.WalletRoleSpender, models.WalletRoleViewer); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPotsByWalletID(ctx, walletID)
+}
+
+// MoveToPot перекладывает amount из свободного остатка кошелька в копилку.
+// Учётный баланс кошелька не меняется; овердрафт для пополнения копилки не используется.
+func (s *service) MoveToPot(ctx context.Context, userID, potID uint64, amount float64) (*models.Pot, error) {
+	return s.movePot(ctx, userID, potID, amount, false)
+}
+
+// MoveFromPot возвращает amount из копилки в свободный остаток кошелька.
+func (s *service) MoveFromPot(ctx context.Context, userID, potID uint64, amount float64) (*models.Pot, error) {
+	return s.movePot(ctx, userID, potID, -amount, false)
+}
+
+// ClosePot закрывает копилку, возвращая её остаток в свободный остаток кошелька.
+func (s *service) ClosePot(ctx context.Context, userID, potID uint64) (*models.Pot, error) {
+	return s.movePot(ctx, userID, potID, 0, true)
+}
+
+// GetPotMovements возвращает историю перемещений копилки. Доступно любому участнику её кошелька.
+func (s *service) GetPotMovements(ctx context.Context, userID, potID uint64) ([]*models.PotMovement, error) {
+	pot, err := s.repo.GetPotByID(ctx, potID)
+	if err != nil {
+		return nil, err
+	}
+	if pot == nil {
+		return nil, ErrPotNotFound
+	}
+	wallet, err := s.repo.GetWalletByID(ctx, pot.WalletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet %d: %v", pot.WalletID, err)
+	}
+	if err := s.authorizePot(ctx, s.repo, userID, wallet, models.WalletRoleOwner, models.WalletRoleSpender, models.WalletRoleViewer); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPotMovements(ctx, potID)
+}
+
+// movePot изменяет баланс копилки на amount (положительный - пополнение копилки) и записывает
+// перемещение. При closing весь остаток возвращается в кошелёк, а копилка закрывается.
+// Распоряжаться копилками могут только владельцы кошелька.
+func (s *service) movePot(ctx context.Context, userID, potID uint64, amount float64, closing bool) (*models.Pot, error) {
+	var pot *models.Pot
+	ctx = withActor(ctx, userID)
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		pot, err = repo.GetPotByID(ctx, potID)
+		if err != nil {
+			return err
+		}
+		if pot == nil {
+			return ErrPotNotFound
+		}
+
+		// Кошелёк блокируется раньше копилки, как и при любых операциях с его балансом.
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, pot.WalletID)
+		if err != nil {
+			return fmt.Errorf("failed to lock wallet %d: %v", pot.WalletID, err)
+		}
+		if err := s.authorizePot(ctx, repo, userID, wallet, models.WalletRoleOwner); err != nil {
+			return err
+		}
+		if !closing {
+			if err := s.validateAmount(ctx, pot.Currency, math.Abs(amount)); err != nil {
+				return err
+			}
+		}
+		if err := s.ensureCreditAllowed(ctx, repo, wallet); err != nil {
+			return err
+		}
+
+		pot, err = repo.GetPotByIDForUpdate(ctx, potID)
+		if err != nil {
+			return err
+		}
+		if pot.Status != models.PotStatusActive {
+			return ErrPotClosed
+		}
+
+		if closing {
+			amount = -pot.Balance
+		} else if amount > 0 {
+			free, err := s.freeFunds(ctx, repo, wallet)
+			if err != nil {
+				return err
+			}
+			if free < amount {
+				return ErrInsufficientFunds
+			}
+		} else if pot.Balance < -amount {
+			return ErrPotInsufficientFunds
+		}
+
+		pot.Balance = roundAmount(pot.Balance + amount)
+		if closing {
+			now := time.Now()
+			pot.Status = models.PotStatusClosed
+			pot.ClosedAt = &now
+		}
+		if err := repo.UpdatePot(ctx, pot); err != nil {
+			return err
+		}
+
+		if amount == 0 {
+			return nil
+		}
+		return repo.CreatePotMovement(ctx, &models.PotMovement{
+			PotID:        pot.ID,
+			Amount:       roundAmount(amount),
+			BalanceAfter: pot.Balance,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pot, nil
+}
+
+// createPot создаёт копилку в кошельке wallet по проверенному запросу.
+func (s *service) createPot(ctx context.Context, wallet *models.Wallet, request *models.PotRequest) (*models.Pot, error) {
+	pot := &models.Pot{
+		WalletID:   wallet.ID,
+		UserID:     wallet.UserID,
+		Currency:   wallet.Currency,
+		Name:       strings.TrimSpace(request.Name),
+		GoalAmount: roundAmount(request.GoalAmount),
+		GoalDate:   request.GoalDate,
+		Status:     models.PotStatusActive,
+	}
+	if err := s.repo.CreatePot(ctx, pot); err != nil {
+		return nil, err
+	}
+
+	return pot, nil
+}
+
+// authorizePot проверяет роль пользователя в кошельке копилки. Копилки кошельков,
+// к которым у пользователя нет доступа, для него не существуют.
+func (s *service) authorizePot(ctx context.Context, repo repository.Repository, userID uint64, wallet *models.Wallet, roles ...string) error {
+	_, err := s.authorizeWallet(ctx, repo, userID, wallet, roles...)
+	if errors.Is(err, ErrWalletNotFound) {
+		return ErrPotNotFound
+	}
+	return err
+}
+
+func validatePot(request *models.PotRequest) error {
+	name := strings.TrimSpace(request.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxPotNameLength || request.GoalAmount < 0 {
+		return ErrInvalidPot
+	}
+	if request.GoalDate != nil && request.GoalDate.Before(time.Now().UTC().Truncate(24*time.Hour)) {
+		return ErrInvalidPot
+	}
+	return nil
+}
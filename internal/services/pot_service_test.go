@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveToPot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	pot := &models.Pot{ID: 5, WalletID: 7, UserID: 1, Currency: "USD", Balance: 10, Status: models.PotStatusActive}
+	mockRepo.EXPECT().GetPotByID(gomock.Any(), uint64(5)).Return(pot, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	wallet := &models.Wallet{ID: 7, UserID: 1, Balance: 100, Currency: "USD"}
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).Return(wallet, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetPotByIDForUpdate(gomock.Any(), uint64(5)).Return(pot, nil)
+	mockRepo.EXPECT().GetHeldAmount(gomock.Any(), uint64(7)).Return(20.0, nil)
+	mockRepo.EXPECT().GetPottedAmount(gomock.Any(), uint64(7)).Return(10.0, nil)
+	mockRepo.EXPECT().UpdatePot(gomock.Any(), pot).Return(nil)
+	mockRepo.EXPECT().CreatePotMovement(gomock.Any(), &models.PotMovement{PotID: 5, Amount: 70, BalanceAfter: 80}).Return(nil)
+
+	// Свободно 100 - 20 - 10 = 70; учётный баланс кошелька не меняется
+	updated, err := service.MoveToPot(ctx, 1, 5, 70)
+	assert.NoError(t, err)
+	assert.Equal(t, 80.0, updated.Balance)
+	assert.Equal(t, 100.0, wallet.Balance)
+}
+
+func TestMoveToPotInsufficientFunds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	pot := &models.Pot{ID: 5, WalletID: 7, UserID: 1, Currency: "USD", Status: models.PotStatusActive}
+	mockRepo.EXPECT().GetPotByID(gomock.Any(), uint64(5)).Return(pot, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 50, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetPotByIDForUpdate(gomock.Any(), uint64(5)).Return(pot, nil)
+	mockRepo.EXPECT().GetHeldAmount(gomock.Any(), uint64(7)).Return(0.0, nil)
+	mockRepo.EXPECT().GetPottedAmount(gomock.Any(), uint64(7)).Return(0.0, nil)
+
+	// Кредитная линия не используется для пополнения копилки
+	_, err := service.MoveToPot(ctx, 1, 5, 50.01)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestClosePotReturnsBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	pot := &models.Pot{ID: 5, WalletID: 7, UserID: 1, Currency: "USD", Balance: 35, Status: models.PotStatusActive}
+	mockRepo.EXPECT().GetPotByID(gomock.Any(), uint64(5)).Return(pot, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 100, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetPotByIDForUpdate(gomock.Any(), uint64(5)).Return(pot, nil)
+	mockRepo.EXPECT().UpdatePot(gomock.Any(), pot).Return(nil)
+	mockRepo.EXPECT().CreatePotMovement(gomock.Any(), &models.PotMovement{PotID: 5, Amount: -35, BalanceAfter: 0}).Return(nil)
+
+	closed, err := service.ClosePot(ctx, 1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, models.PotStatusClosed, closed.Status)
+	assert.Zero(t, closed.Balance)
+	assert.NotNil(t, closed.ClosedAt)
+}
+
+func TestMoveFromPotOtherUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetPotByID(gomock.Any(), uint64(5)).Return(&models.Pot{ID: 5, WalletID: 9, UserID: 2, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(9)).Return(&models.Wallet{ID: 9, UserID: 2, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(9), uint64(1)).Return(nil, nil)
+
+	_, err := service.MoveFromPot(ctx, 1, 5, 10)
+	assert.ErrorIs(t, err, ErrPotNotFound)
+}
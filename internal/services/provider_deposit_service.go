@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+	"github.com/google/uuid"
+)
+
+// ProviderDepositsLimit - максимальное число пополнений в списке для администратора.
+const ProviderDepositsLimit = 100
+
+// GetDepositReference возвращает код пополнения пользователя, который он указывает
+// в назначении платежа у провайдера.
+func (s *service) GetDepositReference(ctx context.Context, userID uint64) (string, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %v", err)
+	}
+	return user.DepositReference, nil
+}
+
+// HandleProviderDeposit обрабатывает уведомление провайдера providerName о входящем пополнении:
+// проверяет подпись, отбрасывает повторные доставки того же события и зачисляет сумму на кошелёк
+// пользователя, найденного по коду пополнения. Уведомления, которые нельзя зачислить, сохраняются
+// со статусом unmatched или rejected, чтобы провайдер не повторял доставку. Второе значение
+// сообщает, что событие уже было обработано ранее.
+func (s *service) HandleProviderDeposit(ctx context.Context, providerName string, payload []byte, signature string) (*models.ProviderDeposit, bool, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, false, ErrUnknownProvider
+	}
+	if !provider.VerifySignature(payload, signature) {
+		return nil, false, ErrInvalidSignature
+	}
+
+	event, err := provider.ParseDeposit(payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidDepositEvent, err)
+	}
+
+	deposit := &models.ProviderDeposit{
+		Provider:  providerName,
+		EventID:   event.EventID,
+		Reference: strings.ToUpper(strings.TrimSpace(event.Reference)),
+		Amount:    event.Amount,
+		Currency:  event.Currency,
+		Status:    models.ProviderDepositStatusReceived,
+		TenantID:  tenant.ID(ctx),
+	}
+
+	duplicate := false
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		created, err := repo.CreateProviderDeposit(ctx, deposit)
+		if err != nil {
+			return fmt.Errorf("failed to save provider deposit: %v", err)
+		}
+		if !created {
+			existing, err := repo.GetProviderDeposit(ctx, providerName, event.EventID)
+			if err != nil {
+				return fmt.Errorf("failed to get provider deposit: %v", err)
+			}
+			deposit, duplicate = existing, true
+			return nil
+		}
+
+		if err := s.creditProviderDeposit(ctx, repo, deposit); err != nil {
+			return err
+		}
+		return repo.UpdateProviderDeposit(ctx, deposit)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !duplicate {
+		s.logger.Infof("Provider deposit %s/%s processed with status %s", providerName, deposit.EventID, deposit.Status)
+	}
+	return deposit, duplicate, nil
+}
+
+// creditProviderDeposit сопоставляет пополнение с кошельком и зачисляет его. Если зачислить нельзя,
+// выставляет статус unmatched или rejected с причиной; ошибку возвращает только при сбое хранилища.
+func (s *service) creditProviderDeposit(ctx context.Context, repo repository.Repository, deposit *models.ProviderDeposit) error {
+	if err := s.validateAmount(ctx, deposit.Currency, deposit.Amount); err != nil {
+		if errors.Is(err, ErrInvalidAmount) || errors.Is(err, ErrUnsupportedCurrency) {
+			return rejectProviderDeposit(deposit, models.ProviderDepositStatusRejected, err.Error())
+		}
+		return err
+	}
+
+	user, err := repo.GetUserByDepositReference(ctx, deposit.Reference)
+	if err != nil {
+		return fmt.Errorf("failed to find user by deposit reference: %v", err)
+	}
+	if user == nil {
+		return rejectProviderDeposit(deposit, models.ProviderDepositStatusUnmatched, "unknown deposit reference")
+	}
+	deposit.UserID = &user.ID
+
+	wallet, err := s.lockWallet(ctx, repo, user.ID, deposit.Currency)
+	if err != nil {
+		if errors.Is(err, ErrWalletNotFound) {
+			return rejectProviderDeposit(deposit, models.ProviderDepositStatusUnmatched, "no wallet in "+deposit.Currency)
+		}
+		return err
+	}
+	deposit.WalletID = &wallet.ID
+
+	// Средства уже поступили к провайдеру, поэтому пополнение замороженного кошелька
+	// не теряется, а откладывается для ручного разбора.
+	if err := s.ensureCreditAllowed(ctx, repo, wallet); err != nil {
+		if errors.Is(err, ErrAccountFrozen) || errors.Is(err, ErrWalletFrozen) {
+			return rejectProviderDeposit(deposit, models.ProviderDepositStatusRejected, err.Error())
+		}
+		return err
+	}
+
+	deposit.OperationID = uuid.NewString()
+	description := fmt.Sprintf("Deposit via %s (%s)", deposit.Provider, deposit.EventID)
+	if _, err := s.postEntry(ctx, repo, wallet, deposit.Amount, models.TransactionTypeDeposit, deposit.OperationID, description); err != nil {
+		return err
+	}
+	deposit.Status = models.ProviderDepositStatusCredited
+	return nil
+}
+
+// rejectProviderDeposit выставляет пополнению статус status и причину reason.
+func rejectProviderDeposit(deposit *models.ProviderDeposit, status, reason string) error {
+	deposit.Status = status
+	deposit.Reason = reason
+	return nil
+}
+
+// GetProviderDeposits возвращает последние пополнения от провайдеров, при непустом status - только в этом статусе.
+func (s *service) GetProviderDeposits(ctx context.Context, status string) ([]*models.ProviderDeposit, error) {
+	switch status {
+	case "", models.ProviderDepositStatusReceived, models.ProviderDepositStatusCredited,
+		models.ProviderDepositStatusUnmatched, models.ProviderDepositStatusRejected:
+	default:
+		return nil, ErrInvalidDepositStatus
+	}
+
+	deposits, err := s.repo.GetProviderDeposits(ctx, status, ProviderDepositsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider deposits: %v", err)
+	}
+	return deposits, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/providers"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProviderDepositService(mockRepo *mocks.MockRepository, provider DepositProvider) *service {
+	return &service{
+		repo:      mockRepo,
+		providers: map[string]DepositProvider{provider.Name(): provider},
+		logger:    logrus.New(),
+	}
+}
+
+func TestHandleProviderDepositCredits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	provider := providers.NewFakeProvider("secret")
+	service := newProviderDepositService(mockRepo, provider)
+	ctx := context.Background()
+
+	payload, signature, err := provider.Deposit(&models.DepositEvent{EventID: "evt-1", Reference: "gwabc", Amount: 50, Currency: "USD"})
+	assert.NoError(t, err)
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	mockRepo.EXPECT().CreateProviderDeposit(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, deposit *models.ProviderDeposit) (bool, error) {
+			deposit.ID = 7
+			return true, nil
+		})
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetUserByDepositReference(ctx, "GWABC").Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrencyForUpdate(ctx, uint64(1), "USD").Return(&models.Wallet{ID: 3, UserID: 1, Balance: 100, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(3), 150.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdateProviderDeposit(ctx, gomock.Any()).Return(nil)
+
+	deposit, duplicate, err := service.HandleProviderDeposit(ctx, "fake", payload, signature)
+	assert.NoError(t, err)
+	assert.False(t, duplicate)
+	assert.Equal(t, models.ProviderDepositStatusCredited, deposit.Status)
+	assert.Equal(t, uint64(3), *deposit.WalletID)
+	assert.NotEmpty(t, deposit.OperationID)
+}
+
+func TestHandleProviderDepositDuplicate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	provider := providers.NewFakeProvider("secret")
+	service := newProviderDepositService(mockRepo, provider)
+	ctx := context.Background()
+
+	payload, signature, err := provider.Deposit(&models.DepositEvent{EventID: "evt-1", Reference: "GWABC", Amount: 50, Currency: "USD"})
+	assert.NoError(t, err)
+
+	// Повторная доставка возвращает сохранённый результат без зачисления
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().CreateProviderDeposit(ctx, gomock.Any()).Return(false, nil)
+	mockRepo.EXPECT().GetProviderDeposit(ctx, "fake", "evt-1").Return(&models.ProviderDeposit{
+		ID: 7, Provider: "fake", EventID: "evt-1", Status: models.ProviderDepositStatusCredited,
+	}, nil)
+
+	deposit, duplicate, err := service.HandleProviderDeposit(ctx, "fake", payload, signature)
+	assert.NoError(t, err)
+	assert.True(t, duplicate)
+	assert.Equal(t, uint64(7), deposit.ID)
+}
+
+func TestHandleProviderDepositUnmatchedReference(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	provider := providers.NewFakeProvider("secret")
+	service := newProviderDepositService(mockRepo, provider)
+	ctx := context.Background()
+
+	payload, signature, err := provider.Deposit(&models.DepositEvent{EventID: "evt-2", Reference: "GWNONE", Amount: 50, Currency: "USD"})
+	assert.NoError(t, err)
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().CreateProviderDeposit(ctx, gomock.Any()).Return(true, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetUserByDepositReference(ctx, "GWNONE").Return(nil, nil)
+	mockRepo.EXPECT().UpdateProviderDeposit(ctx, gomock.Any()).Return(nil)
+
+	deposit, _, err := service.HandleProviderDeposit(ctx, "fake", payload, signature)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ProviderDepositStatusUnmatched, deposit.Status)
+	assert.Nil(t, deposit.WalletID)
+}
+
+func TestHandleProviderDepositInvalidSignature(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := newProviderDepositService(mockRepo, providers.NewFakeProvider("secret"))
+
+	payload, signature, err := providers.NewFakeProvider("other").Deposit(&models.DepositEvent{EventID: "evt-3", Amount: 50, Currency: "USD"})
+	assert.NoError(t, err)
+
+	_, _, err = service.HandleProviderDeposit(context.Background(), "fake", payload, signature)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, _, err = service.HandleProviderDeposit(context.Background(), "unknown", payload, signature)
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
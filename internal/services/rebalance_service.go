@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// RebalanceQuoteTTL - срок, в течение которого план ребалансировки можно подтвердить по курсам предпросмотра.
+	RebalanceQuoteTTL = time.Minute
+	// rebalanceWeightTolerance - допустимое отклонение суммы целевых долей от 100%.
+	rebalanceWeightTolerance = 0.01
+	// rebalanceMinValue - расхождение со стоимостью меньше копейки базовой валюты не требует обмена.
+	rebalanceMinValue = 0.01
+)
+
+// PreviewRebalance рассчитывает обмены, приводящие свободные средства пользователя к целевому
+// распределению по текущим курсам, и сохраняет план для подтверждения.
+func (s *service) PreviewRebalance(ctx context.Context, userID uint64, request *models.RebalanceRequest) (*models.RebalancePlan, error) {
+	base := strings.ToUpper(strings.TrimSpace(request.Base))
+	if base == "" {
+		base = DefaultValuationBase
+	}
+	targets, err := s.validateRebalanceTargets(ctx, request.Targets)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.requireCurrency(ctx, base); err != nil {
+		return nil, err
+	}
+
+	wallets, err := s.repo.GetWalletsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve wallets: %v", err)
+	}
+	owned := make(map[string]bool, len(wallets))
+	for _, wallet := range wallets {
+		owned[wallet.Currency] = true
+	}
+	for currency := range targets {
+		if !owned[currency] {
+			return nil, fmt.Errorf("%w: %s", ErrWalletNotFound, currency)
+		}
+	}
+
+	rates, err := s.GetAllRates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %v", err)
+	}
+	ratesAt := time.Now().UTC()
+	baseRate, ok := rates[base]
+	if !ok || baseRate <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRateUnavailable, base)
+	}
+
+	// Оценка свободных средств: заблокированные и отложенные в копилки суммы не перераспределяются,
+	// а отрицательный баланс по овердрафту не продаётся.
+	allocations := make([]*models.RebalanceAllocation, 0, len(wallets))
+	total := 0.0
+	for _, wallet := range wallets {
+		rate, ok := rates[wallet.Currency]
+		if !ok || rate <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrRateUnavailable, wallet.Currency)
+		}
+		free, err := s.freeFunds(ctx, s.repo, wallet)
+		if err != nil {
+			return nil, err
+		}
+		free = math.Max(roundAmount(free), 0)
+
+		value := free * rate / baseRate
+		total += value
+		allocations = append(allocations, &models.RebalanceAllocation{
+			Currency:     wallet.Currency,
+			Available:    free,
+			Value:        value,
+			TargetWeight: targets[wallet.Currency],
+		})
+	}
+	if total < rebalanceMinValue {
+		return nil, ErrNothingToRebalance
+	}
+
+	for _, allocation := range allocations {
+		allocation.TargetValue = total * allocation.TargetWeight / 100
+		allocation.CurrentWeight = roundWeight(allocation.Value / total * 100)
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].Currency < allocations[j].Currency
+	})
+
+	plan := &models.RebalancePlan{
+		UserID:    userID,
+		Base:      base,
+		Total:     roundAmount(total),
+		Status:    models.RebalanceStatusPending,
+		Legs:      planRebalance(allocations, rates, base),
+		RatesAt:   ratesAt,
+		ExpiresAt: ratesAt.Add(RebalanceQuoteTTL),
+	}
+	for _, allocation := range allocations {
+		allocation.Value = roundAmount(allocation.Value)
+		allocation.TargetValue = roundAmount(allocation.TargetValue)
+	}
+	plan.Allocations = allocations
+
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		return repo.CreateRebalancePlan(ctx, plan)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save rebalance plan: %v", err)
+	}
+
+	return plan, nil
+}
+
+// ConfirmRebalance исполняет все обмены плана одной операцией по курсам предпросмотра.
+// Если свободных средств для какого-либо обмена уже не хватает, не исполняется ни один обмен.
+func (s *service) ConfirmRebalance(ctx context.Context, userID, planID uint64) (*models.RebalancePlan, error) {
+	var plan *models.RebalancePlan
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		plan, err = repo.GetRebalancePlanByIDForUpdate(ctx, planID)
+		if err != nil {
+			return fmt.Errorf("failed to get rebalance plan: %v", err)
+		}
+		if plan == nil || plan.UserID != userID {
+			return ErrRebalanceNotFound
+		}
+		if plan.Status != models.RebalanceStatusPending {
+			return ErrRebalanceNotPending
+		}
+		if !plan.ExpiresAt.After(time.Now()) {
+			return ErrRebalanceExpired
+		}
+
+		wallets, err := s.lockRebalanceWallets(ctx, repo, userID, plan.Legs)
+		if err != nil {
+			return err
+		}
+
+		operationID := uuid.NewString()
+		for _, leg := range plan.Legs {
+			fromWallet, toWallet := wallets[leg.FromCurrency], wallets[leg.ToCurrency]
+
+			// Ребалансировка перераспределяет только собственные средства, без кредитной линии.
+			free, err := s.freeFunds(ctx, repo, fromWallet)
+			if err != nil {
+				return err
+			}
+			if free < leg.Amount {
+				return fmt.Errorf("%w in %s wallet", ErrInsufficientFunds, leg.FromCurrency)
+			}
+
+			description := fmt.Sprintf("Rebalance %s to %s", leg.FromCurrency, leg.ToCurrency)
+			if _, err := s.postEntry(ctx, repo, fromWallet, -leg.Amount, models.TransactionTypeExchangeOut, operationID, description); err != nil {
+				return err
+			}
+			if _, err := s.postEntry(ctx, repo, toWallet, leg.ExchangedAmount, models.TransactionTypeExchangeIn, operationID, description); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		plan.Status = models.RebalanceStatusExecuted
+		plan.OperationID = operationID
+		plan.ExecutedAt = &now
+		return repo.UpdateRebalancePlan(ctx, plan)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// validateRebalanceTargets проверяет целевые доли и возвращает их с нормализованными кодами валют.
+func (s *service) validateRebalanceTargets(ctx context.Context, targets map[string]float64) (map[string]float64, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: targets are required", ErrInvalidRebalance)
+	}
+
+	normalized := make(map[string]float64, len(targets))
+	sum := 0.0
+	for code, weight := range targets {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if weight < 0 || weight > 100 {
+			return nil, fmt.Errorf("%w: weight of %s must be between 0 and 100", ErrInvalidRebalance, code)
+		}
+		if _, ok := normalized[code]; ok {
+			return nil, fmt.Errorf("%w: duplicate currency %s", ErrInvalidRebalance, code)
+		}
+		if _, err := s.requireCurrency(ctx, code); err != nil {
+			return nil, err
+		}
+		normalized[code] = weight
+		sum += weight
+	}
+	if math.Abs(sum-100) > rebalanceWeightTolerance {
+		return nil, fmt.Errorf("%w: weights must sum to 100, got %.2f", ErrInvalidRebalance, sum)
+	}
+	return normalized, nil
+}
+
+// lockRebalanceWallets блокирует кошельки всех валют плана в порядке возрастания ID и проверяет,
+// что списания и зачисления по ним разрешены.
+func (s *service) lockRebalanceWallets(ctx context.Context, repo repository.Repository, userID uint64, legs []*models.RebalanceLeg) (map[string]*models.Wallet, error) {
+	debited := make(map[string]bool)
+	credited := make(map[string]bool)
+	for _, leg := range legs {
+		debited[leg.FromCurrency] = true
+		credited[leg.ToCurrency] = true
+	}
+
+	ids := make([]uint64, 0, len(debited)+len(credited))
+	byCurrency := make(map[string]uint64)
+	for _, currencies := range []map[string]bool{debited, credited} {
+		for currency := range currencies {
+			if _, ok := byCurrency[currency]; ok {
+				continue
+			}
+			wallet, err := s.findWallet(repo, userID, currency)
+			if err != nil {
+				return nil, err
+			}
+			byCurrency[currency] = wallet.ID
+			ids = append(ids, wallet.ID)
+		}
+	}
+
+	locked, err := s.lockWallets(ctx, repo, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make(map[string]*models.Wallet, len(byCurrency))
+	for currency, id := range byCurrency {
+		wallet := locked[id]
+		if debited[currency] {
+			if err := s.ensureDebitAllowed(ctx, repo, wallet); err != nil {
+				return nil, err
+			}
+		}
+		if credited[currency] {
+			if err := s.ensureCreditAllowed(ctx, repo, wallet); err != nil {
+				return nil, err
+			}
+		}
+		wallets[currency] = wallet
+	}
+	return wallets, nil
+}
+
+// planRebalance подбирает обмены, переводящие избыток стоимости одних валют в недостаток других.
+// Наибольший избыток каждый раз покрывает наибольший недостаток, поэтому каждый обмен полностью
+// закрывает хотя бы одну валюту и обменов не больше, чем валют минус один.
+// Стоимости allocations указаны в валюте base, курсы rates выражают стоимость единицы валюты
+// в общей опорной валюте.
+func planRebalance(allocations []*models.RebalanceAllocation, rates map[string]float64, base string) []*models.RebalanceLeg {
+	type imbalance struct {
+		allocation *models.RebalanceAllocation
+		value      float64
+	}
+
+	var surpluses, deficits []*imbalance
+	for _, allocation := range allocations {
+		diff := allocation.Value - allocation.TargetValue
+		switch {
+		case diff >= rebalanceMinValue:
+			surpluses = append(surpluses, &imbalance{allocation: allocation, value: diff})
+		case -diff >= rebalanceMinValue:
+			deficits = append(deficits, &imbalance{allocation: allocation, value: -diff})
+		}
+	}
+	byValue := func(items []*imbalance) func(i, j int) bool {
+		return func(i, j int) bool {
+			if items[i].value != items[j].value {
+				return items[i].value > items[j].value
+			}
+			return items[i].allocation.Currency < items[j].allocation.Currency
+		}
+	}
+	sort.Slice(surpluses, byValue(surpluses))
+	sort.Slice(deficits, byValue(deficits))
+
+	var legs []*models.RebalanceLeg
+	sold := make(map[string]float64)
+	for i, j := 0, 0; i < len(surpluses) && j < len(deficits); {
+		from, to := surpluses[i], deficits[j]
+		value := math.Min(from.value, to.value)
+
+		fromCurrency, toCurrency := from.allocation.Currency, to.allocation.Currency
+		amount := roundAmount(value * rates[base] / rates[fromCurrency])
+		// Округление не должно продать больше свободного остатка.
+		amount = math.Min(amount, roundAmount(from.allocation.Available-sold[fromCurrency]))
+
+		rate := rates[fromCurrency] / rates[toCurrency]
+		exchanged := roundAmount(amount * rate)
+		if amount > 0 && exchanged > 0 {
+			sold[fromCurrency] += amount
+			legs = append(legs, &models.RebalanceLeg{
+				FromCurrency:    fromCurrency,
+				ToCurrency:      toCurrency,
+				Amount:          amount,
+				Rate:            rate,
+				ExchangedAmount: exchanged,
+			})
+		}
+
+		from.value -= value
+		to.value -= value
+		if from.value < rebalanceMinValue {
+			i++
+		}
+		if to.value < rebalanceMinValue {
+			j++
+		}
+	}
+
+	return legs
+}
+
+// roundWeight округляет долю в процентах до сотых.
+func roundWeight(weight float64) float64 {
+	return math.Round(weight*100) / 100
+}
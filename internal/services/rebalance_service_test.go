@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanRebalanceMinimalLegs(t *testing.T) {
+	// Стоимость единицы валюты в опорной валюте: 1 EUR = 1.25 USD, 1 RUB = 0.01 USD
+	rates := map[string]float64{"USD": 1, "EUR": 1.25, "RUB": 0.01}
+	allocations := []*models.RebalanceAllocation{
+		{Currency: "USD", Available: 1000, Value: 1000, TargetValue: 600},
+		{Currency: "EUR", Available: 0, Value: 0, TargetValue: 250},
+		{Currency: "RUB", Available: 0, Value: 0, TargetValue: 150},
+	}
+
+	legs := planRebalance(allocations, rates, "USD")
+	assert.Len(t, legs, 2)
+
+	assert.Equal(t, "USD", legs[0].FromCurrency)
+	assert.Equal(t, "EUR", legs[0].ToCurrency)
+	assert.Equal(t, 250.0, legs[0].Amount)
+	assert.Equal(t, 200.0, legs[0].ExchangedAmount)
+
+	assert.Equal(t, "USD", legs[1].FromCurrency)
+	assert.Equal(t, "RUB", legs[1].ToCurrency)
+	assert.Equal(t, 150.0, legs[1].Amount)
+	assert.Equal(t, 15000.0, legs[1].ExchangedAmount)
+}
+
+func TestPlanRebalanceBalancedPortfolio(t *testing.T) {
+	rates := map[string]float64{"USD": 1, "EUR": 1.25}
+	allocations := []*models.RebalanceAllocation{
+		{Currency: "USD", Available: 500, Value: 500, TargetValue: 500.004},
+		{Currency: "EUR", Available: 400, Value: 500, TargetValue: 499.996},
+	}
+
+	assert.Empty(t, planRebalance(allocations, rates, "USD"))
+}
+
+func TestPreviewRebalanceInvalidWeights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, gomock.Any()).Return(&models.Currency{MinorUnits: 2, Enabled: true}, nil).AnyTimes()
+
+	_, err := service.PreviewRebalance(ctx, 1, &models.RebalanceRequest{Targets: map[string]float64{"USD": 50, "EUR": 30}})
+	assert.ErrorIs(t, err, ErrInvalidRebalance)
+
+	_, err = service.PreviewRebalance(ctx, 1, &models.RebalanceRequest{Targets: map[string]float64{"USD": 120, "EUR": -20}})
+	assert.ErrorIs(t, err, ErrInvalidRebalance)
+}
+
+func TestConfirmRebalanceExecutesAllLegs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	plan := &models.RebalancePlan{
+		ID: 4, UserID: 1, Status: models.RebalanceStatusPending, ExpiresAt: time.Now().Add(time.Minute),
+		Legs: []*models.RebalanceLeg{
+			{FromCurrency: "USD", ToCurrency: "EUR", Amount: 250, Rate: 0.8, ExchangedAmount: 200},
+			{FromCurrency: "USD", ToCurrency: "RUB", Amount: 150, Rate: 100, ExchangedAmount: 15000},
+		},
+	}
+	mockRepo.EXPECT().GetRebalancePlanByIDForUpdate(ctx, uint64(4)).Return(plan, nil)
+
+	usd := &models.Wallet{ID: 1, UserID: 1, Balance: 1000, Currency: "USD"}
+	eur := &models.Wallet{ID: 2, UserID: 1, Balance: 0, Currency: "EUR"}
+	rub := &models.Wallet{ID: 3, UserID: 1, Balance: 0, Currency: "RUB"}
+	for _, wallet := range []*models.Wallet{usd, eur, rub} {
+		mockRepo.EXPECT().GetWalletByUserAndCurrency(uint64(1), wallet.Currency).Return(wallet, nil)
+	}
+	gomock.InOrder(
+		mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(1)).Return(usd, nil),
+		mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(2)).Return(eur, nil),
+		mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(3)).Return(rub, nil),
+	)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil).AnyTimes()
+	mockRepo.EXPECT().GetHeldAmount(ctx, uint64(1)).Return(0.0, nil).Times(2)
+	mockRepo.EXPECT().GetPottedAmount(ctx, uint64(1)).Return(0.0, nil).Times(2)
+
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(1), 750.0).Return(nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(2), 200.0).Return(nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(1), 600.0).Return(nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(3), 15000.0).Return(nil)
+
+	var operations []string
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, transaction *models.Transaction) error {
+			operations = append(operations, transaction.OperationID)
+			return nil
+		}).Times(4)
+	mockRepo.EXPECT().UpdateRebalancePlan(ctx, plan).Return(nil)
+
+	executed, err := service.ConfirmRebalance(ctx, 1, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RebalanceStatusExecuted, executed.Status)
+	assert.NotNil(t, executed.ExecutedAt)
+	for _, operationID := range operations {
+		assert.Equal(t, executed.OperationID, operationID)
+	}
+}
+
+func TestConfirmRebalanceExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetRebalancePlanByIDForUpdate(ctx, uint64(4)).Return(&models.RebalancePlan{
+		ID: 4, UserID: 1, Status: models.RebalanceStatusPending, ExpiresAt: time.Now().Add(-time.Second),
+	}, nil)
+
+	_, err := service.ConfirmRebalance(ctx, 1, 4)
+	assert.ErrorIs(t, err, ErrRebalanceExpired)
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+)
+
+// reconciliationRunsLimit - количество последних сверок, возвращаемых администратору.
+const reconciliationRunsLimit = 90
+
+// RunReconciliation делает снимок балансов всех кошельков за текущие сутки (UTC) и сверяет их
+// с журналом транзакций. Сверка выполняется не чаще раза в сутки: если за сегодня она уже есть,
+// возвращается nil. Воркер может вызывать метод чаще - лишние вызовы ничего не делают.
+func (s *service) RunReconciliation(ctx context.Context) (*models.ReconciliationRun, error) {
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+
+	var run *models.ReconciliationRun
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		run, err = repo.CreateReconciliationRun(ctx, date)
+		if err != nil || run == nil {
+			return err
+		}
+		return repo.SnapshotBalances(ctx, run)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if run != nil && run.Mismatches > 0 {
+		s.logger.Warnf("Reconciliation %d for %s found %d mismatched wallets out of %d",
+			run.ID, date.Format("2006-01-02"), run.Mismatches, run.WalletsChecked)
+	}
+	return run, nil
+}
+
+// GetReconciliationRuns возвращает последние сверки.
+func (s *service) GetReconciliationRuns(ctx context.Context) ([]*models.ReconciliationRun, error) {
+	return s.repo.GetReconciliationRuns(ctx, reconciliationRunsLimit)
+}
+
+// GetReconciliationReport возвращает сверку и снимки балансов; если onlyMismatches - только расхождения.
+func (s *service) GetReconciliationReport(ctx context.Context, runID uint64, onlyMismatches bool) (*models.ReconciliationRun, []*models.BalanceSnapshot, error) {
+	run, err := s.repo.GetReconciliationRunByID(ctx, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if run == nil {
+		return nil, nil, ErrReconciliationNotFound
+	}
+
+	snapshots, err := s.repo.GetBalanceSnapshots(ctx, runID, onlyMismatches)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, snapshots, nil
+}
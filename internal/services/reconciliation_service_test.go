@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunReconciliationOncePerDay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	mockRepo.EXPECT().CreateReconciliationRun(ctx, today).Return(&models.ReconciliationRun{ID: 1, SnapshotDate: today}, nil)
+	mockRepo.EXPECT().SnapshotBalances(ctx, gomock.Any()).
+		Do(func(_ context.Context, run *models.ReconciliationRun) {
+			run.WalletsChecked, run.Mismatches = 10, 1
+		}).Return(nil)
+
+	run, err := service.RunReconciliation(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), run.WalletsChecked)
+	assert.Equal(t, int64(1), run.Mismatches)
+
+	// Повторный запуск в те же сутки ничего не делает
+	mockRepo.EXPECT().CreateReconciliationRun(ctx, today).Return(nil, nil)
+
+	run, err = service.RunReconciliation(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, run)
+}
+
+func TestGetReconciliationReportNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetReconciliationRunByID(ctx, uint64(5)).Return(nil, nil)
+
+	_, _, err := service.GetReconciliationReport(ctx, 5, true)
+	assert.ErrorIs(t, err, ErrReconciliationNotFound)
+}
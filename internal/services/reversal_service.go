@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ReverseTransaction сторнирует операцию, к которой относится проводка transactionID: по каждой
+// проводке операции (например, по обеим ногам обмена или перевода) записывается компенсирующая
+// проводка на противоположную сумму. Повторное сторнирование одной операции запрещено.
+func (s *service) ReverseTransaction(ctx context.Context, operatorID, transactionID uint64, reason string) (*models.Reversal, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	original, err := s.repo.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	reversal := &models.Reversal{
+		OriginalOperationID: original.OperationID,
+		ReversalOperationID: uuid.NewString(),
+		OperatorID:          operatorID,
+		Reason:              reason,
+	}
+
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		entries, err := repo.GetTransactionsByOperationID(ctx, original.OperationID)
+		if err != nil {
+			return err
+		}
+
+		walletIDs := make([]uint64, 0, len(entries))
+		for _, entry := range entries {
+			if entry.Type == models.TransactionTypeOpeningBalance || entry.Type == models.TransactionTypeReversal {
+				return ErrNotReversible
+			}
+			walletIDs = append(walletIDs, entry.WalletID)
+		}
+
+		// Кошельки блокируются до проверки, чтобы параллельное сторнирование той же операции
+		// дождалось завершения текущего и увидело запись о нём.
+		wallets, err := s.lockWallets(ctx, repo, walletIDs...)
+		if err != nil {
+			return err
+		}
+		for _, wallet := range wallets {
+			if !sameTenant(ctx, wallet.TenantID) {
+				return ErrTransactionNotFound
+			}
+		}
+
+		existing, err := repo.GetReversalByOperationID(ctx, original.OperationID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrAlreadyReversed
+		}
+
+		for _, entry := range entries {
+			wallet := wallets[entry.WalletID]
+			if entry.Amount > 0 {
+				if err := s.ensureAvailable(ctx, repo, wallet, entry.Amount); err != nil {
+					return err
+				}
+			}
+
+			compensating, err := s.postTransaction(ctx, repo, wallet, &models.Transaction{
+				OperationID:           reversal.ReversalOperationID,
+				Type:                  models.TransactionTypeReversal,
+				Amount:                -entry.Amount,
+				Description:           fmt.Sprintf("Reversal of transaction %d: %s", entry.ID, reason),
+				ReversedTransactionID: entry.ID,
+			})
+			if err != nil {
+				return err
+			}
+			reversal.Entries = append(reversal.Entries, compensating)
+		}
+
+		return repo.CreateReversal(ctx, reversal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Operation %s reversed by operator %d: %s", reversal.OriginalOperationID, operatorID, reason)
+	return reversal, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseTransactionExchange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	out := &models.Transaction{ID: 11, WalletID: 9, OperationID: "op-1", Type: models.TransactionTypeExchangeOut, Amount: -100}
+	in := &models.Transaction{ID: 12, WalletID: 4, OperationID: "op-1", Type: models.TransactionTypeExchangeIn, Amount: 90}
+	mockRepo.EXPECT().GetTransactionByID(ctx, uint64(12)).Return(in, nil)
+	mockRepo.EXPECT().GetTransactionsByOperationID(ctx, "op-1").Return([]*models.Transaction{out, in}, nil)
+
+	// Кошельки блокируются в порядке возрастания ID
+	gomock.InOrder(
+		mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(4)).
+			Return(&models.Wallet{ID: 4, Balance: 90, Currency: "EUR"}, nil),
+		mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(9)).
+			Return(&models.Wallet{ID: 9, Balance: 0, Currency: "USD"}, nil),
+	)
+	mockRepo.EXPECT().GetReversalByOperationID(ctx, "op-1").Return(nil, nil)
+	mockRepo.EXPECT().GetHeldAmount(ctx, uint64(4)).Return(0.0, nil)
+	mockRepo.EXPECT().GetPottedAmount(ctx, uint64(4)).Return(0.0, nil)
+	mockRepo.EXPECT().GetCreditLine(ctx, uint64(4)).Return(nil, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(9), 100.0).Return(nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(4), 0.0).Return(nil)
+
+	var compensating []*models.Transaction
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).
+		Do(func(_ context.Context, transaction *models.Transaction) {
+			compensating = append(compensating, transaction)
+		}).Return(nil).Times(2)
+	mockRepo.EXPECT().CreateReversal(ctx, gomock.Any()).
+		Do(func(_ context.Context, reversal *models.Reversal) {
+			assert.Equal(t, "op-1", reversal.OriginalOperationID)
+			assert.Equal(t, uint64(1), reversal.OperatorID)
+			assert.Equal(t, "duplicate charge", reversal.Reason)
+		}).Return(nil)
+
+	reversal, err := service.ReverseTransaction(ctx, 1, 12, " duplicate charge ")
+	assert.NoError(t, err)
+	assert.Len(t, reversal.Entries, 2)
+	for i, original := range []*models.Transaction{out, in} {
+		assert.Equal(t, models.TransactionTypeReversal, compensating[i].Type)
+		assert.Equal(t, -original.Amount, compensating[i].Amount)
+		assert.Equal(t, original.ID, compensating[i].ReversedTransactionID)
+		assert.Equal(t, reversal.ReversalOperationID, compensating[i].OperationID)
+	}
+}
+
+func TestReverseTransactionAlreadyReversed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	deposit := &models.Transaction{ID: 5, WalletID: 2, OperationID: "op-2", Type: models.TransactionTypeDeposit, Amount: 50}
+	mockRepo.EXPECT().GetTransactionByID(ctx, uint64(5)).Return(deposit, nil)
+	mockRepo.EXPECT().GetTransactionsByOperationID(ctx, "op-2").Return([]*models.Transaction{deposit}, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(2)).Return(&models.Wallet{ID: 2, Balance: 50}, nil)
+	mockRepo.EXPECT().GetReversalByOperationID(ctx, "op-2").Return(&models.Reversal{ID: 1, OriginalOperationID: "op-2"}, nil)
+
+	reversal, err := service.ReverseTransaction(ctx, 1, 5, "mistake")
+	assert.ErrorIs(t, err, ErrAlreadyReversed)
+	assert.Nil(t, reversal)
+}
+
+func TestReverseTransactionRequiresReason(t *testing.T) {
+	service := &service{logger: logrus.New()}
+
+	_, err := service.ReverseTransaction(context.Background(), 1, 5, "  ")
+	assert.ErrorIs(t, err, ErrReasonRequired)
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/cron"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const (
+	// MinScheduleInterval - минимальный интервал повторяющейся операции.
+	MinScheduleInterval = time.Minute
+	// scheduleBatchSize - количество операций, захватываемых за один проход воркера.
+	scheduleBatchSize = 50
+	// scheduleLease - время, на которое операция резервируется за экземпляром сервиса.
+	scheduleLease = 5 * time.Minute
+)
+
+// CreateSchedule создаёт разовое или повторяющееся поручение на обмен или перевод.
+func (s *service) CreateSchedule(ctx context.Context, userID uint64, request *models.ScheduleRequest) (*models.ScheduledTransfer, error) {
+	if err := s.validateAmount(ctx, request.FromCurrency, request.Amount); err != nil {
+		return nil, err
+	}
+
+	schedule := &models.ScheduledTransfer{
+		UserID:       userID,
+		Type:         request.Type,
+		FromCurrency: request.FromCurrency,
+		Amount:       request.Amount,
+		Status:       models.ScheduleStatusActive,
+	}
+
+	switch request.Type {
+	case models.ScheduleTypeExchange:
+		if request.ToCurrency == "" {
+			return nil, fmt.Errorf("%w: to_currency is required", ErrInvalidSchedule)
+		}
+		if request.ToCurrency == request.FromCurrency {
+			return nil, ErrSameCurrency
+		}
+		if _, err := s.requireCurrency(ctx, request.ToCurrency); err != nil {
+			return nil, err
+		}
+		if _, err := s.findWallet(s.repo, userID, request.ToCurrency); err != nil {
+			return nil, err
+		}
+		schedule.ToCurrency = request.ToCurrency
+	case models.ScheduleTypeTransfer:
+		if request.ToUsername == "" {
+			return nil, fmt.Errorf("%w: to_username is required", ErrInvalidSchedule)
+		}
+		recipient, err := s.repo.GetUserByUsername(ctx, request.ToUsername)
+		if err != nil {
+			return nil, ErrRecipientNotFound
+		}
+		if recipient.ID == userID {
+			return nil, ErrSelfTransfer
+		}
+		schedule.ToUserID = recipient.ID
+		schedule.ToCurrency = request.FromCurrency
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidSchedule, request.Type)
+	}
+
+	if _, err := s.findWallet(s.repo, userID, request.FromCurrency); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	start := now
+	if request.RunAt != nil && request.RunAt.After(now) {
+		start = *request.RunAt
+	}
+
+	switch {
+	case request.Cron != "" && request.IntervalSeconds != 0:
+		return nil, fmt.Errorf("%w: cron and interval_seconds are mutually exclusive", ErrInvalidSchedule)
+	case request.Cron != "":
+		expr, err := cron.Parse(request.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		next := expr.Next(start)
+		if next.IsZero() {
+			return nil, fmt.Errorf("%w: cron expression never fires", ErrInvalidSchedule)
+		}
+		schedule.Kind = models.ScheduleKindCron
+		schedule.CronExpr = request.Cron
+		schedule.NextRunAt = &next
+	case request.IntervalSeconds != 0:
+		interval := time.Duration(request.IntervalSeconds) * time.Second
+		if interval < MinScheduleInterval {
+			return nil, fmt.Errorf("%w: interval must be at least %s", ErrInvalidSchedule, MinScheduleInterval)
+		}
+		schedule.Kind = models.ScheduleKindInterval
+		schedule.IntervalSeconds = request.IntervalSeconds
+		schedule.NextRunAt = &start
+	default:
+		schedule.Kind = models.ScheduleKindOnce
+		schedule.NextRunAt = &start
+	}
+
+	if _, err := s.repo.CreateScheduledTransfer(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// GetSchedules возвращает запланированные операции пользователя.
+func (s *service) GetSchedules(ctx context.Context, userID uint64) ([]*models.ScheduledTransfer, error) {
+	return s.repo.GetScheduledTransfersByUserID(ctx, userID)
+}
+
+// GetScheduleExecutions возвращает историю выполнения операции пользователя.
+func (s *service) GetScheduleExecutions(ctx context.Context, userID, scheduleID uint64) ([]*models.ScheduleExecution, error) {
+	if _, err := s.getUserSchedule(ctx, userID, scheduleID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetScheduleExecutions(ctx, scheduleID)
+}
+
+// PauseSchedule приостанавливает активную операцию.
+func (s *service) PauseSchedule(ctx context.Context, userID, scheduleID uint64) (*models.ScheduledTransfer, error) {
+	schedule, err := s.getUserSchedule(ctx, userID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != models.ScheduleStatusActive {
+		return nil, ErrScheduleNotAllowed
+	}
+
+	schedule.Status = models.ScheduleStatusPaused
+	if err := s.repo.UpdateScheduledTransferStatus(ctx, schedule.ID, schedule.Status, schedule.NextRunAt); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// ResumeSchedule возобновляет приостановленную операцию. Пропущенные за время паузы
+// запуски повторяющихся операций не выполняются.
+func (s *service) ResumeSchedule(ctx context.Context, userID, scheduleID uint64) (*models.ScheduledTransfer, error) {
+	schedule, err := s.getUserSchedule(ctx, userID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != models.ScheduleStatusPaused {
+		return nil, ErrScheduleNotAllowed
+	}
+
+	now := time.Now()
+	if schedule.Kind != models.ScheduleKindOnce && schedule.NextRunAt != nil && schedule.NextRunAt.Before(now) {
+		schedule.NextRunAt = nextRunAfter(schedule, now)
+	}
+
+	schedule.Status = models.ScheduleStatusActive
+	if err := s.repo.UpdateScheduledTransferStatus(ctx, schedule.ID, schedule.Status, schedule.NextRunAt); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// CancelSchedule отменяет активную или приостановленную операцию.
+func (s *service) CancelSchedule(ctx context.Context, userID, scheduleID uint64) (*models.ScheduledTransfer, error) {
+	schedule, err := s.getUserSchedule(ctx, userID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != models.ScheduleStatusActive && schedule.Status != models.ScheduleStatusPaused {
+		return nil, ErrScheduleNotAllowed
+	}
+
+	schedule.Status = models.ScheduleStatusCancelled
+	schedule.NextRunAt = nil
+	if err := s.repo.UpdateScheduledTransferStatus(ctx, schedule.ID, schedule.Status, nil); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// errScheduleRunFailed откатывает транзакцию запуска, в котором операция завершилась ошибкой,
+// чтобы её частичные изменения не сохранились.
+var errScheduleRunFailed = errors.New("scheduled run failed")
+
+// errScheduleRunExecuted означает, что запуск уже выполнен другим экземпляром сервиса.
+var errScheduleRunExecuted = errors.New("scheduled run has already been executed")
+
+// RunDueSchedules выполняет все операции, время запуска которых наступило,
+// и возвращает количество обработанных операций.
+func (s *service) RunDueSchedules(ctx context.Context) (int, error) {
+	now := time.Now()
+	schedules, err := s.repo.ClaimDueScheduledTransfers(ctx, now, scheduleBatchSize, scheduleLease)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, schedule := range schedules {
+		if err := s.runSchedule(ctx, schedule); err != nil {
+			s.logger.Errorf("Failed to run schedule %d: %v", schedule.ID, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// runSchedule выполняет запуск операции в одной транзакции с записью истории и переносом
+// следующего запуска: при сбое запуск либо сохраняется целиком, либо повторяется после
+// истечения аренды. Если операция завершилась ошибкой, её изменения откатываются,
+// а неудачное выполнение записывается отдельной транзакцией.
+func (s *service) runSchedule(ctx context.Context, schedule *models.ScheduledTransfer) error {
+	scheduledFor := *schedule.NextRunAt
+
+	var execution *models.ScheduleExecution
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		execution = s.withRepo(repo).executeSchedule(ctx, schedule, scheduledFor)
+		if execution.Status == models.ExecutionStatusFailed {
+			return errScheduleRunFailed
+		}
+		return s.completeScheduleRun(ctx, repo, schedule, execution)
+	})
+	if errors.Is(err, errScheduleRunFailed) {
+		err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+			return s.completeScheduleRun(ctx, repo, schedule, execution)
+		})
+	}
+	return err
+}
+
+// completeScheduleRun записывает выполнение запуска в историю и переносит операцию
+// на следующий запуск или завершает её.
+func (s *service) completeScheduleRun(ctx context.Context, repo repository.Repository, schedule *models.ScheduledTransfer, execution *models.ScheduleExecution) error {
+	created, err := repo.CreateScheduleExecution(ctx, execution)
+	if err != nil {
+		return fmt.Errorf("failed to save execution: %v", err)
+	}
+	if !created {
+		return errScheduleRunExecuted
+	}
+
+	executedAt := execution.ExecutedAt
+	schedule.LastRunAt = &executedAt
+	schedule.NextRunAt = nextRunAfter(schedule, executedAt)
+	if schedule.NextRunAt == nil {
+		schedule.Status = models.ScheduleStatusCompleted
+	}
+	if err := repo.CompleteScheduledTransferRun(ctx, schedule); err != nil {
+		return fmt.Errorf("failed to complete run: %v", err)
+	}
+	return nil
+}
+
+// executeSchedule выполняет операцию через сервис кошельков и возвращает запись для истории.
+// Операция выполняется в тенанте владельца, чтобы действовали его справочник валют и лимиты.
+func (s *service) executeSchedule(ctx context.Context, schedule *models.ScheduledTransfer, scheduledFor time.Time) *models.ScheduleExecution {
+	execution := &models.ScheduleExecution{
+		ScheduleID:   schedule.ID,
+		Status:       models.ExecutionStatusSucceeded,
+		Amount:       schedule.Amount,
+		ScheduledFor: scheduledFor,
+	}
+
+	owner, err := s.repo.GetUserByID(ctx, schedule.UserID)
+	if err != nil {
+		err = fmt.Errorf("failed to retrieve schedule owner: %v", err)
+	} else {
+		ctx = tenant.WithID(ctx, owner.TenantID)
+		switch schedule.Type {
+		case models.ScheduleTypeExchange:
+			execution.ExchangedAmount, _, err = s.Exchange(ctx, schedule.UserID, schedule.FromCurrency, schedule.ToCurrency, schedule.Amount)
+		case models.ScheduleTypeTransfer:
+			_, err = s.Transfer(ctx, schedule.UserID, schedule.ToUserID, schedule.FromCurrency, schedule.Amount)
+		default:
+			err = fmt.Errorf("unknown schedule type %q", schedule.Type)
+		}
+	}
+	execution.ExecutedAt = time.Now()
+
+	if err != nil {
+		s.logger.Errorf("Scheduled transfer %d failed: %v", schedule.ID, err)
+		execution.Status = models.ExecutionStatusFailed
+		execution.Error = err.Error()
+	}
+	return execution
+}
+
+// getUserSchedule возвращает запланированную операцию, принадлежащую пользователю.
+func (s *service) getUserSchedule(ctx context.Context, userID, scheduleID uint64) (*models.ScheduledTransfer, error) {
+	schedule, err := s.repo.GetScheduledTransferByID(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil || schedule.UserID != userID {
+		return nil, ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+// nextRunAfter вычисляет ближайший запуск повторяющейся операции строго после after.
+// Для разовых операций возвращает nil.
+func nextRunAfter(schedule *models.ScheduledTransfer, after time.Time) *time.Time {
+	switch schedule.Kind {
+	case models.ScheduleKindInterval:
+		interval := time.Duration(schedule.IntervalSeconds) * time.Second
+		if interval <= 0 {
+			return nil
+		}
+		next := after.Add(interval)
+		if schedule.NextRunAt != nil {
+			// Сохраняем исходную сетку запусков, пропуская прошедшие
+			next = *schedule.NextRunAt
+			if !next.After(after) {
+				next = next.Add((after.Sub(next)/interval + 1) * interval)
+			}
+		}
+		return &next
+	case models.ScheduleKindCron:
+		expr, err := cron.Parse(schedule.CronExpr)
+		if err != nil {
+			return nil
+		}
+		next := expr.Next(after)
+		if next.IsZero() {
+			return nil
+		}
+		return &next
+	default:
+		return nil
+	}
+}
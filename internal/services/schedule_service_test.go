@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextRunAfter(t *testing.T) {
+	scheduled := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	// Интервальное расписание сохраняет сетку запусков и пропускает прошедшие
+	interval := &models.ScheduledTransfer{
+		Kind:            models.ScheduleKindInterval,
+		IntervalSeconds: 3600,
+		NextRunAt:       &scheduled,
+	}
+	next := nextRunAfter(interval, scheduled.Add(150*time.Minute))
+	assert.Equal(t, scheduled.Add(3*time.Hour), *next)
+
+	// Cron-расписание: первое число каждого месяца
+	monthly := &models.ScheduledTransfer{Kind: models.ScheduleKindCron, CronExpr: "0 9 1 * *"}
+	next = nextRunAfter(monthly, scheduled.Add(time.Second))
+	assert.Equal(t, time.Date(2024, time.April, 1, 9, 0, 0, 0, time.UTC), *next)
+
+	// Разовая операция не повторяется
+	once := &models.ScheduledTransfer{Kind: models.ScheduleKindOnce, NextRunAt: &scheduled}
+	assert.Nil(t, nextRunAfter(once, scheduled))
+}
+
+func TestCreateScheduleInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	usd := &models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(usd, nil).AnyTimes()
+	mockRepo.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{ID: 2}, nil).AnyTimes()
+	mockRepo.EXPECT().GetUserByUsername(ctx, "alice").Return(&models.User{ID: 1}, nil).AnyTimes()
+	mockRepo.EXPECT().GetWalletByUserAndCurrency(uint64(1), "USD").Return(&models.Wallet{ID: 3, UserID: 1, Currency: "USD"}, nil).AnyTimes()
+
+	for _, tc := range []struct {
+		name    string
+		request *models.ScheduleRequest
+		err     error
+	}{
+		{"cron and interval", &models.ScheduleRequest{Type: models.ScheduleTypeTransfer, FromCurrency: "USD", ToUsername: "bob", Amount: 10, Cron: "0 9 * * *", IntervalSeconds: 3600}, ErrInvalidSchedule},
+		{"short interval", &models.ScheduleRequest{Type: models.ScheduleTypeTransfer, FromCurrency: "USD", ToUsername: "bob", Amount: 10, IntervalSeconds: 30}, ErrInvalidSchedule},
+		{"invalid cron", &models.ScheduleRequest{Type: models.ScheduleTypeTransfer, FromCurrency: "USD", ToUsername: "bob", Amount: 10, Cron: "61 * * * *"}, ErrInvalidSchedule},
+		{"self transfer", &models.ScheduleRequest{Type: models.ScheduleTypeTransfer, FromCurrency: "USD", ToUsername: "alice", Amount: 10}, ErrSelfTransfer},
+		{"same currency", &models.ScheduleRequest{Type: models.ScheduleTypeExchange, FromCurrency: "USD", ToCurrency: "USD", Amount: 10}, ErrSameCurrency},
+		{"unknown type", &models.ScheduleRequest{Type: "payout", FromCurrency: "USD", Amount: 10}, ErrInvalidSchedule},
+		{"zero amount", &models.ScheduleRequest{Type: models.ScheduleTypeTransfer, FromCurrency: "USD", ToUsername: "bob"}, ErrInvalidAmount},
+	} {
+		_, err := service.CreateSchedule(ctx, 1, tc.request)
+		assert.ErrorIs(t, err, tc.err, tc.name)
+	}
+}
+
+func TestCreateScheduleInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{ID: 2}, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrency(uint64(1), "USD").Return(&models.Wallet{ID: 3, UserID: 1, Currency: "USD"}, nil)
+	mockRepo.EXPECT().CreateScheduledTransfer(ctx, gomock.Any()).Return(uint64(7), nil)
+
+	runAt := time.Now().Add(time.Hour)
+	schedule, err := service.CreateSchedule(ctx, 1, &models.ScheduleRequest{
+		Type: models.ScheduleTypeTransfer, FromCurrency: "USD", ToUsername: "bob", Amount: 10, IntervalSeconds: 3600, RunAt: &runAt,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ScheduleKindInterval, schedule.Kind)
+	assert.Equal(t, uint64(2), schedule.ToUserID)
+	assert.Equal(t, runAt, *schedule.NextRunAt)
+}
+
+// expectScheduledTransfer настраивает мок на перевод 10 USD от пользователя 1 пользователю 2
+// с балансом отправителя balance.
+func expectScheduledTransfer(mockRepo *mocks.MockRepository, balance float64) {
+	fromWallet := &models.Wallet{ID: 3, UserID: 1, Balance: balance, Currency: "USD"}
+	toWallet := &models.Wallet{ID: 4, UserID: 2, Currency: "USD"}
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, TenantID: 1}, nil).AnyTimes()
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(2)).Return(&models.User{ID: 2, TenantID: 1}, nil).AnyTimes()
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrency(uint64(1), "USD").Return(fromWallet, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrency(uint64(2), "USD").Return(toWallet, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(3)).Return(fromWallet, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(4)).Return(toWallet, nil)
+	mockRepo.EXPECT().GetHeldAmount(gomock.Any(), uint64(3)).Return(0.0, nil)
+	mockRepo.EXPECT().GetPottedAmount(gomock.Any(), uint64(3)).Return(0.0, nil)
+	mockRepo.EXPECT().GetCreditLine(gomock.Any(), uint64(3)).Return(nil, nil)
+}
+
+func TestRunDueSchedulesTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	expectScheduledTransfer(mockRepo, 100)
+
+	scheduledFor := time.Now().Add(-time.Minute).Truncate(time.Second)
+	schedule := &models.ScheduledTransfer{
+		ID: 5, UserID: 1, ToUserID: 2, Type: models.ScheduleTypeTransfer, FromCurrency: "USD", ToCurrency: "USD",
+		Amount: 10, Kind: models.ScheduleKindInterval, IntervalSeconds: 3600, Status: models.ScheduleStatusActive, NextRunAt: &scheduledFor,
+	}
+	mockRepo.EXPECT().ClaimDueScheduledTransfers(ctx, gomock.Any(), scheduleBatchSize, scheduleLease).Return([]*models.ScheduledTransfer{schedule}, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(3), 90.0).Return(nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(4), 10.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockRepo.EXPECT().GetWalletsByUserID(uint64(1)).Return(nil, nil)
+
+	// Выполнение, история и следующий запуск сохраняются в той же транзакции
+	mockRepo.EXPECT().CreateScheduleExecution(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, execution *models.ScheduleExecution) (bool, error) {
+		assert.Equal(t, models.ExecutionStatusSucceeded, execution.Status)
+		assert.Equal(t, scheduledFor, execution.ScheduledFor)
+		return true, nil
+	})
+	mockRepo.EXPECT().CompleteScheduledTransferRun(ctx, schedule).Return(nil)
+
+	processed, err := service.RunDueSchedules(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, scheduledFor.Add(time.Hour), *schedule.NextRunAt)
+	assert.Equal(t, models.ScheduleStatusActive, schedule.Status)
+}
+
+func TestRunDueSchedulesFailureRecorded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectScheduledTransfer(mockRepo, 5)
+
+	scheduledFor := time.Now().Add(-time.Minute)
+	schedule := &models.ScheduledTransfer{
+		ID: 5, UserID: 1, ToUserID: 2, Type: models.ScheduleTypeTransfer, FromCurrency: "USD", ToCurrency: "USD",
+		Amount: 10, Kind: models.ScheduleKindOnce, Status: models.ScheduleStatusActive, NextRunAt: &scheduledFor,
+	}
+	mockRepo.EXPECT().ClaimDueScheduledTransfers(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return([]*models.ScheduledTransfer{schedule}, nil)
+	mockRepo.EXPECT().CreateScheduleExecution(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, execution *models.ScheduleExecution) (bool, error) {
+		assert.Equal(t, models.ExecutionStatusFailed, execution.Status)
+		assert.Contains(t, execution.Error, ErrInsufficientFunds.Error())
+		return true, nil
+	})
+	mockRepo.EXPECT().CompleteScheduledTransferRun(ctx, schedule).Return(nil)
+
+	processed, err := service.RunDueSchedules(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	// Разовая операция завершается и после неудачного запуска
+	assert.Equal(t, models.ScheduleStatusCompleted, schedule.Status)
+	assert.Nil(t, schedule.NextRunAt)
+}
+
+func TestRunDueSchedulesAlreadyExecuted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	expectScheduledTransfer(mockRepo, 100)
+
+	scheduledFor := time.Now().Add(-time.Minute)
+	schedule := &models.ScheduledTransfer{
+		ID: 5, UserID: 1, ToUserID: 2, Type: models.ScheduleTypeTransfer, FromCurrency: "USD", ToCurrency: "USD",
+		Amount: 10, Kind: models.ScheduleKindOnce, Status: models.ScheduleStatusActive, NextRunAt: &scheduledFor,
+	}
+	mockRepo.EXPECT().ClaimDueScheduledTransfers(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return([]*models.ScheduledTransfer{schedule}, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockRepo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockRepo.EXPECT().GetWalletsByUserID(uint64(1)).Return(nil, nil)
+	// Запуск уже выполнен другим экземпляром: транзакция с переводом откатывается
+	mockRepo.EXPECT().CreateScheduleExecution(ctx, gomock.Any()).Return(false, nil)
+
+	processed, err := service.RunDueSchedules(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, processed)
+}
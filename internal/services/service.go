@@ -2,9 +2,9 @@ package services
 
 import (
 	"context"
+	"io"
 	"time"
 
-	"github.com/VadimBorzenkov/gw-currency-wallet/internal/grpc"
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
 	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/utils"
@@ -14,38 +14,239 @@ import (
 // Service определяет методы бизнес-логики.
 type Service interface {
 	// User methods
-	RegisterUser(user *models.User) (int64, error)
-	GetUserByID(userID uint64) (*models.User, error)
-	AuthenticateUser(username, password string) (*models.User, error)
+	RegisterUser(ctx context.Context, user *models.User) (int64, error)
+	GetUserByID(ctx context.Context, userID uint64) (*models.User, error)
+	AuthenticateUser(ctx context.Context, username, password string) (*models.User, error)
+
+	// Tenant methods
+	ResolveTenant(ctx context.Context, slug, host string) (uint64, error)
 
 	// Wallet methods
-	CreateWallet(wallet *models.Wallet) (int, error)
-	GetBalance(userID uint64) (map[string]float64, error)
-	Deposit(userID uint64, amount float64, currency string) (map[string]float64, error)
-	Withdraw(userID uint64, amount float64, currency string) (map[string]float64, error)
+	CreateWallet(ctx context.Context, wallet *models.Wallet) (int, error)
+	GetBalance(userID uint64) (*models.Balances, error)
+	Deposit(ctx context.Context, userID uint64, amount float64, currency string) (map[string]float64, error)
+	Withdraw(ctx context.Context, userID uint64, amount float64, currency string) (map[string]float64, error)
 	UpdateUserBalance(userID uint64, fromCurrency, toCurrency string, amount, exchangedAmount float64) (map[string]float64, error)
 	GetAllBalances(userID uint64) (map[string]float64, error)
+	Exchange(ctx context.Context, userID uint64, fromCurrency, toCurrency string, amount float64) (float64, map[string]float64, error)
+	Transfer(ctx context.Context, fromUserID, toUserID uint64, currency string, amount float64) (map[string]float64, error)
+	GetValuation(ctx context.Context, userID uint64, base string) (*models.ValuationResponse, error)
+	PreviewRebalance(ctx context.Context, userID uint64, request *models.RebalanceRequest) (*models.RebalancePlan, error)
+	ConfirmRebalance(ctx context.Context, userID, planID uint64) (*models.RebalancePlan, error)
+
+	// Provider deposit methods
+	GetDepositReference(ctx context.Context, userID uint64) (string, error)
+	HandleProviderDeposit(ctx context.Context, providerName string, payload []byte, signature string) (*models.ProviderDeposit, bool, error)
+	GetProviderDeposits(ctx context.Context, status string) ([]*models.ProviderDeposit, error)
+
+	// Voucher methods
+	CreateVoucherBatch(ctx context.Context, operatorID uint64, request *models.VoucherBatchRequest) (*models.VoucherBatch, error)
+	GetVoucherBatches(ctx context.Context) ([]*models.VoucherBatch, error)
+	GetVoucherBatch(ctx context.Context, batchID uint64) (*models.VoucherBatch, error)
+	RedeemVoucher(ctx context.Context, userID uint64, code string) (*models.VoucherRedemption, error)
+
+	// Withdrawal methods
+	RequestWithdrawal(ctx context.Context, userID uint64, request *models.WithdrawalRequest) (*models.Withdrawal, error)
+	GetWithdrawals(ctx context.Context, userID uint64) ([]*models.Withdrawal, error)
+	GetWithdrawal(ctx context.Context, userID, withdrawalID uint64) (*models.Withdrawal, []*models.WithdrawalEvent, error)
+	CancelWithdrawal(ctx context.Context, userID, withdrawalID uint64) (*models.Withdrawal, error)
+	GetWithdrawalsByStatus(ctx context.Context, status string) ([]*models.Withdrawal, error)
+	ApproveWithdrawal(ctx context.Context, operatorID, withdrawalID uint64) (*models.Withdrawal, error)
+	RejectWithdrawal(ctx context.Context, operatorID, withdrawalID uint64, reason string) (*models.Withdrawal, error)
+	ProcessWithdrawals(ctx context.Context) (int, error)
+
+	// Currency methods
+	GetCurrencies(ctx context.Context, includeDisabled bool) ([]*models.Currency, error)
+	CreateCurrency(ctx context.Context, request *models.CurrencyRequest) (*models.Currency, error)
+	UpdateCurrency(ctx context.Context, code string, request *models.CurrencyRequest) (*models.Currency, error)
+	GetEnabledRates(ctx context.Context) (map[string]float64, error)
+
+	// Statement methods
+	PrepareStatement(ctx context.Context, userID uint64, currency string, from, to time.Time) (*models.Statement, error)
+	WriteStatement(ctx context.Context, statement *models.Statement, format string, w io.Writer) error
+
+	PlaceLimitOrder(ctx context.Context, userID uint64, request *models.LimitOrderRequest) (*models.LimitOrder, error)
+	GetLimitOrders(ctx context.Context, userID uint64) ([]*models.LimitOrder, error)
+	CancelLimitOrder(ctx context.Context, userID, orderID uint64) (*models.LimitOrder, error)
+	ExpireLimitOrders(ctx context.Context) (int64, error)
+	MatchLimitOrders(ctx context.Context) (int, error)
+
+	RunReconciliation(ctx context.Context) (*models.ReconciliationRun, error)
+	GetReconciliationRuns(ctx context.Context) ([]*models.ReconciliationRun, error)
+	GetReconciliationReport(ctx context.Context, runID uint64, onlyMismatches bool) (*models.ReconciliationRun, []*models.BalanceSnapshot, error)
+
+	CreateJournalCheckpoint(ctx context.Context) (*models.JournalCheckpoint, error)
+	VerifyJournal(ctx context.Context) (*models.JournalVerification, error)
+
+	ReverseTransaction(ctx context.Context, operatorID, transactionID uint64, reason string) (*models.Reversal, error)
+
+	SetUserFreeze(ctx context.Context, operatorID, userID uint64, state, reason string) (*models.FreezeEvent, error)
+	SetWalletFreeze(ctx context.Context, operatorID, walletID uint64, state, reason string) (*models.FreezeEvent, error)
+	GetFreezeEvents(ctx context.Context, targetType string, targetID uint64) ([]*models.FreezeEvent, error)
+
+	// Shared wallet methods
+	GetAccessibleWallets(ctx context.Context, userID uint64) ([]*models.WalletAccess, error)
+	GetSharedWallet(ctx context.Context, userID, walletID uint64) (*models.WalletAccess, error)
+	DepositToWallet(ctx context.Context, userID, walletID uint64, amount float64) (*models.Wallet, error)
+	WithdrawFromWallet(ctx context.Context, userID, walletID uint64, amount float64) (*models.Wallet, error)
+	ExchangeFromWallet(ctx context.Context, userID, walletID uint64, toCurrency string, amount float64) (float64, map[string]float64, error)
+	TransferFromWallet(ctx context.Context, userID, walletID, toUserID uint64, amount float64) (*models.Wallet, error)
+	AuthorizeWalletHold(ctx context.Context, userID, walletID uint64, amount float64, reference string, ttl time.Duration) (*models.Hold, error)
+	CreateWalletPot(ctx context.Context, userID, walletID uint64, request *models.PotRequest) (*models.Pot, error)
+	GetWalletPots(ctx context.Context, userID, walletID uint64) ([]*models.Pot, error)
+	PrepareWalletStatement(ctx context.Context, userID, walletID uint64, from, to time.Time) (*models.Statement, error)
+	InviteWalletMember(ctx context.Context, userID, walletID uint64, request *models.WalletMemberRequest) (*models.WalletMember, error)
+	GetWalletMembers(ctx context.Context, userID, walletID uint64) ([]*models.WalletMember, error)
+	GetWalletInvitations(ctx context.Context, userID uint64) ([]*models.WalletMember, error)
+	AcceptWalletInvitation(ctx context.Context, userID, walletID uint64) (*models.WalletMember, error)
+	DeclineWalletInvitation(ctx context.Context, userID, walletID uint64) error
+	RemoveWalletMember(ctx context.Context, userID, walletID, memberID uint64) error
+
+	// Payment request methods
+	CreatePaymentRequest(ctx context.Context, requesterID uint64, request *models.CreatePaymentRequest) (*models.PaymentRequest, error)
+	GetPaymentRequests(ctx context.Context, userID uint64, direction string) ([]*models.PaymentRequest, error)
+	PayPaymentRequest(ctx context.Context, payerID, requestID uint64, payCurrency string) (*models.PaymentRequest, error)
+	DeclinePaymentRequest(ctx context.Context, payerID, requestID uint64) (*models.PaymentRequest, error)
+	CancelPaymentRequest(ctx context.Context, requesterID, requestID uint64) (*models.PaymentRequest, error)
+	ExpirePaymentRequests(ctx context.Context) (int64, error)
+
+	// Pot methods
+	CreatePot(ctx context.Context, userID uint64, request *models.PotRequest) (*models.Pot, error)
+	GetPots(ctx context.Context, userID uint64) ([]*models.Pot, error)
+	MoveToPot(ctx context.Context, userID, potID uint64, amount float64) (*models.Pot, error)
+	MoveFromPot(ctx context.Context, userID, potID uint64, amount float64) (*models.Pot, error)
+	ClosePot(ctx context.Context, userID, potID uint64) (*models.Pot, error)
+	GetPotMovements(ctx context.Context, userID, potID uint64) ([]*models.PotMovement, error)
+
+	// Credit line methods
+	GetCreditLine(ctx context.Context, walletID uint64) (*models.CreditLine, error)
+	SetCreditLine(ctx context.Context, walletID uint64, request *models.CreditLineRequest) (*models.CreditLine, error)
+	DeleteCreditLine(ctx context.Context, walletID uint64) error
+	AccrueOverdraftCharges(ctx context.Context) (int, error)
+
+	// Interest methods
+	GetAccruedInterest(ctx context.Context, userID uint64) ([]*models.InterestSummary, error)
+	AccrueInterest(ctx context.Context) (int, error)
+
+	// Hold methods
+	AuthorizeHold(ctx context.Context, userID uint64, amount float64, currency, reference string, ttl time.Duration) (*models.Hold, error)
+	CaptureHold(ctx context.Context, userID, holdID uint64, amount float64) (*models.Hold, error)
+	ReleaseHold(ctx context.Context, userID, holdID uint64) (*models.Hold, error)
+	GetHolds(ctx context.Context, userID uint64) ([]*models.Hold, error)
+	ExpireHolds(ctx context.Context) (int64, error)
+
+	// Scheduled transfer methods
+	CreateSchedule(ctx context.Context, userID uint64, request *models.ScheduleRequest) (*models.ScheduledTransfer, error)
+	GetSchedules(ctx context.Context, userID uint64) ([]*models.ScheduledTransfer, error)
+	GetScheduleExecutions(ctx context.Context, userID, scheduleID uint64) ([]*models.ScheduleExecution, error)
+	PauseSchedule(ctx context.Context, userID, scheduleID uint64) (*models.ScheduledTransfer, error)
+	ResumeSchedule(ctx context.Context, userID, scheduleID uint64) (*models.ScheduledTransfer, error)
+	CancelSchedule(ctx context.Context, userID, scheduleID uint64) (*models.ScheduledTransfer, error)
+	RunDueSchedules(ctx context.Context) (int, error)
+
+	// Outbox methods
+	RelayOutbox(ctx context.Context) (int, error)
+
+	// Webhook methods
+	CreateWebhookSubscription(ctx context.Context, userID uint64, request *models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	GetWebhookSubscriptions(ctx context.Context, userID uint64) ([]*models.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, userID, subscriptionID uint64) error
+	GetWebhookDeliveries(ctx context.Context, userID, subscriptionID uint64) ([]*models.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, userID, subscriptionID, deliveryID uint64) (*models.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, userID, subscriptionID, deliveryID uint64) (*models.WebhookDelivery, error)
+	DeliverWebhooks(ctx context.Context) (int, error)
+
+	// Notification methods
+	GetNotifications(ctx context.Context, userID uint64, unreadOnly bool) ([]*models.Notification, int, error)
+	MarkNotificationRead(ctx context.Context, userID, notificationID uint64) error
+	MarkAllNotificationsRead(ctx context.Context, userID uint64) (int, error)
+	GetNotificationPreferences(ctx context.Context, userID uint64) ([]*models.NotificationPreference, error)
+	UpdateNotificationPreferences(ctx context.Context, userID uint64, request *models.NotificationPreferencesRequest) ([]*models.NotificationPreference, error)
+	DeliverNotificationEmails(ctx context.Context) (int, error)
+
+	// Stream methods
+	SubscribeStream(ctx context.Context, userID, lastEventID uint64, pairs []string) (*StreamSubscription, error)
+	PollStreamEvents(ctx context.Context) (int, error)
+	PollStreamRates(ctx context.Context) (int, error)
 
 	// gw-exchanger methods
 	GetAllRates() (map[string]float64, error)
 	GetRate(fromCurrency, toCurrency string) (float64, error)
 	ExchangeCurrency(fromCurrency, toCurrency string, amount float64) (float64, error)
 
-	NewJWT(userId uint64, email, ipAddress, tokenID string) (string, error)
+	NewJWT(userId, tenantID uint64, email, ipAddress, tokenID string) (string, error)
 	AccessTTL() time.Duration
 	CreateRefreshTokenModel(ctx context.Context, refreshToken *models.RefreshToken) error
 	GetRefreshTokenModelByID(ctx context.Context, userID uint64, deviceId string) (*models.RefreshToken, error)
 	DeleteRefreshTokenModel(ctx context.Context, refreshToken *models.RefreshToken) error
 }
 
+// CurrencyClient определяет методы сервиса курсов валют, которые использует бизнес-логика.
+// Реализуется gRPC-клиентом grpc.CurrencyClient.
+type CurrencyClient interface {
+	GetExchangeRate(fromCurrency, toCurrency string) (float64, error)
+	GetAllRates() (map[string]float64, error)
+	ConvertCurrency(fromCurrency, toCurrency string, amount float64) (float64, error)
+}
+
+// DepositProvider определяет платёжного провайдера, присылающего уведомления о входящих пополнениях.
+// Реализуется пакетом providers.
+type DepositProvider interface {
+	Name() string
+	VerifySignature(payload []byte, signature string) bool
+	ParseDeposit(payload []byte) (*models.DepositEvent, error)
+}
+
+// PayoutProvider определяет провайдера выплат для вывода средств. Send должен быть идемпотентен
+// по ID вывода: повторная отправка того же вывода возвращает ту же выплату, а не создаёт новую.
+// Реализуется пакетом providers.
+type PayoutProvider interface {
+	Name() string
+	Send(ctx context.Context, withdrawal *models.Withdrawal) (string, error)
+	GetStatus(ctx context.Context, reference string) (string, error)
+}
+
+// EventPublisher определяет брокер, в который публикуются доменные события из outbox.
+// Publish возвращает nil только после того, как брокер принял событие; одно и то же событие
+// может быть опубликовано повторно, получатели отличают повторы по ID и Sequence.
+// Реализуется пакетом publishers.
+type EventPublisher interface {
+	Name() string
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+}
+
+// Mailer определяет способ отправки писем с уведомлениями пользователям.
+// Реализуется пакетом mailers.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
 type service struct {
 	repo           repository.Repository
-	currencyClient *grpc.CurrencyClient
+	currencyClient CurrencyClient
+	providers      map[string]DepositProvider
+	payout         PayoutProvider
+	publisher      EventPublisher
+	mailer         Mailer
+	stream         *streamHub
 	tokenManger    utils.Manager
 	logger         *logrus.Logger
 }
 
-// Новый сервис с зависимостью от клиента валют
-func NewService(repo repository.Repository, currencyClient *grpc.CurrencyClient, tokenManger utils.Manager, logger *logrus.Logger) Service {
-	return &service{repo: repo, currencyClient: currencyClient, tokenManger: tokenManger, logger: logger}
+// Новый сервис с зависимостью от клиента валют, платёжных провайдеров, провайдера выплат
+// брокера событий и почты
+func NewService(repo repository.Repository, currencyClient CurrencyClient, providers []DepositProvider, payout PayoutProvider, publisher EventPublisher, mailer Mailer, tokenManger utils.Manager, logger *logrus.Logger) Service {
+	registry := make(map[string]DepositProvider, len(providers))
+	for _, provider := range providers {
+		registry[provider.Name()] = provider
+	}
+	return &service{repo: repo, currencyClient: currencyClient, providers: registry, payout: payout, publisher: publisher, mailer: mailer, stream: newStreamHub(), tokenManger: tokenManger, logger: logger}
+}
+
+// withRepo возвращает копию сервиса, работающую через repo. Позволяет выполнить операцию сервиса
+// внутри уже открытой транзакции: WithinTransaction транзакционного репозитория переиспользует её.
+func (s *service) withRepo(repo repository.Repository) *service {
+	copied := *s
+	copied.repo = repo
+	return &copied
 }
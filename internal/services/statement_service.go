@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/statement"
+)
+
+// MaxStatementPeriod - максимальный период одной выписки.
+const MaxStatementPeriod = 366 * 24 * time.Hour
+
+// PrepareStatement проверяет параметры выписки за период [from, to) и вычисляет
+// входящий и исходящий остатки. Сами проводки выгружаются позже методом WriteStatement.
+func (s *service) PrepareStatement(ctx context.Context, userID uint64, currency string, from, to time.Time) (*models.Statement, error) {
+	if !from.Before(to) || to.Sub(from) > MaxStatementPeriod {
+		return nil, ErrInvalidPeriod
+	}
+
+	wallet, err := s.findWallet(s.repo, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+	return s.prepareStatement(ctx, wallet, from, to)
+}
+
+// PrepareWalletStatement готовит выписку по кошельку с ID walletID. Доступно любому участнику кошелька.
+func (s *service) PrepareWalletStatement(ctx context.Context, userID, walletID uint64, from, to time.Time) (*models.Statement, error) {
+	if !from.Before(to) || to.Sub(from) > MaxStatementPeriod {
+		return nil, ErrInvalidPeriod
+	}
+
+	wallet, _, err := s.getAuthorizedWallet(ctx, userID, walletID, models.WalletRoleOwner, models.WalletRoleSpender, models.WalletRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.prepareStatement(ctx, wallet, from, to)
+}
+
+// prepareStatement вычисляет входящий и исходящий остатки кошелька за период [from, to).
+func (s *service) prepareStatement(ctx context.Context, wallet *models.Wallet, from, to time.Time) (*models.Statement, error) {
+	opening, err := s.repo.GetBalanceAt(ctx, wallet.ID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening balance: %v", err)
+	}
+	closing, err := s.repo.GetBalanceAt(ctx, wallet.ID, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get closing balance: %v", err)
+	}
+
+	return &models.Statement{
+		WalletID:       wallet.ID,
+		Currency:       wallet.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening,
+		ClosingBalance: closing,
+		GeneratedAt:    time.Now(),
+	}, nil
+}
+
+// WriteStatement выгружает выписку в w в указанном формате, передавая проводки потоком.
+func (s *service) WriteStatement(ctx context.Context, st *models.Statement, format string, w io.Writer) error {
+	f, err := statement.Lookup(format)
+	if err != nil {
+		return err
+	}
+
+	writer := f.New(w)
+	if err := writer.Begin(st); err != nil {
+		return err
+	}
+	if err := s.repo.StreamTransactions(ctx, st.WalletID, st.From, st.To, writer.Entry); err != nil {
+		return err
+	}
+	return writer.End()
+}
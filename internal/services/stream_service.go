@@ -0,0 +1,410 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+const (
+	// MaxStreamPairs - максимальное количество пар валют в одной подписке на поток обновлений.
+	MaxStreamPairs = 20
+
+	// streamBufferSize - количество событий, которые поток накапливает для медленного клиента.
+	// При переполнении поток закрывается, и клиент возобновляет его с последнего ID события.
+	streamBufferSize = 256
+	// streamReplayLimit - максимальное количество пропущенных событий, передаваемых при
+	// возобновлении потока; если их больше, клиент получает событие resync.
+	streamReplayLimit = 500
+	// streamBatchSize - количество событий outbox, читаемых за один опрос.
+	streamBatchSize = 500
+	// streamGapTimeout - сколько ожидаются события с пропущенными ID: транзакция с меньшим ID
+	// может зафиксироваться позже транзакции с большим, а отменённая не фиксируется никогда.
+	streamGapTimeout = time.Minute
+	// streamMaxGap - максимальное количество пропущенных ID, отслеживаемых между двумя событиями.
+	streamMaxGap = 1000
+)
+
+// streamHub рассылает события потока обновлений подписчикам этого экземпляра сервиса.
+// События о балансах каждый экземпляр читает из outbox сам, поэтому подписчик получает
+// изменения, сделанные любым экземпляром.
+type streamHub struct {
+	mu          sync.Mutex
+	subscribers map[*StreamSubscription]struct{}
+
+	// Состояние опроса; используется только фоновыми задачами PollStreamEvents и PollStreamRates.
+	started bool
+	cursor  uint64
+	gaps    map[uint64]time.Time
+	rates   map[string]float64
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{
+		subscribers: make(map[*StreamSubscription]struct{}),
+		gaps:        make(map[uint64]time.Time),
+	}
+}
+
+// StreamSubscription - подписка клиента на поток обновлений: изменения балансов доступных
+// пользователю кошельков и курсы выбранных пар валют.
+type StreamSubscription struct {
+	hub      *streamHub
+	userID   uint64
+	pairs    map[string]bool
+	events   chan *models.StreamEvent
+	pending  []*models.StreamEvent
+	replayed map[uint64]bool
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Next возвращает следующее событие потока, ожидая его до отмены ctx. Возвращает
+// ErrStreamClosed, если поток закрыт, например из-за того, что клиент не успевал читать события.
+func (sub *StreamSubscription) Next(ctx context.Context) (*models.StreamEvent, error) {
+	if len(sub.pending) > 0 {
+		event := sub.pending[0]
+		sub.pending = sub.pending[1:]
+		return event, nil
+	}
+
+	for {
+		select {
+		case event := <-sub.events:
+			// Событие уже передано при возобновлении потока
+			if event.ID != 0 && sub.replayed[event.ID] {
+				continue
+			}
+			return event, nil
+		case <-sub.done:
+			return nil, ErrStreamClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close отписывает клиента от потока.
+func (sub *StreamSubscription) Close() {
+	sub.hub.mu.Lock()
+	delete(sub.hub.subscribers, sub)
+	sub.hub.mu.Unlock()
+	sub.close()
+}
+
+func (sub *StreamSubscription) close() {
+	sub.once.Do(func() { close(sub.done) })
+}
+
+// subscribe регистрирует подписчика userID на изменения балансов и курсы пар pairs.
+func (h *streamHub) subscribe(userID uint64, pairs []string) *StreamSubscription {
+	sub := &StreamSubscription{
+		hub:      h,
+		userID:   userID,
+		pairs:    make(map[string]bool, len(pairs)),
+		events:   make(chan *models.StreamEvent, streamBufferSize),
+		replayed: make(map[uint64]bool),
+		done:     make(chan struct{}),
+	}
+	for _, pair := range pairs {
+		sub.pairs[pair] = true
+	}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// broadcast передаёт событие подписчикам, для которых match возвращает true. Подписчик,
+// буфер которого переполнен, отключается.
+func (h *streamHub) broadcast(event *models.StreamEvent, match func(sub *StreamSubscription) bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers {
+		if !match(sub) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(h.subscribers, sub)
+			sub.close()
+		}
+	}
+}
+
+// subscribedPairs возвращает пары валют, на которые подписан хотя бы один клиент.
+func (h *streamHub) subscribedPairs() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var pairs []string
+	for sub := range h.subscribers {
+		for pair := range sub.pairs {
+			if !seen[pair] {
+				seen[pair] = true
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// SubscribeStream подписывает пользователя на поток обновлений. Если задан lastEventID,
+// сначала передаются пропущенные события о балансах с большими ID, затем текущие курсы
+// пар pairs вида "USD/EUR" и далее новые события.
+func (s *service) SubscribeStream(ctx context.Context, userID, lastEventID uint64, pairs []string) (*StreamSubscription, error) {
+	pairs, err := s.streamPairs(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Подписчик регистрируется до чтения пропущенных событий, чтобы не потерять события,
+	// записанные между чтением и подпиской; повторы отбрасывает Next.
+	sub := s.stream.subscribe(userID, pairs)
+
+	if lastEventID > 0 {
+		if err := s.replayStream(ctx, sub, lastEventID); err != nil {
+			sub.Close()
+			return nil, err
+		}
+	}
+
+	if len(pairs) > 0 {
+		rates, err := s.GetAllRates()
+		if err != nil {
+			// Курсы придут со следующим опросом
+			s.logger.Warnf("Failed to fetch rates for stream of user %d: %v", userID, err)
+		}
+		now := time.Now().UTC()
+		for _, pair := range pairs {
+			if rate, ok := pairRate(rates, pair); ok {
+				sub.pending = append(sub.pending, rateEvent(pair, rate, now))
+			}
+		}
+	}
+
+	return sub, nil
+}
+
+// replayStream добавляет в подписку события о балансах пользователя с ID больше lastEventID.
+func (s *service) replayStream(ctx context.Context, sub *StreamSubscription, lastEventID uint64) error {
+	events, err := s.repo.GetUserStreamEventsAfter(ctx, sub.userID, lastEventID, streamReplayLimit+1)
+	if err != nil {
+		return err
+	}
+
+	if len(events) > streamReplayLimit {
+		lastID, err := s.repo.GetLastOutboxEventID(ctx)
+		if err != nil {
+			return err
+		}
+		sub.pending = append(sub.pending, &models.StreamEvent{ID: lastID, Type: models.StreamEventResync})
+		return nil
+	}
+
+	for _, event := range events {
+		update, err := balanceEvent(event)
+		if err != nil {
+			s.logger.Errorf("Failed to replay stream event %d: %v", event.ID, err)
+			continue
+		}
+		sub.pending = append(sub.pending, update)
+		sub.replayed[event.ID] = true
+	}
+	return nil
+}
+
+// PollStreamEvents читает новые события outbox и рассылает подписчикам изменения балансов
+// их кошельков. Возвращает количество прочитанных событий.
+func (s *service) PollStreamEvents(ctx context.Context) (int, error) {
+	hub := s.stream
+	if !hub.started {
+		// Поток передаёт только события, записанные после запуска; более ранние клиент
+		// получает при возобновлении по lastEventID.
+		cursor, err := s.repo.GetLastOutboxEventID(ctx)
+		if err != nil {
+			return 0, err
+		}
+		hub.cursor, hub.started = cursor, true
+		return 0, nil
+	}
+
+	events, err := s.repo.GetStreamEventsAfter(ctx, hub.cursor, streamBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		for id := hub.cursor + 1; id < event.ID && id <= hub.cursor+streamMaxGap; id++ {
+			hub.gaps[id] = now
+		}
+		hub.cursor = event.ID
+	}
+
+	if len(hub.gaps) > 0 {
+		ids := make([]uint64, 0, len(hub.gaps))
+		for id, seenAt := range hub.gaps {
+			if now.Sub(seenAt) > streamGapTimeout {
+				delete(hub.gaps, id)
+				continue
+			}
+			ids = append(ids, id)
+		}
+		if len(ids) > 0 {
+			late, err := s.repo.GetStreamEventsByIDs(ctx, ids)
+			if err != nil {
+				return 0, err
+			}
+			for _, event := range late {
+				delete(hub.gaps, event.ID)
+			}
+			events = append(events, late...)
+		}
+	}
+
+	for _, event := range events {
+		update, err := balanceEvent(event)
+		if err != nil {
+			s.logger.Errorf("Failed to stream event %d: %v", event.ID, err)
+			continue
+		}
+		recipients := make(map[uint64]bool, len(event.UserIDs))
+		for _, userID := range event.UserIDs {
+			recipients[userID] = true
+		}
+		hub.broadcast(update, func(sub *StreamSubscription) bool {
+			return recipients[sub.userID]
+		})
+	}
+
+	return len(events), nil
+}
+
+// PollStreamRates запрашивает курсы, если на них подписан хотя бы один клиент, и рассылает
+// изменившиеся курсы пар. Возвращает количество изменившихся пар.
+func (s *service) PollStreamRates(ctx context.Context) (int, error) {
+	pairs := s.stream.subscribedPairs()
+	if len(pairs) == 0 {
+		return 0, nil
+	}
+
+	rates, err := s.GetAllRates()
+	if err != nil {
+		return 0, err
+	}
+	previous := s.stream.rates
+	s.stream.rates = rates
+
+	now := time.Now().UTC()
+	changed := 0
+	for _, pair := range pairs {
+		rate, ok := pairRate(rates, pair)
+		if !ok {
+			continue
+		}
+		if old, ok := pairRate(previous, pair); ok && old == rate {
+			continue
+		}
+
+		changed++
+		s.stream.broadcast(rateEvent(pair, rate, now), func(sub *StreamSubscription) bool {
+			return sub.pairs[pair]
+		})
+	}
+
+	return changed, nil
+}
+
+// streamPairs приводит пары валют к виду "USD/EUR" и проверяет, что обе валюты включены.
+func (s *service) streamPairs(ctx context.Context, pairs []string) ([]string, error) {
+	seen := make(map[string]bool)
+	checked := make(map[string]bool)
+	var normalized []string
+	for _, pair := range pairs {
+		pair = strings.ToUpper(strings.TrimSpace(pair))
+		if pair == "" || seen[pair] {
+			continue
+		}
+
+		from, to, ok := strings.Cut(pair, "/")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("%w: pair %q must look like USD/EUR", ErrInvalidStream, pair)
+		}
+		if from == to {
+			return nil, ErrSameCurrency
+		}
+		for _, code := range []string{from, to} {
+			if checked[code] {
+				continue
+			}
+			if _, err := s.requireCurrency(ctx, code); err != nil {
+				return nil, err
+			}
+			checked[code] = true
+		}
+
+		seen[pair] = true
+		normalized = append(normalized, pair)
+	}
+
+	if len(normalized) > MaxStreamPairs {
+		return nil, fmt.Errorf("%w: at most %d pairs", ErrInvalidStream, MaxStreamPairs)
+	}
+	return normalized, nil
+}
+
+// pairRate вычисляет курс пары "FROM/TO" по курсам GetAllRates, выраженным в общей опорной валюте.
+func pairRate(rates map[string]float64, pair string) (float64, bool) {
+	from, to, _ := strings.Cut(pair, "/")
+	fromRate, ok := rates[from]
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := rates[to]
+	if !ok || toRate == 0 {
+		return 0, false
+	}
+	return fromRate / toRate, true
+}
+
+func rateEvent(pair string, rate float64, at time.Time) *models.StreamEvent {
+	return &models.StreamEvent{
+		Type: models.StreamEventRate,
+		Data: &models.RateUpdate{Pair: pair, Rate: rate, At: at},
+	}
+}
+
+// balanceEvent преобразует событие outbox о проводке в событие потока об изменении баланса.
+func balanceEvent(event *models.OutboxEvent) (*models.StreamEvent, error) {
+	var data models.WalletEventData
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode wallet event: %v", err)
+	}
+
+	return &models.StreamEvent{
+		ID:   event.ID,
+		Type: models.StreamEventBalance,
+		Data: &models.BalanceUpdate{
+			WalletID:      event.WalletID,
+			Currency:      data.Currency,
+			Balance:       data.BalanceAfter,
+			Amount:        data.Amount,
+			EventType:     event.Type,
+			Sequence:      event.Sequence,
+			TransactionID: data.TransactionID,
+			CreatedAt:     event.CreatedAt,
+		},
+	}, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// streamOutboxEvent создаёт событие outbox о пополнении кошелька 7, доступного userIDs.
+func streamOutboxEvent(id uint64, balance float64, userIDs ...uint64) *models.OutboxEvent {
+	payload, _ := json.Marshal(models.WalletEventData{TransactionID: id * 10, Amount: 10, Currency: "USD", BalanceAfter: balance})
+	return &models.OutboxEvent{ID: id, WalletID: 7, Sequence: id, Type: "wallet.deposit", Payload: payload, UserIDs: userIDs}
+}
+
+// nextEvent возвращает следующее событие подписки или nil, если событий нет.
+func nextEvent(t *testing.T, sub *StreamSubscription) *models.StreamEvent {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	event, err := sub.Next(ctx)
+	if err == context.DeadlineExceeded {
+		return nil
+	}
+	assert.NoError(t, err)
+	return event
+}
+
+func TestSubscribeStreamReplaysMissedEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	client := &fakeCurrencyClient{rates: map[string]float64{"USD": 1, "EUR": 1.25}}
+	service := &service{repo: mockRepo, currencyClient: client, stream: newStreamHub(), logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", Enabled: true}, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "EUR").Return(&models.Currency{Code: "EUR", Enabled: true}, nil)
+	mockRepo.EXPECT().GetUserStreamEventsAfter(ctx, uint64(1), uint64(10), streamReplayLimit+1).Return([]*models.OutboxEvent{
+		streamOutboxEvent(11, 110, 1),
+	}, nil)
+
+	sub, err := service.SubscribeStream(ctx, 1, 10, []string{"usd/eur", "USD/EUR"})
+	assert.NoError(t, err)
+	defer sub.Close()
+
+	// Сначала пропущенные события, затем текущие курсы
+	event := nextEvent(t, sub)
+	assert.Equal(t, uint64(11), event.ID)
+	assert.Equal(t, models.StreamEventBalance, event.Type)
+	assert.Equal(t, 110.0, event.Data.(*models.BalanceUpdate).Balance)
+
+	event = nextEvent(t, sub)
+	assert.Equal(t, models.StreamEventRate, event.Type)
+	assert.Equal(t, "USD/EUR", event.Data.(*models.RateUpdate).Pair)
+	assert.Equal(t, 0.8, event.Data.(*models.RateUpdate).Rate)
+
+	// Событие 11 уже передано при возобновлении и не повторяется
+	service.stream.started, service.stream.cursor = true, 10
+	mockRepo.EXPECT().GetStreamEventsAfter(ctx, uint64(10), streamBatchSize).Return([]*models.OutboxEvent{
+		streamOutboxEvent(11, 110, 1),
+		streamOutboxEvent(12, 120, 1),
+	}, nil)
+	_, err = service.PollStreamEvents(ctx)
+	assert.NoError(t, err)
+
+	event = nextEvent(t, sub)
+	assert.Equal(t, uint64(12), event.ID)
+	assert.Nil(t, nextEvent(t, sub))
+}
+
+func TestSubscribeStreamResyncWhenTooManyMissed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, stream: newStreamHub(), logger: logrus.New()}
+	ctx := context.Background()
+
+	missed := make([]*models.OutboxEvent, streamReplayLimit+1)
+	for i := range missed {
+		missed[i] = streamOutboxEvent(uint64(i+2), 0, 1)
+	}
+	mockRepo.EXPECT().GetUserStreamEventsAfter(ctx, uint64(1), uint64(1), streamReplayLimit+1).Return(missed, nil)
+	mockRepo.EXPECT().GetLastOutboxEventID(ctx).Return(uint64(900), nil)
+
+	sub, err := service.SubscribeStream(ctx, 1, 1, nil)
+	assert.NoError(t, err)
+	defer sub.Close()
+
+	event := nextEvent(t, sub)
+	assert.Equal(t, models.StreamEventResync, event.Type)
+	assert.Equal(t, uint64(900), event.ID)
+	assert.Nil(t, nextEvent(t, sub))
+}
+
+func TestSubscribeStreamInvalidPairs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, stream: newStreamHub(), logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", Enabled: true}, nil).AnyTimes()
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "XXX").Return(nil, nil).AnyTimes()
+
+	_, err := service.SubscribeStream(ctx, 1, 0, []string{"USDEUR"})
+	assert.ErrorIs(t, err, ErrInvalidStream)
+	_, err = service.SubscribeStream(ctx, 1, 0, []string{"USD/USD"})
+	assert.ErrorIs(t, err, ErrSameCurrency)
+	_, err = service.SubscribeStream(ctx, 1, 0, []string{"USD/XXX"})
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+	assert.Empty(t, service.stream.subscribers)
+}
+
+func TestPollStreamEventsRoutesByUserAndFillsGaps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, stream: newStreamHub(), logger: logrus.New()}
+	ctx := context.Background()
+
+	owner := service.stream.subscribe(1, nil)
+	member := service.stream.subscribe(2, nil)
+
+	// Первый опрос только запоминает последнее событие
+	mockRepo.EXPECT().GetLastOutboxEventID(ctx).Return(uint64(5), nil)
+	_, err := service.PollStreamEvents(ctx)
+	assert.NoError(t, err)
+
+	// Событие 7 ещё не зафиксировано
+	mockRepo.EXPECT().GetStreamEventsAfter(ctx, uint64(5), streamBatchSize).Return([]*models.OutboxEvent{
+		streamOutboxEvent(6, 60, 1),
+		streamOutboxEvent(8, 80, 1, 2),
+	}, nil)
+	mockRepo.EXPECT().GetStreamEventsByIDs(ctx, []uint64{7}).Return(nil, nil)
+	read, err := service.PollStreamEvents(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, read)
+
+	assert.Equal(t, uint64(6), nextEvent(t, owner).ID)
+	assert.Equal(t, uint64(8), nextEvent(t, owner).ID)
+	assert.Equal(t, uint64(8), nextEvent(t, member).ID)
+	assert.Nil(t, nextEvent(t, member))
+
+	// Событие 7 зафиксировано позже события 8
+	mockRepo.EXPECT().GetStreamEventsAfter(ctx, uint64(8), streamBatchSize).Return(nil, nil)
+	mockRepo.EXPECT().GetStreamEventsByIDs(ctx, []uint64{7}).Return([]*models.OutboxEvent{streamOutboxEvent(7, 70, 2)}, nil)
+	_, err = service.PollStreamEvents(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint64(7), nextEvent(t, member).ID)
+	assert.Nil(t, nextEvent(t, owner))
+	assert.Empty(t, service.stream.gaps)
+}
+
+func TestPollStreamRatesSendsChangedPairs(t *testing.T) {
+	client := &fakeCurrencyClient{rates: map[string]float64{"USD": 1, "EUR": 1.25, "RUB": 0.01}}
+	service := &service{currencyClient: client, stream: newStreamHub(), logger: logrus.New()}
+	ctx := context.Background()
+
+	// Без подписчиков курсы не запрашиваются
+	changed, err := service.PollStreamRates(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, changed)
+
+	sub := service.stream.subscribe(1, []string{"USD/EUR", "USD/RUB"})
+	defer sub.Close()
+
+	changed, err = service.PollStreamRates(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, changed)
+	assert.NotNil(t, nextEvent(t, sub))
+	assert.NotNil(t, nextEvent(t, sub))
+
+	client.rates = map[string]float64{"USD": 1, "EUR": 1.25, "RUB": 0.0125}
+	changed, err = service.PollStreamRates(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	event := nextEvent(t, sub)
+	assert.Equal(t, "USD/RUB", event.Data.(*models.RateUpdate).Pair)
+	assert.Equal(t, 80.0, event.Data.(*models.RateUpdate).Rate)
+	assert.Nil(t, nextEvent(t, sub))
+}
+
+func TestStreamSlowSubscriberClosed(t *testing.T) {
+	hub := newStreamHub()
+	sub := hub.subscribe(1, nil)
+
+	for i := 0; i <= streamBufferSize; i++ {
+		hub.broadcast(&models.StreamEvent{ID: uint64(i + 1), Type: models.StreamEventBalance}, func(*StreamSubscription) bool { return true })
+	}
+	assert.Empty(t, hub.subscribers)
+
+	var err error
+	for i := 0; i <= streamBufferSize && err == nil; i++ {
+		_, err = sub.Next(context.Background())
+	}
+	assert.ErrorIs(t, err, ErrStreamClosed)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+// ResolveTenant определяет тенант запроса: по slug, если он указан явно, иначе по хосту.
+// Запросы на хост без тенанта относятся к тенанту по умолчанию.
+func (s *service) ResolveTenant(ctx context.Context, slug, host string) (uint64, error) {
+	if slug != "" {
+		found, err := s.repo.GetTenantBySlug(ctx, slug)
+		if err != nil {
+			return 0, err
+		}
+		if found == nil {
+			return 0, ErrTenantNotFound
+		}
+		return found.ID, nil
+	}
+
+	if host != "" {
+		found, err := s.repo.GetTenantByHost(ctx, host)
+		if err != nil {
+			return 0, err
+		}
+		if found != nil {
+			return found.ID, nil
+		}
+	}
+	return tenant.DefaultID, nil
+}
+
+// sameTenant сообщает, доступна ли запись тенанта tenantID в контексте ctx.
+// В системном контексте доступны записи всех тенантов.
+func sameTenant(ctx context.Context, tenantID uint64) bool {
+	id, ok := tenant.FromContext(ctx)
+	return !ok || id == tenantID
+}
+
+// userInTenant проверяет, что пользователь существует и доступен в тенанте контекста.
+func userInTenant(ctx context.Context, repo repository.Repository, userID uint64) (bool, error) {
+	if _, ok := tenant.FromContext(ctx); !ok {
+		return true, nil
+	}
+	user, err := repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return sameTenant(ctx, user.TenantID), nil
+}
+
+// walletInTenant проверяет, что кошелёк существует и доступен в тенанте контекста.
+func walletInTenant(ctx context.Context, repo repository.Repository, walletID uint64) (bool, error) {
+	if _, ok := tenant.FromContext(ctx); !ok {
+		return true, nil
+	}
+	wallet, err := repo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return sameTenant(ctx, wallet.TenantID), nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	// Заголовок важнее хоста
+	mockRepo.EXPECT().GetTenantBySlug(ctx, "acme").Return(&models.Tenant{ID: 2, Slug: "acme"}, nil)
+	id, err := service.ResolveTenant(ctx, "acme", "wallet.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), id)
+
+	mockRepo.EXPECT().GetTenantBySlug(ctx, "unknown").Return(nil, nil)
+	_, err = service.ResolveTenant(ctx, "unknown", "")
+	assert.ErrorIs(t, err, ErrTenantNotFound)
+
+	mockRepo.EXPECT().GetTenantByHost(ctx, "pay.acme.test").Return(&models.Tenant{ID: 2}, nil)
+	id, err = service.ResolveTenant(ctx, "", "pay.acme.test")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), id)
+
+	// Хост без тенанта относится к тенанту по умолчанию
+	mockRepo.EXPECT().GetTenantByHost(ctx, "localhost").Return(nil, nil)
+	id, err = service.ResolveTenant(ctx, "", "localhost")
+	assert.NoError(t, err)
+	assert.Equal(t, tenant.DefaultID, id)
+}
+
+func TestValidateAmountTenantLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := tenant.WithID(context.Background(), 2)
+
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").
+		Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true, MaxAmount: 1000}, nil).Times(2)
+
+	assert.NoError(t, service.validateAmount(ctx, "USD", 1000))
+	assert.ErrorIs(t, service.validateAmount(ctx, "USD", 1000.01), ErrInvalidAmount)
+}
+
+func TestSetUserFreezeOtherTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := tenant.WithID(context.Background(), 2)
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(5)).Return(&models.User{ID: 5, TenantID: 1, FreezeState: models.FreezeStateNone}, nil)
+
+	// Администратор тенанта не видит пользователей других тенантов
+	_, err := service.SetUserFreeze(ctx, 9, 5, models.FreezeStateFull, "fraud")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestGetCreditLineOtherTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := tenant.WithID(context.Background(), 2)
+
+	mockRepo.EXPECT().GetWalletByID(gomock.Any(), uint64(7)).Return(&models.Wallet{ID: 7, TenantID: 1}, nil)
+
+	_, err := service.GetCreditLine(ctx, 7)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
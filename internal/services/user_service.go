@@ -7,19 +7,34 @@ import (
 	"time"
 
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
 )
 
-func (s *service) NewJWT(userId uint64, email, ipAddress, tokenID string) (string, error) {
-	return s.tokenManger.NewJWT(userId, email, ipAddress, tokenID)
+func (s *service) NewJWT(userId, tenantID uint64, email, ipAddress, tokenID string) (string, error) {
+	return s.tokenManger.NewJWT(userId, tenantID, email, ipAddress, tokenID)
 }
 
 func (s *service) AccessTTL() time.Duration {
 	return s.tokenManger.GetAccessTTL()
 }
 
+// CreateRefreshTokenModel сохраняет refresh-токен входа. Вход с устройства, для которого
+// у пользователя ещё нет токена, считается новой сессией, и пользователь получает уведомление.
 func (s *service) CreateRefreshTokenModel(ctx context.Context, refreshToken *models.RefreshToken) error {
-	return s.repo.SetRefreshTokenModel(ctx, refreshToken)
+	existing, err := s.repo.GetRefreshTokenModelByID(ctx, refreshToken.UserID, refreshToken.DeviceID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetRefreshTokenModel(ctx, refreshToken); err != nil {
+		return err
+	}
 
+	if existing == nil {
+		if err := s.notifyLogin(ctx, refreshToken.UserID, refreshToken.TenantID, models.NotificationNewSession); err != nil {
+			s.logger.Errorf("Failed to notify user %d about new session: %v", refreshToken.UserID, err)
+		}
+	}
+	return nil
 }
 
 func (s *service) GetRefreshTokenModelByID(ctx context.Context, userID uint64, deviceId string) (*models.RefreshToken, error) {
@@ -30,10 +45,10 @@ func (s *service) DeleteRefreshTokenModel(ctx context.Context, refreshToken *mod
 	return s.repo.DeleteRefreshTokenModel(ctx, refreshToken)
 }
 
-// RegisterUser регистрирует нового пользователя.
-func (s *service) RegisterUser(user *models.User) (int64, error) {
+// RegisterUser регистрирует нового пользователя в тенанте контекста.
+func (s *service) RegisterUser(ctx context.Context, user *models.User) (int64, error) {
 	// Проверка на существование пользователя с таким же именем.
-	existingUser, err := s.repo.GetUserByUsername(user.Username)
+	existingUser, err := s.repo.GetUserByUsername(ctx, user.Username)
 	if err == nil && existingUser != nil {
 		return 0, errors.New("username already exists")
 	}
@@ -46,19 +61,24 @@ func (s *service) RegisterUser(user *models.User) (int64, error) {
 	}
 	user.Password = hashedPassword
 
+	// Роль не принимается от клиента: администраторы назначаются отдельно.
+	user.Role = models.RoleUser
+	// Тенант определяется запросом, а не телом.
+	user.TenantID = tenant.ID(ctx)
+
 	// Создание пользователя в базе данных.
 	return s.repo.CreateUser(user)
 }
 
-// GetUserByID возвращает пользователя по его ID.
-func (s *service) GetUserByID(userID uint64) (*models.User, error) {
-	return s.repo.GetUserByID(userID)
+// GetUserByID возвращает пользователя по его ID среди пользователей тенанта контекста.
+func (s *service) GetUserByID(ctx context.Context, userID uint64) (*models.User, error) {
+	return s.repo.GetUserByID(ctx, userID)
 }
 
-// AuthenticateUser аутентифицирует пользователя.
-func (s *service) AuthenticateUser(username, password string) (*models.User, error) {
+// AuthenticateUser аутентифицирует пользователя тенанта контекста.
+func (s *service) AuthenticateUser(ctx context.Context, username, password string) (*models.User, error) {
 	// Получение пользователя из базы данных.
-	user, err := s.repo.GetUserByUsername(username)
+	user, err := s.repo.GetUserByUsername(ctx, username)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
@@ -66,8 +86,16 @@ func (s *service) AuthenticateUser(username, password string) (*models.User, err
 	// Валидация пароля.
 	err = s.tokenManger.ValidatePassword(password, user.Password)
 	if err != nil {
+		if err := s.notifyLogin(ctx, user.ID, user.TenantID, models.NotificationFailedLogin); err != nil {
+			s.logger.Errorf("Failed to notify user %d about failed login: %v", user.ID, err)
+		}
 		return nil, errors.New("invalid password")
 	}
 
+	// Полностью замороженный пользователь не может войти; при заморозке списаний вход разрешён.
+	if user.FreezeState == models.FreezeStateFull {
+		return nil, ErrAccountFrozen
+	}
+
 	return user, nil
 }
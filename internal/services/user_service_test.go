@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -23,7 +24,7 @@ func TestRegisterUser(t *testing.T) {
 
 	manager := utils.NewManager(cfg)
 
-	service := NewService(mockRepo, nil, manager, logger)
+	service := NewService(mockRepo, nil, nil, nil, nil, nil, manager, logger)
 
 	user := &models.User{
 		Username: "test_user",
@@ -33,7 +34,7 @@ func TestRegisterUser(t *testing.T) {
 
 	mockRepo.EXPECT().CreateUser(user).Return(int64(1), nil)
 
-	userID, err := service.RegisterUser(user)
+	userID, err := service.RegisterUser(context.Background(), user)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), userID)
 }
@@ -49,29 +50,43 @@ func TestAuthenticateUser(t *testing.T) {
 	cfg, _ := config.LoadConfig()
 	logger := logrus.New()
 	manager := utils.NewManager(cfg)
-	service := NewService(mockRepo, nil, manager, logger)
+	service := NewService(mockRepo, nil, nil, nil, nil, nil, manager, logger)
 
 	// Тестовые данные
+	username := "testuser"
+	password := "correctpassword"
+	hashedPassword, err := manager.HashPassword(password)
+	assert.NoError(t, err)
 	validUser := &models.User{
 		ID:       1,
-		Username: "testuser",
-		Password: "hashedpassword",
+		Username: username,
+		Password: hashedPassword,
 	}
-	username := "testuser"
-	password := "correctpassword"
 
 	// Успешный сценарий
-	mockRepo.EXPECT().GetUserByUsername(username).Return(validUser, nil)
+	mockRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(validUser, nil)
 
-	user, err := service.AuthenticateUser(username, password)
+	user, err := service.AuthenticateUser(context.Background(), username, password)
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
 	assert.Equal(t, validUser.Username, user.Username)
 
+	// Сценарий: неверный пароль - пользователь уведомляется о неудачном входе
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(validUser, nil)
+	mockRepo.EXPECT().LockUserNotifications(gomock.Any(), uint64(1), models.NotificationFailedLogin).Return(nil)
+	mockRepo.EXPECT().GetLatestNotification(gomock.Any(), uint64(1), models.NotificationFailedLogin).Return(nil, nil)
+	mockRepo.EXPECT().GetNotificationPreference(gomock.Any(), uint64(1), models.NotificationFailedLogin).Return(nil, nil)
+	mockRepo.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(nil)
+
+	user, err = service.AuthenticateUser(context.Background(), username, "wrongpassword")
+	assert.Nil(t, user)
+	assert.EqualError(t, err, "invalid password")
+
 	// Сценарий: пользователь не найден
-	mockRepo.EXPECT().GetUserByUsername("nonexistent").Return(nil, errors.New("user not found"))
+	mockRepo.EXPECT().GetUserByUsername(gomock.Any(), "nonexistent").Return(nil, errors.New("user not found"))
 
-	user, err = service.AuthenticateUser("nonexistent", password)
+	user, err = service.AuthenticateUser(context.Background(), "nonexistent", password)
 	assert.Error(t, err)
 	assert.Nil(t, user)
 	assert.EqualError(t, err, "user not found")
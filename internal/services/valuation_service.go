@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// DefaultValuationBase - базовая валюта оценки по умолчанию.
+const DefaultValuationBase = "USD"
+
+// GetValuation оценивает все кошельки пользователя в базовой валюте base
+// по единому снимку курсов GetAllRates.
+func (s *service) GetValuation(ctx context.Context, userID uint64, base string) (*models.ValuationResponse, error) {
+	if base == "" {
+		base = DefaultValuationBase
+	}
+	if _, err := s.requireCurrency(ctx, base); err != nil {
+		return nil, err
+	}
+
+	wallets, err := s.repo.GetWalletsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve wallets: %v", err)
+	}
+
+	rates, err := s.GetAllRates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates: %v", err)
+	}
+	ratesAt := time.Now().UTC()
+
+	baseRate, ok := rates[base]
+	if !ok || baseRate <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRateUnavailable, base)
+	}
+
+	valuation := &models.ValuationResponse{
+		Base:    base,
+		Wallets: make([]*models.WalletValuation, 0, len(wallets)),
+		RatesAt: ratesAt,
+	}
+	for _, wallet := range wallets {
+		rate, ok := rates[wallet.Currency]
+		if !ok || rate <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrRateUnavailable, wallet.Currency)
+		}
+
+		// Курсы GetAllRates выражают стоимость единицы валюты в общей опорной валюте,
+		// поэтому курс к базовой валюте - отношение двух курсов.
+		crossRate := rate / baseRate
+		value := roundAmount(wallet.Balance * crossRate)
+		valuation.Wallets = append(valuation.Wallets, &models.WalletValuation{
+			Currency: wallet.Currency,
+			Balance:  wallet.Balance,
+			Rate:     crossRate,
+			Value:    value,
+		})
+		valuation.Total += value
+	}
+	valuation.Total = roundAmount(valuation.Total)
+
+	sort.Slice(valuation.Wallets, func(i, j int) bool {
+		return valuation.Wallets[i].Currency < valuation.Wallets[j].Currency
+	})
+
+	return valuation, nil
+}
+
+// roundAmount округляет сумму до копеек, как она хранится в БД (NUMERIC(18, 2)).
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCurrencyClient возвращает фиксированные курсы относительно общей опорной валюты.
+type fakeCurrencyClient struct {
+	rates map[string]float64
+}
+
+func (f *fakeCurrencyClient) GetExchangeRate(fromCurrency, toCurrency string) (float64, error) {
+	return f.rates[fromCurrency] / f.rates[toCurrency], nil
+}
+
+func (f *fakeCurrencyClient) GetAllRates() (map[string]float64, error) {
+	return f.rates, nil
+}
+
+func (f *fakeCurrencyClient) ConvertCurrency(fromCurrency, toCurrency string, amount float64) (float64, error) {
+	return amount * f.rates[fromCurrency] / f.rates[toCurrency], nil
+}
+
+func TestGetValuation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	client := &fakeCurrencyClient{rates: map[string]float64{"USD": 1, "EUR": 1.1, "RUB": 0.01}}
+	service := &service{repo: mockRepo, currencyClient: client, logger: logrus.New()}
+
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "EUR").Return(&models.Currency{Code: "EUR", Enabled: true}, nil)
+	mockRepo.EXPECT().GetWalletsByUserID(uint64(1)).Return([]*models.Wallet{
+		{Currency: "USD", Balance: 100},
+		{Currency: "EUR", Balance: 50},
+		{Currency: "RUB", Balance: 1000},
+	}, nil)
+
+	valuation, err := service.GetValuation(context.Background(), 1, "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", valuation.Base)
+	assert.Len(t, valuation.Wallets, 3)
+	assert.Equal(t, "EUR", valuation.Wallets[0].Currency)
+	assert.Equal(t, 50.0, valuation.Wallets[0].Value)
+	assert.Equal(t, 9.09, valuation.Wallets[1].Value)
+	assert.Equal(t, 90.91, valuation.Wallets[2].Value)
+	assert.Equal(t, 150.0, valuation.Total)
+
+	// Валюта отсутствует в справочнике
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "GBP").Return(nil, nil)
+	_, err = service.GetValuation(context.Background(), 1, "GBP")
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	// Валюта есть в справочнике, но курс для неё не публикуется
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "CHF").Return(&models.Currency{Code: "CHF", Enabled: true}, nil)
+	mockRepo.EXPECT().GetWalletsByUserID(uint64(1)).Return(nil, nil)
+	_, err = service.GetValuation(context.Background(), 1, "CHF")
+	assert.ErrorIs(t, err, ErrRateUnavailable)
+}
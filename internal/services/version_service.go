@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+)
+
+// Оптимистичная проверка конкурентных изменений: версия кошелька увеличивается при каждом
+// изменении его строки, клиент передаёт ETag прочитанного ресурса в If-Match, и операция
+// отклоняется с ErrPreconditionFailed, если ресурс успел измениться.
+
+type ifMatchKey struct{}
+
+// WithIfMatch возвращает контекст с условием If-Match для изменяющей операции.
+// Пустое значение означает, что условие не задано.
+func WithIfMatch(ctx context.Context, ifMatch string) context.Context {
+	if strings.TrimSpace(ifMatch) == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, ifMatchKey{}, ifMatch)
+}
+
+// WalletETag возвращает ETag кошелька с версией version.
+func WalletETag(version uint64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// BalanceETag возвращает ETag баланса пользователя, построенный по версиям всех его кошельков.
+func BalanceETag(wallets []*models.Wallet) string {
+	sorted := make([]*models.Wallet, len(wallets))
+	copy(sorted, wallets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	hash := sha256.New()
+	for _, wallet := range sorted {
+		fmt.Fprintf(hash, "%d:%d;", wallet.ID, wallet.Version)
+	}
+	return fmt.Sprintf(`"%x"`, hash.Sum(nil)[:8])
+}
+
+// checkWalletVersion проверяет условие If-Match контекста по версии заблокированного кошелька.
+func checkWalletVersion(ctx context.Context, wallet *models.Wallet) error {
+	ifMatch, ok := ctx.Value(ifMatchKey{}).(string)
+	if !ok || matchesIfMatch(ifMatch, WalletETag(wallet.Version)) {
+		return nil
+	}
+	return ErrPreconditionFailed
+}
+
+// checkBalanceVersion проверяет условие If-Match контекста по ETag баланса пользователя.
+// Вызывается после блокировки изменяемых кошельков, чтобы их версии не изменились
+// до конца транзакции.
+func checkBalanceVersion(ctx context.Context, repo repository.Repository, userID uint64) error {
+	ifMatch, ok := ctx.Value(ifMatchKey{}).(string)
+	if !ok {
+		return nil
+	}
+
+	wallets, err := repo.GetWalletsByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve wallets: %v", err)
+	}
+	if !matchesIfMatch(ifMatch, BalanceETag(wallets)) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// matchesIfMatch сравнивает etag со списком из заголовка If-Match: "*" совпадает с любым
+// ресурсом, слабые ETag (W/"...") при строгом сравнении не совпадают никогда.
+func matchesIfMatch(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceETag(t *testing.T) {
+	usd := &models.Wallet{ID: 1, Currency: "USD", Version: 3}
+	eur := &models.Wallet{ID: 2, Currency: "EUR", Version: 5}
+
+	// ETag не зависит от порядка кошельков и меняется вместе с версией любого из них
+	etag := BalanceETag([]*models.Wallet{usd, eur})
+	assert.Equal(t, etag, BalanceETag([]*models.Wallet{eur, usd}))
+	eur.Version++
+	assert.NotEqual(t, etag, BalanceETag([]*models.Wallet{usd, eur}))
+}
+
+func TestMatchesIfMatch(t *testing.T) {
+	assert.True(t, matchesIfMatch(`"4"`, WalletETag(4)))
+	assert.True(t, matchesIfMatch(`"3", "4"`, WalletETag(4)))
+	assert.True(t, matchesIfMatch("*", WalletETag(4)))
+	assert.False(t, matchesIfMatch(`"3"`, WalletETag(4)))
+	assert.False(t, matchesIfMatch(`W/"4"`, WalletETag(4)))
+}
+
+func TestDepositBalanceVersionMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	wallets := []*models.Wallet{{ID: 11, UserID: 1, Balance: 20, Currency: "USD", Version: 2}}
+	ctx := WithIfMatch(context.Background(), BalanceETag([]*models.Wallet{{ID: 11, Version: 1}}))
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrencyForUpdate(ctx, uint64(1), "USD").Return(wallets[0], nil)
+	mockRepo.EXPECT().GetWalletsByUserID(uint64(1)).Return(wallets, nil)
+
+	_, err := service.Deposit(ctx, 1, 10, "USD")
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+}
+
+func TestDepositToWalletVersionMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := WithIfMatch(context.Background(), WalletETag(4))
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD", Version: 5}, nil)
+
+	_, err := service.DepositToWallet(ctx, 1, 7, 10)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+}
+
+func TestDepositToWalletIncrementsVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := WithIfMatch(context.Background(), WalletETag(4))
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD", Version: 4}, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(7), 510.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil)
+
+	wallet, err := service.DepositToWallet(ctx, 1, 7, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), wallet.Version)
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxVoucherBatchSize - максимальное количество кодов в одной партии.
+	MaxVoucherBatchSize = 1000
+	// VoucherBatchesLimit - количество партий, возвращаемых администратору.
+	VoucherBatchesLimit = 100
+	// maxVoucherNameLength совпадает с размером колонки voucher_batches.name.
+	maxVoucherNameLength = 100
+	// voucherCodeLength - длина сгенерированного кода ваучера.
+	voucherCodeLength = 12
+	// voucherCodeAlphabet не содержит похожих символов (0/O, 1/I/L), чтобы код было проще ввести вручную.
+	voucherCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+)
+
+// voucherCodePattern - допустимый формат кода после нормализации.
+var voucherCodePattern = regexp.MustCompile(`^[A-Z0-9]{4,32}$`)
+
+// CreateVoucherBatch выпускает партию ваучеров: один промокод с заданным кодом
+// или request.Count случайных кодов.
+func (s *service) CreateVoucherBatch(ctx context.Context, operatorID uint64, request *models.VoucherBatchRequest) (*models.VoucherBatch, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxVoucherNameLength {
+		return nil, fmt.Errorf("%w: name is required and must be at most %d characters", ErrInvalidVoucherBatch, maxVoucherNameLength)
+	}
+	if (request.Code == "" && request.Count < 1) || request.Count > MaxVoucherBatchSize {
+		return nil, fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidVoucherBatch, MaxVoucherBatchSize)
+	}
+	if request.MaxRedemptions < 0 {
+		return nil, fmt.Errorf("%w: max_redemptions must be positive", ErrInvalidVoucherBatch)
+	}
+	if !request.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidVoucherBatch)
+	}
+	if err := s.validateAmount(ctx, request.Currency, request.Amount); err != nil {
+		return nil, err
+	}
+
+	batch := &models.VoucherBatch{
+		Name:           name,
+		Amount:         request.Amount,
+		Currency:       request.Currency,
+		MaxRedemptions: request.MaxRedemptions,
+		ExpiresAt:      request.ExpiresAt.UTC(),
+		CreatedBy:      operatorID,
+		TenantID:       tenant.ID(ctx),
+	}
+	if batch.MaxRedemptions == 0 {
+		batch.MaxRedemptions = 1
+	}
+
+	if request.Code != "" {
+		code := normalizeVoucherCode(request.Code)
+		if !voucherCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("%w: code must be 4 to 32 letters or digits", ErrInvalidVoucherBatch)
+		}
+		if request.Count > 1 {
+			return nil, fmt.Errorf("%w: count must be 1 for a custom code", ErrInvalidVoucherBatch)
+		}
+		batch.Vouchers = []*models.Voucher{{Code: code}}
+	} else {
+		for i := 0; i < request.Count; i++ {
+			code, err := generateVoucherCode()
+			if err != nil {
+				return nil, err
+			}
+			batch.Vouchers = append(batch.Vouchers, &models.Voucher{Code: code})
+		}
+	}
+
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		created, err := repo.CreateVoucherBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrVoucherCodeExists
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Voucher batch %d with %d codes created by %d", batch.ID, batch.Codes, operatorID)
+	return batch, nil
+}
+
+// GetVoucherBatches возвращает последние партии ваучеров с числом погашений.
+func (s *service) GetVoucherBatches(ctx context.Context) ([]*models.VoucherBatch, error) {
+	return s.repo.GetVoucherBatches(ctx, VoucherBatchesLimit)
+}
+
+// GetVoucherBatch возвращает партию ваучеров с кодами и числом погашений каждого.
+func (s *service) GetVoucherBatch(ctx context.Context, batchID uint64) (*models.VoucherBatch, error) {
+	batch, err := s.repo.GetVoucherBatchByID(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, ErrVoucherNotFound
+	}
+
+	batch.Vouchers, err = s.repo.GetVouchersByBatchID(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// RedeemVoucher погашает ваучер: в одной транзакции проверяет срок и лимит погашений,
+// фиксирует погашение и зачисляет сумму на кошелёк пользователя в валюте партии.
+func (s *service) RedeemVoucher(ctx context.Context, userID uint64, code string) (*models.VoucherRedemption, error) {
+	code = normalizeVoucherCode(code)
+	if !voucherCodePattern.MatchString(code) {
+		return nil, ErrVoucherNotFound
+	}
+
+	var redemption *models.VoucherRedemption
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		// Блокировка кода упорядочивает параллельные погашения и защищает лимит
+		voucher, err := repo.GetVoucherByCodeForUpdate(ctx, code)
+		if err != nil {
+			return err
+		}
+		if voucher == nil {
+			return ErrVoucherNotFound
+		}
+
+		batch, err := repo.GetVoucherBatchByID(ctx, voucher.BatchID)
+		if err != nil {
+			return err
+		}
+		if batch == nil {
+			return ErrVoucherNotFound
+		}
+		if !time.Now().Before(batch.ExpiresAt) {
+			return ErrVoucherExpired
+		}
+		if voucher.Redemptions >= batch.MaxRedemptions {
+			return ErrVoucherExhausted
+		}
+
+		wallet, err := s.lockWallet(ctx, repo, userID, batch.Currency)
+		if err != nil {
+			return err
+		}
+		if err := s.ensureCreditAllowed(ctx, repo, wallet); err != nil {
+			return err
+		}
+
+		redemption = &models.VoucherRedemption{
+			VoucherID:   voucher.ID,
+			UserID:      userID,
+			WalletID:    wallet.ID,
+			Amount:      batch.Amount,
+			Currency:    batch.Currency,
+			OperationID: uuid.NewString(),
+		}
+		created, err := repo.CreateVoucherRedemption(ctx, redemption)
+		if err != nil {
+			return err
+		}
+		if !created {
+			return ErrVoucherAlreadyRedeemed
+		}
+
+		description := fmt.Sprintf("Voucher %s (%s)", code, batch.Name)
+		_, err = s.postEntry(ctx, repo, wallet, batch.Amount, models.TransactionTypeVoucher, redemption.OperationID, description)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return redemption, nil
+}
+
+// normalizeVoucherCode приводит код к верхнему регистру и убирает пробелы и дефисы,
+// чтобы код можно было ввести в любом написании.
+func normalizeVoucherCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// generateVoucherCode генерирует случайный код из voucherCodeAlphabet.
+func generateVoucherCode() (string, error) {
+	limit := big.NewInt(int64(len(voucherCodeAlphabet)))
+	code := make([]byte, voucherCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate voucher code: %v", err)
+		}
+		code[i] = voucherCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedeemVoucher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	batch := &models.VoucherBatch{ID: 3, Name: "Welcome", Amount: 10, Currency: "USD", MaxRedemptions: 100, ExpiresAt: time.Now().Add(time.Hour)}
+
+	// Код вводится в любом регистре и с дефисами
+	mockRepo.EXPECT().GetVoucherByCodeForUpdate(ctx, "WELCOME2026").Return(&models.Voucher{ID: 7, BatchID: 3, Code: "WELCOME2026", Redemptions: 5}, nil)
+	mockRepo.EXPECT().GetVoucherBatchByID(ctx, uint64(3)).Return(batch, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrencyForUpdate(ctx, uint64(1), "USD").Return(&models.Wallet{ID: 11, UserID: 1, Balance: 20, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().CreateVoucherRedemption(ctx, gomock.Any()).Return(true, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(11), 30.0).Return(nil)
+	var entry *models.Transaction
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).
+		Do(func(_ context.Context, transaction *models.Transaction) {
+			entry = transaction
+		}).Return(nil)
+
+	redemption, err := service.RedeemVoucher(ctx, 1, "welcome-2026")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(11), redemption.WalletID)
+	assert.Equal(t, 10.0, redemption.Amount)
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, models.TransactionTypeVoucher, entry.Type)
+		assert.Equal(t, redemption.OperationID, entry.OperationID)
+	}
+}
+
+func TestRedeemVoucherExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	batch := &models.VoucherBatch{ID: 3, Amount: 10, Currency: "USD", MaxRedemptions: 1, ExpiresAt: time.Now().Add(time.Hour)}
+
+	mockRepo.EXPECT().GetVoucherByCodeForUpdate(ctx, "ABCDEFGH2345").Return(&models.Voucher{ID: 7, BatchID: 3, Redemptions: 1}, nil)
+	mockRepo.EXPECT().GetVoucherBatchByID(ctx, uint64(3)).Return(batch, nil)
+
+	_, err := service.RedeemVoucher(ctx, 1, "ABCDEFGH2345")
+	assert.ErrorIs(t, err, ErrVoucherExhausted)
+}
+
+func TestRedeemVoucherExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	batch := &models.VoucherBatch{ID: 3, Amount: 10, Currency: "USD", MaxRedemptions: 5, ExpiresAt: time.Now().Add(-time.Minute)}
+
+	mockRepo.EXPECT().GetVoucherByCodeForUpdate(ctx, "ABCDEFGH2345").Return(&models.Voucher{ID: 7, BatchID: 3}, nil)
+	mockRepo.EXPECT().GetVoucherBatchByID(ctx, uint64(3)).Return(batch, nil)
+
+	_, err := service.RedeemVoucher(ctx, 1, "ABCDEFGH2345")
+	assert.ErrorIs(t, err, ErrVoucherExpired)
+}
+
+func TestRedeemVoucherTwice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	batch := &models.VoucherBatch{ID: 3, Amount: 10, Currency: "USD", MaxRedemptions: 5, ExpiresAt: time.Now().Add(time.Hour)}
+
+	mockRepo.EXPECT().GetVoucherByCodeForUpdate(ctx, "PROMO").Return(&models.Voucher{ID: 7, BatchID: 3, Redemptions: 1}, nil)
+	mockRepo.EXPECT().GetVoucherBatchByID(ctx, uint64(3)).Return(batch, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrencyForUpdate(ctx, uint64(1), "USD").Return(&models.Wallet{ID: 11, UserID: 1, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().CreateVoucherRedemption(ctx, gomock.Any()).Return(false, nil)
+
+	_, err := service.RedeemVoucher(ctx, 1, "promo")
+	assert.ErrorIs(t, err, ErrVoucherAlreadyRedeemed)
+}
+
+func TestCreateVoucherBatchGeneratesCodes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "EUR").Return(&models.Currency{Code: "EUR", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().CreateVoucherBatch(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, batch *models.VoucherBatch) (bool, error) {
+		batch.Codes = len(batch.Vouchers)
+		return true, nil
+	})
+
+	batch, err := service.CreateVoucherBatch(ctx, 9, &models.VoucherBatchRequest{
+		Name:      "Autumn campaign",
+		Amount:    5,
+		Currency:  "EUR",
+		Count:     20,
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, batch.MaxRedemptions)
+	assert.Equal(t, uint64(9), batch.CreatedBy)
+	codes := make(map[string]bool)
+	for _, voucher := range batch.Vouchers {
+		assert.Regexp(t, voucherCodePattern, voucher.Code)
+		codes[voucher.Code] = true
+	}
+	assert.Len(t, codes, 20)
+}
+
+func TestCreateVoucherBatchCustomCodeWithCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+
+	_, err := service.CreateVoucherBatch(ctx, 9, &models.VoucherBatchRequest{
+		Name:      "Promo",
+		Amount:    5,
+		Currency:  "USD",
+		Count:     3,
+		Code:      "PROMO",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.ErrorIs(t, err, ErrInvalidVoucherBatch)
+}
+
+func TestCreateVoucherBatchWithoutCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := &service{repo: mocks.NewMockRepository(ctrl), logger: logrus.New()}
+
+	_, err := service.CreateVoucherBatch(context.Background(), 9, &models.VoucherBatchRequest{
+		Name:      "Autumn campaign",
+		Amount:    5,
+		Currency:  "EUR",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.ErrorIs(t, err, ErrInvalidVoucherBatch)
+}
+
+func TestCreateVoucherBatchCodeExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	// Код уже занят у тенанта: вставка не создаёт строку, транзакция откатывается
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().CreateVoucherBatch(ctx, gomock.Any()).Return(false, nil)
+
+	_, err := service.CreateVoucherBatch(ctx, 9, &models.VoucherBatchRequest{
+		Name:      "Promo",
+		Amount:    5,
+		Currency:  "USD",
+		Code:      "promo-2026",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.ErrorIs(t, err, ErrVoucherCodeExists)
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/journal"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+)
+
+// SpendLimitPeriod - скользящий период, за который суммируются списания участника spender
+// при проверке его лимита.
+const SpendLimitPeriod = 30 * 24 * time.Hour
+
+type actorKey struct{}
+
+// withActor возвращает контекст с пользователем, от имени которого выполняются проводки
+// по кошельку. postTransaction записывает его в InitiatedBy проводки.
+func withActor(ctx context.Context, userID uint64) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// actorFromContext возвращает пользователя, от имени которого выполняется операция, или 0.
+func actorFromContext(ctx context.Context) uint64 {
+	userID, _ := ctx.Value(actorKey{}).(uint64)
+	return userID
+}
+
+// GetAccessibleWallets возвращает собственные кошельки пользователя и совместные кошельки,
+// в которых он состоит, с его ролью в каждом.
+func (s *service) GetAccessibleWallets(ctx context.Context, userID uint64) ([]*models.WalletAccess, error) {
+	wallets, err := s.repo.GetWalletsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessible := make([]*models.WalletAccess, 0, len(wallets))
+	for _, wallet := range wallets {
+		accessible = append(accessible, walletAccess(wallet, ownerMembership(wallet)))
+	}
+
+	memberships, err := s.repo.GetWalletMembershipsByUserID(ctx, userID, models.WalletMemberStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range memberships {
+		wallet, err := s.repo.GetWalletByID(ctx, member.WalletID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get wallet %d: %v", member.WalletID, err)
+		}
+		accessible = append(accessible, walletAccess(wallet, member))
+	}
+
+	return accessible, nil
+}
+
+// GetSharedWallet возвращает кошелёк по ID, если пользователь имеет к нему доступ с любой ролью.
+func (s *service) GetSharedWallet(ctx context.Context, userID, walletID uint64) (*models.WalletAccess, error) {
+	wallet, member, err := s.getAuthorizedWallet(ctx, userID, walletID, models.WalletRoleOwner, models.WalletRoleSpender, models.WalletRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return walletAccess(wallet, member), nil
+}
+
+// DepositToWallet пополняет кошелёк по ID от имени участника с ролью owner или spender.
+func (s *service) DepositToWallet(ctx context.Context, userID, walletID uint64, amount float64) (*models.Wallet, error) {
+	roles := []string{models.WalletRoleOwner, models.WalletRoleSpender}
+	ctx = withActor(ctx, userID)
+	return s.operateWallet(ctx, userID, walletID, amount, roles, func(repo repository.Repository, wallet *models.Wallet, member *models.WalletMember) error {
+		return s.depositLocked(ctx, repo, wallet, amount, fmt.Sprintf("Deposit by user %d", userID))
+	})
+}
+
+// WithdrawFromWallet списывает средства с кошелька по ID от имени участника с ролью owner
+// или spender. Сумма списаний участника spender за SpendLimitPeriod ограничена его лимитом.
+func (s *service) WithdrawFromWallet(ctx context.Context, userID, walletID uint64, amount float64) (*models.Wallet, error) {
+	roles := []string{models.WalletRoleOwner, models.WalletRoleSpender}
+	ctx = withActor(ctx, userID)
+	return s.operateWallet(ctx, userID, walletID, amount, roles, func(repo repository.Repository, wallet *models.Wallet, member *models.WalletMember) error {
+		if err := ensureSpendLimit(ctx, repo, member, amount); err != nil {
+			return err
+		}
+		return s.withdrawLocked(ctx, repo, wallet, amount, fmt.Sprintf("Withdrawal by user %d", userID))
+	})
+}
+
+// ExchangeFromWallet обменивает amount с кошелька по ID на валюту toCurrency по текущему курсу,
+// зачисляя результат на кошелёк владельца в этой валюте. Участник должен иметь роль owner
+// или spender в обоих кошельках; списание участника spender учитывается в его лимите.
+func (s *service) ExchangeFromWallet(ctx context.Context, userID, walletID uint64, toCurrency string, amount float64) (float64, map[string]float64, error) {
+	fromWallet, _, err := s.getAuthorizedWallet(ctx, userID, walletID, models.WalletRoleOwner, models.WalletRoleSpender)
+	if err != nil {
+		return 0, nil, err
+	}
+	if fromWallet.Currency == toCurrency {
+		return 0, nil, ErrSameCurrency
+	}
+	if err := s.validateAmount(ctx, fromWallet.Currency, amount); err != nil {
+		return 0, nil, err
+	}
+	if _, err := s.requireCurrency(ctx, toCurrency); err != nil {
+		return 0, nil, err
+	}
+	toWallet, err := s.findWallet(s.repo, fromWallet.UserID, toCurrency)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	exchangedAmount, err := s.ExchangeCurrency(fromWallet.Currency, toCurrency, amount)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrConversionFailed, err)
+	}
+
+	ctx = withActor(ctx, userID)
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		fromWallet, toWallet, err = s.lockWalletsByID(ctx, repo, fromWallet.ID, toWallet.ID)
+		if err != nil {
+			return err
+		}
+		member, err := s.authorizeWallet(ctx, repo, userID, fromWallet, models.WalletRoleOwner, models.WalletRoleSpender)
+		if err != nil {
+			return err
+		}
+		if _, err := s.authorizeWallet(ctx, repo, userID, toWallet, models.WalletRoleOwner, models.WalletRoleSpender); err != nil {
+			return fmt.Errorf("%w: %s", err, toCurrency)
+		}
+		if err := checkWalletVersion(ctx, fromWallet); err != nil {
+			return err
+		}
+		if err := ensureSpendLimit(ctx, repo, member, amount); err != nil {
+			return err
+		}
+		return s.exchangeLocked(ctx, repo, fromWallet, toWallet, amount, exchangedAmount)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return exchangedAmount, map[string]float64{
+		fromWallet.Currency: fromWallet.Balance,
+		toWallet.Currency:   toWallet.Balance,
+	}, nil
+}
+
+// TransferFromWallet переводит amount с кошелька по ID на кошелёк пользователя toUserID
+// в той же валюте. Доступно владельцам и участникам с ролью spender в пределах их лимита.
+func (s *service) TransferFromWallet(ctx context.Context, userID, walletID, toUserID uint64, amount float64) (*models.Wallet, error) {
+	fromWallet, _, err := s.getAuthorizedWallet(ctx, userID, walletID, models.WalletRoleOwner, models.WalletRoleSpender)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateAmount(ctx, fromWallet.Currency, amount); err != nil {
+		return nil, err
+	}
+	if toUserID == fromWallet.UserID {
+		return nil, ErrSelfTransfer
+	}
+	toWallet, err := s.findWallet(s.repo, toUserID, fromWallet.Currency)
+	if err != nil {
+		if errors.Is(err, ErrWalletNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrRecipientWalletNotFound, fromWallet.Currency)
+		}
+		return nil, err
+	}
+	// Переводы между тенантами не поддерживаются
+	if toWallet.TenantID != fromWallet.TenantID {
+		return nil, fmt.Errorf("%w: %s", ErrRecipientWalletNotFound, fromWallet.Currency)
+	}
+
+	ctx = withActor(ctx, userID)
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		fromWallet, toWallet, err = s.lockWalletsByID(ctx, repo, fromWallet.ID, toWallet.ID)
+		if err != nil {
+			return err
+		}
+		member, err := s.authorizeWallet(ctx, repo, userID, fromWallet, models.WalletRoleOwner, models.WalletRoleSpender)
+		if err != nil {
+			return err
+		}
+		if err := checkWalletVersion(ctx, fromWallet); err != nil {
+			return err
+		}
+		if err := ensureSpendLimit(ctx, repo, member, amount); err != nil {
+			return err
+		}
+		return s.transferLocked(ctx, repo, fromWallet, toWallet, amount)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromWallet, nil
+}
+
+// InviteWalletMember приглашает пользователя в совместный кошелёк. Приглашать могут только владельцы.
+func (s *service) InviteWalletMember(ctx context.Context, userID, walletID uint64, request *models.WalletMemberRequest) (*models.WalletMember, error) {
+	switch request.Role {
+	case models.WalletRoleOwner, models.WalletRoleViewer:
+		request.SpendLimit = 0
+	case models.WalletRoleSpender:
+		if request.SpendLimit <= 0 {
+			return nil, ErrInvalidMember
+		}
+	default:
+		return nil, ErrInvalidMember
+	}
+
+	invitee, err := s.repo.GetUserByUsername(ctx, strings.TrimSpace(request.Username))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	member := &models.WalletMember{
+		WalletID:   walletID,
+		UserID:     invitee.ID,
+		Username:   invitee.Username,
+		Role:       request.Role,
+		SpendLimit: roundAmount(request.SpendLimit),
+		Status:     models.WalletMemberStatusInvited,
+		InvitedBy:  userID,
+	}
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, walletID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+		if _, err := s.authorizeWallet(ctx, repo, userID, wallet, models.WalletRoleOwner); err != nil {
+			return err
+		}
+		member.Currency = wallet.Currency
+
+		if invitee.ID == wallet.UserID {
+			return ErrMemberExists
+		}
+		existing, err := repo.GetWalletMember(ctx, walletID, invitee.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrMemberExists
+		}
+		return repo.CreateWalletMember(ctx, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("User %d invited user %d to wallet %d as %s", userID, invitee.ID, walletID, member.Role)
+	return member, nil
+}
+
+// GetWalletMembers возвращает участников и приглашения кошелька. Доступно любому участнику.
+func (s *service) GetWalletMembers(ctx context.Context, userID, walletID uint64) ([]*models.WalletMember, error) {
+	if _, err := s.GetSharedWallet(ctx, userID, walletID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetWalletMembers(ctx, walletID)
+}
+
+// GetWalletInvitations возвращает приглашения пользователя в совместные кошельки.
+func (s *service) GetWalletInvitations(ctx context.Context, userID uint64) ([]*models.WalletMember, error) {
+	return s.repo.GetWalletMembershipsByUserID(ctx, userID, models.WalletMemberStatusInvited)
+}
+
+// AcceptWalletInvitation принимает приглашение в совместный кошелёк.
+func (s *service) AcceptWalletInvitation(ctx context.Context, userID, walletID uint64) (*models.WalletMember, error) {
+	var member *models.WalletMember
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		member, err = repo.GetWalletMember(ctx, walletID, userID)
+		if err != nil {
+			return err
+		}
+		if member == nil || member.Status != models.WalletMemberStatusInvited {
+			return ErrInvitationNotFound
+		}
+		if err := repo.ActivateWalletMember(ctx, walletID, userID); err != nil {
+			return err
+		}
+		member.Status = models.WalletMemberStatusActive
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// DeclineWalletInvitation отклоняет приглашение в совместный кошелёк.
+func (s *service) DeclineWalletInvitation(ctx context.Context, userID, walletID uint64) error {
+	member, err := s.repo.GetWalletMember(ctx, walletID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Status != models.WalletMemberStatusInvited {
+		return ErrInvitationNotFound
+	}
+	return s.repo.DeleteWalletMember(ctx, walletID, userID)
+}
+
+// RemoveWalletMember исключает участника из кошелька или отзывает приглашение.
+// Владельцы могут исключить любого участника, остальные - только выйти сами.
+func (s *service) RemoveWalletMember(ctx context.Context, userID, walletID, memberID uint64) error {
+	return s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, walletID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+
+		if memberID != userID {
+			if _, err := s.authorizeWallet(ctx, repo, userID, wallet, models.WalletRoleOwner); err != nil {
+				return err
+			}
+		}
+
+		member, err := repo.GetWalletMember(ctx, walletID, memberID)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			return ErrMemberNotFound
+		}
+		return repo.DeleteWalletMember(ctx, walletID, memberID)
+	})
+}
+
+// operateWallet блокирует кошелёк по ID, проверяет роль пользователя и сумму операции
+// и выполняет fn в одной транзакции.
+func (s *service) operateWallet(ctx context.Context, userID, walletID uint64, amount float64, roles []string, fn func(repo repository.Repository, wallet *models.Wallet, member *models.WalletMember) error) (*models.Wallet, error) {
+	var wallet *models.Wallet
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		wallet, err = repo.GetWalletByIDForUpdate(ctx, walletID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+
+		member, err := s.authorizeWallet(ctx, repo, userID, wallet, roles...)
+		if err != nil {
+			return err
+		}
+		if err := checkWalletVersion(ctx, wallet); err != nil {
+			return err
+		}
+		if err := s.validateAmount(ctx, wallet.Currency, amount); err != nil {
+			return err
+		}
+		return fn(repo, wallet, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// getAuthorizedWallet получает кошелёк по ID без блокировки и проверяет роль пользователя в нём.
+func (s *service) getAuthorizedWallet(ctx context.Context, userID, walletID uint64, roles ...string) (*models.Wallet, *models.WalletMember, error) {
+	wallet, err := s.repo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrWalletNotFound
+		}
+		return nil, nil, err
+	}
+
+	member, err := s.authorizeWallet(ctx, s.repo, userID, wallet, roles...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wallet, member, nil
+}
+
+// authorizeWallet проверяет, что пользователь - владелец кошелька или его активный участник
+// с одной из ролей roles, и возвращает его участие. Не участникам кошелёк не раскрывается.
+func (s *service) authorizeWallet(ctx context.Context, repo repository.Repository, userID uint64, wallet *models.Wallet, roles ...string) (*models.WalletMember, error) {
+	member := ownerMembership(wallet)
+	if wallet.UserID != userID {
+		var err error
+		member, err = repo.GetWalletMember(ctx, wallet.ID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get wallet membership: %v", err)
+		}
+		if member == nil || member.Status != models.WalletMemberStatusActive {
+			return nil, ErrWalletNotFound
+		}
+	}
+
+	for _, role := range roles {
+		if member.Role == role {
+			return member, nil
+		}
+	}
+	return nil, ErrWalletForbidden
+}
+
+// ensureSpendLimit проверяет, что списание amount вместе со списаниями участника spender
+// за SpendLimitPeriod и его действующими блокировками не превышает его лимит. Кошелёк должен
+// быть заблокирован вызывающим кодом, чтобы параллельные списания участника не прошли проверку одновременно.
+func ensureSpendLimit(ctx context.Context, repo repository.Repository, member *models.WalletMember, amount float64) error {
+	if member.Role != models.WalletRoleSpender {
+		return nil
+	}
+	since := journal.Timestamp(time.Now().Add(-SpendLimitPeriod))
+	spent, err := repo.GetMemberDebits(ctx, member.WalletID, member.UserID, since)
+	if err != nil {
+		return fmt.Errorf("failed to get member debits: %v", err)
+	}
+	held, err := repo.GetMemberHeldAmount(ctx, member.WalletID, member.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get member holds: %v", err)
+	}
+	if roundAmount(spent+held+amount) > member.SpendLimit {
+		return ErrSpendLimitExceeded
+	}
+	return nil
+}
+
+// ownerMembership описывает неявное участие владельца в собственном кошельке.
+func ownerMembership(wallet *models.Wallet) *models.WalletMember {
+	return &models.WalletMember{
+		WalletID: wallet.ID,
+		UserID:   wallet.UserID,
+		Currency: wallet.Currency,
+		Role:     models.WalletRoleOwner,
+		Status:   models.WalletMemberStatusActive,
+	}
+}
+
+func walletAccess(wallet *models.Wallet, member *models.WalletMember) *models.WalletAccess {
+	return &models.WalletAccess{
+		WalletID:   wallet.ID,
+		OwnerID:    wallet.UserID,
+		Currency:   wallet.Currency,
+		Balance:    wallet.Balance,
+		Role:       member.Role,
+		SpendLimit: member.SpendLimit,
+		Version:    wallet.Version,
+	}
+}
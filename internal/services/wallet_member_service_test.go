@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/journal"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithdrawFromWalletSpenderLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleSpender, SpendLimit: 50, Status: models.WalletMemberStatusActive,
+	}, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetMemberDebits(gomock.Any(), uint64(7), uint64(2), gomock.Any()).Return(0.0, nil)
+	mockRepo.EXPECT().GetMemberHeldAmount(gomock.Any(), uint64(7), uint64(2)).Return(0.0, nil)
+
+	_, err := service.WithdrawFromWallet(ctx, 2, 7, 60)
+	assert.ErrorIs(t, err, ErrSpendLimitExceeded)
+}
+
+func TestWithdrawFromWalletSpenderCumulativeLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleSpender, SpendLimit: 50, Status: models.WalletMemberStatusActive,
+	}, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	// Каждое списание укладывается в лимит, но вместе с прошлыми списаниями за период - нет
+	mockRepo.EXPECT().GetMemberDebits(gomock.Any(), uint64(7), uint64(2), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ uint64, since time.Time) (float64, error) {
+			assert.WithinDuration(t, time.Now().Add(-SpendLimitPeriod), since, time.Minute)
+			return 30, nil
+		})
+	mockRepo.EXPECT().GetMemberHeldAmount(gomock.Any(), uint64(7), uint64(2)).Return(0.0, nil)
+
+	_, err := service.WithdrawFromWallet(ctx, 2, 7, 30)
+	assert.ErrorIs(t, err, ErrSpendLimitExceeded)
+}
+
+func TestWithdrawFromWalletSpender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleSpender, SpendLimit: 50, Status: models.WalletMemberStatusActive,
+	}, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetMemberDebits(gomock.Any(), uint64(7), uint64(2), gomock.Any()).Return(20.0, nil)
+	mockRepo.EXPECT().GetMemberHeldAmount(gomock.Any(), uint64(7), uint64(2)).Return(0.0, nil)
+	// Заморозка проверяется по владельцу кошелька и по участнику, выполняющему операцию
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(2)).Return(&models.User{ID: 2, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetHeldAmount(gomock.Any(), uint64(7)).Return(0.0, nil)
+	mockRepo.EXPECT().GetPottedAmount(gomock.Any(), uint64(7)).Return(0.0, nil)
+	mockRepo.EXPECT().GetCreditLine(gomock.Any(), uint64(7)).Return(nil, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(7), 470.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, transaction *models.Transaction) {
+			assert.Equal(t, models.TransactionTypeWithdrawal, transaction.Type)
+			assert.Equal(t, "Withdrawal by user 2", transaction.Description)
+			assert.Equal(t, uint64(2), transaction.InitiatedBy)
+			assert.Equal(t, journal.Hash(transaction), transaction.Hash)
+		}).Return(nil)
+
+	wallet, err := service.WithdrawFromWallet(ctx, 2, 7, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, 470.0, wallet.Balance)
+}
+
+func TestWithdrawFromWalletFrozenMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleSpender, SpendLimit: 50, Status: models.WalletMemberStatusActive,
+	}, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetMemberDebits(gomock.Any(), uint64(7), uint64(2), gomock.Any()).Return(0.0, nil)
+	mockRepo.EXPECT().GetMemberHeldAmount(gomock.Any(), uint64(7), uint64(2)).Return(0.0, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(2)).Return(&models.User{ID: 2, FreezeState: models.FreezeStateDebit}, nil)
+
+	// Заморозка участника запрещает ему списания с чужого кошелька
+	_, err := service.WithdrawFromWallet(ctx, 2, 7, 10)
+	assert.ErrorIs(t, err, ErrAccountFrozen)
+}
+
+func TestDepositToWalletViewerForbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(3)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 3, Role: models.WalletRoleViewer, Status: models.WalletMemberStatusActive,
+	}, nil)
+
+	_, err := service.DepositToWallet(ctx, 3, 7, 10)
+	assert.ErrorIs(t, err, ErrWalletForbidden)
+}
+
+func TestGetSharedWalletPendingInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetWalletByID(gomock.Any(), uint64(7)).Return(&models.Wallet{ID: 7, UserID: 1, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(ctx, uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleViewer, Status: models.WalletMemberStatusInvited,
+	}, nil)
+
+	// До принятия приглашения кошелёк недоступен
+	_, err := service.GetSharedWallet(ctx, 2, 7)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
+
+func TestInviteWalletMemberRequiresOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetUserByUsername(ctx, "bob").Return(&models.User{ID: 4, Username: "bob"}, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(ctx, uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleSpender, SpendLimit: 10, Status: models.WalletMemberStatusActive,
+	}, nil)
+
+	request := &models.WalletMemberRequest{Username: "bob", Role: models.WalletRoleViewer}
+	_, err := service.InviteWalletMember(ctx, 2, 7, request)
+	assert.ErrorIs(t, err, ErrWalletForbidden)
+}
+
+func TestTransferFromWalletSpender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	wallet := &models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD"}
+	recipient := &models.Wallet{ID: 9, UserID: 3, Balance: 0, Currency: "USD"}
+	mockRepo.EXPECT().GetWalletByID(gomock.Any(), uint64(7)).Return(wallet, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleSpender, SpendLimit: 50, Status: models.WalletMemberStatusActive,
+	}, nil).Times(2)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetWalletByUserAndCurrency(uint64(3), "USD").Return(recipient, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).Return(wallet, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(9)).Return(recipient, nil)
+	mockRepo.EXPECT().GetMemberDebits(gomock.Any(), uint64(7), uint64(2), gomock.Any()).Return(10.0, nil)
+	mockRepo.EXPECT().GetMemberHeldAmount(gomock.Any(), uint64(7), uint64(2)).Return(0.0, nil)
+	// Заморозка участника проверяется только для списания с кошелька, в котором он состоит
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(2)).Return(&models.User{ID: 2, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(3)).Return(&models.User{ID: 3, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetHeldAmount(gomock.Any(), uint64(7)).Return(0.0, nil)
+	mockRepo.EXPECT().GetPottedAmount(gomock.Any(), uint64(7)).Return(0.0, nil)
+	mockRepo.EXPECT().GetCreditLine(gomock.Any(), uint64(7)).Return(nil, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(7), 460.0).Return(nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(9), 40.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, transaction *models.Transaction) {
+			if transaction.WalletID == 7 {
+				assert.Equal(t, uint64(2), transaction.InitiatedBy)
+			} else {
+				assert.Zero(t, transaction.InitiatedBy)
+			}
+		}).Return(nil).Times(2)
+
+	updated, err := service.TransferFromWallet(ctx, 2, 7, 3, 40)
+	assert.NoError(t, err)
+	assert.Equal(t, 460.0, updated.Balance)
+}
+
+func TestAuthorizeWalletHoldSpenderLimitCountsHolds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleSpender, SpendLimit: 50, Status: models.WalletMemberStatusActive,
+	}, nil)
+	mockRepo.EXPECT().GetCurrencyByCode(gomock.Any(), "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	mockRepo.EXPECT().GetMemberDebits(gomock.Any(), uint64(7), uint64(2), gomock.Any()).Return(0.0, nil)
+	// Списаний ещё не было, но действующие блокировки участника уже занимают лимит
+	mockRepo.EXPECT().GetMemberHeldAmount(gomock.Any(), uint64(7), uint64(2)).Return(40.0, nil)
+
+	_, err := service.AuthorizeWalletHold(ctx, 2, 7, 20, "order-1", time.Hour)
+	assert.ErrorIs(t, err, ErrSpendLimitExceeded)
+}
+
+func TestCaptureHoldSpenderLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	hold := &models.Hold{ID: 3, WalletID: 7, UserID: 1, Amount: 30, Kind: models.HoldKindUser, Status: models.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo.EXPECT().GetHoldByID(gomock.Any(), uint64(3)).Return(hold, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleSpender, SpendLimit: 50, Status: models.WalletMemberStatusActive,
+	}, nil)
+	mockRepo.EXPECT().GetHoldByIDForUpdate(gomock.Any(), uint64(3)).Return(hold, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Return(&models.User{FreezeState: models.FreezeStateNone}, nil).Times(2)
+	mockRepo.EXPECT().GetMemberDebits(gomock.Any(), uint64(7), uint64(2), gomock.Any()).Return(40.0, nil)
+	mockRepo.EXPECT().GetMemberHeldAmount(gomock.Any(), uint64(7), uint64(2)).Return(0.0, nil)
+
+	_, err := service.CaptureHold(ctx, 2, 3, 0)
+	assert.ErrorIs(t, err, ErrSpendLimitExceeded)
+}
+
+func TestCaptureOwnHoldSpender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	hold := &models.Hold{ID: 3, WalletID: 7, UserID: 1, Amount: 30, Kind: models.HoldKindUser, Status: models.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour), InitiatedBy: 2}
+	mockRepo.EXPECT().GetHoldByID(gomock.Any(), uint64(3)).Return(hold, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(gomock.Any(), uint64(7)).
+		Return(&models.Wallet{ID: 7, UserID: 1, Balance: 500, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(2)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 2, Role: models.WalletRoleSpender, SpendLimit: 50, Status: models.WalletMemberStatusActive,
+	}, nil)
+	mockRepo.EXPECT().GetHoldByIDForUpdate(gomock.Any(), uint64(3)).Return(hold, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), gomock.Any()).Return(&models.User{FreezeState: models.FreezeStateNone}, nil).Times(2)
+	// Собственная блокировка участника уже учтена в лимите и не считается дважды: 15 + 30 <= 50
+	mockRepo.EXPECT().GetMemberDebits(gomock.Any(), uint64(7), uint64(2), gomock.Any()).Return(15.0, nil)
+	mockRepo.EXPECT().GetMemberHeldAmount(gomock.Any(), uint64(7), uint64(2)).Return(30.0, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(7), 470.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdateHold(gomock.Any(), gomock.Any()).Return(nil)
+
+	captured, err := service.CaptureHold(ctx, 2, 3, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 30.0, captured.CapturedAmount)
+}
+
+func TestReleaseHoldNotMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetHoldByID(gomock.Any(), uint64(3)).
+		Return(&models.Hold{ID: 3, WalletID: 7, UserID: 1, Amount: 30, Status: models.HoldStatusActive}, nil)
+	mockRepo.EXPECT().GetWalletByID(gomock.Any(), uint64(7)).Return(&models.Wallet{ID: 7, UserID: 1, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(gomock.Any(), uint64(7), uint64(4)).Return(nil, nil)
+
+	// Блокировки чужих кошельков не раскрываются
+	_, err := service.ReleaseHold(ctx, 4, 3)
+	assert.ErrorIs(t, err, ErrHoldNotFound)
+}
+
+func TestPrepareWalletStatementViewer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	mockRepo.EXPECT().GetWalletByID(gomock.Any(), uint64(7)).Return(&models.Wallet{ID: 7, UserID: 1, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWalletMember(ctx, uint64(7), uint64(3)).Return(&models.WalletMember{
+		WalletID: 7, UserID: 3, Role: models.WalletRoleViewer, Status: models.WalletMemberStatusActive,
+	}, nil)
+	mockRepo.EXPECT().GetBalanceAt(ctx, uint64(7), from).Return(100.0, nil)
+	mockRepo.EXPECT().GetBalanceAt(ctx, uint64(7), to).Return(150.0, nil)
+
+	st, err := service.PrepareWalletStatement(ctx, 3, 7, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), st.WalletID)
+	assert.Equal(t, 100.0, st.OpeningBalance)
+	assert.Equal(t, 150.0, st.ClosingBalance)
+}
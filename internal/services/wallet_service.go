@@ -1,10 +1,18 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
+	"time"
 
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/journal"
 	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+	"github.com/google/uuid"
 )
 
 // GetAllRates - получение всех курсов валют.
@@ -37,49 +45,91 @@ func (s *service) ExchangeCurrency(fromCurrency, toCurrency string, amount float
 	return converted, nil
 }
 
-// CreateWallet создаёт новый кошелёк для пользователя.
-func (s *service) CreateWallet(wallet *models.Wallet) (int, error) {
+// CreateWallet создаёт новый кошелёк для пользователя в тенанте контекста.
+func (s *service) CreateWallet(ctx context.Context, wallet *models.Wallet) (int, error) {
+	if _, err := s.requireCurrency(ctx, wallet.Currency); err != nil {
+		return 0, err
+	}
+	wallet.TenantID = tenant.ID(ctx)
 	return s.repo.CreateWallet(wallet)
 }
 
-// GetBalance возвращает баланс пользователя по валютам.
-func (s *service) GetBalance(userID uint64) (map[string]float64, error) {
+// GetBalance возвращает учётный и доступный балансы пользователя по валютам.
+func (s *service) GetBalance(userID uint64) (*models.Balances, error) {
 	// Получаем все записи кошелька пользователя
 	wallets, err := s.repo.GetWalletsByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Формируем баланс в виде карты
-	balances := make(map[string]float64)
+	// Суммы действующих блокировок уменьшают доступный баланс
+	held, err := s.repo.GetHeldAmountsByUserID(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Лимиты овердрафта увеличивают доступный баланс
+	limits, err := s.repo.GetCreditLimitsByUserID(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Средства копилок входят в учётный баланс, но недоступны для списаний
+	pots, err := s.repo.GetPotsByUserID(context.Background(), userID, true)
+	if err != nil {
+		return nil, err
+	}
+	potted := make(map[string]float64)
+	for _, pot := range pots {
+		potted[pot.Currency] += pot.Balance
+	}
+
+	// Формируем баланс в виде карт
+	balances := &models.Balances{
+		Total:     make(map[string]float64),
+		Available: make(map[string]float64),
+		Versions:  make(map[string]uint64),
+		ETag:      BalanceETag(wallets),
+	}
 	for _, wallet := range wallets {
-		balances[wallet.Currency] = wallet.Balance
+		balances.Total[wallet.Currency] = wallet.Balance
+		balances.Versions[wallet.Currency] = wallet.Version
+		balances.Available[wallet.Currency] = roundAmount(wallet.Balance - held[wallet.Currency] - potted[wallet.Currency] + limits[wallet.Currency])
+	}
+	if len(limits) > 0 {
+		balances.CreditLimits = limits
+	}
+	if len(pots) > 0 {
+		balances.Pots = make(map[string][]*models.Pot)
+		for _, pot := range pots {
+			balances.Pots[pot.Currency] = append(balances.Pots[pot.Currency], pot)
+		}
 	}
 
 	return balances, nil
 }
 
 // Deposit пополняет кошелёк пользователя в указанной валюте.
-func (s *service) Deposit(userID uint64, amount float64, currency string) (map[string]float64, error) {
-	// Проверяем корректность суммы
-	if amount <= 0 {
-		return nil, errors.New("invalid deposit amount")
+func (s *service) Deposit(ctx context.Context, userID uint64, amount float64, currency string) (map[string]float64, error) {
+	// Проверяем валюту и корректность суммы
+	if err := s.validateAmount(ctx, currency, amount); err != nil {
+		return nil, err
 	}
 
-	// Получаем кошелёк по валюте
-	wallet, err := s.repo.GetWalletByUserAndCurrency(userID, currency)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve wallet for currency %s: %v", currency, err)
-	}
-	if wallet == nil {
-		return nil, errors.New("wallet for the specified currency does not exist")
-	}
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		// Получаем и блокируем кошелёк по валюте
+		wallet, err := s.lockWallet(ctx, repo, userID, currency)
+		if err != nil {
+			return err
+		}
+		if err := checkBalanceVersion(ctx, repo, userID); err != nil {
+			return err
+		}
 
-	// Обновляем баланс
-	newBalance := wallet.Balance + amount
-	err = s.repo.UpdateWalletBalance(wallet.ID, newBalance)
+		return s.depositLocked(ctx, repo, wallet, amount, "Deposit")
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update wallet balance: %v", err)
+		return nil, err
 	}
 
 	// Возвращаем текущие балансы пользователя
@@ -87,35 +137,58 @@ func (s *service) Deposit(userID uint64, amount float64, currency string) (map[s
 }
 
 // Withdraw выводит средства из кошелька пользователя в указанной валюте.
-func (s *service) Withdraw(userID uint64, amount float64, currency string) (map[string]float64, error) {
-	// Проверяем корректность суммы
-	if amount <= 0 {
-		return nil, errors.New("invalid withdrawal amount")
+func (s *service) Withdraw(ctx context.Context, userID uint64, amount float64, currency string) (map[string]float64, error) {
+	// Проверяем валюту и корректность суммы
+	if err := s.validateAmount(ctx, currency, amount); err != nil {
+		return nil, err
 	}
 
-	// Получаем кошелёк по валюте
-	wallet, err := s.repo.GetWalletByUserAndCurrency(userID, currency)
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		// Получаем и блокируем кошелёк по валюте
+		wallet, err := s.lockWallet(ctx, repo, userID, currency)
+		if err != nil {
+			return err
+		}
+		if err := checkBalanceVersion(ctx, repo, userID); err != nil {
+			return err
+		}
+
+		return s.withdrawLocked(ctx, repo, wallet, amount, "Withdrawal")
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve wallet for currency %s: %v", currency, err)
-	}
-	if wallet == nil {
-		return nil, errors.New("wallet for the specified currency does not exist")
+		return nil, err
 	}
 
-	// Проверяем баланс
-	if wallet.Balance < amount {
-		return nil, errors.New("insufficient funds")
+	// Возвращаем текущие балансы пользователя
+	return s.GetAllBalances(userID)
+}
+
+// depositLocked зачисляет amount на заблокированный кошелёк.
+func (s *service) depositLocked(ctx context.Context, repo repository.Repository, wallet *models.Wallet, amount float64, description string) error {
+	// Зачисление запрещено только при полной заморозке
+	if err := s.ensureCreditAllowed(ctx, repo, wallet); err != nil {
+		return err
 	}
 
 	// Обновляем баланс
-	newBalance := wallet.Balance - amount
-	err = s.repo.UpdateWalletBalance(wallet.ID, newBalance)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update wallet balance: %v", err)
+	_, err := s.postEntry(ctx, repo, wallet, amount, models.TransactionTypeDeposit, uuid.NewString(), description)
+	return err
+}
+
+// withdrawLocked списывает amount с заблокированного кошелька в пределах доступного баланса.
+func (s *service) withdrawLocked(ctx context.Context, repo repository.Repository, wallet *models.Wallet, amount float64, description string) error {
+	if err := s.ensureDebitAllowed(ctx, repo, wallet); err != nil {
+		return err
 	}
 
-	// Возвращаем текущие балансы пользователя
-	return s.GetAllBalances(userID)
+	// Проверяем доступный баланс
+	if err := s.ensureAvailable(ctx, repo, wallet, amount); err != nil {
+		return err
+	}
+
+	// Обновляем баланс
+	_, err := s.postEntry(ctx, repo, wallet, -amount, models.TransactionTypeWithdrawal, uuid.NewString(), description)
+	return err
 }
 
 // GetAllBalances возвращает балансы во всех валютах для пользователя.
@@ -133,42 +206,327 @@ func (s *service) GetAllBalances(userID uint64) (map[string]float64, error) {
 	return balances, nil
 }
 
+// UpdateUserBalance атомарно списывает amount с кошелька fromCurrency
+// и зачисляет exchangedAmount на кошелёк toCurrency.
 func (s *service) UpdateUserBalance(userID uint64, fromCurrency, toCurrency string, amount, exchangedAmount float64) (map[string]float64, error) {
-	// Получаем кошелёк, откуда списываем средства
-	fromWallet, err := s.repo.GetWalletByUserAndCurrency(userID, fromCurrency)
+	return s.updateUserBalance(context.Background(), userID, fromCurrency, toCurrency, amount, exchangedAmount)
+}
+
+func (s *service) updateUserBalance(ctx context.Context, userID uint64, fromCurrency, toCurrency string, amount, exchangedAmount float64) (map[string]float64, error) {
+	var newFromBalance, newToBalance float64
+
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		fromWallet, toWallet, err := s.lockWalletPair(ctx, repo, userID, fromCurrency, toCurrency)
+		if err != nil {
+			return err
+		}
+		if err := checkBalanceVersion(ctx, repo, userID); err != nil {
+			return err
+		}
+
+		if err := s.exchangeLocked(ctx, repo, fromWallet, toWallet, amount, exchangedAmount); err != nil {
+			return err
+		}
+		newFromBalance, newToBalance = fromWallet.Balance, toWallet.Balance
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get wallet for currency %s: %v", fromCurrency, err)
+		return nil, err
+	}
+
+	// Возвращаем новые балансы
+	return map[string]float64{
+		fromCurrency: newFromBalance,
+		toCurrency:   newToBalance,
+	}, nil
+}
+
+// exchangeLocked списывает amount с заблокированного кошелька fromWallet и зачисляет
+// exchangedAmount на заблокированный кошелёк toWallet одной операцией.
+func (s *service) exchangeLocked(ctx context.Context, repo repository.Repository, fromWallet, toWallet *models.Wallet, amount, exchangedAmount float64) error {
+	fromCurrency, toCurrency := fromWallet.Currency, toWallet.Currency
+
+	if err := s.ensureDebitAllowed(ctx, repo, fromWallet); err != nil {
+		return err
+	}
+	if err := s.ensureCreditAllowed(ctx, repo, toWallet); err != nil {
+		return err
+	}
+
+	// Проверяем доступный баланс кошелька, откуда списываем средства
+	if err := s.ensureAvailable(ctx, repo, fromWallet, amount); err != nil {
+		return fmt.Errorf("%w in %s wallet", err, fromCurrency)
+	}
+
+	// Обе ноги обмена проводятся в рамках одной операции
+	operationID := uuid.NewString()
+	description := fmt.Sprintf("Exchange %s to %s", fromCurrency, toCurrency)
+
+	// Обновляем баланс кошелька "FromCurrency"
+	if _, err := s.postEntry(ctx, repo, fromWallet, -amount, models.TransactionTypeExchangeOut, operationID, description); err != nil {
+		return fmt.Errorf("failed to update wallet balance for %s: %v", fromCurrency, err)
+	}
+
+	// Обновляем баланс кошелька "ToCurrency"
+	if _, err := s.postEntry(ctx, repo, toWallet, exchangedAmount, models.TransactionTypeExchangeIn, operationID, description); err != nil {
+		return fmt.Errorf("failed to update wallet balance for %s: %v", toCurrency, err)
+	}
+
+	return nil
+}
+
+// Exchange обменивает amount из fromCurrency в toCurrency по текущему курсу
+// и возвращает полученную сумму вместе с новыми балансами обоих кошельков.
+func (s *service) Exchange(ctx context.Context, userID uint64, fromCurrency, toCurrency string, amount float64) (float64, map[string]float64, error) {
+	if err := s.validateAmount(ctx, fromCurrency, amount); err != nil {
+		return 0, nil, err
 	}
-	if fromWallet == nil || fromWallet.Balance < amount {
-		return nil, fmt.Errorf("insufficient funds in %s wallet", fromCurrency)
+	if _, err := s.requireCurrency(ctx, toCurrency); err != nil {
+		return 0, nil, err
 	}
 
-	// Получаем кошелёк, куда зачисляем средства
-	toWallet, err := s.repo.GetWalletByUserAndCurrency(userID, toCurrency)
+	// Предварительная проверка, чтобы не запрашивать курс впустую;
+	// окончательная проверка выполняется в транзакции UpdateUserBalance.
+	balance, err := s.GetBalance(userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get wallet for currency %s: %v", toCurrency, err)
+		return 0, nil, fmt.Errorf("failed to retrieve user balance: %v", err)
 	}
-	if toWallet == nil {
-		return nil, fmt.Errorf("wallet for currency %s does not exist", toCurrency)
+	if _, ok := balance.Available[toCurrency]; !ok {
+		return 0, nil, fmt.Errorf("%w: %s", ErrWalletNotFound, toCurrency)
+	}
+	available, ok := balance.Available[fromCurrency]
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: %s", ErrWalletNotFound, fromCurrency)
+	}
+	if available < amount {
+		return 0, nil, ErrInsufficientFunds
 	}
 
-	// Обновляем баланс кошелька "FromCurrency"
-	newFromBalance := fromWallet.Balance - amount
-	err = s.repo.UpdateWalletBalance(fromWallet.ID, newFromBalance)
+	exchangedAmount, err := s.ExchangeCurrency(fromCurrency, toCurrency, amount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update wallet balance for %s: %v", fromCurrency, err)
+		return 0, nil, fmt.Errorf("%w: %v", ErrConversionFailed, err)
 	}
 
-	// Обновляем баланс кошелька "ToCurrency"
-	newToBalance := toWallet.Balance + exchangedAmount
-	err = s.repo.UpdateWalletBalance(toWallet.ID, newToBalance)
+	newBalance, err := s.updateUserBalance(ctx, userID, fromCurrency, toCurrency, amount, exchangedAmount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update wallet balance for %s: %v", toCurrency, err)
+		return 0, nil, err
 	}
 
-	// Возвращаем новые балансы
-	return map[string]float64{
-		fromCurrency: newFromBalance,
-		toCurrency:   newToBalance,
-	}, nil
+	return exchangedAmount, newBalance, nil
+}
+
+// Transfer переводит amount в валюте currency с кошелька одного пользователя на кошелёк другого.
+func (s *service) Transfer(ctx context.Context, fromUserID, toUserID uint64, currency string, amount float64) (map[string]float64, error) {
+	if err := s.validateAmount(ctx, currency, amount); err != nil {
+		return nil, err
+	}
+	if fromUserID == toUserID {
+		return nil, ErrSelfTransfer
+	}
+
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		fromWallet, err := s.findWallet(repo, fromUserID, currency)
+		if err != nil {
+			return err
+		}
+		toWallet, err := s.findWallet(repo, toUserID, currency)
+		if err != nil {
+			if errors.Is(err, ErrWalletNotFound) {
+				return fmt.Errorf("%w: %s", ErrRecipientWalletNotFound, currency)
+			}
+			return err
+		}
+		// Переводы между тенантами не поддерживаются
+		if toWallet.TenantID != fromWallet.TenantID {
+			return fmt.Errorf("%w: %s", ErrRecipientWalletNotFound, currency)
+		}
+
+		fromWallet, toWallet, err = s.lockWalletsByID(ctx, repo, fromWallet.ID, toWallet.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.transferLocked(ctx, repo, fromWallet, toWallet, amount)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAllBalances(fromUserID)
+}
+
+// transferLocked переводит amount с заблокированного кошелька fromWallet на заблокированный
+// кошелёк получателя toWallet в той же валюте.
+func (s *service) transferLocked(ctx context.Context, repo repository.Repository, fromWallet, toWallet *models.Wallet, amount float64) error {
+	if err := s.ensureDebitAllowed(ctx, repo, fromWallet); err != nil {
+		return err
+	}
+	// Участник кошелька отправителя не относится к кошельку получателя
+	if err := s.ensureCreditAllowed(withActor(ctx, 0), repo, toWallet); err != nil {
+		return fmt.Errorf("recipient %w", err)
+	}
+
+	if err := s.ensureAvailable(ctx, repo, fromWallet, amount); err != nil {
+		return err
+	}
+
+	operationID := uuid.NewString()
+	if _, err := s.postEntry(ctx, repo, fromWallet, -amount, models.TransactionTypeTransferOut, operationID,
+		fmt.Sprintf("Transfer to user %d", toWallet.UserID)); err != nil {
+		return err
+	}
+	_, err := s.postEntry(withActor(ctx, 0), repo, toWallet, amount, models.TransactionTypeTransferIn, operationID,
+		fmt.Sprintf("Transfer from user %d", fromWallet.UserID))
+	return err
+}
+
+// lockWallet получает кошелёк пользователя по валюте с блокировкой строки.
+// Должен вызываться внутри транзакции.
+func (s *service) lockWallet(ctx context.Context, repo repository.Repository, userID uint64, currency string) (*models.Wallet, error) {
+	wallet, err := repo.GetWalletByUserAndCurrencyForUpdate(ctx, userID, currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve wallet for currency %s: %v", currency, err)
+	}
+	return wallet, nil
+}
+
+// lockWalletPair блокирует кошельки пользователя в двух валютах для обмена.
+func (s *service) lockWalletPair(ctx context.Context, repo repository.Repository, userID uint64, fromCurrency, toCurrency string) (*models.Wallet, *models.Wallet, error) {
+	if fromCurrency == toCurrency {
+		return nil, nil, ErrSameCurrency
+	}
+
+	fromWallet, err := s.findWallet(repo, userID, fromCurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	toWallet, err := s.findWallet(repo, userID, toCurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.lockWalletsByID(ctx, repo, fromWallet.ID, toWallet.ID)
+}
+
+// findWallet получает кошелёк пользователя по валюте без блокировки.
+func (s *service) findWallet(repo repository.Repository, userID uint64, currency string) (*models.Wallet, error) {
+	wallet, err := repo.GetWalletByUserAndCurrency(userID, currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrWalletNotFound, currency)
+		}
+		return nil, fmt.Errorf("failed to get wallet for currency %s: %v", currency, err)
+	}
+	return wallet, nil
+}
+
+// lockWalletsByID блокирует два кошелька в порядке возрастания ID, чтобы параллельные
+// операции в противоположных направлениях не приводили к взаимной блокировке.
+func (s *service) lockWalletsByID(ctx context.Context, repo repository.Repository, firstID, secondID uint64) (*models.Wallet, *models.Wallet, error) {
+	locked, err := s.lockWallets(ctx, repo, firstID, secondID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return locked[firstID], locked[secondID], nil
+}
+
+// lockWallets блокирует кошельки по ID в порядке возрастания ID, чтобы параллельные операции
+// над теми же кошельками не приводили к взаимоблокировке.
+func (s *service) lockWallets(ctx context.Context, repo repository.Repository, ids ...uint64) (map[uint64]*models.Wallet, error) {
+	sorted := append([]uint64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	locked := make(map[uint64]*models.Wallet, len(sorted))
+	for _, id := range sorted {
+		if _, ok := locked[id]; ok {
+			continue
+		}
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock wallet %d: %v", id, err)
+		}
+		locked[id] = wallet
+	}
+
+	return locked, nil
+}
+
+func (s *service) postEntry(ctx context.Context, repo repository.Repository, wallet *models.Wallet, amount float64, transactionType, operationID, description string) (*models.Transaction, error) {
+	return s.postTransaction(ctx, repo, wallet, &models.Transaction{
+		OperationID: operationID,
+		Type:        transactionType,
+		Amount:      amount,
+		Description: description,
+	})
+}
+
+// postTransaction изменяет баланс заблокированного кошелька на transaction.Amount, записывает проводку
+// в журнал, продолжая цепочку хешей кошелька, и событие о ней в outbox.
+func (s *service) postTransaction(ctx context.Context, repo repository.Repository, wallet *models.Wallet, transaction *models.Transaction) (*models.Transaction, error) {
+	balance := roundAmount(wallet.Balance + transaction.Amount)
+	if err := repo.UpdateWalletBalance(wallet.ID, balance); err != nil {
+		return nil, fmt.Errorf("failed to update wallet balance: %v", err)
+	}
+	wallet.Balance = balance
+	wallet.Version++
+
+	// Кошелёк заблокирован, поэтому последняя проводка не изменится до конца транзакции.
+	prevHash, err := repo.GetLastTransactionHash(ctx, wallet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal head: %v", err)
+	}
+
+	transaction.WalletID = wallet.ID
+	transaction.Amount = roundAmount(transaction.Amount)
+	transaction.BalanceAfter = balance
+	transaction.CreatedAt = journal.Timestamp(time.Now())
+	transaction.PrevHash = prevHash
+	if transaction.InitiatedBy == 0 {
+		transaction.InitiatedBy = actorFromContext(ctx)
+	}
+	transaction.Hash = journal.Hash(transaction)
+	if err := repo.CreateTransaction(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to record transaction: %v", err)
+	}
+	if err := s.recordWalletEvent(ctx, repo, wallet, transaction); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// ensureAvailable проверяет, что списание amount укладывается в доступный баланс кошелька:
+// собственные свободные средства плюс лимит кредитной линии, если она открыта.
+func (s *service) ensureAvailable(ctx context.Context, repo repository.Repository, wallet *models.Wallet, amount float64) error {
+	free, err := s.freeFunds(ctx, repo, wallet)
+	if err != nil {
+		return err
+	}
+	creditLimit := 0.0
+	line, err := repo.GetCreditLine(ctx, wallet.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get credit line: %v", err)
+	}
+	if line != nil {
+		creditLimit = line.Limit
+	}
+	if free+creditLimit < amount {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// freeFunds возвращает остаток кошелька за вычетом блокировок и средств в копилках.
+func (s *service) freeFunds(ctx context.Context, repo repository.Repository, wallet *models.Wallet) (float64, error) {
+	held, err := repo.GetHeldAmount(ctx, wallet.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get held amount: %v", err)
+	}
+	potted, err := repo.GetPottedAmount(ctx, wallet.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get potted amount: %v", err)
+	}
+	return wallet.Balance - held - potted, nil
 }
@@ -0,0 +1,473 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
+)
+
+const (
+	// MaxWebhookSubscriptions - максимальное количество подписок на вебхуки у пользователя.
+	MaxWebhookSubscriptions = 10
+	// MaxWebhookAttempts - количество попыток доставки, после которого доставка переходит в статус dead.
+	MaxWebhookAttempts = 8
+	// WebhookDeliveriesLimit - количество последних доставок, возвращаемых по подписке.
+	WebhookDeliveriesLimit = 100
+	// webhookRetryBase - задержка перед второй попыткой; каждая следующая задержка вдвое больше.
+	webhookRetryBase = 30 * time.Second
+	// webhookRetryMax ограничивает задержку между попытками.
+	webhookRetryMax = 6 * time.Hour
+	// webhookBatchSize - количество доставок, отправляемых за один запуск фоновой задачи.
+	webhookBatchSize = 100
+	// webhookLease - время, на которое выбранная доставка скрывается от других обработчиков.
+	webhookLease = time.Minute
+	// minWebhookSecretLength - минимальная длина секрета, заданного пользователем.
+	minWebhookSecretLength = 16
+	// maxWebhookURLLength совпадает с размером колонки webhook_subscriptions.url.
+	maxWebhookURLLength = 2048
+	// webhookResponseLimit - сколько байт ответа получателя читается перед закрытием соединения.
+	webhookResponseLimit = 64 << 10
+)
+
+// Заголовки доставки вебхука.
+const (
+	WebhookIDHeader        = "X-Webhook-ID"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookHTTPClient отправляет доставки вебхуков. Перенаправления не выполняются: ответ 3xx
+// считается неуспешной доставкой. Адрес проверяется при каждом соединении, поэтому имя хоста,
+// которое после подписки стало указывать на внутренний адрес, не даёт обратиться к нему.
+// Прокси не используется, иначе проверялся бы адрес прокси, а не получателя.
+var webhookHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   controlWebhookDial,
+		}).DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// lookupWebhookHost разрешает имя хоста URL подписки.
+var lookupWebhookHost = net.DefaultResolver.LookupIPAddr
+
+// deniedWebhookPrefixes - специальные диапазоны адресов из реестров IANA, на которые нельзя
+// доставлять вебхуки: локальные, частные, служебные, документационные, групповые и адреса
+// трансляции, через которые можно обратиться к сервисам в сети приложения.
+var deniedWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("::ffff:0:0/96"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// webhookAddressAllowed сообщает, можно ли доставлять вебхуки на адрес ip. IPv4-адрес,
+// записанный в форме IPv6, проверяется как IPv4, чтобы запрет нельзя было обойти сменой записи.
+var webhookAddressAllowed = func(ip net.IP) bool {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range deniedWebhookPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// webhookEventTypes - типы событий, на которые можно подписаться.
+var webhookEventTypes = map[string]bool{models.WebhookEventAll: true}
+
+func init() {
+	for _, transactionType := range []string{
+		models.TransactionTypeOpeningBalance,
+		models.TransactionTypeDeposit,
+		models.TransactionTypeWithdrawal,
+		models.TransactionTypeExchangeOut,
+		models.TransactionTypeExchangeIn,
+		models.TransactionTypeTransferOut,
+		models.TransactionTypeTransferIn,
+		models.TransactionTypeHoldCapture,
+		models.TransactionTypeReversal,
+		models.TransactionTypeOverdraftInterest,
+		models.TransactionTypeOverdraftFee,
+		models.TransactionTypeWithdrawalReturn,
+		models.TransactionTypeInterest,
+		models.TransactionTypeVoucher,
+	} {
+		webhookEventTypes[models.EventTypePrefix+transactionType] = true
+	}
+}
+
+// SignWebhook возвращает подпись доставки: HMAC-SHA256 строки "<timestamp>.<тело>" на секрете
+// подписки в шестнадцатеричном виде с префиксом "sha256=". Метка времени входит в подпись,
+// чтобы получатель мог отклонять повторно отправленные старые запросы.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// CreateWebhookSubscription подписывает пользователя на события его кошельков с доставкой на URL.
+func (s *service) CreateWebhookSubscription(ctx context.Context, userID uint64, request *models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	if err := validateWebhookURL(ctx, request.URL); err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := normalizeWebhookEventTypes(request.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	} else if len(secret) < minWebhookSecretLength || len(secret) > 128 {
+		return nil, fmt.Errorf("%w: secret must be %d to 128 characters", ErrInvalidWebhook, minWebhookSecretLength)
+	}
+
+	existing, err := s.repo.GetWebhookSubscriptionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxWebhookSubscriptions {
+		return nil, fmt.Errorf("%w: at most %d subscriptions are allowed", ErrInvalidWebhook, MaxWebhookSubscriptions)
+	}
+
+	subscription := &models.WebhookSubscription{
+		TenantID:   tenant.ID(ctx),
+		UserID:     userID,
+		URL:        request.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	}
+	if err := s.repo.CreateWebhookSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Webhook subscription %d created by user %d", subscription.ID, userID)
+	return subscription, nil
+}
+
+// GetWebhookSubscriptions возвращает подписки пользователя на вебхуки.
+func (s *service) GetWebhookSubscriptions(ctx context.Context, userID uint64) ([]*models.WebhookSubscription, error) {
+	return s.repo.GetWebhookSubscriptionsByUserID(ctx, userID)
+}
+
+// DeleteWebhookSubscription удаляет подписку пользователя вместе с журналом её доставок.
+func (s *service) DeleteWebhookSubscription(ctx context.Context, userID, subscriptionID uint64) error {
+	if _, err := s.userWebhookSubscription(ctx, userID, subscriptionID); err != nil {
+		return err
+	}
+	return s.repo.DeleteWebhookSubscription(ctx, subscriptionID)
+}
+
+// GetWebhookDeliveries возвращает последние доставки подписки пользователя.
+func (s *service) GetWebhookDeliveries(ctx context.Context, userID, subscriptionID uint64) ([]*models.WebhookDelivery, error) {
+	if _, err := s.userWebhookSubscription(ctx, userID, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetWebhookDeliveriesBySubscriptionID(ctx, subscriptionID, WebhookDeliveriesLimit)
+}
+
+// GetWebhookDelivery возвращает доставку подписки пользователя с журналом попыток.
+func (s *service) GetWebhookDelivery(ctx context.Context, userID, subscriptionID, deliveryID uint64) (*models.WebhookDelivery, error) {
+	delivery, err := s.userWebhookDelivery(ctx, s.repo, userID, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Log, err = s.repo.GetWebhookAttempts(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// RedeliverWebhook ставит доставку в очередь на немедленную отправку с новым счётчиком попыток,
+// в том числе уже успешную или исчерпавшую попытки.
+func (s *service) RedeliverWebhook(ctx context.Context, userID, subscriptionID, deliveryID uint64) (*models.WebhookDelivery, error) {
+	delivery, err := s.userWebhookDelivery(ctx, s.repo, userID, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	if err := s.repo.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Webhook delivery %d queued for redelivery by user %d", deliveryID, userID)
+	return delivery, nil
+}
+
+// DeliverWebhooks отправляет доставки, время попытки которых наступило, и возвращает
+// количество успешных.
+func (s *service) DeliverWebhooks(ctx context.Context) (int, error) {
+	deliveries, err := s.repo.ClaimDueWebhookDeliveries(ctx, time.Now(), webhookBatchSize, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		if err := s.deliverWebhook(ctx, delivery); err != nil {
+			s.logger.Errorf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+			continue
+		}
+		if delivery.Status == models.WebhookDeliverySucceeded {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// recordWebhookDeliveries создаёт доставки события всем подпискам владельца кошелька на этот тип событий.
+func (s *service) recordWebhookDeliveries(ctx context.Context, repo repository.Repository, userID uint64, event *models.OutboxEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %v", err)
+	}
+	if err := repo.CreateWebhookDeliveries(ctx, userID, event, payload); err != nil {
+		return fmt.Errorf("failed to record webhook deliveries: %v", err)
+	}
+	return nil
+}
+
+// deliverWebhook выполняет попытку доставки и сохраняет её результат: успех, время следующей
+// попытки с экспоненциальной задержкой или переход в статус dead после MaxWebhookAttempts попыток.
+func (s *service) deliverWebhook(ctx context.Context, delivery *models.WebhookDelivery) error {
+	started := time.Now()
+	statusCode, sendErr := sendWebhook(ctx, delivery, started)
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	attempt := &models.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		StatusCode: statusCode,
+		DurationMS: now.Sub(started).Milliseconds(),
+	}
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= MaxWebhookAttempts:
+		attempt.Error = sendErr.Error()
+		delivery.Status = models.WebhookDeliveryDead
+		delivery.LastError = attempt.Error
+		delivery.NextAttemptAt = nil
+		s.logger.Warnf("Webhook delivery %d is dead after %d attempts: %v", delivery.ID, delivery.Attempts, sendErr)
+	default:
+		attempt.Error = sendErr.Error()
+		next := now.Add(webhookBackoff(delivery.Attempts))
+		delivery.LastError = attempt.Error
+		delivery.NextAttemptAt = &next
+	}
+
+	return s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		if err := repo.CreateWebhookAttempt(ctx, attempt); err != nil {
+			return err
+		}
+		return repo.UpdateWebhookDelivery(ctx, delivery)
+	})
+}
+
+// sendWebhook отправляет подписанную доставку и возвращает код ответа. Успешными считаются
+// только ответы 2xx.
+func sendWebhook(ctx context.Context, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "gw-currency-wallet-webhooks")
+	request.Header.Set(WebhookIDHeader, strconv.FormatUint(delivery.ID, 10))
+	request.Header.Set(WebhookEventHeader, delivery.EventType)
+	request.Header.Set(WebhookTimestampHeader, timestamp)
+	request.Header.Set(WebhookSignatureHeader, SignWebhook(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := webhookHTTPClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, webhookResponseLimit))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("unexpected response status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// webhookBackoff возвращает задержку после attempts неуспешных попыток.
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookRetryMax {
+			return webhookRetryMax
+		}
+	}
+	return delay
+}
+
+// userWebhookSubscription возвращает подписку пользователя. Чужие подписки не раскрываются.
+func (s *service) userWebhookSubscription(ctx context.Context, userID, subscriptionID uint64) (*models.WebhookSubscription, error) {
+	subscription, err := s.repo.GetWebhookSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil || subscription.UserID != userID {
+		return nil, ErrWebhookNotFound
+	}
+	return subscription, nil
+}
+
+// userWebhookDelivery возвращает доставку подписки пользователя.
+func (s *service) userWebhookDelivery(ctx context.Context, repo repository.Repository, userID, subscriptionID, deliveryID uint64) (*models.WebhookDelivery, error) {
+	if _, err := s.userWebhookSubscription(ctx, userID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := repo.GetWebhookDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil || delivery.SubscriptionID != subscriptionID {
+		return nil, ErrWebhookNotFound
+	}
+	return delivery, nil
+}
+
+// validateWebhookURL проверяет, что URL подписки - абсолютный адрес http или https,
+// хост которого разрешается только в публичные адреса.
+func validateWebhookURL(ctx context.Context, rawURL string) error {
+	if rawURL == "" || len(rawURL) > maxWebhookURLLength {
+		return fmt.Errorf("%w: url is required and must be at most %d characters", ErrInvalidWebhook, maxWebhookURLLength)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+
+	addrs, err := lookupWebhookHost(ctx, parsed.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: url host cannot be resolved", ErrInvalidWebhook)
+	}
+	for _, addr := range addrs {
+		if !webhookAddressAllowed(addr.IP) {
+			return fmt.Errorf("%w: url must point to a public address", ErrInvalidWebhook)
+		}
+	}
+	return nil
+}
+
+// controlWebhookDial запрещает соединение доставки с внутренним адресом. Вызывается для адреса,
+// уже полученного при разрешении имени, поэтому подмена DNS после проверки URL не помогает.
+func controlWebhookDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !webhookAddressAllowed(ip) {
+		return fmt.Errorf("webhook address %s is not allowed", host)
+	}
+	return nil
+}
+
+// normalizeWebhookEventTypes проверяет типы событий подписки и убирает повторы.
+func normalizeWebhookEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%w: event_types is required", ErrInvalidWebhook)
+	}
+
+	seen := make(map[string]bool, len(eventTypes))
+	normalized := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !webhookEventTypes[eventType] {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			normalized = append(normalized, eventType)
+		}
+	}
+	return normalized, nil
+}
+
+// generateWebhookSecret генерирует случайный секрет подписки.
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %v", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubWebhookLookup подменяет разрешение имён хостов подписок до конца теста.
+func stubWebhookLookup(t *testing.T, hosts map[string]string) {
+	lookup := lookupWebhookHost
+	lookupWebhookHost = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		if ip := net.ParseIP(host); ip != nil {
+			return []net.IPAddr{{IP: ip}}, nil
+		}
+		if addr, ok := hosts[host]; ok {
+			return []net.IPAddr{{IP: net.ParseIP(addr)}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	t.Cleanup(func() { lookupWebhookHost = lookup })
+}
+
+// allowLoopbackWebhooks разрешает доставку на локальный тестовый сервер до конца теста.
+func allowLoopbackWebhooks(t *testing.T) {
+	allowed := webhookAddressAllowed
+	webhookAddressAllowed = func(net.IP) bool { return true }
+	t.Cleanup(func() { webhookAddressAllowed = allowed })
+}
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	stubWebhookLookup(t, map[string]string{"example.com": "93.184.216.34"})
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetWebhookSubscriptionsByUserID(ctx, uint64(1)).Return(nil, nil)
+	mockRepo.EXPECT().CreateWebhookSubscription(ctx, gomock.Any()).Return(nil)
+
+	subscription, err := service.CreateWebhookSubscription(ctx, 1, &models.WebhookSubscriptionRequest{
+		URL:        "https://example.com/hooks",
+		EventTypes: []string{"wallet.deposit", " wallet.deposit", "wallet.exchange_in"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"wallet.deposit", "wallet.exchange_in"}, subscription.EventTypes)
+	// Секрет генерируется, если не задан
+	assert.Len(t, subscription.Secret, 64)
+}
+
+func TestCreateWebhookSubscriptionInvalid(t *testing.T) {
+	stubWebhookLookup(t, map[string]string{"example.com": "93.184.216.34"})
+	service := &service{logger: logrus.New()}
+	ctx := context.Background()
+
+	for _, request := range []*models.WebhookSubscriptionRequest{
+		{URL: "ftp://example.com", EventTypes: []string{"*"}},
+		{URL: "/hooks", EventTypes: []string{"*"}},
+		{URL: "https://example.com", EventTypes: nil},
+		{URL: "https://example.com", EventTypes: []string{"wallet.unknown"}},
+		{URL: "https://example.com", EventTypes: []string{"*"}, Secret: "short"},
+	} {
+		_, err := service.CreateWebhookSubscription(ctx, 1, request)
+		assert.ErrorIs(t, err, ErrInvalidWebhook, request.URL)
+	}
+}
+
+func TestCreateWebhookSubscriptionInternalAddress(t *testing.T) {
+	stubWebhookLookup(t, map[string]string{"rebind.example.com": "10.1.2.3"})
+	service := &service{logger: logrus.New()}
+	ctx := context.Background()
+
+	for _, rawURL := range []string{
+		"http://127.0.0.1:8080/hooks",
+		"http://[::1]/hooks",
+		"http://10.0.0.5/hooks",
+		"http://192.168.1.1/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[fe80::1]/hooks",
+		"http://0.0.0.0/hooks",
+		"http://224.0.0.1/hooks",
+		"https://rebind.example.com/hooks",
+		"https://unknown.example.com/hooks",
+	} {
+		_, err := service.CreateWebhookSubscription(ctx, 1, &models.WebhookSubscriptionRequest{URL: rawURL, EventTypes: []string{"*"}})
+		assert.ErrorIs(t, err, ErrInvalidWebhook, rawURL)
+	}
+}
+
+func TestWebhookAddressAllowed(t *testing.T) {
+	for address, allowed := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::1":   true,
+		"0.1.2.3":              false,
+		"100.64.0.1":           false,
+		"127.0.0.1":            false,
+		"172.16.0.1":           false,
+		"192.0.0.8":            false,
+		"192.0.2.1":            false,
+		"198.18.0.1":           false,
+		"203.0.113.7":          false,
+		"240.0.0.1":            false,
+		"255.255.255.255":      false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:10.0.0.5":      false,
+		"::ffff:93.184.216.34": true,
+		"64:ff9b::a00:5":       false,
+		"64:ff9b:1::a00:5":     false,
+		"2001:db8::1":          false,
+		"2002:a00:5::1":        false,
+		"fd00::1":              false,
+		"ff02::1":              false,
+		"100::1":               false,
+	} {
+		assert.Equal(t, allowed, webhookAddressAllowed(net.ParseIP(address)), address)
+	}
+}
+
+func TestDeliverWebhooksSigned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	allowLoopbackWebhooks(t)
+
+	payload := json.RawMessage(`{"id":15,"type":"wallet.deposit"}`)
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(WebhookTimestampHeader)
+		verified = string(body) == string(payload) &&
+			r.Header.Get(WebhookEventHeader) == "wallet.deposit" &&
+			r.Header.Get(WebhookSignatureHeader) == SignWebhook("secret", timestamp, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().ClaimDueWebhookDeliveries(ctx, gomock.Any(), webhookBatchSize, webhookLease).Return([]*models.WebhookDelivery{{
+		ID: 3, SubscriptionID: 2, EventType: "wallet.deposit", Payload: payload, Status: models.WebhookDeliveryPending,
+		URL: server.URL, Secret: "secret",
+	}}, nil)
+	mockRepo.EXPECT().CreateWebhookAttempt(ctx, gomock.Any()).Do(func(_ context.Context, attempt *models.WebhookAttempt) {
+		assert.Equal(t, 1, attempt.Attempt)
+		assert.Equal(t, http.StatusNoContent, attempt.StatusCode)
+		assert.Empty(t, attempt.Error)
+	}).Return(nil)
+	mockRepo.EXPECT().UpdateWebhookDelivery(ctx, gomock.Any()).Do(func(_ context.Context, delivery *models.WebhookDelivery) {
+		assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+		assert.NotNil(t, delivery.DeliveredAt)
+		assert.Nil(t, delivery.NextAttemptAt)
+	}).Return(nil)
+
+	delivered, err := service.DeliverWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.True(t, verified)
+}
+
+func TestDeliverWebhooksRetryAndDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	allowLoopbackWebhooks(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().ClaimDueWebhookDeliveries(ctx, gomock.Any(), webhookBatchSize, webhookLease).Return([]*models.WebhookDelivery{
+		{ID: 3, Payload: json.RawMessage(`{}`), Status: models.WebhookDeliveryPending, Attempts: 2, URL: server.URL, Secret: "secret"},
+		{ID: 4, Payload: json.RawMessage(`{}`), Status: models.WebhookDeliveryPending, Attempts: MaxWebhookAttempts - 1, URL: server.URL, Secret: "secret"},
+	}, nil)
+	mockRepo.EXPECT().CreateWebhookAttempt(ctx, gomock.Any()).Return(nil).Times(2)
+	updated := make(map[uint64]*models.WebhookDelivery)
+	mockRepo.EXPECT().UpdateWebhookDelivery(ctx, gomock.Any()).Do(func(_ context.Context, delivery *models.WebhookDelivery) {
+		updated[delivery.ID] = delivery
+	}).Return(nil).Times(2)
+
+	started := time.Now()
+	delivered, err := service.DeliverWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	retried := updated[3]
+	assert.Equal(t, models.WebhookDeliveryPending, retried.Status)
+	assert.Equal(t, 3, retried.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, retried.LastStatusCode)
+	if assert.NotNil(t, retried.NextAttemptAt) {
+		assert.WithinDuration(t, started.Add(4*webhookRetryBase), *retried.NextAttemptAt, 5*time.Second)
+	}
+
+	dead := updated[4]
+	assert.Equal(t, models.WebhookDeliveryDead, dead.Status)
+	assert.Equal(t, MaxWebhookAttempts, dead.Attempts)
+	assert.Nil(t, dead.NextAttemptAt)
+}
+
+func TestDeliverWebhooksInternalAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var requested bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requested = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	// Хост подписки мог начать указывать на внутренний адрес уже после проверки URL
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().ClaimDueWebhookDeliveries(ctx, gomock.Any(), webhookBatchSize, webhookLease).Return([]*models.WebhookDelivery{
+		{ID: 3, Payload: json.RawMessage(`{}`), Status: models.WebhookDeliveryPending, URL: server.URL, Secret: "secret"},
+	}, nil)
+	mockRepo.EXPECT().CreateWebhookAttempt(ctx, gomock.Any()).Do(func(_ context.Context, attempt *models.WebhookAttempt) {
+		assert.Zero(t, attempt.StatusCode)
+		assert.Contains(t, attempt.Error, "is not allowed")
+	}).Return(nil)
+	mockRepo.EXPECT().UpdateWebhookDelivery(ctx, gomock.Any()).Return(nil)
+
+	delivered, err := service.DeliverWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.False(t, requested)
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, webhookRetryBase, webhookBackoff(1))
+	assert.Equal(t, 2*webhookRetryBase, webhookBackoff(2))
+	assert.Equal(t, webhookRetryMax, webhookBackoff(30))
+}
+
+func TestRedeliverWebhookOtherUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetWebhookSubscriptionByID(ctx, uint64(2)).Return(&models.WebhookSubscription{ID: 2, UserID: 5}, nil)
+
+	_, err := service.RedeliverWebhook(ctx, 1, 2, 3)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, logger: logrus.New()}
+	ctx := context.Background()
+
+	mockRepo.EXPECT().GetWebhookSubscriptionByID(ctx, uint64(2)).Return(&models.WebhookSubscription{ID: 2, UserID: 1}, nil)
+	mockRepo.EXPECT().GetWebhookDeliveryByID(ctx, uint64(3)).Return(&models.WebhookDelivery{
+		ID: 3, SubscriptionID: 2, Status: models.WebhookDeliveryDead, Attempts: MaxWebhookAttempts,
+	}, nil)
+	mockRepo.EXPECT().UpdateWebhookDelivery(ctx, gomock.Any()).Return(nil)
+
+	delivery, err := service.RedeliverWebhook(ctx, 1, 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 0, delivery.Attempts)
+	assert.NotNil(t, delivery.NextAttemptAt)
+}
@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// WithdrawalHoldTTL - срок блокировки средств вывода. Блокировку снимает только сам вывод
+	// при завершении, ошибке или отмене: общее истечение блокировок её не затрагивает.
+	WithdrawalHoldTTL = 365 * 24 * time.Hour
+	// WithdrawalReturnWindow - период после выплаты, в течение которого отслеживается возврат средств.
+	WithdrawalReturnWindow = 14 * 24 * time.Hour
+	// withdrawalBatchSize - число выводов в каждом статусе, обрабатываемых за один проход.
+	withdrawalBatchSize = 50
+)
+
+// RequestWithdrawal создаёт вывод средств на реквизиты destination, блокируя сумму на кошельке
+// до завершения выплаты. Вывод ожидает одобрения администратором.
+func (s *service) RequestWithdrawal(ctx context.Context, userID uint64, request *models.WithdrawalRequest) (*models.Withdrawal, error) {
+	destination := strings.TrimSpace(request.Destination)
+	if destination == "" || len(destination) > 255 {
+		return nil, fmt.Errorf("%w: destination is required", ErrInvalidWithdrawal)
+	}
+	if s.payout == nil {
+		return nil, ErrPayoutUnavailable
+	}
+	if err := s.validateAmount(ctx, request.Currency, request.Amount); err != nil {
+		return nil, err
+	}
+
+	var withdrawal *models.Withdrawal
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		wallet, err := s.lockWallet(ctx, repo, userID, request.Currency)
+		if err != nil {
+			return err
+		}
+
+		hold, err := s.createHold(ctx, repo, wallet, models.HoldKindWithdrawal, request.Amount, "Withdrawal to "+destination, time.Now().Add(WithdrawalHoldTTL))
+		if err != nil {
+			return err
+		}
+
+		withdrawal = &models.Withdrawal{
+			UserID:      userID,
+			WalletID:    wallet.ID,
+			Amount:      request.Amount,
+			Currency:    wallet.Currency,
+			Destination: destination,
+			Status:      models.WithdrawalStatusHeld,
+			HoldID:      hold.ID,
+			Provider:    s.payout.Name(),
+		}
+		if err := repo.CreateWithdrawal(ctx, withdrawal); err != nil {
+			return fmt.Errorf("failed to create withdrawal: %v", err)
+		}
+
+		if err := s.recordWithdrawalEvent(ctx, repo, withdrawal.ID, models.WithdrawalStatusRequested, ""); err != nil {
+			return err
+		}
+		return s.recordWithdrawalEvent(ctx, repo, withdrawal.ID, models.WithdrawalStatusHeld, fmt.Sprintf("hold %d", hold.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// GetWithdrawals возвращает выводы средств пользователя.
+func (s *service) GetWithdrawals(ctx context.Context, userID uint64) ([]*models.Withdrawal, error) {
+	return s.repo.GetWithdrawalsByUserID(ctx, userID)
+}
+
+// GetWithdrawal возвращает вывод средств пользователя и историю его статусов.
+func (s *service) GetWithdrawal(ctx context.Context, userID, withdrawalID uint64) (*models.Withdrawal, []*models.WithdrawalEvent, error) {
+	withdrawal, err := s.getUserWithdrawal(ctx, userID, withdrawalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, err := s.repo.GetWithdrawalEvents(ctx, withdrawalID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get withdrawal events: %v", err)
+	}
+	return withdrawal, events, nil
+}
+
+// CancelWithdrawal отменяет ещё не одобренный вывод средств и снимает блокировку.
+func (s *service) CancelWithdrawal(ctx context.Context, userID, withdrawalID uint64) (*models.Withdrawal, error) {
+	withdrawal, err := s.getUserWithdrawal(ctx, userID, withdrawalID)
+	if err != nil {
+		return nil, err
+	}
+	return s.closeHeldWithdrawal(ctx, withdrawal.WalletID, withdrawalID, models.WithdrawalStatusCancelled, "", "cancelled by user")
+}
+
+// GetWithdrawalsByStatus возвращает выводы средств в статусе status для администратора.
+func (s *service) GetWithdrawalsByStatus(ctx context.Context, status string) ([]*models.Withdrawal, error) {
+	switch status {
+	case models.WithdrawalStatusHeld, models.WithdrawalStatusApproved, models.WithdrawalStatusSent,
+		models.WithdrawalStatusSettled, models.WithdrawalStatusFailed, models.WithdrawalStatusReturned,
+		models.WithdrawalStatusCancelled, models.WithdrawalStatusManualReview:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidWithdrawal, status)
+	}
+	return s.repo.GetWithdrawalsByStatus(ctx, status, time.Time{}, withdrawalBatchSize)
+}
+
+// ApproveWithdrawal одобряет вывод средств. Одобренный вывод отправляется провайдеру
+// фоновой задачей и больше не может быть отменён.
+func (s *service) ApproveWithdrawal(ctx context.Context, operatorID, withdrawalID uint64) (*models.Withdrawal, error) {
+	var withdrawal *models.Withdrawal
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		withdrawal, err = s.lockWithdrawal(ctx, repo, withdrawalID, models.WithdrawalStatusHeld)
+		if err != nil {
+			return err
+		}
+		ok, err := walletInTenant(ctx, repo, withdrawal.WalletID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrWithdrawalNotFound
+		}
+
+		note := fmt.Sprintf("approved by operator %d", operatorID)
+		return s.setWithdrawalStatus(ctx, repo, withdrawal, models.WithdrawalStatusApproved, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// RejectWithdrawal отклоняет ещё не одобренный вывод средств и снимает блокировку.
+func (s *service) RejectWithdrawal(ctx context.Context, operatorID, withdrawalID uint64, reason string) (*models.Withdrawal, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	withdrawal, err := s.repo.GetWithdrawalByID(ctx, withdrawalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawal: %v", err)
+	}
+	if withdrawal == nil {
+		return nil, ErrWithdrawalNotFound
+	}
+	ok, err := walletInTenant(ctx, s.repo, withdrawal.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrWithdrawalNotFound
+	}
+
+	note := fmt.Sprintf("rejected by operator %d", operatorID)
+	return s.closeHeldWithdrawal(ctx, withdrawal.WalletID, withdrawalID, models.WithdrawalStatusFailed, reason, note)
+}
+
+// ProcessWithdrawals отправляет одобренные выводы провайдеру выплат и обновляет статусы
+// отправленных и недавно выплаченных выводов. Возвращает число выводов, сменивших статус.
+func (s *service) ProcessWithdrawals(ctx context.Context) (int, error) {
+	if s.payout == nil {
+		return 0, nil
+	}
+
+	processed := 0
+	approved, err := s.repo.GetWithdrawalsByStatus(ctx, models.WithdrawalStatusApproved, time.Time{}, withdrawalBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, withdrawal := range approved {
+		if err := s.sendWithdrawal(ctx, withdrawal); err != nil {
+			s.logger.Errorf("Failed to send withdrawal %d: %v", withdrawal.ID, err)
+			continue
+		}
+		processed++
+	}
+
+	sent, err := s.repo.GetWithdrawalsByStatus(ctx, models.WithdrawalStatusSent, time.Time{}, withdrawalBatchSize)
+	if err != nil {
+		return processed, err
+	}
+	settled, err := s.repo.GetWithdrawalsByStatus(ctx, models.WithdrawalStatusSettled, time.Now().Add(-WithdrawalReturnWindow), withdrawalBatchSize)
+	if err != nil {
+		return processed, err
+	}
+	for _, withdrawal := range append(sent, settled...) {
+		changed, err := s.trackWithdrawal(ctx, withdrawal)
+		if err != nil {
+			s.logger.Errorf("Failed to track withdrawal %d: %v", withdrawal.ID, err)
+			continue
+		}
+		if changed {
+			processed++
+		}
+	}
+
+	return processed, nil
+}
+
+// sendWithdrawal передаёт одобренный вывод провайдеру выплат. Провайдер не повторяет выплату
+// при повторной отправке того же вывода, поэтому сбой после отправки безопасно повторить.
+func (s *service) sendWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) error {
+	reference, err := s.payout.Send(ctx, withdrawal)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		locked, err := s.lockWithdrawal(ctx, repo, withdrawal.ID, models.WithdrawalStatusApproved)
+		if err != nil {
+			return err
+		}
+
+		locked.ProviderReference = reference
+		return s.setWithdrawalStatus(ctx, repo, locked, models.WithdrawalStatusSent, "payout "+reference)
+	})
+}
+
+// trackWithdrawal запрашивает у провайдера статус выплаты и применяет его: при зачислении получателю
+// списывает заблокированные средства, при ошибке снимает блокировку, при возврате выплаченных средств
+// зачисляет их обратно на кошелёк. Возвращает true, если статус вывода изменился.
+func (s *service) trackWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) (bool, error) {
+	status, err := s.payout.GetStatus(ctx, withdrawal.ProviderReference)
+	if err != nil {
+		return false, err
+	}
+
+	var next string
+	switch {
+	case withdrawal.Status == models.WithdrawalStatusSent && status == models.PayoutStatusSettled:
+		next = models.WithdrawalStatusSettled
+	case withdrawal.Status == models.WithdrawalStatusSent && (status == models.PayoutStatusFailed || status == models.PayoutStatusReturned):
+		next = models.WithdrawalStatusFailed
+	case withdrawal.Status == models.WithdrawalStatusSettled && status == models.PayoutStatusReturned:
+		next = models.WithdrawalStatusReturned
+	default:
+		return false, nil
+	}
+
+	err = s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		wallet, err := repo.GetWalletByIDForUpdate(ctx, withdrawal.WalletID)
+		if err != nil {
+			return fmt.Errorf("failed to lock wallet %d: %v", withdrawal.WalletID, err)
+		}
+		locked, err := s.lockWithdrawal(ctx, repo, withdrawal.ID, withdrawal.Status)
+		if err != nil {
+			return err
+		}
+
+		switch next {
+		case models.WithdrawalStatusSettled:
+			return s.settleWithdrawal(ctx, repo, wallet, locked)
+		case models.WithdrawalStatusReturned:
+			return s.returnWithdrawal(ctx, repo, wallet, locked)
+		default:
+			if err := s.releaseWithdrawalHold(ctx, repo, locked); err != nil {
+				return err
+			}
+			locked.FailureReason = "payout " + status
+			return s.setWithdrawalStatus(ctx, repo, locked, models.WithdrawalStatusFailed, "held funds released")
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// settleWithdrawal списывает выплаченную сумму с кошелька. Средства уже переданы получателю,
+// поэтому списание выполняется без проверки заморозки и доступного баланса.
+func (s *service) settleWithdrawal(ctx context.Context, repo repository.Repository, wallet *models.Wallet, withdrawal *models.Withdrawal) error {
+	hold, err := repo.GetHoldByIDForUpdate(ctx, withdrawal.HoldID)
+	if err != nil {
+		return fmt.Errorf("failed to lock hold %d: %v", withdrawal.HoldID, err)
+	}
+	// Резерв снят в обход вывода - средства могли быть потрачены, списывать их повторно нельзя.
+	// Выплата при этом уже у получателя, поэтому вывод не считается ошибочным и ждёт разбора администратором.
+	if hold == nil || hold.Status != models.HoldStatusActive {
+		s.logger.Errorf("Withdrawal %d settled by provider, but hold %d is no longer active", withdrawal.ID, withdrawal.HoldID)
+		withdrawal.FailureReason = "held funds are no longer reserved"
+		return s.setWithdrawalStatus(ctx, repo, withdrawal, models.WithdrawalStatusManualReview, "payout settled without reserved funds")
+	}
+
+	withdrawal.OperationID = uuid.NewString()
+	description := fmt.Sprintf("Withdrawal %d to %s", withdrawal.ID, withdrawal.Destination)
+	if _, err := s.postEntry(ctx, repo, wallet, -withdrawal.Amount, models.TransactionTypeWithdrawal, withdrawal.OperationID, description); err != nil {
+		return err
+	}
+
+	hold.Status = models.HoldStatusCaptured
+	hold.CapturedAmount = withdrawal.Amount
+	if err := repo.UpdateHold(ctx, hold); err != nil {
+		return err
+	}
+
+	return s.setWithdrawalStatus(ctx, repo, withdrawal, models.WithdrawalStatusSettled, "")
+}
+
+// returnWithdrawal зачисляет на кошелёк средства, возвращённые провайдером после выплаты.
+func (s *service) returnWithdrawal(ctx context.Context, repo repository.Repository, wallet *models.Wallet, withdrawal *models.Withdrawal) error {
+	description := fmt.Sprintf("Return of withdrawal %d", withdrawal.ID)
+	if _, err := s.postEntry(ctx, repo, wallet, withdrawal.Amount, models.TransactionTypeWithdrawalReturn, uuid.NewString(), description); err != nil {
+		return err
+	}
+
+	withdrawal.FailureReason = "payout returned"
+	return s.setWithdrawalStatus(ctx, repo, withdrawal, models.WithdrawalStatusReturned, "")
+}
+
+// closeHeldWithdrawal переводит ещё не одобренный вывод в статус status и снимает блокировку средств.
+func (s *service) closeHeldWithdrawal(ctx context.Context, walletID, withdrawalID uint64, status, reason, note string) (*models.Withdrawal, error) {
+	var withdrawal *models.Withdrawal
+	err := s.repo.WithinTransaction(ctx, func(repo repository.Repository) error {
+		// Кошелёк блокируется раньше вывода и блокировки средств, как и при выплате.
+		if _, err := repo.GetWalletByIDForUpdate(ctx, walletID); err != nil {
+			return fmt.Errorf("failed to lock wallet %d: %v", walletID, err)
+		}
+
+		var err error
+		withdrawal, err = s.lockWithdrawal(ctx, repo, withdrawalID, models.WithdrawalStatusHeld)
+		if err != nil {
+			return err
+		}
+		if err := s.releaseWithdrawalHold(ctx, repo, withdrawal); err != nil {
+			return err
+		}
+
+		withdrawal.FailureReason = reason
+		return s.setWithdrawalStatus(ctx, repo, withdrawal, status, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// releaseWithdrawalHold снимает блокировку средств вывода, если она ещё активна.
+func (s *service) releaseWithdrawalHold(ctx context.Context, repo repository.Repository, withdrawal *models.Withdrawal) error {
+	hold, err := repo.GetHoldByIDForUpdate(ctx, withdrawal.HoldID)
+	if err != nil {
+		return fmt.Errorf("failed to lock hold %d: %v", withdrawal.HoldID, err)
+	}
+	if hold == nil || hold.Status != models.HoldStatusActive {
+		return nil
+	}
+
+	hold.Status = models.HoldStatusReleased
+	return repo.UpdateHold(ctx, hold)
+}
+
+// getUserWithdrawal возвращает вывод средств, принадлежащий пользователю.
+func (s *service) getUserWithdrawal(ctx context.Context, userID, withdrawalID uint64) (*models.Withdrawal, error) {
+	withdrawal, err := s.repo.GetWithdrawalByID(ctx, withdrawalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawal: %v", err)
+	}
+	if withdrawal == nil || withdrawal.UserID != userID {
+		return nil, ErrWithdrawalNotFound
+	}
+	return withdrawal, nil
+}
+
+// lockWithdrawal блокирует строку вывода и проверяет, что он находится в статусе status.
+func (s *service) lockWithdrawal(ctx context.Context, repo repository.Repository, withdrawalID uint64, status string) (*models.Withdrawal, error) {
+	withdrawal, err := repo.GetWithdrawalByIDForUpdate(ctx, withdrawalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock withdrawal: %v", err)
+	}
+	if withdrawal == nil {
+		return nil, ErrWithdrawalNotFound
+	}
+	if withdrawal.Status != status {
+		return nil, fmt.Errorf("%w: withdrawal is %s", ErrWithdrawalStateConflict, withdrawal.Status)
+	}
+	return withdrawal, nil
+}
+
+// setWithdrawalStatus сохраняет новый статус вывода и записывает его в историю.
+func (s *service) setWithdrawalStatus(ctx context.Context, repo repository.Repository, withdrawal *models.Withdrawal, status, note string) error {
+	withdrawal.Status = status
+	if err := repo.UpdateWithdrawal(ctx, withdrawal); err != nil {
+		return fmt.Errorf("failed to update withdrawal: %v", err)
+	}
+	return s.recordWithdrawalEvent(ctx, repo, withdrawal.ID, status, note)
+}
+
+func (s *service) recordWithdrawalEvent(ctx context.Context, repo repository.Repository, withdrawalID uint64, status, note string) error {
+	event := &models.WithdrawalEvent{WithdrawalID: withdrawalID, Status: status, Note: note}
+	if err := repo.CreateWithdrawalEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to record withdrawal event: %v", err)
+	}
+	return nil
+}
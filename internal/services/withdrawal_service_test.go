@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePayout - провайдер выплат с заранее заданными статусами выплат.
+type fakePayout struct {
+	statuses map[string]string
+	sent     []uint64
+}
+
+func (p *fakePayout) Name() string { return "fake" }
+
+func (p *fakePayout) Send(_ context.Context, withdrawal *models.Withdrawal) (string, error) {
+	p.sent = append(p.sent, withdrawal.ID)
+	return "payout-1", nil
+}
+
+func (p *fakePayout) GetStatus(_ context.Context, reference string) (string, error) {
+	return p.statuses[reference], nil
+}
+
+func TestRequestWithdrawalHoldsFunds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, payout: &fakePayout{}, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().GetCurrencyByCode(ctx, "USD").Return(&models.Currency{Code: "USD", MinorUnits: 2, Enabled: true}, nil)
+	wallet := &models.Wallet{ID: 3, UserID: 1, Balance: 100, Currency: "USD"}
+	mockRepo.EXPECT().GetWalletByUserAndCurrencyForUpdate(ctx, uint64(1), "USD").Return(wallet, nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uint64(1)).Return(&models.User{ID: 1, FreezeState: models.FreezeStateNone}, nil)
+	mockRepo.EXPECT().GetHeldAmount(ctx, uint64(3)).Return(0.0, nil)
+	mockRepo.EXPECT().GetPottedAmount(ctx, uint64(3)).Return(0.0, nil)
+	mockRepo.EXPECT().GetCreditLine(ctx, uint64(3)).Return(nil, nil)
+	mockRepo.EXPECT().CreateHold(ctx, gomock.Any()).Return(uint64(11), nil)
+	mockRepo.EXPECT().CreateWithdrawal(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, withdrawal *models.Withdrawal) error {
+			withdrawal.ID = 5
+			return nil
+		})
+
+	var statuses []string
+	mockRepo.EXPECT().CreateWithdrawalEvent(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, event *models.WithdrawalEvent) error {
+			statuses = append(statuses, event.Status)
+			return nil
+		}).Times(2)
+
+	withdrawal, err := service.RequestWithdrawal(ctx, 1, &models.WithdrawalRequest{Amount: 40, Currency: "USD", Destination: " DE89 "})
+	assert.NoError(t, err)
+	assert.Equal(t, models.WithdrawalStatusHeld, withdrawal.Status)
+	assert.Equal(t, uint64(11), withdrawal.HoldID)
+	assert.Equal(t, "DE89", withdrawal.Destination)
+	assert.Equal(t, []string{models.WithdrawalStatusRequested, models.WithdrawalStatusHeld}, statuses)
+}
+
+func TestProcessWithdrawalsSettlesAndFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	payout := &fakePayout{statuses: map[string]string{"payout-1": models.PayoutStatusSettled, "payout-2": models.PayoutStatusFailed}}
+	service := &service{repo: mockRepo, payout: payout, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	expectJournalHead(mockRepo)
+	mockRepo.EXPECT().CreateWithdrawalEvent(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	// Одобренный вывод отправляется провайдеру
+	approved := &models.Withdrawal{ID: 1, WalletID: 3, Amount: 40, Status: models.WithdrawalStatusApproved, HoldID: 11}
+	mockRepo.EXPECT().GetWithdrawalsByStatus(ctx, models.WithdrawalStatusApproved, time.Time{}, withdrawalBatchSize).Return([]*models.Withdrawal{approved}, nil)
+	mockRepo.EXPECT().GetWithdrawalByIDForUpdate(ctx, uint64(1)).Return(approved, nil)
+	mockRepo.EXPECT().UpdateWithdrawal(ctx, approved).Return(nil)
+
+	// Выплаченный вывод списывается с кошелька, неуспешный - освобождает блокировку
+	settled := &models.Withdrawal{ID: 2, WalletID: 3, Amount: 40, Status: models.WithdrawalStatusSent, HoldID: 12, ProviderReference: "payout-1"}
+	failed := &models.Withdrawal{ID: 3, WalletID: 4, Amount: 25, Status: models.WithdrawalStatusSent, HoldID: 13, ProviderReference: "payout-2"}
+	mockRepo.EXPECT().GetWithdrawalsByStatus(ctx, models.WithdrawalStatusSent, time.Time{}, withdrawalBatchSize).Return([]*models.Withdrawal{settled, failed}, nil)
+	mockRepo.EXPECT().GetWithdrawalsByStatus(ctx, models.WithdrawalStatusSettled, gomock.Any(), withdrawalBatchSize).Return(nil, nil)
+
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(3)).Return(&models.Wallet{ID: 3, Balance: 100, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWithdrawalByIDForUpdate(ctx, uint64(2)).Return(settled, nil)
+	settledHold := &models.Hold{ID: 12, Amount: 40, Status: models.HoldStatusActive}
+	mockRepo.EXPECT().GetHoldByIDForUpdate(ctx, uint64(12)).Return(settledHold, nil)
+	mockRepo.EXPECT().UpdateWalletBalance(uint64(3), 60.0).Return(nil)
+	mockRepo.EXPECT().CreateTransaction(ctx, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdateHold(ctx, settledHold).Return(nil)
+	mockRepo.EXPECT().UpdateWithdrawal(ctx, settled).Return(nil)
+
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(4)).Return(&models.Wallet{ID: 4, Balance: 50, Currency: "EUR"}, nil)
+	mockRepo.EXPECT().GetWithdrawalByIDForUpdate(ctx, uint64(3)).Return(failed, nil)
+	failedHold := &models.Hold{ID: 13, Amount: 25, Status: models.HoldStatusActive}
+	mockRepo.EXPECT().GetHoldByIDForUpdate(ctx, uint64(13)).Return(failedHold, nil)
+	mockRepo.EXPECT().UpdateHold(ctx, failedHold).Return(nil)
+	mockRepo.EXPECT().UpdateWithdrawal(ctx, failed).Return(nil)
+
+	processed, err := service.ProcessWithdrawals(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, processed)
+	assert.Equal(t, []uint64{1}, payout.sent)
+
+	assert.Equal(t, models.WithdrawalStatusSent, approved.Status)
+	assert.Equal(t, "payout-1", approved.ProviderReference)
+	assert.Equal(t, models.WithdrawalStatusSettled, settled.Status)
+	assert.Equal(t, models.HoldStatusCaptured, settledHold.Status)
+	assert.NotEmpty(t, settled.OperationID)
+	assert.Equal(t, models.WithdrawalStatusFailed, failed.Status)
+	assert.Equal(t, models.HoldStatusReleased, failedHold.Status)
+}
+
+func TestProcessWithdrawalsHoldNotActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	payout := &fakePayout{statuses: map[string]string{"payout-1": models.PayoutStatusSettled}}
+	service := &service{repo: mockRepo, payout: payout, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	mockRepo.EXPECT().CreateWithdrawalEvent(ctx, gomock.Any()).Return(nil).AnyTimes()
+
+	sent := &models.Withdrawal{ID: 2, WalletID: 3, Amount: 40, Status: models.WithdrawalStatusSent, HoldID: 12, ProviderReference: "payout-1"}
+	mockRepo.EXPECT().GetWithdrawalsByStatus(ctx, models.WithdrawalStatusApproved, time.Time{}, withdrawalBatchSize).Return(nil, nil)
+	mockRepo.EXPECT().GetWithdrawalsByStatus(ctx, models.WithdrawalStatusSent, time.Time{}, withdrawalBatchSize).Return([]*models.Withdrawal{sent}, nil)
+	mockRepo.EXPECT().GetWithdrawalsByStatus(ctx, models.WithdrawalStatusSettled, gomock.Any(), withdrawalBatchSize).Return(nil, nil)
+
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(3)).Return(&models.Wallet{ID: 3, Balance: 100, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWithdrawalByIDForUpdate(ctx, uint64(2)).Return(sent, nil)
+	mockRepo.EXPECT().GetHoldByIDForUpdate(ctx, uint64(12)).
+		Return(&models.Hold{ID: 12, Amount: 40, Kind: models.HoldKindWithdrawal, Status: models.HoldStatusExpired}, nil)
+	mockRepo.EXPECT().UpdateWithdrawal(ctx, sent).Return(nil)
+
+	// Без действующего резерва средства не списываются повторно, вывод ждёт разбора администратором
+	processed, err := service.ProcessWithdrawals(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, models.WithdrawalStatusManualReview, sent.Status)
+	assert.Empty(t, sent.OperationID)
+}
+
+func TestCancelApprovedWithdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	service := &service{repo: mockRepo, payout: &fakePayout{}, logger: logrus.New()}
+	ctx := context.Background()
+
+	expectTransaction(mockRepo)
+	withdrawal := &models.Withdrawal{ID: 5, UserID: 1, WalletID: 3, Status: models.WithdrawalStatusApproved}
+	mockRepo.EXPECT().GetWithdrawalByID(ctx, uint64(5)).Return(withdrawal, nil)
+	mockRepo.EXPECT().GetWalletByIDForUpdate(ctx, uint64(3)).Return(&models.Wallet{ID: 3}, nil)
+	mockRepo.EXPECT().GetWithdrawalByIDForUpdate(ctx, uint64(5)).Return(withdrawal, nil)
+
+	// Одобренный вывод может быть уже передан провайдеру, поэтому его нельзя отменить
+	_, err := service.CancelWithdrawal(ctx, 1, 5)
+	assert.ErrorIs(t, err, ErrWithdrawalStateConflict)
+
+	// Чужой вывод не виден пользователю
+	mockRepo.EXPECT().GetWithdrawalByID(ctx, uint64(5)).Return(withdrawal, nil)
+	_, err = service.CancelWithdrawal(ctx, 2, 5)
+	assert.ErrorIs(t, err, ErrWithdrawalNotFound)
+}
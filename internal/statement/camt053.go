@@ -0,0 +1,125 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// camt053Namespace - пространство имён ISO 20022 BankToCustomerStatement.
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtDateTime struct {
+	DateTime string `xml:"DtTm"`
+}
+
+type camtEntry struct {
+	XMLName        xml.Name     `xml:"Ntry"`
+	Reference      string       `xml:"NtryRef"`
+	Amount         camtAmount   `xml:"Amt"`
+	CreditDebit    string       `xml:"CdtDbtInd"`
+	Status         string       `xml:"Sts"`
+	BookingDate    camtDateTime `xml:"BookgDt"`
+	ValueDate      camtDateTime `xml:"ValDt"`
+	BankTxCode     string       `xml:"BkTxCd>Prtry>Cd"`
+	BankTxIssuer   string       `xml:"BkTxCd>Prtry>Issr"`
+	EndToEndID     string       `xml:"NtryDtls>TxDtls>Refs>EndToEndId"`
+	AdditionalInfo string       `xml:"AddtlNtryInf,omitempty"`
+}
+
+type camt053Writer struct {
+	w         io.Writer
+	enc       *xml.Encoder
+	statement *models.Statement
+}
+
+// NewCamt053Writer создаёт выписку в формате ISO 20022 camt.053.001.02.
+func NewCamt053Writer(w io.Writer) Writer {
+	return &camt053Writer{w: w, enc: xml.NewEncoder(w)}
+}
+
+func (c *camt053Writer) Begin(statement *models.Statement) error {
+	c.statement = statement
+	id := fmt.Sprintf("STMT-%d-%s", statement.WalletID, utc(statement.GeneratedAt).Format("20060102150405"))
+	_, err := fmt.Fprintf(c.w, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="%s">
+<BkToCstmrStmt>
+<GrpHdr><MsgId>%s</MsgId><CreDtTm>%s</CreDtTm></GrpHdr>
+<Stmt>
+<Id>%s</Id><CreDtTm>%s</CreDtTm>
+<FrToDt><FrDtTm>%s</FrDtTm><ToDtTm>%s</ToDtTm></FrToDt>
+<Acct><Id><Othr><Id>%d</Id></Othr></Id><Ccy>%s</Ccy></Acct>
+%s
+%s
+`,
+		camt053Namespace,
+		id, camtDateTimeString(statement.GeneratedAt),
+		id, camtDateTimeString(statement.GeneratedAt),
+		camtDateTimeString(statement.From), camtDateTimeString(statement.To),
+		statement.WalletID, escape(statement.Currency),
+		camtBalance("OPBD", statement.OpeningBalance, statement.Currency, statement.From),
+		camtBalance("CLBD", statement.ClosingBalance, statement.Currency, statement.To),
+	)
+	return err
+}
+
+func (c *camt053Writer) Entry(transaction *models.Transaction) error {
+	booked := camtDateTimeString(transaction.CreatedAt)
+	if err := c.enc.Encode(camtEntry{
+		Reference:      strconv.FormatUint(transaction.ID, 10),
+		Amount:         camtAmount{Currency: c.statement.Currency, Value: formatAmount(abs(transaction.Amount))},
+		CreditDebit:    creditDebit(transaction.Amount),
+		Status:         "BOOK",
+		BookingDate:    camtDateTime{DateTime: booked},
+		ValueDate:      camtDateTime{DateTime: booked},
+		BankTxCode:     transaction.Type,
+		BankTxIssuer:   ofxBankID,
+		EndToEndID:     transaction.OperationID,
+		AdditionalInfo: transaction.Description,
+	}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(c.w, "\n")
+	return err
+}
+
+func (c *camt053Writer) End() error {
+	_, err := io.WriteString(c.w, "</Stmt>\n</BkToCstmrStmt>\n</Document>\n")
+	return err
+}
+
+func camtBalance(code string, amount float64, currency string, at time.Time) string {
+	return fmt.Sprintf(
+		`<Bal><Tp><CdOrPrtry><Cd>%s</Cd></CdOrPrtry></Tp><Amt Ccy="%s">%s</Amt><CdtDbtInd>%s</CdtDbtInd><Dt><Dt>%s</Dt></Dt></Bal>`,
+		code, escape(currency), formatAmount(abs(amount)), creditDebit(amount), utc(at).Format("2006-01-02"),
+	)
+}
+
+func camtDateTimeString(t time.Time) string {
+	return utc(t).Format("2006-01-02T15:04:05Z")
+}
+
+// creditDebit возвращает индикатор CRDT для неотрицательных сумм и DBIT для отрицательных.
+func creditDebit(amount float64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// escape экранирует строку для вставки в XML.
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,55 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+type csvWriter struct {
+	w         *csv.Writer
+	statement *models.Statement
+}
+
+// NewCSVWriter создаёт выписку в формате CSV: строка входящего остатка, проводки и строка исходящего остатка.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Begin(statement *models.Statement) error {
+	c.statement = statement
+	if err := c.w.Write([]string{"date", "transaction_id", "operation_id", "type", "description", "amount", "currency", "balance"}); err != nil {
+		return err
+	}
+	return c.w.Write([]string{
+		utc(statement.From).Format(time.RFC3339), "", "", "opening_balance", "Opening balance",
+		"", statement.Currency, formatAmount(statement.OpeningBalance),
+	})
+}
+
+func (c *csvWriter) Entry(transaction *models.Transaction) error {
+	return c.w.Write([]string{
+		utc(transaction.CreatedAt).Format(time.RFC3339),
+		strconv.FormatUint(transaction.ID, 10),
+		transaction.OperationID,
+		transaction.Type,
+		transaction.Description,
+		formatAmount(transaction.Amount),
+		c.statement.Currency,
+		formatAmount(transaction.BalanceAfter),
+	})
+}
+
+func (c *csvWriter) End() error {
+	if err := c.w.Write([]string{
+		utc(c.statement.To).Format(time.RFC3339), "", "", "closing_balance", "Closing balance",
+		"", c.statement.Currency, formatAmount(c.statement.ClosingBalance),
+	}); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
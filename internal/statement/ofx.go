@@ -0,0 +1,96 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// ofxBankID - идентификатор учреждения в выписках OFX.
+const ofxBankID = "GWWALLET"
+
+const ofxDateLayout = "20060102150405"
+
+type ofxTransaction struct {
+	XMLName xml.Name `xml:"STMTTRN"`
+	Type    string   `xml:"TRNTYPE"`
+	Posted  string   `xml:"DTPOSTED"`
+	Amount  string   `xml:"TRNAMT"`
+	FITID   string   `xml:"FITID"`
+	Name    string   `xml:"NAME"`
+	Memo    string   `xml:"MEMO,omitempty"`
+	RefNum  string   `xml:"REFNUM,omitempty"`
+}
+
+type ofxWriter struct {
+	w         io.Writer
+	enc       *xml.Encoder
+	statement *models.Statement
+}
+
+// NewOFXWriter создаёт выписку в формате OFX 2.2 (XML).
+func NewOFXWriter(w io.Writer) Writer {
+	return &ofxWriter{w: w, enc: xml.NewEncoder(w)}
+}
+
+func (o *ofxWriter) Begin(statement *models.Statement) error {
+	o.statement = statement
+	_, err := fmt.Fprintf(o.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>%d</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>%s</BANKID><ACCTID>%d</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`,
+		ofxDate(statement.GeneratedAt),
+		statement.GeneratedAt.Unix(),
+		escape(statement.Currency),
+		ofxBankID,
+		statement.WalletID,
+		ofxDate(statement.From),
+		ofxDate(statement.To),
+	)
+	return err
+}
+
+func (o *ofxWriter) Entry(transaction *models.Transaction) error {
+	trnType := "CREDIT"
+	if transaction.Amount < 0 {
+		trnType = "DEBIT"
+	}
+	if err := o.enc.Encode(ofxTransaction{
+		Type:   trnType,
+		Posted: ofxDate(transaction.CreatedAt),
+		Amount: formatAmount(transaction.Amount),
+		FITID:  strconv.FormatUint(transaction.ID, 10),
+		Name:   transaction.Type,
+		Memo:   transaction.Description,
+		RefNum: transaction.OperationID,
+	}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(o.w, "\n")
+	return err
+}
+
+func (o *ofxWriter) End() error {
+	_, err := fmt.Fprintf(o.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`,
+		formatAmount(o.statement.ClosingBalance),
+		ofxDate(o.statement.To),
+	)
+	return err
+}
+
+func ofxDate(t time.Time) string {
+	return utc(t).Format(ofxDateLayout)
+}
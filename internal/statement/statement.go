@@ -0,0 +1,65 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+)
+
+// Поддерживаемые форматы выписок.
+const (
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCamt053 = "camt053"
+)
+
+// Writer последовательно записывает выписку: заголовок, проводки по одной и завершение.
+// Реализации не накапливают проводки в памяти, поэтому подходят для потоковой выгрузки.
+type Writer interface {
+	Begin(statement *models.Statement) error
+	Entry(transaction *models.Transaction) error
+	End() error
+}
+
+// Format описывает формат выписки для HTTP-ответа.
+type Format struct {
+	ContentType string
+	Extension   string
+	New         func(w io.Writer) Writer
+}
+
+var formats = map[string]Format{
+	FormatCSV:     {ContentType: "text/csv; charset=utf-8", Extension: "csv", New: NewCSVWriter},
+	FormatOFX:     {ContentType: "application/x-ofx", Extension: "ofx", New: NewOFXWriter},
+	FormatCamt053: {ContentType: "application/xml", Extension: "xml", New: NewCamt053Writer},
+}
+
+// Lookup возвращает описание формата по имени.
+func Lookup(name string) (Format, error) {
+	format, ok := formats[name]
+	if !ok {
+		return Format{}, fmt.Errorf("unsupported statement format %q", name)
+	}
+	return format, nil
+}
+
+// formatAmount форматирует сумму с двумя знаками после запятой.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// abs возвращает модуль суммы.
+func abs(amount float64) float64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
+
+// utc приводит время к UTC для единообразного представления в выписках.
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
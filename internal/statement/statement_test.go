@@ -0,0 +1,81 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VadimBorzenkov/gw-currency-wallet/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testStatement() (*models.Statement, []*models.Transaction) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	st := &models.Statement{
+		WalletID:       5,
+		Currency:       "USD",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 100,
+		ClosingBalance: 70.5,
+		GeneratedAt:    from.AddDate(0, 1, 1),
+	}
+	transactions := []*models.Transaction{
+		{ID: 1, WalletID: 5, OperationID: "op-1", Type: models.TransactionTypeDeposit, Amount: 20, BalanceAfter: 120, Description: "Deposit", CreatedAt: from.Add(time.Hour)},
+		{ID: 2, WalletID: 5, OperationID: "op-2", Type: models.TransactionTypeWithdrawal, Amount: -49.5, BalanceAfter: 70.5, Description: "Rent & <utilities>", CreatedAt: from.Add(48 * time.Hour)},
+	}
+	return st, transactions
+}
+
+func write(t *testing.T, format string) string {
+	f, err := Lookup(format)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	st, transactions := testStatement()
+	w := f.New(&buf)
+	assert.NoError(t, w.Begin(st))
+	for _, transaction := range transactions {
+		assert.NoError(t, w.Entry(transaction))
+	}
+	assert.NoError(t, w.End())
+	return buf.String()
+}
+
+func TestCSVWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(write(t, FormatCSV)), "\n")
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "2024-01-01T00:00:00Z,,,opening_balance,Opening balance,,USD,100.00", lines[1])
+	assert.Equal(t, "2024-01-03T00:00:00Z,2,op-2,withdrawal,Rent & <utilities>,-49.50,USD,70.50", lines[3])
+	assert.Equal(t, "2024-02-01T00:00:00Z,,,closing_balance,Closing balance,,USD,70.50", lines[4])
+}
+
+func TestXMLWritersProduceWellFormedDocuments(t *testing.T) {
+	for _, format := range []string{FormatOFX, FormatCamt053} {
+		out := write(t, format)
+		decoder := xml.NewDecoder(strings.NewReader(out))
+		for {
+			_, err := decoder.Token()
+			if err != nil {
+				assert.Equal(t, "EOF", err.Error(), format)
+				break
+			}
+		}
+		assert.Contains(t, out, "Rent &amp; &lt;utilities&gt;", format)
+	}
+
+	camt := write(t, FormatCamt053)
+	assert.Contains(t, camt, `<Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="USD">100.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>`)
+	assert.Contains(t, camt, `<Amt Ccy="USD">49.50</Amt><CdtDbtInd>DBIT</CdtDbtInd>`)
+
+	ofx := write(t, FormatOFX)
+	assert.Contains(t, ofx, "<TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20240103000000</DTPOSTED><TRNAMT>-49.50</TRNAMT>")
+	assert.Contains(t, ofx, "<LEDGERBAL><BALAMT>70.50</BALAMT>")
+}
+
+func TestLookupUnknownFormat(t *testing.T) {
+	_, err := Lookup("pdf")
+	assert.Error(t, err)
+}
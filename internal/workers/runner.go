@@ -0,0 +1,70 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job описывает периодическую фоновую задачу.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner запускает фоновые задачи с заданным интервалом до отмены контекста.
+type Runner struct {
+	jobs   []Job
+	logger *logrus.Logger
+	wg     sync.WaitGroup
+}
+
+// NewRunner создаёт новый планировщик фоновых задач.
+func NewRunner(logger *logrus.Logger) *Runner {
+	return &Runner{logger: logger}
+}
+
+// Add регистрирует задачу. Задачи с неположительным интервалом не запускаются.
+func (r *Runner) Add(job Job) {
+	if job.Interval <= 0 {
+		r.logger.Warnf("Job %s is disabled: non-positive interval", job.Name)
+		return
+	}
+	r.jobs = append(r.jobs, job)
+}
+
+// Start запускает все зарегистрированные задачи в отдельных горутинах.
+func (r *Runner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		go r.loop(ctx, job)
+	}
+}
+
+// Wait ожидает завершения всех задач после отмены контекста.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	r.logger.Infof("Starting job %s with interval %s", job.Name, job.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Infof("Stopping job %s", job.Name)
+			return
+		case <-ticker.C:
+			if err := job.Run(ctx); err != nil {
+				r.logger.Errorf("Job %s failed: %v", job.Name, err)
+			}
+		}
+	}
+}
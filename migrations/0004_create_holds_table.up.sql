@@ -0,0 +1,14 @@
+CREATE TABLE holds (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0),
+    captured_amount NUMERIC(18, 2) NOT NULL DEFAULT 0.00,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_holds_wallet_status ON holds (wallet_id, status);
+CREATE INDEX idx_holds_active_expires_at ON holds (expires_at) WHERE status = 'active';
@@ -0,0 +1,37 @@
+CREATE TABLE scheduled_transfers (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    from_currency VARCHAR(10) NOT NULL,
+    to_currency VARCHAR(10) NOT NULL,
+    to_user_id INT REFERENCES users (id) ON DELETE CASCADE,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0),
+    kind VARCHAR(20) NOT NULL,
+    cron_expr VARCHAR(100) NOT NULL DEFAULT '',
+    interval_seconds BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    next_run_at TIMESTAMP,
+    last_run_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scheduled_transfers_user_id ON scheduled_transfers (user_id);
+CREATE INDEX idx_scheduled_transfers_due ON scheduled_transfers (next_run_at) WHERE status = 'active';
+
+CREATE TABLE scheduled_transfer_executions (
+    id SERIAL PRIMARY KEY,
+    schedule_id INT NOT NULL REFERENCES scheduled_transfers (id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    amount NUMERIC(18, 2) NOT NULL,
+    exchanged_amount NUMERIC(18, 2) NOT NULL DEFAULT 0.00,
+    scheduled_for TIMESTAMP NOT NULL,
+    executed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scheduled_transfer_executions_schedule_id ON scheduled_transfer_executions (schedule_id);
+-- Время запуска по расписанию уникально для операции: один запуск не выполняется дважды.
+CREATE UNIQUE INDEX idx_scheduled_transfer_executions_run
+    ON scheduled_transfer_executions (schedule_id, scheduled_for);
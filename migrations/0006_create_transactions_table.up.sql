@@ -0,0 +1,19 @@
+CREATE TABLE transactions (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    operation_id VARCHAR(36) NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    amount NUMERIC(18, 2) NOT NULL,
+    balance_after NUMERIC(18, 2) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transactions_wallet_created_at ON transactions (wallet_id, created_at, id);
+CREATE INDEX idx_transactions_operation_id ON transactions (operation_id);
+
+-- Балансы, накопленные до появления журнала, фиксируются входящим остатком.
+INSERT INTO transactions (wallet_id, operation_id, type, amount, balance_after, description)
+SELECT id, md5(random()::text || id::text), 'opening_balance', balance, balance, 'Balance before transaction journal'
+FROM wallets
+WHERE balance <> 0;
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS fk_wallets_currency;
+DROP TABLE IF EXISTS currencies;
@@ -0,0 +1,26 @@
+CREATE TABLE currencies (
+    code VARCHAR(10) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    minor_units SMALLINT NOT NULL DEFAULT 2 CHECK (minor_units BETWEEN 0 AND 2),
+    symbol VARCHAR(10) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO currencies (code, name, minor_units, symbol, enabled) VALUES
+    ('USD', 'US Dollar', 2, '$', TRUE),
+    ('EUR', 'Euro', 2, '€', TRUE),
+    ('RUB', 'Russian Ruble', 2, '₽', TRUE);
+
+-- Валюты существующих кошельков, отсутствующие в справочнике, добавляются отключёнными.
+INSERT INTO currencies (code, name, enabled)
+SELECT DISTINCT currency, currency, FALSE
+FROM wallets
+WHERE currency NOT IN (SELECT code FROM currencies);
+
+ALTER TABLE wallets
+    ADD CONSTRAINT fk_wallets_currency FOREIGN KEY (currency) REFERENCES currencies (code);
+
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
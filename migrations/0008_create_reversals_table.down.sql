@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reversals;
+DROP INDEX IF EXISTS idx_transactions_reversed_transaction_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversed_transaction_id;
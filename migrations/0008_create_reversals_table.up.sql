@@ -0,0 +1,15 @@
+ALTER TABLE transactions
+    ADD COLUMN reversed_transaction_id INT REFERENCES transactions (id);
+
+CREATE UNIQUE INDEX idx_transactions_reversed_transaction_id
+    ON transactions (reversed_transaction_id)
+    WHERE reversed_transaction_id IS NOT NULL;
+
+CREATE TABLE reversals (
+    id SERIAL PRIMARY KEY,
+    original_operation_id VARCHAR(36) NOT NULL UNIQUE,
+    reversal_operation_id VARCHAR(36) NOT NULL UNIQUE,
+    operator_id INT NOT NULL REFERENCES users (id),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
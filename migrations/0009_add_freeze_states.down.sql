@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS freeze_events;
+ALTER TABLE wallets DROP COLUMN IF EXISTS freeze_state;
+ALTER TABLE users DROP COLUMN IF EXISTS freeze_state;
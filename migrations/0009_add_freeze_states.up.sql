@@ -0,0 +1,20 @@
+ALTER TABLE users
+    ADD COLUMN freeze_state VARCHAR(10) NOT NULL DEFAULT 'none'
+        CHECK (freeze_state IN ('none', 'debit', 'full'));
+
+ALTER TABLE wallets
+    ADD COLUMN freeze_state VARCHAR(10) NOT NULL DEFAULT 'none'
+        CHECK (freeze_state IN ('none', 'debit', 'full'));
+
+CREATE TABLE freeze_events (
+    id SERIAL PRIMARY KEY,
+    target_type VARCHAR(10) NOT NULL CHECK (target_type IN ('user', 'wallet')),
+    target_id INT NOT NULL,
+    previous_state VARCHAR(10) NOT NULL,
+    state VARCHAR(10) NOT NULL,
+    reason TEXT NOT NULL,
+    operator_id INT NOT NULL REFERENCES users (id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_freeze_events_target ON freeze_events (target_type, target_id, created_at);
@@ -0,0 +1,20 @@
+CREATE TABLE limit_orders (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    from_currency VARCHAR(10) NOT NULL,
+    to_currency VARCHAR(10) NOT NULL,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0),
+    target_rate NUMERIC(18, 8) NOT NULL CHECK (target_rate > 0),
+    hold_id INT NOT NULL REFERENCES holds (id),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    filled_rate NUMERIC(18, 8) NOT NULL DEFAULT 0,
+    filled_amount NUMERIC(18, 2) NOT NULL DEFAULT 0.00,
+    operation_id VARCHAR(36) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    filled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_limit_orders_user_id ON limit_orders (user_id);
+CREATE INDEX idx_limit_orders_open ON limit_orders (expires_at) WHERE status = 'open';
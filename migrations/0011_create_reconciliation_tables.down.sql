@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS balance_snapshots;
+DROP TABLE IF EXISTS reconciliation_runs;
@@ -0,0 +1,22 @@
+CREATE TABLE reconciliation_runs (
+    id SERIAL PRIMARY KEY,
+    snapshot_date DATE NOT NULL UNIQUE,
+    wallets_checked INT NOT NULL DEFAULT 0,
+    mismatches INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE balance_snapshots (
+    id SERIAL PRIMARY KEY,
+    run_id INT NOT NULL REFERENCES reconciliation_runs (id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    balance NUMERIC(18, 2) NOT NULL,
+    computed_balance NUMERIC(18, 2) NOT NULL,
+    difference NUMERIC(18, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (wallet_id, snapshot_date)
+);
+
+CREATE INDEX idx_balance_snapshots_run_status ON balance_snapshots (run_id, status);
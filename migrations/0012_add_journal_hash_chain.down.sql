@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS journal_checkpoints;
+DROP INDEX IF EXISTS idx_transactions_wallet_id_id;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash;
@@ -0,0 +1,53 @@
+ALTER TABLE transactions
+    ADD COLUMN prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN hash VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_transactions_wallet_id_id ON transactions (wallet_id, id);
+
+-- Цепочка хешей для уже существующих проводок. Формат содержимого совпадает с journal.Hash:
+-- поля с префиксом длины в байтах, суммы с двумя знаками, время в UTC с микросекундами.
+DO $$
+DECLARE
+    entry RECORD;
+    fields TEXT[];
+    content TEXT;
+    field TEXT;
+    prev TEXT := '';
+    current_wallet INT := NULL;
+    entry_hash TEXT;
+BEGIN
+    FOR entry IN SELECT * FROM transactions ORDER BY wallet_id, id LOOP
+        IF current_wallet IS DISTINCT FROM entry.wallet_id THEN
+            prev := '';
+            current_wallet := entry.wallet_id;
+        END IF;
+
+        fields := ARRAY[
+            prev,
+            entry.wallet_id::TEXT,
+            entry.operation_id,
+            entry.type,
+            entry.amount::TEXT,
+            entry.balance_after::TEXT,
+            entry.description,
+            COALESCE(entry.reversed_transaction_id::TEXT, ''),
+            to_char(entry.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+        ];
+        content := '';
+        FOREACH field IN ARRAY fields LOOP
+            content := content || octet_length(field) || ':' || field;
+        END LOOP;
+
+        entry_hash := encode(sha256(convert_to(content, 'UTF8')), 'hex');
+        UPDATE transactions SET prev_hash = prev, hash = entry_hash WHERE id = entry.id;
+        prev := entry_hash;
+    END LOOP;
+END $$;
+
+CREATE TABLE journal_checkpoints (
+    id SERIAL PRIMARY KEY,
+    last_transaction_id INT NOT NULL,
+    wallets INT NOT NULL,
+    digest VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
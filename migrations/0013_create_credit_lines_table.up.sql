@@ -0,0 +1,11 @@
+CREATE TABLE credit_lines (
+    wallet_id INT PRIMARY KEY REFERENCES wallets (id) ON DELETE CASCADE,
+    credit_limit NUMERIC(18, 2) NOT NULL CHECK (credit_limit >= 0),
+    annual_rate NUMERIC(9, 6) NOT NULL DEFAULT 0 CHECK (annual_rate >= 0),
+    daily_fee NUMERIC(18, 2) NOT NULL DEFAULT 0.00 CHECK (daily_fee >= 0),
+    last_accrued_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_credit_lines_last_accrued_on ON credit_lines (last_accrued_on);
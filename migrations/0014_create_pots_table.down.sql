@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pot_movements;
+DROP TABLE IF EXISTS pots;
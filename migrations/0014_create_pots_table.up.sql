@@ -0,0 +1,24 @@
+CREATE TABLE pots (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    goal_amount NUMERIC(18, 2) NOT NULL DEFAULT 0.00 CHECK (goal_amount >= 0),
+    goal_date DATE,
+    balance NUMERIC(18, 2) NOT NULL DEFAULT 0.00 CHECK (balance >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMP
+);
+
+CREATE INDEX idx_pots_wallet_status ON pots (wallet_id, status);
+
+CREATE TABLE pot_movements (
+    id SERIAL PRIMARY KEY,
+    pot_id INT NOT NULL REFERENCES pots (id) ON DELETE CASCADE,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount <> 0),
+    balance_after NUMERIC(18, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pot_movements_pot_id ON pot_movements (pot_id, id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_initiated_by;
+ALTER TABLE transactions DROP COLUMN IF EXISTS initiated_by;
+DROP TABLE IF EXISTS wallet_members;
@@ -0,0 +1,22 @@
+-- Владелец кошелька (wallets.user_id) имеет полный доступ неявно; здесь хранятся
+-- остальные участники совместного кошелька и приглашения.
+CREATE TABLE wallet_members (
+    wallet_id INT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'spender', 'viewer')),
+    spend_limit NUMERIC(18, 2) NOT NULL DEFAULT 0.00 CHECK (spend_limit >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'invited' CHECK (status IN ('invited', 'active')),
+    invited_by INT NOT NULL REFERENCES users (id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    accepted_at TIMESTAMP,
+    PRIMARY KEY (wallet_id, user_id)
+);
+
+CREATE INDEX idx_wallet_members_user_id ON wallet_members (user_id, status);
+
+-- Пользователь, выполнивший операцию. Для совместных кошельков по нему считаются
+-- списания участника с ролью spender в пределах его лимита.
+ALTER TABLE transactions ADD COLUMN initiated_by INT REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_initiated_by ON transactions (wallet_id, initiated_by, created_at)
+    WHERE initiated_by IS NOT NULL;
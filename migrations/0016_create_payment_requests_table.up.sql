@@ -0,0 +1,22 @@
+CREATE TABLE payment_requests (
+    id SERIAL PRIMARY KEY,
+    requester_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    payer_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    paid_currency VARCHAR(10) NOT NULL DEFAULT '',
+    paid_amount NUMERIC(18, 2) NOT NULL DEFAULT 0.00,
+    rate NUMERIC(18, 8) NOT NULL DEFAULT 0,
+    operation_id VARCHAR(36) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (requester_id <> payer_id)
+);
+
+CREATE INDEX idx_payment_requests_requester_id ON payment_requests (requester_id);
+CREATE INDEX idx_payment_requests_payer_id ON payment_requests (payer_id);
+CREATE INDEX idx_payment_requests_pending ON payment_requests (expires_at) WHERE status = 'pending';
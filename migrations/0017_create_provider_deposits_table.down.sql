@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS provider_deposits;
+DROP INDEX IF EXISTS idx_users_deposit_reference;
+ALTER TABLE users DROP COLUMN IF EXISTS deposit_reference;
@@ -0,0 +1,27 @@
+-- Код для сопоставления входящих пополнений от провайдеров с пользователем.
+-- Значение по умолчанию вычисляется для каждой строки, поэтому существующие пользователи тоже получают код.
+ALTER TABLE users
+    ADD COLUMN deposit_reference VARCHAR(20) NOT NULL
+        DEFAULT 'GW' || upper(substr(md5(random()::text || clock_timestamp()::text), 1, 10));
+
+CREATE UNIQUE INDEX idx_users_deposit_reference ON users (deposit_reference);
+
+CREATE TABLE provider_deposits (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(100) NOT NULL,
+    reference VARCHAR(100) NOT NULL DEFAULT '',
+    amount NUMERIC(18, 2) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'received'
+        CHECK (status IN ('received', 'credited', 'unmatched', 'rejected')),
+    user_id INT REFERENCES users (id) ON DELETE SET NULL,
+    wallet_id INT REFERENCES wallets (id) ON DELETE SET NULL,
+    operation_id VARCHAR(36) NOT NULL DEFAULT '',
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, event_id)
+);
+
+CREATE INDEX idx_provider_deposits_status ON provider_deposits (status, created_at);
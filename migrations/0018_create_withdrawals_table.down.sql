@@ -0,0 +1,3 @@
+ALTER TABLE holds DROP COLUMN IF EXISTS kind;
+DROP TABLE IF EXISTS withdrawal_events;
+DROP TABLE IF EXISTS withdrawals;
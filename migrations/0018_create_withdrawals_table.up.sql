@@ -0,0 +1,36 @@
+CREATE TABLE withdrawals (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    destination VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('requested', 'held', 'approved', 'sent', 'settled', 'failed', 'returned', 'cancelled')),
+    hold_id INT NOT NULL REFERENCES holds (id),
+    provider VARCHAR(50) NOT NULL,
+    provider_reference VARCHAR(100) NOT NULL DEFAULT '',
+    operation_id VARCHAR(36) NOT NULL DEFAULT '',
+    failure_reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_withdrawals_user_id ON withdrawals (user_id, created_at);
+CREATE INDEX idx_withdrawals_status ON withdrawals (status, updated_at);
+
+CREATE TABLE withdrawal_events (
+    id SERIAL PRIMARY KEY,
+    withdrawal_id INT NOT NULL REFERENCES withdrawals (id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_withdrawal_events_withdrawal_id ON withdrawal_events (withdrawal_id, id);
+
+-- Вид блокировки. Блокировки выводов и лимитных заявок создаёт и снимает сам сервис,
+-- через API блокировок доступны только блокировки вида user.
+ALTER TABLE holds ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'user';
+
+UPDATE holds SET kind = 'limit_order' WHERE id IN (SELECT hold_id FROM limit_orders);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rebalance_legs;
+DROP TABLE IF EXISTS rebalance_plans;
@@ -0,0 +1,26 @@
+CREATE TABLE rebalance_plans (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    base VARCHAR(10) NOT NULL,
+    total NUMERIC(18, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'executed')),
+    operation_id VARCHAR(36) NOT NULL DEFAULT '',
+    rates_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    executed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rebalance_plans_user_id ON rebalance_plans (user_id, created_at);
+
+CREATE TABLE rebalance_legs (
+    id SERIAL PRIMARY KEY,
+    plan_id INT NOT NULL REFERENCES rebalance_plans (id) ON DELETE CASCADE,
+    from_currency VARCHAR(10) NOT NULL,
+    to_currency VARCHAR(10) NOT NULL,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0),
+    rate NUMERIC(18, 8) NOT NULL,
+    exchanged_amount NUMERIC(18, 2) NOT NULL CHECK (exchanged_amount > 0)
+);
+
+CREATE INDEX idx_rebalance_legs_plan_id ON rebalance_legs (plan_id, id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS interest_accruals;
+ALTER TABLE currencies DROP COLUMN IF EXISTS interest_rate;
@@ -0,0 +1,15 @@
+ALTER TABLE currencies ADD COLUMN interest_rate NUMERIC(9, 6) NOT NULL DEFAULT 0 CHECK (interest_rate >= 0 AND interest_rate <= 1);
+
+CREATE TABLE interest_accruals (
+    wallet_id INT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    accrual_date DATE NOT NULL,
+    balance NUMERIC(18, 2) NOT NULL,
+    annual_rate NUMERIC(9, 6) NOT NULL,
+    amount NUMERIC(18, 8) NOT NULL DEFAULT 0,
+    operation_id VARCHAR(36) NOT NULL DEFAULT '',
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (wallet_id, accrual_date)
+);
+
+CREATE INDEX idx_interest_accruals_unpaid ON interest_accruals (wallet_id, accrual_date) WHERE paid_at IS NULL;
@@ -0,0 +1,32 @@
+CREATE TABLE voucher_batches (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    amount NUMERIC(18, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    max_redemptions INT NOT NULL CHECK (max_redemptions > 0),
+    expires_at TIMESTAMP NOT NULL,
+    created_by INT NOT NULL REFERENCES users (id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE vouchers (
+    id SERIAL PRIMARY KEY,
+    batch_id INT NOT NULL REFERENCES voucher_batches (id) ON DELETE CASCADE,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    redemptions INT NOT NULL DEFAULT 0 CHECK (redemptions >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vouchers_batch_id ON vouchers (batch_id);
+
+CREATE TABLE voucher_redemptions (
+    id SERIAL PRIMARY KEY,
+    voucher_id INT NOT NULL REFERENCES vouchers (id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    amount NUMERIC(18, 2) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    operation_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (voucher_id, user_id)
+);
@@ -0,0 +1,26 @@
+DROP INDEX IF EXISTS idx_vouchers_tenant_code;
+ALTER TABLE vouchers ADD CONSTRAINT vouchers_code_key UNIQUE (code);
+ALTER TABLE vouchers DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE voucher_batches DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE provider_deposits DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS fk_wallets_currency;
+ALTER TABLE wallets DROP COLUMN IF EXISTS tenant_id;
+
+DELETE FROM currencies WHERE tenant_id <> 1;
+ALTER TABLE currencies DROP CONSTRAINT currencies_pkey;
+ALTER TABLE currencies DROP COLUMN IF EXISTS max_amount;
+ALTER TABLE currencies DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE currencies ADD PRIMARY KEY (code);
+ALTER TABLE wallets
+    ADD CONSTRAINT fk_wallets_currency FOREIGN KEY (currency) REFERENCES currencies (code);
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS idx_users_tenant_username;
+DROP INDEX IF EXISTS idx_users_tenant_email;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
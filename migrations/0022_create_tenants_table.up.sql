@@ -0,0 +1,43 @@
+-- Тенант - бренд, для которого работает сервис. Тенант запроса определяется по заголовку
+-- X-Tenant-ID (slug) или по хосту. Ключи JWT задаются путями к PEM-файлам; если не заданы,
+-- используются ключи из конфигурации сервиса.
+CREATE TABLE tenants (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    host VARCHAR(255) UNIQUE,
+    jwt_public_key_path VARCHAR(255) NOT NULL DEFAULT '',
+    jwt_private_key_path VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Существующие данные принадлежат тенанту по умолчанию (tenant.DefaultID).
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', 'Default');
+SELECT setval('tenants_id_seq', (SELECT MAX(id) FROM tenants));
+
+ALTER TABLE users ADD COLUMN tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+CREATE UNIQUE INDEX idx_users_tenant_email ON users (tenant_id, email);
+CREATE INDEX idx_users_tenant_username ON users (tenant_id, username);
+
+ALTER TABLE refresh_tokens ADD COLUMN tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+
+-- Справочник валют настраивается для каждого тенанта отдельно.
+ALTER TABLE currencies ADD COLUMN tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+-- Лимит суммы одной операции в валюте; 0 - без ограничения.
+ALTER TABLE currencies ADD COLUMN max_amount NUMERIC(18, 2) NOT NULL DEFAULT 0 CHECK (max_amount >= 0);
+ALTER TABLE wallets DROP CONSTRAINT fk_wallets_currency;
+ALTER TABLE currencies DROP CONSTRAINT currencies_pkey;
+ALTER TABLE currencies ADD PRIMARY KEY (tenant_id, code);
+
+ALTER TABLE wallets ADD COLUMN tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE wallets
+    ADD CONSTRAINT fk_wallets_currency FOREIGN KEY (tenant_id, currency) REFERENCES currencies (tenant_id, code);
+
+ALTER TABLE provider_deposits ADD COLUMN tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE voucher_batches ADD COLUMN tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+
+-- Коды ваучеров уникальны в пределах тенанта.
+ALTER TABLE vouchers ADD COLUMN tenant_id INT NOT NULL DEFAULT 1 REFERENCES tenants (id);
+ALTER TABLE vouchers DROP CONSTRAINT vouchers_code_key;
+CREATE UNIQUE INDEX idx_vouchers_tenant_code ON vouchers (tenant_id, code);
@@ -0,0 +1,3 @@
+-- Версия кошелька увеличивается при каждом изменении строки и служит ETag
+-- для оптимистичной проверки конкурентных изменений (If-Match).
+ALTER TABLE wallets ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,18 @@
+-- Исходящие доменные события. Событие записывается в той же транзакции, что и изменение
+-- баланса, и публикуется фоновой задачей; published_at заполняется после успешной публикации.
+-- sequence нумерует события кошелька подряд, начиная с 1.
+CREATE TABLE outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL REFERENCES tenants (id),
+    wallet_id INT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    sequence BIGINT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP,
+    UNIQUE (wallet_id, sequence)
+);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events (id) WHERE published_at IS NULL;
@@ -0,0 +1,32 @@
+-- Настройки уведомлений пользователя по типам событий. Для типов без записи действуют
+-- настройки по умолчанию; threshold - минимальная сумма вывода для large_withdrawal.
+CREATE TABLE notification_preferences (
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    in_app BOOLEAN NOT NULL,
+    email BOOLEAN NOT NULL,
+    threshold NUMERIC(18, 2) NOT NULL DEFAULT 0.00 CHECK (threshold >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, event_type)
+);
+
+-- Уведомления пользователей. in_app - показывается во входящих; письмо отправляется фоновой
+-- задачей, пока email_status = 'pending', с повторами до next_attempt_at.
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INT NOT NULL REFERENCES tenants (id),
+    user_id INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    in_app BOOLEAN NOT NULL,
+    email_status VARCHAR(20) NOT NULL CHECK (email_status IN ('pending', 'sent', 'failed', 'skipped')),
+    email_attempts INT NOT NULL DEFAULT 0,
+    email_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user ON notifications (user_id, event_type, id);
+CREATE INDEX idx_notifications_email_due ON notifications (next_attempt_at) WHERE email_status = 'pending';
@@ -0,0 +1,4 @@
+UPDATE withdrawals SET status = 'failed' WHERE status = 'manual_review';
+ALTER TABLE withdrawals DROP CONSTRAINT withdrawals_status_check;
+ALTER TABLE withdrawals ADD CONSTRAINT withdrawals_status_check
+    CHECK (status IN ('requested', 'held', 'approved', 'sent', 'settled', 'failed', 'returned', 'cancelled'));
@@ -0,0 +1,4 @@
+-- Выплата, завершённая провайдером без действующей блокировки средств, ждёт разбора администратором.
+ALTER TABLE withdrawals DROP CONSTRAINT withdrawals_status_check;
+ALTER TABLE withdrawals ADD CONSTRAINT withdrawals_status_check
+    CHECK (status IN ('requested', 'held', 'approved', 'sent', 'settled', 'failed', 'returned', 'cancelled', 'manual_review'));
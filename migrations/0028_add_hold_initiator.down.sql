@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_holds_initiated_by;
+ALTER TABLE holds DROP COLUMN IF EXISTS initiated_by;
@@ -0,0 +1,6 @@
+-- Пользователь, создавший блокировку. Действующие блокировки участника с ролью spender
+-- учитываются в его лимите списаний вместе с проведёнными списаниями.
+ALTER TABLE holds ADD COLUMN initiated_by INT REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_holds_initiated_by ON holds (wallet_id, initiated_by)
+    WHERE initiated_by IS NOT NULL AND status = 'active';
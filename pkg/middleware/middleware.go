@@ -3,6 +3,7 @@ package middleware
 import (
 	"strings"
 
+	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/tenant"
 	"github.com/VadimBorzenkov/gw-currency-wallet/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 )
@@ -30,8 +31,33 @@ func AuthMiddleware(tokenManager utils.TokenManager) fiber.Handler {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token: " + err.Error()})
 		}
 
+		// Токены, выданные до появления тенантов, принадлежат тенанту по умолчанию
+		if claims.TenantID == 0 {
+			claims.TenantID = tenant.DefaultID
+		}
+		if claims.TenantID != tenant.ID(c.UserContext()) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token issued for another tenant"})
+		}
+
 		c.Locals(claimsKey, claims)
 
 		return c.Next()
 	}
 }
+
+// accessTokenQuery - параметр запроса с токеном для клиентов, которые не могут передать
+// заголовок Authorization (EventSource и WebSocket в браузере).
+const accessTokenQuery = "access_token"
+
+// QueryTokenMiddleware переносит токен из параметра access_token в заголовок Authorization,
+// если заголовок не задан. Подключается перед AuthMiddleware только на маршрутах потока обновлений.
+func QueryTokenMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get(authorizationHeader) == "" {
+			if token := c.Query(accessTokenQuery); token != "" {
+				c.Request().Header.Set(authorizationHeader, bearerPrefix+token)
+			}
+		}
+		return c.Next()
+	}
+}
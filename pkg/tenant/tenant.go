@@ -0,0 +1,38 @@
+// Package tenant передаёт идентификатор тенанта (бренда) через context.Context
+// от HTTP-слоя до репозитория.
+package tenant
+
+import "context"
+
+// DefaultID - тенант, которому принадлежат данные, созданные до появления тенантов,
+// и запросы, для которых тенант не указан.
+const DefaultID uint64 = 1
+
+type contextKey struct{}
+
+// WithID возвращает контекст, привязанный к тенанту id.
+func WithID(ctx context.Context, id uint64) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext возвращает тенант контекста. ok == false означает системный контекст
+// (фоновые задачи, утилиты), в котором данные всех тенантов доступны без фильтрации.
+func FromContext(ctx context.Context) (id uint64, ok bool) {
+	id, ok = ctx.Value(contextKey{}).(uint64)
+	return id, ok
+}
+
+// ID возвращает тенант контекста или DefaultID для системного контекста.
+// Используется там, где запись всегда принадлежит одному тенанту (создание, поиск по логину).
+func ID(ctx context.Context) uint64 {
+	if id, ok := FromContext(ctx); ok {
+		return id
+	}
+	return DefaultID
+}
+
+// Filter возвращает тенант для условия "$n = 0 OR tenant_id = $n": 0 в системном контексте.
+func Filter(ctx context.Context) uint64 {
+	id, _ := FromContext(ctx)
+	return id
+}
@@ -0,0 +1,24 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, DefaultID, ID(ctx))
+	assert.Equal(t, uint64(0), Filter(ctx))
+
+	ctx = WithID(ctx, 7)
+	id, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(7), id)
+	assert.Equal(t, uint64(7), ID(ctx))
+	assert.Equal(t, uint64(7), Filter(ctx))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -22,20 +23,30 @@ type Claims struct {
 	Email     string `json:"sub"`
 	IPAddress string `json:"ip"`
 	TokenID   string `json:"tip"`
+	TenantID  uint64 `json:"tid"`
 	jwt.StandardClaims
 }
 
 type TokenManager interface {
-	NewJWT(userId uint64, email, ipAddress, tokenID string) (string, error)
+	NewJWT(userId, tenantID uint64, email, ipAddress, tokenID string) (string, error)
 	ParseJWT(accessToken string) (*Claims, error)
 	HashPassword(password string) (string, error)
 	ValidatePassword(password, hashedPassword string) error
 	GetAccessTTL() time.Duration
 }
 
+// KeyPair - пути к PEM-файлам ключей подписи JWT.
+type KeyPair struct {
+	PublicKey  string
+	PrivateKey string
+}
+
+// Manager подписывает токены ключами тенанта из TenantKeys, а если они
+// для тенанта не заданы - ключами PublicKey/PrivateKey.
 type Manager struct {
 	PublicKey  string
 	PrivateKey string
+	TenantKeys map[uint64]KeyPair
 	AccessTTL  time.Duration
 }
 
@@ -43,24 +54,36 @@ func NewManager(cfg Config) Manager {
 	return Manager{
 		PublicKey:  cfg.GetAuthJWTPublicKeyPath(),
 		PrivateKey: cfg.GetAuthJWTPrivateKeyPath(),
+		TenantKeys: make(map[uint64]KeyPair),
 		AccessTTL:  cfg.GetAccessTokenExpiration(),
 	}
 }
 
-func (m *Manager) NewJWT(userId uint64, email, ipAddress, tokenID string) (string, error) {
+// keys возвращает пути к ключам тенанта.
+func (m *Manager) keys(tenantID uint64) KeyPair {
+	if keys, ok := m.TenantKeys[tenantID]; ok {
+		return keys
+	}
+	return KeyPair{PublicKey: m.PublicKey, PrivateKey: m.PrivateKey}
+}
+
+func (m *Manager) NewJWT(userId, tenantID uint64, email, ipAddress, tokenID string) (string, error) {
 	claims := Claims{
 		UserID:    userId,
 		Email:     email,
 		IPAddress: ipAddress,
 		TokenID:   tokenID,
+		TenantID:  tenantID,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(m.AccessTTL).Unix(),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	// kid указывает, ключом какого тенанта подписан токен
+	token.Header["kid"] = strconv.FormatUint(tenantID, 10)
 
-	privateKeyData, err := os.ReadFile(m.PrivateKey)
+	privateKeyData, err := os.ReadFile(m.keys(tenantID).PrivateKey)
 	if err != nil {
 		log.Fatalf("could not read private key file: %v", err)
 	}
@@ -81,7 +104,12 @@ func (m *Manager) ParseJWT(accessToken string) (*Claims, error) {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
-		publicKeyData, err := os.ReadFile(m.PublicKey)
+		// Токен проверяется ключом тенанта, которому он выдан
+		claims, ok := token.Claims.(*Claims)
+		if !ok {
+			return nil, fmt.Errorf("invalid token claims")
+		}
+		publicKeyData, err := os.ReadFile(m.keys(claims.TenantID).PublicKey)
 		if err != nil {
 			return nil, fmt.Errorf("could not read private key file: %v", err)
 		}
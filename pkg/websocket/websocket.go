@@ -0,0 +1,234 @@
+// Package websocket реализует серверную сторону протокола WebSocket (RFC 6455) для Fiber
+// в объёме, нужном потоку обновлений: рукопожатие, текстовые сообщения, ping/pong и закрытие.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// acceptGUID - строка из RFC 6455, из которой вместе с ключом клиента вычисляется ответ рукопожатия.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Коды операций кадров.
+const (
+	opContinuation byte = 0x0
+	opText         byte = 0x1
+	opBinary       byte = 0x2
+	opClose        byte = 0x8
+	opPing         byte = 0x9
+	opPong         byte = 0xA
+)
+
+// CloseNormal - код нормального закрытия соединения.
+const CloseNormal = 1000
+
+// MaxMessageSize - максимальный размер сообщения клиента в байтах.
+const MaxMessageSize = 64 << 10
+
+// writeTimeout ограничивает запись одного кадра, чтобы зависший клиент не блокировал сервер.
+const writeTimeout = 10 * time.Second
+
+var (
+	ErrBadHandshake    = errors.New("websocket: bad handshake")
+	ErrProtocol        = errors.New("websocket: protocol error")
+	ErrMessageTooLarge = errors.New("websocket: message too large")
+)
+
+// IsUpgrade сообщает, запрашивает ли клиент переход на WebSocket версии 13.
+func IsUpgrade(c *fiber.Ctx) bool {
+	return strings.EqualFold(c.Get(fiber.HeaderUpgrade), "websocket") &&
+		headerContains(c.Get(fiber.HeaderConnection), "upgrade") &&
+		c.Get(fiber.HeaderSecWebSocketVersion) == "13" &&
+		c.Get(fiber.HeaderSecWebSocketKey) != ""
+}
+
+// AcceptKey вычисляет значение заголовка Sec-WebSocket-Accept для ключа клиента.
+func AcceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Upgrade отвечает на рукопожатие и после отправки ответа передаёт соединение handler.
+// Соединение закрывается, когда handler возвращает управление.
+func Upgrade(c *fiber.Ctx, handler func(conn *Conn)) error {
+	if !IsUpgrade(c) {
+		return ErrBadHandshake
+	}
+
+	c.Set(fiber.HeaderUpgrade, "websocket")
+	c.Set(fiber.HeaderConnection, "Upgrade")
+	c.Set(fiber.HeaderSecWebSocketAccept, AcceptKey(c.Get(fiber.HeaderSecWebSocketKey)))
+	c.Status(fiber.StatusSwitchingProtocols)
+
+	c.Context().Hijack(func(netConn net.Conn) {
+		handler(&Conn{conn: netConn, reader: bufio.NewReader(netConn)})
+	})
+	return nil
+}
+
+// Conn - соединение WebSocket на стороне сервера. Запись безопасна из нескольких горутин,
+// чтение - только из одной.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	mu     sync.Mutex
+}
+
+// WriteText отправляет текстовое сообщение.
+func (c *Conn) WriteText(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// Ping отправляет кадр ping; клиент отвечает на него pong.
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close отправляет кадр закрытия с кодом code и закрывает соединение.
+func (c *Conn) Close(code int) error {
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	_ = c.writeFrame(opClose, payload)
+	return c.conn.Close()
+}
+
+// ReadMessage возвращает следующее текстовое или двоичное сообщение клиента, собирая его из
+// фрагментов. На ping отвечает pong; при получении кадра закрытия возвращает io.EOF.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	started := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			_ = c.writeFrame(opClose, payload)
+			return nil, io.EOF
+		case opText, opBinary:
+			if started {
+				return nil, ErrProtocol
+			}
+			started = true
+		case opContinuation:
+			if !started {
+				return nil, ErrProtocol
+			}
+		default:
+			return nil, ErrProtocol
+		}
+
+		if len(message)+len(payload) > MaxMessageSize {
+			return nil, ErrMessageTooLarge
+		}
+		message = append(message, payload...)
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// readFrame читает один кадр клиента. Кадры клиента всегда маскированы.
+func (c *Conn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	if header[0]&0x70 != 0 || header[1]&0x80 == 0 {
+		return false, 0, nil, ErrProtocol
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	// Управляющие кадры не фрагментируются и не длиннее 125 байт
+	if opcode >= opClose && (!fin || length > 125) {
+		return false, 0, nil, ErrProtocol
+	}
+	if length > MaxMessageSize {
+		return false, 0, nil, ErrMessageTooLarge
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame отправляет неразделённый немаскированный кадр, как положено серверу.
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|opcode)
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	frame = append(frame, payload...)
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// headerContains сообщает, содержит ли список значений заголовка через запятую значение token.
+func headerContains(header, token string) bool {
+	for _, value := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(value), token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Пример из RFC 6455, раздел 1.3
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestUpgradeEcho(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws", func(c *fiber.Ctx) error {
+		if !IsUpgrade(c) {
+			return c.SendStatus(fiber.StatusUpgradeRequired)
+		}
+		return Upgrade(c, func(conn *Conn) {
+			defer conn.Close(CloseNormal)
+			if err := conn.WriteText([]byte("hello")); err != nil {
+				return
+			}
+			for {
+				message, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				if err := conn.WriteText(message); err != nil {
+					return
+				}
+			}
+		})
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() { _ = app.Listener(listener) }()
+	defer func() { _ = app.Shutdown() }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = io.WriteString(conn, "GET /ws HTTP/1.1\r\n"+
+		"Host: localhost\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	assert.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, response.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", response.Header.Get("Sec-WebSocket-Accept"))
+
+	opcode, payload := readServerFrame(t, reader)
+	assert.Equal(t, opText, opcode)
+	assert.Equal(t, "hello", string(payload))
+
+	// Сообщение из двух фрагментов и ping между ними
+	writeClientFrame(t, conn, false, opText, []byte("wal"))
+	writeClientFrame(t, conn, true, opPing, []byte("p"))
+	writeClientFrame(t, conn, true, opContinuation, []byte("let"))
+
+	opcode, payload = readServerFrame(t, reader)
+	assert.Equal(t, opPong, opcode)
+	assert.Equal(t, "p", string(payload))
+	opcode, payload = readServerFrame(t, reader)
+	assert.Equal(t, opText, opcode)
+	assert.Equal(t, "wallet", string(payload))
+
+	writeClientFrame(t, conn, true, opClose, []byte{0x03, 0xE8})
+	opcode, _ = readServerFrame(t, reader)
+	assert.Equal(t, opClose, opcode)
+}
+
+func TestIsUpgradeRequiresHeaders(t *testing.T) {
+	app := fiber.New()
+	app.Get("/ws", func(c *fiber.Ctx) error {
+		if err := Upgrade(c, func(*Conn) {}); err != nil {
+			return c.SendStatus(fiber.StatusUpgradeRequired)
+		}
+		return nil
+	})
+
+	request, err := http.NewRequest(http.MethodGet, "/ws", nil)
+	assert.NoError(t, err)
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Connection", "Upgrade")
+
+	response, err := app.Test(request)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusUpgradeRequired, response.StatusCode)
+}
+
+func readServerFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	t.Helper()
+
+	var header [2]byte
+	_, err := io.ReadFull(reader, header[:])
+	assert.NoError(t, err)
+	assert.Zero(t, header[1]&0x80, "server frames must not be masked")
+
+	length := int(header[1] & 0x7F)
+	assert.Less(t, length, 126)
+	payload := make([]byte, length)
+	_, err = io.ReadFull(reader, payload)
+	assert.NoError(t, err)
+	return header[0] & 0x0F, payload
+}
+
+func writeClientFrame(t *testing.T, conn net.Conn, fin bool, opcode byte, payload []byte) {
+	t.Helper()
+
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{first, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := conn.Write(frame)
+	assert.NoError(t, err)
+}